@@ -101,7 +101,21 @@
       });
   }
 
-  function initGraph(globalContainer, localContainer, localContainerWrapper) {
+  // Per-page local graph data, keyed by URL
+  const localDataCache = {};
+
+  // Drops links pointing to nodes that are not part of the data
+  function prepareData(data) {
+    data.nodes = data.nodes || [];
+    data.links = data.links || [];
+    const nodeIds = new Set(data.nodes.map((n) => n.id));
+    data.links = data.links.filter(
+      (l) => nodeIds.has(l.source) && nodeIds.has(l.target),
+    );
+    return data;
+  }
+
+  function loadGlobalData() {
     // Initialize cache if empty
     if (!graphDataPromise) {
       // Use the injected BaseURL for fetching
@@ -110,26 +124,7 @@
           if (!res.ok) throw new Error(`HTTP error! status: ${res.status}`);
           return res.json();
         })
-        .then((data) => {
-          // Pre-process: Filter out links pointing to non-existent nodes
-          const nodeIds = new Set(data.nodes.map((n) => n.id));
-          data.links = data.links.filter(
-            (l) => nodeIds.has(l.source) && nodeIds.has(l.target),
-          );
-
-          // Calculate Degrees (Connections per node)
-          const degreeMap = {};
-          data.links.forEach((l) => {
-            degreeMap[l.source] = (degreeMap[l.source] || 0) + 1;
-            degreeMap[l.target] = (degreeMap[l.target] || 0) + 1;
-          });
-
-          data.nodes.forEach((n) => {
-            n.degree = degreeMap[n.id] || 0;
-          });
-
-          return data;
-        })
+        .then(prepareData)
         .catch((err) => {
           console.error("Graph loading failed:", err);
           graphDataPromise = null;
@@ -138,30 +133,62 @@
           }
         });
     }
+    return graphDataPromise;
+  }
 
-    graphDataPromise.then((data) => {
-      if (!data) return;
+  // Loads the local graph of the current page. Uses the pre-computed file when
+  // available, otherwise filters the global graph.
+  function loadLocalData() {
+    const pageTitleEl = document.getElementById("page-title-data");
+    if (!pageTitleEl) return Promise.resolve(null);
+
+    const url = pageTitleEl.dataset.graph;
+    if (!url) {
+      return loadGlobalData().then((data) =>
+        data
+          ? filterLocalData(
+              JSON.parse(JSON.stringify(data)),
+              pageTitleEl.dataset.title,
+            )
+          : null,
+      );
+    }
+
+    if (!localDataCache[url]) {
+      localDataCache[url] = fetch(url)
+        .then((res) => {
+          if (!res.ok) throw new Error(`HTTP error! status: ${res.status}`);
+          return res.json();
+        })
+        .then(prepareData)
+        .catch(() => {
+          // Pages hidden by the graph filters have no local graph
+          delete localDataCache[url];
+          return null;
+        });
+    }
+    return localDataCache[url].then((data) =>
+      data ? JSON.parse(JSON.stringify(data)) : null,
+    );
+  }
 
-      if (globalContainer) {
+  function initGraph(globalContainer, localContainer, localContainerWrapper) {
+    if (globalContainer) {
+      loadGlobalData().then((data) => {
+        if (!data) return;
         renderGraph(globalContainer, JSON.parse(JSON.stringify(data)), false);
-      }
-      if (localContainer) {
-        const pageTitleEl = document.getElementById("page-title-data");
-        if (pageTitleEl) {
-          const currentTitle = pageTitleEl.dataset.title;
-          const localData = filterLocalData(
-            JSON.parse(JSON.stringify(data)),
-            currentTitle,
-          );
-          if (localData.nodes.length > 0) {
-            localContainerWrapper.style.display = "";
-            renderGraph(localContainer, localData, true);
-          } else {
-            localContainerWrapper.style.display = "none";
-          }
+      });
+    }
+    if (localContainer) {
+      loadLocalData().then((localData) => {
+        if (localData && localData.nodes.length > 0) {
+          localContainerWrapper.style.display = "";
+          renderGraph(localContainer, localData, true);
+        } else {
+          localContainerWrapper.style.display = "none";
         }
-      }
-    });
+      });
+    }
   }
 
   function filterLocalData(data, currentId) {
@@ -202,6 +229,21 @@
     const assetNodeColor =
       style.getPropertyValue("--color-red").trim() || "#FF0000";
 
+    // Color groups from kiln.yaml can be palette names or hex colors
+    const nodeColor = (d) => {
+      if (d.color) {
+        if (d.color.startsWith("#")) return d.color;
+        return (
+          style.getPropertyValue("--color-" + d.color).trim() || d.color
+        );
+      }
+      return d.type == "folder"
+        ? folderNodeColor
+        : d.type == ".md" || d.type == ".canvas" || d.type == ".base"
+          ? neutralNodeColor
+          : assetNodeColor;
+    };
+
     container.innerHTML = "";
 
     const zoom = d3
@@ -222,8 +264,8 @@
 
     const svgGroup = svg.append("g");
 
-    // Helper to calculate radius
-    const getNodeRadius = (d) => 4 + Math.sqrt(d.degree || 0) * 2;
+    // Helper to calculate radius, val is computed at build time (degree or PageRank)
+    const getNodeRadius = (d) => 4 + Math.sqrt(d.val ?? d.degree ?? 0) * 2;
 
     // Force Simulation
     const simulation = d3
@@ -278,13 +320,7 @@
     const node = nodeGroup
       .append("circle")
       .attr("r", (d) => getNodeRadius(d))
-      .attr("fill", (d) =>
-        d.type == "folder"
-          ? folderNodeColor
-          : d.type == ".md" || d.type == ".canvas" || d.type == ".base"
-            ? neutralNodeColor
-            : assetNodeColor,
-      )
+      .attr("fill", nodeColor)
       .call(drag(simulation));
 
     const label = svgGroup
@@ -327,14 +363,7 @@
         const currentR = getNodeRadius(d);
         d3.select(this)
           .attr("r", currentR)
-          .attr(
-            "fill",
-            d.type == "folder"
-              ? folderNodeColor
-              : d.type == ".md" || d.type == ".canvas" || d.type == ".base"
-                ? neutralNodeColor
-                : assetNodeColor,
-          );
+          .attr("fill", nodeColor(d));
 
         link.style("stroke", neutralLinkColor);
         link.style("stroke-opacity", 0.6);
@@ -400,18 +429,9 @@
   window.addEventListener("graph-overlay-opened", function () {
     var modalContainer = document.getElementById("graph-modal-container");
     if (!modalContainer) return;
-    if (!graphDataPromise) return;
-
-    graphDataPromise.then(function (data) {
-      if (!data) return;
-      var pageTitleEl = document.getElementById("page-title-data");
-      if (!pageTitleEl) return;
-      var currentTitle = pageTitleEl.dataset.title;
-      var localData = filterLocalData(
-        JSON.parse(JSON.stringify(data)),
-        currentTitle,
-      );
-      if (localData.nodes.length > 0) {
+
+    loadLocalData().then(function (localData) {
+      if (localData && localData.nodes.length > 0) {
         renderGraph(modalContainer, localData, true);
       }
     });
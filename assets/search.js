@@ -1,20 +1,30 @@
 // @feature:search Client-side full-text search with inverted index and modal overlay results.
 (function () {
   var BASE_URL = "{{.BaseURL}}";
+  var DEFAULT_LANG = "{{.Lang}}";
   var MAX_RESULTS = 15;
   var SNIPPET_LEN = 120;
 
   var invertedIndex = null;
   var indexEntries = null;
 
+  // Multilingual sites have an index per language, search-index.<lang>.json
+  // for the languages other than the default one.
+  function indexFile(lang) {
+    if (!lang || lang === DEFAULT_LANG) return "/search-index.json";
+    return "/search-index." + lang + ".json";
+  }
+
   function fetchIndex() {
-    if (window._searchIndex) {
-      return Promise.resolve(window._searchIndex);
+    var lang = document.documentElement.lang;
+    window._searchIndexes = window._searchIndexes || {};
+    if (window._searchIndexes[lang]) {
+      return Promise.resolve(window._searchIndexes[lang]);
     }
-    return fetch(BASE_URL + "/search-index.json")
+    return fetch(BASE_URL + indexFile(lang))
       .then(function (res) { return res.json(); })
       .then(function (data) {
-        window._searchIndex = data;
+        window._searchIndexes[lang] = data;
         return data;
       });
   }
@@ -237,6 +247,7 @@
     fetchIndex().then(function (data) {
       indexEntries = data;
       invertedIndex = buildInvertedIndex(data);
+      openFromQuery();
     });
 
     var overlay = getOverlay();
@@ -272,6 +283,17 @@
     });
   };
 
+  // Opens the search with the "q" parameter of the URL, the target of the
+  // SearchAction in the structured data of the home page.
+  function openFromQuery() {
+    var query = new URLSearchParams(window.location.search).get("q");
+    if (!query) return;
+    showOverlay();
+    var input = document.getElementById("search-modal-input");
+    input.value = query;
+    showResults(searchEntries(query.trim()), query.trim());
+  }
+
   window.openSearchModal = function () {
     showOverlay();
   };
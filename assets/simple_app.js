@@ -188,8 +188,8 @@ window.changeGiscusTheme = function () {
   // Now 'target' holds the correct theme name ("dark" or "light")
   const themeUrl =
     target === "dark"
-      ? "{{.BaseURL}}/giscus-theme-dark.css"
-      : "{{.BaseURL}}/giscus-theme-light.css";
+      ? "{{.Asset "giscus-theme-dark.css"}}"
+      : "{{.Asset "giscus-theme-light.css"}}";
 
   iframe.contentWindow.postMessage(
     {
@@ -1,7 +1,10 @@
 // Generates PNG palette previews for theme documentation. @feature:palette
 // kiln-palette is a utility used to generate PNG preview of a theme palette for the documentation.
+// Themes defined in kiln.yaml or in the _themes folder of the vault can be previewed too.
 //
 // Usage: go run ./cmd/kiln-palette/main.go --theme "dracula"
+//
+//	go run ./cmd/kiln-palette/main.go --theme "brand" --config ./kiln.yaml --input ./vault
 package main
 
 import (
@@ -16,7 +19,9 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/otaleghani/kiln/internal/appearance"
 	"github.com/otaleghani/kiln/internal/builder"
+	"github.com/otaleghani/kiln/internal/config"
 	"github.com/spf13/cobra"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
@@ -27,9 +32,19 @@ const (
 	FlagTheme        = "theme"
 	FlagThemeShort   = "t"
 	DefaultThemeName = "default"
+
+	FlagConfig      = "config"
+	FlagConfigShort = "c"
+	FlagInput       = "input"
+	FlagInputShort  = "i"
+	DefaultInputDir = "./vault"
 )
 
-var themeName string
+var (
+	themeName  string
+	configPath string
+	inputDir   string
+)
 
 func main() {
 	var cmdGenerate = &cobra.Command{
@@ -42,6 +57,10 @@ func main() {
 
 	cmdGenerate.Flags().
 		StringVarP(&themeName, FlagTheme, FlagThemeShort, DefaultThemeName, "Color theme (default, dracula, catppuccin, nord)")
+	cmdGenerate.Flags().
+		StringVarP(&configPath, FlagConfig, FlagConfigShort, config.DefaultFilename, "Config file defining custom themes")
+	cmdGenerate.Flags().
+		StringVarP(&inputDir, FlagInput, FlagInputShort, "", "Vault holding custom themes in its _themes folder (defaults to the input of the config file, or ./vault)")
 
 	if err := cmdGenerate.Execute(); err != nil {
 		log.Fatal(err)
@@ -53,14 +72,16 @@ func runGenerate() {
 	handler := log.New(os.Stderr)
 	handler.SetReportTimestamp(true)
 	handler.SetFormatter(log.TextFormatter)
-	theme := builder.ResolveTheme(themeName, "", "", slog.New(handler))
+	logger := slog.New(handler)
+	registerCustomThemes(logger)
+	theme := builder.ResolveTheme(themeName, "", "", logger)
 	handler.Info("Generating palette for theme", "name", themeName)
 
 	// Setup Image Canvas
 	// Width: 800 (400 Light | 400 Dark)
 	// Height: Header + (Rows * RowHeight)
 	const (
-		width     = 500
+		width     = 560
 		rowHeight = 60
 		headerH   = 60
 		colWidth  = width / 2
@@ -142,7 +163,7 @@ func runGenerate() {
 		drawBorder(img, padding, y, 40, 40, color.Gray{Y: 128})
 		// Draw Text
 		labelColor := parseHex(theme.Light.Text)
-		drawLabel(padding+50, y+25, fmt.Sprintf("%-14s %s", fieldName, hexLight), labelColor)
+		drawLabel(padding+50, y+25, fmt.Sprintf("%-14s %s%s", fieldName, hexLight, contrastLabel(fieldName, hexLight, theme.Light.Bg)), labelColor)
 
 		// Dark Column
 		hexDark := vDark.FieldByName(fieldName).String()
@@ -162,7 +183,7 @@ func runGenerate() {
 		drawLabel(
 			colWidth+padding+50,
 			y+25,
-			fmt.Sprintf("%-14s %s", fieldName, hexDark),
+			fmt.Sprintf("%-14s %s%s", fieldName, hexDark, contrastLabel(fieldName, hexDark, theme.Dark.Bg)),
 			labelColorDark,
 		)
 	}
@@ -183,6 +204,39 @@ func runGenerate() {
 }
 
 // Helpers
+// registerCustomThemes registers the themes and fonts of the config file and
+// of the vault, so that they can be previewed like the built-in ones.
+func registerCustomThemes(logger *slog.Logger) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	dir := inputDir
+	if dir == "" {
+		dir = cfg.ValueOr("input", DefaultInputDir)
+	}
+	builder.RegisterAppearance(dir, cfg.Appearance, logger)
+}
+
+// contrastLabel returns the contrast ratio of the text colors with the
+// background, marked when failing WCAG AA.
+func contrastLabel(fieldName, hex, bg string) string {
+	if fieldName != "Text" && fieldName != "Accent" && fieldName != "Comment" {
+		return ""
+	}
+	ratio, ok := appearance.ContrastRatio(hex, bg)
+	if !ok {
+		return ""
+	}
+	if ratio < appearance.MinContrast {
+		return fmt.Sprintf(" %4.1f!", ratio)
+	}
+	return fmt.Sprintf(" %4.1f", ratio)
+}
+
 // parseHex converts a hex string (e.g. "#ffffff") to color.RGBA
 func parseHex(s string) color.RGBA {
 	c := color.RGBA{A: 255}
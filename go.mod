@@ -4,6 +4,7 @@ go 1.25.4
 
 require (
 	github.com/alecthomas/chroma/v2 v2.2.0
+	github.com/andybalholm/brotli v1.1.0
 	github.com/charmbracelet/log v0.4.2
 	github.com/litao91/goldmark-mathjax v0.0.0-20210217064022-a43cf739a50f
 	github.com/spf13/cobra v1.10.1
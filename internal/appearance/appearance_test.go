@@ -0,0 +1,102 @@
+// @feature:themes Tests for the theme files, the validation of the user values and the contrast checker.
+package appearance
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"#000000", "#ffffff", 21},
+		{"#fff", "#fff", 1},
+		{"#777777", "#ffffff", 4.48},
+		{"#ffffff", "#767676", 4.54},
+	}
+	for _, tt := range tests {
+		got, ok := ContrastRatio(tt.a, tt.b)
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("ContrastRatio(%q, %q) = %.2f, %v, want %.2f", tt.a, tt.b, got, ok, tt.want)
+		}
+	}
+	if _, ok := ContrastRatio("red", "#fff"); ok {
+		t.Error("ContrastRatio accepted a colour name")
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	issues := CheckContrast(map[string]string{
+		"bg": "#ffffff", "text": "#111111", "sidebar-bg": "#f5f5f5", "hover": "#eeeeee",
+		"accent": "#9ca3af", "comment": "#595959",
+	})
+	if len(issues) != 2 {
+		t.Fatalf("issues = %+v, want accent on bg and sidebar-bg", issues)
+	}
+	for _, issue := range issues {
+		if issue.Foreground != "accent" || issue.Ratio >= MinContrast {
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, Dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Brand.yaml": "extends: nord\nlight:\n  accent: \"#0055aa\"\n",
+		"ocean.yml":  "light:\n  bg: \"#001122\"\n",
+		"notes.txt":  "not a theme",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, Dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defs := LoadDefinitions(dir, Options{Themes: map[string]Definition{
+		"ocean": {Extends: "dracula"},
+	}}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	if len(defs) != 2 {
+		t.Fatalf("definitions = %v, want brand and ocean", defs)
+	}
+	if defs["brand"].Extends != "nord" || defs["brand"].Light["accent"] != "#0055aa" {
+		t.Errorf("brand = %+v", defs["brand"])
+	}
+	if defs["ocean"].Extends != "dracula" || defs["ocean"].Light != nil {
+		t.Errorf("ocean = %+v, want the kiln.yaml theme", defs["ocean"])
+	}
+}
+
+func TestCheckValues(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	colors := CheckColors(map[string]string{
+		"bg": "#fff", "text": "", "accent": "blue", "link": "#000",
+	}, log)
+	if len(colors) != 1 || colors["bg"] != "#fff" {
+		t.Errorf("colors = %v, want bg only", colors)
+	}
+	vars := CheckVars(map[string]string{
+		"--sidebar-width": "300px", "font-size": "18px", "--bad": "1px; } body {",
+	}, log)
+	if len(vars) != 1 || vars["--sidebar-width"] != "300px" {
+		t.Errorf("vars = %v, want --sidebar-width only", vars)
+	}
+
+	for _, want := range []string{"Empty theme colour", "isn't a hex colour", "Unknown theme colour", "font-size", "Invalid CSS variable value"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected the logs to contain %q, got:\n%s", want, logs.String())
+		}
+	}
+}
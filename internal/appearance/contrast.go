@@ -0,0 +1,105 @@
+// @feature:themes WCAG contrast ratios of the text and background colours of a theme.
+package appearance
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinContrast is the contrast ratio WCAG AA requires for normal text.
+const MinContrast = 4.5
+
+// Pair is a text colour shown on a background colour, by colour key.
+type Pair struct {
+	Foreground string
+	Background string
+}
+
+// Pairs are the colour pairs of the UI holding text.
+var Pairs = []Pair{
+	{"text", "bg"},
+	{"text", "sidebar-bg"},
+	{"text", "hover"},
+	{"accent", "bg"},
+	{"accent", "sidebar-bg"},
+	{"comment", "bg"},
+}
+
+// Issue is a colour pair whose contrast is below MinContrast.
+type Issue struct {
+	Pair
+	Ratio float64
+}
+
+// CheckContrast returns the pairs of a theme mode, colours keyed as in
+// ColorKeys, failing WCAG AA. Pairs with a missing or invalid colour are
+// skipped.
+func CheckContrast(colors map[string]string) []Issue {
+	issues := []Issue{}
+	for _, p := range Pairs {
+		ratio, ok := ContrastRatio(colors[p.Foreground], colors[p.Background])
+		if ok && ratio < MinContrast {
+			issues = append(issues, Issue{Pair: p, Ratio: ratio})
+		}
+	}
+	return issues
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two hex colours, from 1
+// to 21. It reports false if either colour isn't a hex colour.
+func ContrastRatio(a, b string) (float64, bool) {
+	la, ok := luminance(a)
+	if !ok {
+		return 0, false
+	}
+	lb, ok := luminance(b)
+	if !ok {
+		return 0, false
+	}
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05), true
+}
+
+// IsHex reports whether s is a #rgb or #rrggbb colour.
+func IsHex(s string) bool {
+	_, _, _, ok := ParseHex(s)
+	return ok
+}
+
+// ParseHex returns the channels of a #rgb or #rrggbb colour.
+func ParseHex(s string) (r, g, b uint8, ok bool) {
+	s, found := strings.CutPrefix(strings.TrimSpace(s), "#")
+	if !found {
+		return 0, 0, 0, false
+	}
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// luminance returns the relative luminance of a hex colour.
+func luminance(hex string) (float64, bool) {
+	r, g, b, ok := ParseHex(hex)
+	if !ok {
+		return 0, false
+	}
+	channel := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b), true
+}
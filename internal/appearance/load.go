@@ -0,0 +1,95 @@
+// @feature:themes Theme files of the vault and validation of the user-defined values.
+package appearance
+
+import (
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is the folder of the vault holding the theme files, one <name>.yaml
+// file per theme.
+const Dir = "_themes"
+
+// ColorKeys are the names of the colours of a theme: the semantic colours of
+// the UI, then the palette.
+var ColorKeys = []string{
+	"bg", "text", "sidebar-bg", "sidebar-border", "accent", "hover", "comment",
+	"red", "orange", "yellow", "green", "blue", "purple", "cyan",
+}
+
+// varName matches the name of a CSS custom property.
+var varName = regexp.MustCompile(`^--[A-Za-z0-9_-]+$`)
+
+// LoadDefinitions returns the themes of the theme files in the _themes folder
+// of the vault and of the "themes" of the appearance section of kiln.yaml,
+// which win over the files.
+func LoadDefinitions(inputDir string, opts Options, log *slog.Logger) map[string]Definition {
+	defs := map[string]Definition{}
+	paths, _ := filepath.Glob(filepath.Join(inputDir, Dir, "*.y*ml"))
+	for _, path := range paths {
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn("Couldn't read theme file", "path", path, "error", err)
+			continue
+		}
+		var def Definition
+		if err := yaml.Unmarshal(content, &def); err != nil {
+			log.Warn("Couldn't parse theme file", "path", path, "error", err)
+			continue
+		}
+		defs[strings.ToLower(strings.TrimSuffix(filepath.Base(path), ext))] = def
+	}
+	for name, def := range opts.Themes {
+		defs[strings.ToLower(name)] = def
+	}
+	return defs
+}
+
+// CheckColors returns the valid colours of a theme mode, warning about
+// unknown names and values that aren't hex colours.
+func CheckColors(colors map[string]string, log *slog.Logger) map[string]string {
+	valid := map[string]string{}
+	for _, key := range slices.Sorted(maps.Keys(colors)) {
+		value := strings.TrimSpace(colors[key])
+		switch {
+		case !slices.Contains(ColorKeys, key):
+			log.Warn("Unknown theme colour", "key", key, "known", strings.Join(ColorKeys, ", "))
+		case value == "":
+			log.Warn("Empty theme colour, quote hex values as # starts a yaml comment", "key", key)
+		case !IsHex(value):
+			log.Warn("Theme colour isn't a hex colour", "key", key, "value", value)
+		default:
+			valid[key] = value
+		}
+	}
+	return valid
+}
+
+// CheckVars returns the valid CSS custom properties, warning about names not
+// starting with "--" and values that could end the style block.
+func CheckVars(vars map[string]string, log *slog.Logger) map[string]string {
+	valid := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		value := strings.TrimSpace(vars[name])
+		switch {
+		case !varName.MatchString(name):
+			log.Warn("CSS variable names start with -- and hold letters, digits, - and _", "name", name)
+		case value == "" || strings.ContainsAny(value, ";{}<>"):
+			log.Warn("Invalid CSS variable value", "name", name, "value", value)
+		default:
+			valid[name] = value
+		}
+	}
+	return valid
+}
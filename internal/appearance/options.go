@@ -0,0 +1,68 @@
+// @feature:themes User-defined themes, fonts and CSS variable overrides.
+package appearance
+
+// Options holds the themes and fonts defined by the user, on top of the ones
+// built into kiln. It maps to the "appearance" section of kiln.yaml.
+type Options struct {
+	Themes  map[string]Definition `yaml:"themes"`   // Themes by name, selected with the theme option
+	Fonts   map[string]Font       `yaml:"fonts"`    // Fonts by name, selected with the font option
+	CSSVars CSSVars               `yaml:"css-vars"` // CSS custom properties set on top of any theme
+}
+
+// Definition is a user-defined theme. Colours are hex values keyed by the
+// names in ColorKeys. Missing colours are taken from the theme it extends.
+type Definition struct {
+	Extends string            `yaml:"extends"`  // Built-in theme providing the missing colours, "default" when empty
+	Light   map[string]string `yaml:"light"`    // Colours of the light mode
+	Dark    map[string]string `yaml:"dark"`     // Colours of the dark mode
+	CSSVars CSSVars           `yaml:"css-vars"` // CSS custom properties set by the theme
+}
+
+// Font is a user-defined font, served from files of the vault.
+type Font struct {
+	Family   string     `yaml:"family"`   // Name of the font family, e.g. "Brand Sans"
+	Fallback string     `yaml:"fallback"` // Generic family used while the files load, sans-serif by default
+	Files    []FontFile `yaml:"files"`    // Web font files, one per weight and style
+	TTF      string     `yaml:"ttf"`      // Vault path of the TrueType file used by the Open Graph cards
+	TTFBold  string     `yaml:"ttf-bold"` // Vault path of the bold TrueType file of the card titles
+}
+
+// FontFile is a web font file of a user-defined font.
+type FontFile struct {
+	Path   string `yaml:"path"`   // Vault path of the file, woff2 preferred
+	Weight int    `yaml:"weight"` // Font weight, 400 by default
+	Style  string `yaml:"style"`  // normal or italic, normal by default
+}
+
+// CSSVars are CSS custom properties by name, such as "--sidebar-width".
+// Light ones are set on the root element, so they apply to the dark mode too
+// unless the dark ones or the colours of the dark mode override them.
+type CSSVars struct {
+	Light map[string]string `yaml:"light"`
+	Dark  map[string]string `yaml:"dark"`
+}
+
+// FallbackOrDefault returns the generic family of the font, sans-serif by
+// default.
+func (f Font) FallbackOrDefault() string {
+	if f.Fallback == "" {
+		return "sans-serif"
+	}
+	return f.Fallback
+}
+
+// WeightOrDefault returns the weight of the file, 400 by default.
+func (f FontFile) WeightOrDefault() int {
+	if f.Weight == 0 {
+		return 400
+	}
+	return f.Weight
+}
+
+// StyleOrDefault returns the style of the file, normal by default.
+func (f FontFile) StyleOrDefault() string {
+	if f.Style == "" {
+		return "normal"
+	}
+	return f.Style
+}
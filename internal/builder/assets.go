@@ -0,0 +1,205 @@
+// Stylesheets and scripts of the layout, minified and fingerprinted, and precompression of the output. @feature:builder
+package builder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	textTemplate "text/template"
+
+	"github.com/andybalholm/brotli"
+	"github.com/otaleghani/kiln/assets"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+)
+
+// AssetManifest is the name of the file mapping the names of the layout
+// assets to their fingerprinted names, e.g. "app.js" to "app.3f2a1c9b.js".
+const AssetManifest = "asset-manifest.json"
+
+// precompressMinSize is the size under which files aren't precompressed,
+// as the savings don't pay for the extra files.
+const precompressMinSize = 1024
+
+// brotliLevel is the quality of the precompressed brotli files. The best
+// one, 11, is many times slower for a few percent.
+const brotliLevel = 9
+
+// precompressExts are the extensions of the files precompressed after the
+// build.
+var precompressExts = map[string]bool{".html": true, ".css": true, ".js": true, ".json": true}
+
+// newMinifier returns the minifier of the pages, stylesheets and scripts.
+func newMinifier() *minify.M {
+	m := minify.New()
+	m.Add("text/html", &html.Minifier{
+		KeepDocumentTags: true,
+		KeepQuotes:       true,
+		KeepEndTags:      true,
+	})
+	m.AddFunc("text/css", css.Minify)
+	m.AddFuncRegexp(regexp.MustCompile(`^(application|text)/(x-)?(java|ecma)script$`), js.Minify)
+	return m
+}
+
+// Asset returns the URL of a layout asset, using its fingerprinted name once
+// written. Used by the script templates, e.g. {{.Asset "giscus-theme-dark.css"}}.
+func (s *DefaultSite) Asset(name string) string {
+	if fingerprinted, ok := s.Assets[name]; ok {
+		name = fingerprinted
+	}
+	return s.BaseURL + "/" + name
+}
+
+// writeAssets renders the stylesheets and scripts of the layout, minifies
+// them, writes them under a name holding the hash of their content and
+// writes the manifest. The giscus themes come before the scripts linking
+// to them.
+func (s *DefaultSite) writeAssets(log *slog.Logger) {
+	s.Assets = map[string]string{}
+	shared, err := assets.TemplateFS.ReadFile("shared.css")
+	if err != nil {
+		log.Error("Couldn't read 'shared.css'", "error", err)
+	}
+
+	for _, a := range []struct {
+		name     string
+		template *textTemplate.Template
+		content  []byte
+	}{
+		{name: "giscus-theme-light.css", template: s.Layout.CssGiscusLightTemplate},
+		{name: "giscus-theme-dark.css", template: s.Layout.CssGiscusDarkTemplate},
+		{name: "style.css", template: s.Layout.CssTemplate},
+		{name: "shared.css", content: shared},
+		{name: "app.js", template: s.Layout.JsTemplate},
+		{name: "graph.js", template: s.Layout.JsGraphTemplate},
+		{name: "canvas.js", template: s.Layout.JsCanvasTemplate},
+		{name: "search.js", template: s.Layout.JsSearchTemplate},
+		{name: "link-preview.js", template: s.Layout.JsLinkPreviewTemplate},
+	} {
+		content := a.content
+		if a.template != nil {
+			var buf bytes.Buffer
+			if err := a.template.Execute(&buf, s); err != nil {
+				log.Error("Couldn't execute template for '"+a.name+"'", "error", err)
+				continue
+			}
+			content = buf.Bytes()
+		}
+		if err := s.writeAsset(a.name, content); err != nil {
+			log.Error("Couldn't write '"+a.name+"'", "error", err)
+		}
+	}
+
+	manifest, err := json.MarshalIndent(s.Assets, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(OutputDir, AssetManifest), manifest, 0644)
+	}
+	if err != nil {
+		log.Error("Couldn't write '"+AssetManifest+"'", "error", err)
+	}
+}
+
+// writeAsset minifies an asset and writes it under its fingerprinted name.
+func (s *DefaultSite) writeAsset(name string, content []byte) error {
+	if s.Minifier != nil {
+		mediaType, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(name)), ";")
+		if minified, err := s.Minifier.Bytes(mediaType, content); err == nil {
+			content = minified
+		} else {
+			s.log.Warn("Couldn't minify asset, keeping it as is", "asset", name, "error", err)
+		}
+	}
+	fingerprinted := fingerprint(name, content)
+	if err := os.WriteFile(filepath.Join(OutputDir, fingerprinted), content, 0644); err != nil {
+		return err
+	}
+	s.Assets[name] = fingerprinted
+	return nil
+}
+
+// fingerprint inserts the first characters of the hash of the content in the
+// name of a file, before its extension.
+func fingerprint(name string, content []byte) string {
+	sum := sha256.Sum256(content)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:4]) + ext
+}
+
+// precompress writes the brotli and gzip versions of the HTML, CSS, JS and
+// JSON files of the directory next to them, e.g. "app.js.br" and
+// "app.js.gz", for the hosts and servers that serve them as is.
+func precompress(dir string, log *slog.Logger) {
+	files := []string{}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !precompressExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() >= precompressMinSize {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				if err := precompressFile(path); err != nil {
+					log.Warn("Couldn't precompress file", "file", path, "error", err)
+				}
+			}
+		}()
+	}
+	for _, path := range files {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+	log.Info("Precompressed files", "amount", len(files))
+}
+
+// precompressFile writes the brotli and gzip versions of a file.
+func precompressFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for ext, newWriter := range map[string]func(io.Writer) io.WriteCloser{
+		".br": func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotliLevel) },
+		".gz": func(w io.Writer) io.WriteCloser {
+			zw, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+			return zw
+		},
+	} {
+		var buf bytes.Buffer
+		zw := newWriter(&buf)
+		if _, err := zw.Write(content); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path+ext, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
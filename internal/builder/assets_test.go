@@ -0,0 +1,136 @@
+// Tests for the fingerprinted assets and the precompressed files. @feature:builder
+package builder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	textTemplate "text/template"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestWriteAssets(t *testing.T) {
+	out := t.TempDir()
+	prev := OutputDir
+	OutputDir = out
+	t.Cleanup(func() { OutputDir = prev })
+
+	tmpl := func(s string) *textTemplate.Template { return textTemplate.Must(textTemplate.New("").Parse(s)) }
+	css := tmpl("body {\n  color : red ;\n}\n")
+	js := tmpl(`const theme = "{{.Asset "giscus-theme-dark.css"}}";` + "\n" + `function  add ( a, b ) { return a + b ; }`)
+	site := &DefaultSite{
+		BaseURL:  "https://example.com/notes",
+		Minifier: newMinifier(),
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Layout: &Layout{
+			CssTemplate:            css,
+			JsTemplate:             js,
+			JsGraphTemplate:        js,
+			JsCanvasTemplate:       js,
+			JsSearchTemplate:       js,
+			JsLinkPreviewTemplate:  js,
+			CssGiscusLightTemplate: css,
+			CssGiscusDarkTemplate:  css,
+		},
+	}
+	site.writeAssets(site.log)
+
+	name := regexp.MustCompile(`^app\.[0-9a-f]{8}\.js$`)
+	if !name.MatchString(site.Assets["app.js"]) {
+		t.Fatalf("app.js = %q, want a fingerprinted name", site.Assets["app.js"])
+	}
+	app, err := os.ReadFile(filepath.Join(out, site.Assets["app.js"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(app), "  ") {
+		t.Errorf("app.js isn't minified: %q", app)
+	}
+	if !strings.Contains(string(app), "https://example.com/notes/"+site.Assets["giscus-theme-dark.css"]) {
+		t.Errorf("app.js doesn't link the fingerprinted giscus theme: %q", app)
+	}
+	style, err := os.ReadFile(filepath.Join(out, site.Assets["style.css"]))
+	if err != nil || string(style) != "body{color:red}" {
+		t.Errorf("style.css = %q, %v", style, err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "app.js")); err == nil {
+		t.Error("app.js is written under its plain name")
+	}
+
+	var manifest map[string]string
+	data, err := os.ReadFile(filepath.Join(out, AssetManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || manifest["shared.css"] != site.Assets["shared.css"] || len(manifest) != 9 {
+		t.Errorf("manifest = %v, %v", manifest, err)
+	}
+	if got := site.Asset("search.js"); got != "https://example.com/notes/"+site.Assets["search.js"] {
+		t.Errorf("Asset(search.js) = %q", got)
+	}
+	if got := site.Asset("unknown.js"); got != "https://example.com/notes/unknown.js" {
+		t.Errorf("Asset(unknown.js) = %q", got)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := fingerprint("giscus-theme-light.css", []byte("a"))
+	if a != fingerprint("giscus-theme-light.css", []byte("a")) {
+		t.Error("the fingerprint isn't stable")
+	}
+	if a == fingerprint("giscus-theme-light.css", []byte("b")) {
+		t.Error("the fingerprint doesn't change with the content")
+	}
+	if !strings.HasPrefix(a, "giscus-theme-light.") || !strings.HasSuffix(a, ".css") {
+		t.Errorf("fingerprint = %q", a)
+	}
+}
+
+func TestPrecompress(t *testing.T) {
+	page := strings.Repeat("<p>hello</p>", 200)
+	dir := writeVault(t, map[string]string{
+		"index.html":      page,
+		"small.html":      "<p>hi</p>",
+		"data/graph.json": strings.Repeat(`{"id":1},`, 200),
+		"photo.png":       strings.Repeat("\x01", 4096),
+	})
+	precompress(dir, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	br, err := os.ReadFile(filepath.Join(dir, "index.html.br"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(brotli.NewReader(bytes.NewReader(br))); string(got) != page {
+		t.Error("index.html.br doesn't decode to the page")
+	}
+	gz, err := os.Open(filepath.Join(dir, "index.html.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != page {
+		t.Error("index.html.gz doesn't decode to the page")
+	}
+
+	for name, want := range map[string]bool{
+		"data/graph.json.br": true,
+		"small.html.br":      false,
+		"photo.png.br":       false,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", name, err == nil, want)
+		}
+	}
+}
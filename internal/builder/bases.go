@@ -3,6 +3,7 @@ package builder
 
 import (
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
@@ -39,13 +40,18 @@ func GetValue(site *DefaultSite, note *obsidian.File, field string) any {
 	case "file.size":
 		return note.Size
 	case "file.backlinks":
-		res := strings.Join(note.Backlinks, " ")
-		backlinks, err := site.Markdown.RenderNote([]byte(res))
-		if err != nil {
-			site.log.Warn("Couldn't render backlinks")
+		if len(note.Backlinks) == 0 {
 			return ""
 		}
-		return backlinks
+		links := make([]string, 0, len(note.Backlinks))
+		for _, bl := range note.Backlinks {
+			links = append(links, fmt.Sprintf(
+				`<a href="%s" class="internal-link">%s</a>`,
+				html.EscapeString(bl.WebPath),
+				html.EscapeString(bl.Name),
+			))
+		}
+		return "<p>" + strings.Join(links, " ") + "</p>"
 	case "file.embeds":
 		res := strings.Join(note.Embeds, " ")
 		embeds, err := site.Markdown.RenderNote([]byte(res))
@@ -106,8 +112,8 @@ func stringify(val any) string {
 	}
 }
 
-func FilterNotes(allFiles []*obsidian.File, baseFilters map[string][]string) []*obsidian.File {
-	filteredBaseFiles := bases.FilterFiles(allFiles, baseFilters)
+func FilterNotes(allFiles []*obsidian.File, baseFilters map[string][]string, links *obsidian.LinkIndex) []*obsidian.File {
+	filteredBaseFiles := bases.FilterFiles(allFiles, baseFilters, links)
 	return filteredBaseFiles
 }
 
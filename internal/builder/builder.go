@@ -2,10 +2,20 @@
 package builder
 
 import (
+	"crypto/sha256"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/otaleghani/kiln/internal/appearance"
+	"github.com/otaleghani/kiln/internal/graph"
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/imgopt"
+	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/listing"
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/otaleghani/kiln/internal/ogimage"
 )
 
 // Build orchestrates the static site generation process.
@@ -19,27 +29,25 @@ func Build(log *slog.Logger) {
 		log.Info("Building site in Default mode")
 		buildDefault(log)
 	}
+	if Precompress {
+		precompress(OutputDir, log)
+	}
 }
 
-func IncrementalBuild(log *slog.Logger, rebuild []string, remove []string) {
+// IncrementalBuild rebuilds the pages of the given files after a change,
+// by RelPath. The changed files are read again into the vault model of the
+// previous build, the removed ones dropped from it and their output deleted.
+func IncrementalBuild(log *slog.Logger, changed, rebuild, remove []string) {
 	for _, relPath := range remove {
-		outPath := filepath.Join(OutputDir, relPath)
-		if strings.HasSuffix(relPath, ".md") {
-			outPath = strings.TrimSuffix(outPath, ".md")
-			if FlatUrls {
-				outPath += ".html"
-			} else {
-				outPath = filepath.Join(outPath, "index.html")
-			}
-		}
-		os.Remove(outPath)
+		removeOutput(relPath)
 	}
 
 	RebuildFilter = make(map[string]struct{}, len(rebuild))
 	for _, relPath := range rebuild {
 		RebuildFilter[relPath] = struct{}{}
 	}
-	defer func() { RebuildFilter = nil }()
+	changedFiles, removedFiles = changed, remove
+	defer func() { RebuildFilter, changedFiles, removedFiles = nil, nil, nil }()
 
 	switch Mode {
 	case "custom":
@@ -51,6 +59,107 @@ func IncrementalBuild(log *slog.Logger, rebuild []string, remove []string) {
 	}
 }
 
+// removeOutput deletes the output of a removed file: the path the previous
+// build wrote it to, or else the one derived from its RelPath, its social
+// cards, canvas export or image variants, and the directory of its pretty
+// URL once empty.
+func removeOutput(relPath string) {
+	outPath, name := "", ""
+	if last.scanned != nil {
+		for _, f := range last.scanned.Vault.Files {
+			if f.RelPath == relPath {
+				outPath, name = f.OutPath, f.Name
+				if result, ok := last.images[f.WebPath]; ok {
+					removeVariants(result)
+				}
+				break
+			}
+		}
+	}
+	if outPath == "" {
+		outPath = filepath.Join(OutputDir, relPath)
+		if strings.HasSuffix(relPath, ".md") {
+			outPath = strings.TrimSuffix(outPath, ".md")
+			if FlatUrls {
+				outPath += ".html"
+			} else {
+				outPath = filepath.Join(outPath, "index.html")
+			}
+		}
+	}
+	os.Remove(outPath)
+	if name != "" && isPageExt(filepath.Ext(relPath)) {
+		dir := filepath.Dir(outPath)
+		for _, suffix := range []string{"-og.png", "-twitter.png", "-canvas.svg"} {
+			os.Remove(filepath.Join(dir, name+suffix))
+		}
+	}
+	if filepath.Base(outPath) == "index.html" {
+		os.Remove(filepath.Dir(outPath))
+	}
+}
+
+// removeVariants deletes the variants of a removed image.
+func removeVariants(result *imgopt.Result) {
+	for _, v := range result.Variants {
+		os.Remove(v.OutPath)
+	}
+	for _, sized := range result.Sized {
+		for _, v := range sized {
+			os.Remove(v.OutPath)
+		}
+	}
+}
+
+// buildState is what a build keeps for the incremental builds after it.
+type buildState struct {
+	scanned *obsidian.Obsidian              // Vault model, updated rather than scanned again
+	navbar  string                          // Signature of the sidebars
+	links   map[string][]obsidian.GraphLink // Graph links found rendering each note, by WebPath
+	graphs  map[string][sha256.Size]byte    // Hash of the local graph files written, by output path
+	derived map[string]struct{}             // Output of the folder and tag pages
+	images  map[string]*imgopt.Result       // Variants of the images, by WebPath
+	custom  customCache                     // Rendered notes of the custom mode
+}
+
+// last is the state of the last build.
+var last buildState
+
+// changedFiles and removedFiles are the files changed since the last build,
+// during an incremental build.
+var changedFiles, removedFiles []string
+
+// scanVault scans the vault with obs, or updates the model of the previous
+// build with the changed files during an incremental build. The model is
+// kept for the next one.
+func scanVault(obs *obsidian.Obsidian) (*obsidian.Obsidian, error) {
+	var err error
+	if RebuildFilter != nil && last.scanned != nil {
+		// The pages showing the backlinks, mentions or paths of the changed
+		// files are rendered again too
+		obs = last.scanned
+		before := pageSignatures(obs)
+		err = obs.Update(changedFiles, removedFiles)
+		for relPath, sig := range pageSignatures(obs) {
+			if prev, ok := before[relPath]; ok && prev != sig {
+				RebuildFilter[relPath] = struct{}{}
+			}
+		}
+	} else {
+		err = obs.Scan()
+	}
+	last.scanned = obs
+	return obs, err
+}
+
+// VaultFiles returns the files of the vault as of the last build.
+func VaultFiles() []*obsidian.File {
+	if last.scanned == nil {
+		return nil
+	}
+	return last.scanned.Vault.Files
+}
+
 var RebuildFilter map[string]struct{}
 
 var (
@@ -66,6 +175,30 @@ var (
 	DisableTOC        bool   // Disables table of contents
 	DisableLocalGraph bool   // Disables local graph
 	DisableBacklinks  bool   // Disables backlinks panel
+	UnlinkedMentions  bool   // Collects plain-text mentions of notes
 	Lang              string // Language code for the site
 	AccentColorName   string // Accent color override (palette color name)
+	CacheDir          string // Build cache directory, kept between builds. Empty disables the cache
+	DateSource        string // Source of the created and modified dates: "filesystem", "frontmatter" or "git"
+	EditURL           string // Pattern of the links to the source of the pages, {path} being the file in the vault
+	StripPrefixes     bool   // Removes numeric prefixes ("01 Intro") from the names and slugs of the pages
+	Precompress       bool   // Writes the brotli and gzip versions of the text files after a full build
+
+	GraphOptions graph.Options   // Global and local graph settings, from kiln.yaml
+	ImageOptions imgopt.Options  // Responsive image settings, from kiln.yaml
+	OGOptions    ogimage.Options // Open Graph card settings, from kiln.yaml
+	SEOOptions   jsonld.Options  // Publisher, authors and FAQ callout, from kiln.yaml
+	I18nOptions  i18n.Options    // Languages of a multilingual site, from kiln.yaml
+
+	AppearanceOptions appearance.Options // User-defined themes, fonts and CSS variables, from kiln.yaml
+	FolderOptions     listing.Options    // Listing of the children of folder pages, from kiln.yaml
 )
+
+// copyStatic copies a static file to the output directory, removing the
+// metadata of images when requested.
+func copyStatic(src, dst, ext string) error {
+	if ImageOptions.StripMetadata && imgopt.IsOptimizable(ext) {
+		return imgopt.CopyStripped(src, dst)
+	}
+	return obsidian.CopyFile(src, dst)
+}
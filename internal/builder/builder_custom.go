@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"regexp"
 	"runtime"
 	"sort"
@@ -49,7 +50,7 @@ func (s *CustomSite) walk() error {
 		case ".canvas":
 			s.Files.Canvas = append(s.Files.Canvas, file)
 		case ".json":
-			if file.Name == "config" {
+			if file.FullName == "config.json" {
 				s.Files.Config = append(s.Files.Config, file)
 				break
 			}
@@ -60,7 +61,7 @@ func (s *CustomSite) walk() error {
 			l.Debug("Found unknown JSON file, added to static files")
 			s.Files.Static = append(s.Files.Static, file)
 		case ".html":
-			if file.Name == "layout" {
+			if file.FullName == "layout.html" {
 				s.Files.Layout[getConfigDirectory(file.RelPath)] = file
 				break
 			}
@@ -126,12 +127,17 @@ func (s *CustomSite) parseLayouts() error {
 }
 
 // parseNotes loops through the pages of the CustomSite and parses the raw data discovered
-// by loadNoteFile.
+// by loadNoteFile. Incremental builds reuse the rendered content of the
+// unchanged pages.
 func (s *CustomSite) parseNotes() error {
 	s.log.Info("Parsing notes...")
 	siblings := make(map[string][]*CustomPage)
+	contents := make(map[string]renderedContent, len(s.Pages))
 
-	for _, page := range s.Pages {
+	// In path order, for the siblings and tags to list the pages the same
+	// way on every build
+	for _, relPath := range slices.Sorted(maps.Keys(s.Pages)) {
+		page := s.Pages[relPath]
 		l := s.log.With("file", page.RelPath)
 
 		// Validate fields
@@ -185,21 +191,29 @@ func (s *CustomSite) parseNotes() error {
 			page.Template = customTemplate
 		}
 
-		obsidianFile := obsidian.File{
-			Path:    page.File.Path,
-			RelPath: page.File.RelPath,
-			Ext:     page.File.Ext,
-			Name:    page.File.Name,
-			OutPath: page.File.OutPath,
-			WebPath: page.File.WebPath,
-		}
+		content, cached := last.custom.content[page.RelPath]
+		if !cached || shouldRebuild(page.RelPath) {
+			obsidianFile := obsidian.File{
+				Path:    page.File.Path,
+				RelPath: page.File.RelPath,
+				Ext:     page.File.Ext,
+				Name:    page.File.Name,
+				OutPath: page.File.OutPath,
+				WebPath: page.File.WebPath,
+			}
 
-		renderedPage, err := s.Markdown.Render(obsidianFile)
-		if err != nil {
-			return err
+			renderedPage, err := s.Markdown.Render(obsidianFile)
+			if err != nil {
+				return err
+			}
+			content = renderedContent{
+				Content: template.HTML(renderedPage.Content),
+				TOC:     renderedPage.TOC,
+			}
 		}
-		page.Content = template.HTML(renderedPage.Content)
-		page.TOC = renderedPage.TOC
+		page.Content = content.Content
+		page.TOC = content.TOC
+		contents[page.RelPath] = content
 
 		// We append the page for the siblings handling
 		if page.Collection != "" && !page.IsIndex {
@@ -214,6 +228,7 @@ func (s *CustomSite) parseNotes() error {
 		}
 	}
 
+	last.custom.content = contents
 	return nil
 }
 
@@ -226,7 +241,7 @@ func (s *CustomSite) parseConfigs() error {
 		l := s.log.With("file", config.RelPath)
 		if filepath.Join(InputDir, "config.json") == config.Path {
 			l.Debug("Found config file in root folder, skipping it")
-			return nil
+			continue
 		}
 
 		// Parse the configuration and check the fields
@@ -254,9 +269,12 @@ func (s *CustomSite) parseConfigs() error {
 }
 
 // parseStaticFiles creates a rappresentation of the given static file and copies the file over
-// the output directory
+// the output directory. Incremental builds copy the changed files only, the
+// variants of the other images come from the previous build.
 func (s *CustomSite) parseStaticFiles() error {
 	s.log.Info("Parsing static files...")
+	imgopt.ReportEncoders(ImageOptions, s.log)
+	sizes := imageSizes(s.Obsidian.Vault)
 	var imgJobs []imgopt.ImageJob
 	for _, file := range s.Files.Static {
 		// Index the asset
@@ -271,32 +289,40 @@ func (s *CustomSite) parseStaticFiles() error {
 			OutputPath:   file.OutPath,
 		}
 
+		s.Assets[cleanName] = asset
+		if !shouldRebuild(file.RelPath) {
+			if result, ok := last.images[asset.RelPermalink]; ok {
+				s.ImageResults[asset.RelPermalink] = result
+			}
+			continue
+		}
+
 		if err := os.MkdirAll(filepath.Dir(asset.OutputPath), 0755); err != nil {
 			return err
 		}
 
-		if imgopt.IsOptimizable(file.Ext) {
+		if imgopt.IsMeasurable(file.Ext) {
 			imgJobs = append(imgJobs, imgopt.ImageJob{
 				SrcPath:  asset.Path,
 				OutDir:   filepath.Dir(asset.OutputPath),
 				WebDir:   filepath.Dir(asset.RelPermalink),
 				BaseName: file.Name,
 				WebPath:  asset.RelPermalink,
+				Sizes:    sizes[asset.RelPermalink],
 			})
 		}
 
-		err := obsidian.CopyFile(asset.Path, asset.OutputPath)
+		err := copyStatic(asset.Path, asset.OutputPath, file.Ext)
 		if err != nil {
 			return err
 		}
 
-		s.Assets[cleanName] = asset
-
 		s.log.Info("Static file parsed correctly", "file", asset.RelPath)
 	}
-	for k, v := range imgopt.ProcessImages(imgJobs, imgopt.DefaultBreakpoints(), runtime.NumCPU()) {
+	for k, v := range imgopt.ProcessImages(imgJobs, ImageOptions, s.imageCache, runtime.NumCPU()) {
 		s.ImageResults[k] = v
 	}
+	last.images = s.ImageResults
 	return nil
 }
 
@@ -380,7 +406,7 @@ func (s *CustomSite) loadConfigFiles() error {
 	for _, file := range s.Files.Config {
 		if filepath.Join(InputDir, "config.json") == file.Path {
 			s.log.Debug("Configuration file is in root folder, skipping it")
-			return nil
+			continue
 		}
 
 		rawData, err := os.ReadFile(file.Path)
@@ -418,22 +444,25 @@ func (s *CustomSite) loadConfigFiles() error {
 	return nil
 }
 
+// render executes the template of every page, or during an incremental
+// build of the pages affected by the changes only.
 func (s *CustomSite) render() error {
+	affected := s.affectedPages()
+	if affected != nil {
+		s.log.Info("Rendering affected pages...", "pages", len(affected))
+	}
 	for _, page := range s.Pages {
+		if affected != nil && !affected[page.RelPath] {
+			continue
+		}
 		l := s.log.With("file", page.RelPath)
 
-		var tmpl *template.Template
-		var tmplPath string
-		if page.Template != nil {
-			l.Debug("Using custom template")
-			tmpl = page.Template
-			tmplPath = strings.TrimSuffix(filepath.Base(page.Path), ".md") + ".html"
-		} else {
-			config := s.ConfigsLookup[page.Collection]
-			tmpl = config.Template
-			l.Debug("Using collection template")
-			tmplPath = filepath.Base(config.LayoutPath)
+		tmpl, tmplPath := s.pageTemplate(page)
+		if tmpl == nil {
+			l.Error("No template found", "collection", page.Collection)
+			return fmt.Errorf("No template found for the page %s", page.RelPath)
 		}
+		l.Debug("Using template", "name", tmplPath)
 
 		if err := os.MkdirAll(filepath.Dir(page.OutputPath), 0755); err != nil {
 			l.Error("Error creating dirs", "path", page.OutputPath, "error", err)
@@ -463,21 +492,26 @@ func (s *CustomSite) render() error {
 // BuildCustom executes the user-first generation logic (Obsidian-SSG)
 // It takes sourceDir (vault root) and outputDir as arguments.
 func buildCustom(log *slog.Logger) {
-	obs := obsidian.New(
+	start := time.Now()
+	obs, err := scanVault(obsidian.New(
 		obsidian.WithBaseURL(BaseURL),
 		obsidian.WithFlatURLs(FlatUrls),
+		obsidian.WithUnlinkedMentions(UnlinkedMentions),
+		obsidian.WithStripPrefixes(StripPrefixes),
+		obsidian.WithDateSource(DateSource),
+		obsidian.WithLang(Lang),
+		obsidian.WithLanguages(I18nOptions.Languages),
 		obsidian.WithInputDir(InputDir),
+		obsidian.WithOutputDir(OutputDir),
 		obsidian.WithLogger(log),
-	)
-
-	err := obs.Scan()
+	))
 	if err != nil {
 		log.Error("Couldn't scan vault", "error", err)
 		os.Exit(1)
 	}
 
 	// Creates markdown renderer
-	obsidianMd := markdown.New(obs.Vault.FileIndex, func(path string) ([]byte, error) {
+	obsidianMd := markdown.New(obs.Vault.FileIndex, obs.Vault.Links, func(path string) ([]byte, error) {
 		return os.ReadFile(filepath.Join(InputDir, path))
 	})
 
@@ -494,6 +528,7 @@ func buildCustom(log *slog.Logger) {
 		Obsidian:      obs,
 		log:           log,
 		ImageResults:  make(map[string]*imgopt.Result),
+		imageCache:    newImageCache(),
 		// Scan:          vaultScan,
 	}
 	site.Template.Funcs(site.getFuncMap())
@@ -560,6 +595,17 @@ func buildCustom(log *slog.Logger) {
 		log.Error("Error rendering pages", "error", err)
 		os.Exit(1)
 	}
+
+	cached, processed := site.imageCache.Stats()
+	log.Info(
+		"Build complete",
+		"seconds",
+		time.Since(start).Seconds(),
+		"images_cached",
+		cached,
+		"images_processed",
+		processed,
+	)
 }
 
 // resolveFieldValue extracts the underlying Go value from a FieldContent wrapper
@@ -579,6 +625,16 @@ func resolveFieldValue(p *CustomPage, key string) any {
 			return p.Collection
 		case "Siblings":
 			return p.Siblings
+		case "Backlinks":
+			if p.File != nil {
+				return p.File.BacklinkContexts
+			}
+			return nil
+		case "UnlinkedMentions":
+			if p.File != nil {
+				return p.File.UnlinkedMentions
+			}
+			return nil
 		default:
 			return nil
 		}
@@ -1043,8 +1099,8 @@ func (s *CustomSite) validateFrontmatterField(
 		return content, nil
 
 	case TypeTags:
-		sliceVal, ok := value.([]string)
-		if !ok {
+		sliceVal, err := extractStringSlice(value)
+		if err != nil {
 			return FieldContent{}, ErrorParsing
 		}
 
@@ -1190,6 +1246,7 @@ type CustomSite struct {
 	// Scan             *VaultScan                 // The result of scanVault
 	Obsidian     *obsidian.Obsidian
 	ImageResults map[string]*imgopt.Result // Optimized image variants keyed by WebPath
+	imageCache   *imgopt.Cache             // Variants kept between builds, nil when disabled
 	log          *slog.Logger
 }
 
@@ -3,24 +3,28 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"html/template"
+	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/otaleghani/kiln/assets"
+	"github.com/otaleghani/kiln/internal/canvas"
+	"github.com/otaleghani/kiln/internal/i18n"
 	"github.com/otaleghani/kiln/internal/imgopt"
 	"github.com/otaleghani/kiln/internal/obsidian"
 	"github.com/otaleghani/kiln/internal/obsidian/bases"
 	"github.com/otaleghani/kiln/internal/obsidian/markdown"
 	"github.com/otaleghani/kiln/internal/ogimage"
 	"github.com/otaleghani/kiln/internal/search"
+	"github.com/otaleghani/kiln/internal/templates"
 	"github.com/tdewolff/minify/v2"
-	"github.com/tdewolff/minify/v2/html"
 	"golang.org/x/image/font"
 	"gopkg.in/yaml.v3"
 )
@@ -28,7 +32,8 @@ import (
 func buildDefault(log *slog.Logger) {
 	start := time.Now()
 
-	// Resolves the theme
+	// Resolves the theme, among the user-defined ones too
+	RegisterAppearance(InputDir, AppearanceOptions, log)
 	theme := ResolveTheme(ThemeName, FontName, AccentColorName, log)
 
 	// Resolves and loads layout
@@ -39,28 +44,49 @@ func buildDefault(log *slog.Logger) {
 		os.Exit(1)
 	}
 
-	// Scans vault
-	obs := obsidian.New(
+	// Scans vault, or updates the model of the previous build with the
+	// changed files only
+	obs, err := scanVault(obsidian.New(
 		obsidian.WithBaseURL(BaseURL),
 		obsidian.WithFlatURLs(FlatUrls),
+		obsidian.WithUnlinkedMentions(UnlinkedMentions),
+		obsidian.WithStripPrefixes(StripPrefixes),
+		obsidian.WithFolderNotes(true),
+		obsidian.WithDateSource(DateSource),
+		obsidian.WithLang(Lang),
+		obsidian.WithLanguages(I18nOptions.Languages),
 		obsidian.WithInputDir(InputDir),
 		obsidian.WithOutputDir(OutputDir),
 		obsidian.WithLogger(log),
-	)
-	// vaultScan, err := scanVault()
-	err = obs.Scan()
+	))
 	if err != nil {
 		log.Error("Error scanning vault", "error", err)
 		os.Exit(1)
 	}
 
+	// Loads the UI labels of the vault and of kiln.yaml
+	i18n.Load(InputDir, I18nOptions, log)
+
 	// Creates markdown renderer
-	obsidianMd := markdown.New(obs.Vault.FileIndex, func(path string) ([]byte, error) {
+	obsidianMd := markdown.New(obs.Vault.FileIndex, obs.Vault.Links, func(path string) ([]byte, error) {
 		return os.ReadFile(filepath.Join(InputDir, path))
 	})
 
 	// Get's the sidebar root node
 	rootNode := obs.GenerateNavbar()
+	var navbars map[string]*obsidian.NavbarNode
+	roots := []*obsidian.NavbarNode{rootNode}
+	if obs.IsMultilingual() {
+		navbars = obs.GenerateNavbars()
+		for _, lang := range obs.Vault.Languages {
+			roots = append(roots, navbars[lang])
+		}
+	}
+
+	// Adds the pages depending on the changed files beyond their links
+	navbar := navbarSignature(roots...)
+	expandRebuild(obs, navbar, log)
+	last.navbar = navbar
 
 	ogFace := theme.Font.LoadFontFace(32, log)
 
@@ -71,24 +97,23 @@ func buildDefault(log *slog.Logger) {
 		Theme:             theme,
 		Layout:            layout,
 		Markdown:          obsidianMd,
-		Minifier:          minify.New(),
+		Minifier:          newMinifier(),
 		NavbarRoot:        rootNode,
+		Navbars:           navbars,
+		Overrides:         loadOverrides(InputDir, BaseURL, log),
 		DisableLocalGraph: DisableLocalGraph,
 		DisableTOC:        DisableTOC,
 		DisableBacklinks:  DisableBacklinks,
 		FlatURLs:          FlatUrls,
 		OGFontFace:        ogFace,
+		OGFonts:           theme.Font.LoadOGFonts(log),
+		OGLogo:            ogLogoPath(log),
 		Lang:              Lang,
+		DefaultLang:       Lang,
 		log:               log,
 		Obsidian:          obs,
 		ImageResults:      make(map[string]*imgopt.Result),
 	}
-	// site.Minifier.AddFunc("text/html", html.Minify)
-	site.Minifier.Add("text/html", &html.Minifier{
-		KeepDocumentTags: true,
-		KeepQuotes:       true,
-		KeepEndTags:      true,
-	})
 
 	// Divide the different files and call "RenderType" directly, instaed of calling Render
 	notePages := []*obsidian.File{}
@@ -119,11 +144,10 @@ func buildDefault(log *slog.Logger) {
 	}
 
 	// Adds base URL to fonts
-	site.Theme.Font.FontFaceReplaced = template.CSS(strings.Replace(
+	site.Theme.Font.FontFaceReplaced = template.CSS(strings.ReplaceAll(
 		string(site.Theme.Font.FontFace),
 		"{{.Site.BaseURL}}",
 		site.BaseURL,
-		4,
 	))
 
 	nodes := []obsidian.GraphNode{}
@@ -139,10 +163,21 @@ func buildDefault(log *slog.Logger) {
 		}
 	}
 
+	// Writes the stylesheets and scripts first, the pages link to their
+	// fingerprinted names
+	log.Info("Rendering static files...")
+	site.writeAssets(log)
+
 	log.Info("Copying static assets...")
+	imgopt.ReportEncoders(ImageOptions, log)
+	sizes := imageSizes(site.Obsidian.Vault)
+	imageCache := newImageCache()
 	var imgJobs []imgopt.ImageJob
 	for _, file := range staticFiles {
 		if !shouldRebuild(file.RelPath) {
+			if result, ok := last.images[file.WebPath]; ok {
+				site.ImageResults[file.WebPath] = result
+			}
 			continue
 		}
 		l := log.With("file", file.Path)
@@ -150,27 +185,34 @@ func buildDefault(log *slog.Logger) {
 			l.Error("Couldn't create output directory", "error", err)
 			continue
 		}
-		if imgopt.IsOptimizable(file.Ext) {
+		if imgopt.IsMeasurable(file.Ext) {
 			imgJobs = append(imgJobs, imgopt.ImageJob{
 				SrcPath:  file.Path,
 				OutDir:   filepath.Dir(file.OutPath),
 				WebDir:   filepath.Dir(file.WebPath),
 				BaseName: file.Name,
 				WebPath:  file.WebPath,
+				Sizes:    sizes[file.WebPath],
 			})
 		}
-		if copyErr := obsidian.CopyFile(file.Path, file.OutPath); copyErr != nil {
+		if copyErr := copyStatic(file.Path, file.OutPath, file.Ext); copyErr != nil {
 			l.Error("Couldn't copy file", "error", copyErr)
 		}
 	}
-	for k, v := range imgopt.ProcessImages(imgJobs, imgopt.DefaultBreakpoints(), runtime.NumCPU()) {
+	for k, v := range imgopt.ProcessImages(imgJobs, ImageOptions, imageCache, runtime.NumCPU()) {
 		site.ImageResults[k] = v
 	}
+	last.images = site.ImageResults
 
 	site.Markdown.ImageResults = site.ImageResults
 
+	// Outputs of the folder and tag pages, the ones of the previous build
+	// not written again are removed
+	derived := make(map[string]struct{})
+
 	log.Info("Rendering folder pages...")
-	for _, folder := range site.Obsidian.Vault.Folders {
+	for _, key := range slices.Sorted(maps.Keys(site.Obsidian.Vault.Folders)) {
+		folder := site.Obsidian.Vault.Folders[key]
 		l := log.With("folder", folder.RelPath)
 		// Check if there are some other that have the same
 		if len(folder.Files) == 0 && len(folder.Folders) == 0 {
@@ -189,18 +231,21 @@ func buildDefault(log *slog.Logger) {
 			l.Error("Couldn't render folder", "error", err)
 			continue
 		}
+		addPageOutputs(derived, folder.OutPath, folder.Name)
 		nodes = append(nodes, obsidian.GraphNode{
-			ID:    folder.WebPath,
-			Label: folder.Name,
-			URL:   folder.WebPath,
-			Val:   1,
-			Type:  "folder",
+			ID:      folder.WebPath,
+			Label:   folder.Name,
+			URL:     folder.WebPath,
+			Val:     1,
+			Type:    "folder",
+			RelPath: folder.RelPath,
 		})
 	}
 
 	log.Info("Rendering canvas pages...")
 	for _, file := range canvasPages {
 		if !shouldRebuild(file.RelPath) {
+			nodes = append(nodes, fileGraphNode(file))
 			continue
 		}
 		l := log.With("file", file.Path)
@@ -209,18 +254,13 @@ func buildDefault(log *slog.Logger) {
 			l.Error("Couldn't copy file", "error", err)
 			continue
 		}
-		nodes = append(nodes, obsidian.GraphNode{
-			ID:    file.WebPath,
-			Label: file.Name,
-			URL:   file.WebPath,
-			Val:   1,
-			Type:  file.Ext,
-		})
+		nodes = append(nodes, fileGraphNode(file))
 	}
 
 	log.Info("Rendering base pages...")
 	for _, base := range basePages {
 		if !shouldRebuild(base.File.RelPath) {
+			nodes = append(nodes, fileGraphNode(base.File))
 			continue
 		}
 		l := log.With("file", base.File.RelPath)
@@ -229,18 +269,13 @@ func buildDefault(log *slog.Logger) {
 			l.Error("Couldn't render base", "error", err)
 			continue
 		}
-		nodes = append(nodes, obsidian.GraphNode{
-			ID:    base.File.WebPath,
-			Label: base.File.Name,
-			URL:   base.File.WebPath,
-			Val:   1,
-			Type:  base.File.Ext,
-		})
+		nodes = append(nodes, fileGraphNode(base.File))
 	}
 
 	log.Info("Rendering markdown pages...")
 	for _, note := range notePages {
 		if !shouldRebuild(note.RelPath) {
+			nodes = append(nodes, fileGraphNode(note))
 			continue
 		}
 		l := log.With("file", note.RelPath)
@@ -250,22 +285,18 @@ func buildDefault(log *slog.Logger) {
 			l.Error("Couldn't render note", "error", err)
 			continue
 		}
-		nodes = append(nodes, obsidian.GraphNode{
-			ID:    note.WebPath,
-			Label: note.Name,
-			URL:   note.WebPath,
-			Val:   1,
-			Type:  note.Ext,
-		})
+		nodes = append(nodes, fileGraphNode(note))
 	}
 
 	log.Info("Rendering tag pages...")
-	for _, tag := range site.Obsidian.Vault.Tags {
+	for _, key := range slices.Sorted(maps.Keys(site.Obsidian.Vault.Tags)) {
+		tag := site.Obsidian.Vault.Tags[key]
 		l := log.With("tag", tag.Name)
 		err := site.RenderTag(tag)
 		if err != nil {
 			l.Error("Couldn't render tag", "error", err)
 		}
+		addPageOutputs(derived, tag.OutPath, tag.Name)
 		nodes = append(nodes, obsidian.GraphNode{
 			ID:    tag.WebPath,
 			Label: tag.Name,
@@ -276,136 +307,49 @@ func buildDefault(log *slog.Logger) {
 	}
 
 	log.Info("Generating search index...")
-	searchEntries := search.BuildIndex(notePages)
-	err = search.WriteIndex(searchEntries, OutputDir)
-	if err != nil {
-		log.Error("Couldn't generate search index", "error", err)
-	}
-
-	log.Info("Rendering static files...")
-	// Generate CSS based on the given theme/font settings
-	cssOut, err := os.Create(filepath.Join(OutputDir, "style.css"))
-	if err != nil {
-		log.Error("Couldn't create 'style.css'", "error", err)
-	}
-	defer cssOut.Close()
-	err = site.Layout.CssTemplate.Execute(cssOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'style.css'", "error", err)
-	}
-
-	// Copies over shared.css - Contains shared styles between layouts
-	cssContent, err := assets.TemplateFS.ReadFile("shared.css")
-	if err != nil {
-		log.Error("Couldn't read 'shared.css'", "error", err)
-	}
-	err = os.WriteFile(filepath.Join(OutputDir, "shared.css"), cssContent, 0644)
-	if err != nil {
-		log.Error("Couldn't write 'shared.css'", "error", err)
-	}
-
-	// Generate app JS
-	jsOut, err := os.Create(filepath.Join(OutputDir, "app.js"))
-	if err != nil {
-		log.Error("Couldn't create 'app.js'", "error", err)
-	}
-	defer jsOut.Close()
-	err = site.Layout.JsTemplate.Execute(jsOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'app.js'", "error", err)
-	}
-
-	// Generate graph JS
-	graphJsOut, err := os.Create(filepath.Join(OutputDir, "graph.js"))
-	if err != nil {
-		log.Error("Couldn't create 'graph.js'", "error", err)
-	}
-	defer graphJsOut.Close()
-	err = site.Layout.JsGraphTemplate.Execute(graphJsOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'graph.js'", "error", err)
-	}
-
-	// Generate canvas JS
-	canvasJsOut, err := os.Create(filepath.Join(OutputDir, "canvas.js"))
-	if err != nil {
-		log.Error("Couldn't create 'canvas.js'", "error", err)
-	}
-	defer canvasJsOut.Close()
-	err = site.Layout.JsCanvasTemplate.Execute(canvasJsOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'canvas.js'", "error", err)
-	}
-
-	// Generate search JS
-	searchJsOut, err := os.Create(filepath.Join(OutputDir, "search.js"))
-	if err != nil {
-		log.Error("Couldn't create 'search.js'", "error", err)
-	}
-	defer searchJsOut.Close()
-	err = site.Layout.JsSearchTemplate.Execute(searchJsOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'search.js'", "error", err)
-	}
-
-	// Generate link preview JS
-	linkPreviewJsOut, err := os.Create(filepath.Join(OutputDir, "link-preview.js"))
-	if err != nil {
-		log.Error("Couldn't create 'link-preview.js'", "error", err)
-	}
-	defer linkPreviewJsOut.Close()
-	err = site.Layout.JsLinkPreviewTemplate.Execute(linkPreviewJsOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'link-preview.js'", "error", err)
-	}
-
-	// Generate discus CSS themes
-	log.Debug("Generating light giscus theme")
-	giscusLightOut, err := os.Create(filepath.Join(OutputDir, "giscus-theme-light.css"))
-	if err != nil {
-		log.Error("Couldn't create 'giscus-theme-light.css'", "error", err)
-	}
-	defer giscusLightOut.Close()
-	log.Debug("Rendering light giscus theme")
-	err = site.Layout.CssGiscusLightTemplate.Execute(giscusLightOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'giscus-theme-light.css'", "error", err)
-	}
-	log.Debug("Done generating light theme")
-	log.Debug("Generating dark giscus theme")
-	giscusDarkOut, err := os.Create(filepath.Join(OutputDir, "giscus-theme-dark.css"))
-	if err != nil {
-		log.Error("Couldn't create 'giscus-theme-dark.css'", "error", err)
-	}
-	defer giscusDarkOut.Close()
-	log.Debug("Rendering dark giscus theme")
-	err = site.Layout.CssGiscusDarkTemplate.Execute(giscusDarkOut, site)
-	if err != nil {
-		log.Error("Couldn't execute template for 'giscus-theme-dark.css'", "error", err)
+	if obs.IsMultilingual() {
+		// One index per language, so that search only lists pages in the
+		// language of the current page
+		for _, lang := range obs.Vault.Languages {
+			pages := []*obsidian.File{}
+			for _, f := range notePages {
+				if f.Lang == lang {
+					pages = append(pages, f)
+				}
+			}
+			name := obsidian.LanguageFileName("search-index.json", lang, Lang)
+			err = search.WriteIndexFile(search.BuildIndex(pages), filepath.Join(OutputDir, name))
+			if err != nil {
+				log.Error("Couldn't generate search index", "lang", lang, "error", err)
+			}
+		}
+	} else {
+		searchEntries := search.BuildIndex(notePages)
+		err = search.WriteIndex(searchEntries, OutputDir)
+		if err != nil {
+			log.Error("Couldn't generate search index", "error", err)
+		}
 	}
-	log.Debug("Done generating dark theme")
 
 	// Extracts fonts
 	site.Theme.extractFonts(OutputDir, log)
 
+	// Copies the stylesheets and scripts of the layout overrides
+	copyOverrideAssets(InputDir, OutputDir, log)
+
 	// Generate Graph JSON data
-	markdownLinks := site.Markdown.Resolver.Links
+	markdownLinks := site.graphLinks(notePages)
 	log.Debug("Markdown links", "amount", len(markdownLinks))
 	links := append(site.Obsidian.GetFolderLinks(), markdownLinks...)
 	links = append(site.Obsidian.GetTagLinks(), links...)
-	log.Debug("Total links", "amount", len(links))
-	graphJSON := map[string]any{
-		"nodes": nodes,
-		"links": links,
-	}
-	jsonBytes, err := json.Marshal(graphJSON)
-	if err != nil {
-		log.Error("Couldn't marshal JSON", "error", err)
-	}
-	err = os.WriteFile(filepath.Join(OutputDir, "graph.json"), jsonBytes, 0644)
-	if err != nil {
-		log.Error("Couldn't create 'graph.json'", "error", err)
+	if GraphOptions.Attachments {
+		attachmentNodes, attachmentLinks := site.attachmentGraph(notePages)
+		nodes = append(nodes, attachmentNodes...)
+		links = append(links, attachmentLinks...)
 	}
+	log.Debug("Total links", "amount", len(links))
+	site.writeGraph(nodes, links, log)
+	removeStale(obs, derived)
 
 	err = site.RenderGraph()
 	if err != nil {
@@ -447,16 +391,26 @@ func buildDefault(log *slog.Logger) {
 		log.Error("Couldn't transfer '_redirects' file", "error", err)
 	}
 
+	err = site.Obsidian.LoadHeaders()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Error("Couldn't transfer '_headers' file", "error", err)
+	}
+
+	cached, processed := imageCache.Stats()
 	log.Info(
 		"Build complete",
 		"seconds",
 		time.Since(start).Seconds(),
+		"images_cached",
+		cached,
+		"images_processed",
+		processed,
 	)
 }
 
 // Render folders
 func (s *DefaultSite) RenderFolder(f *obsidian.Folder) error {
-	obsidian.SetNavbarNodeActive(s.NavbarRoot.Children, f.WebPath)
+	obsidian.SetNavbarNodeActive(s.navbar(f.Lang).Children, f.WebPath)
 
 	// Creates outfile
 	outFile, err := os.Create(f.OutPath)
@@ -481,6 +435,7 @@ func (s *DefaultSite) RenderFolder(f *obsidian.Folder) error {
 		Folder:      f,
 		IsFolder:    true,
 		Breadcrumbs: breadcrumbs,
+		Listing:     s.folderListing(f, nil),
 	}
 
 	// Executes the template
@@ -491,7 +446,7 @@ func (s *DefaultSite) RenderFolder(f *obsidian.Folder) error {
 		return err
 	}
 
-	s.GeneratePageOGImages(f.RelPath, f.RelPath, f.Name, filepath.Dir(f.OutPath))
+	s.GeneratePageOGImages(ogimage.ImageConfig{Title: f.RelPath}, f.Name, filepath.Dir(f.OutPath))
 
 	return nil
 }
@@ -532,14 +487,14 @@ func (s *DefaultSite) RenderTag(t *obsidian.Tag) error {
 		return err
 	}
 
-	s.GeneratePageOGImages(t.Name, t.Name, t.Name, filepath.Dir(t.OutPath))
+	s.GeneratePageOGImages(ogimage.ImageConfig{Title: t.Name}, t.Name, filepath.Dir(t.OutPath))
 
 	return nil
 }
 
 func (s *DefaultSite) RenderBase(b *PageBase, allFiles []*obsidian.File) error {
 	s.log.Info("Found base", "path", b.File.Path)
-	obsidian.SetNavbarNodeActive(s.NavbarRoot.Children, b.File.WebPath)
+	obsidian.SetNavbarNodeActive(s.navbar(b.File.Lang).Children, b.File.WebPath)
 
 	// Creates outfile
 	outFile, err := os.Create(b.File.OutPath)
@@ -557,12 +512,12 @@ func (s *DefaultSite) RenderBase(b *PageBase, allFiles []*obsidian.File) error {
 	minifierWriter := s.Minifier.Writer("text/html", outFile)
 	defer minifierWriter.Close()
 
-	activeFiles := bases.FilterFiles(allFiles, b.Filters)
+	activeFiles := bases.FilterFiles(allFiles, b.Filters, s.Obsidian.Vault.Links)
 
 	var fileGroups []*bases.FileGroup
 	var columns []string
 	if len(b.Views) > 0 {
-		activeFiles = bases.FilterFiles(activeFiles, b.Views[0].Filters)
+		activeFiles = bases.FilterFiles(activeFiles, b.Views[0].Filters, s.Obsidian.Vault.Links)
 		if b.Views[0].GroupBy.Property != "" {
 			fileGroups = bases.GroupFiles(activeFiles, b.Views[0].GroupBy.Property)
 		}
@@ -591,7 +546,7 @@ func (s *DefaultSite) RenderBase(b *PageBase, allFiles []*obsidian.File) error {
 		return err
 	}
 
-	s.GeneratePageOGImages(b.File.Name, b.File.Name, b.File.Name, filepath.Dir(b.File.OutPath))
+	s.GeneratePageOGImages(ogimage.ImageConfig{Title: b.File.Name}, b.File.Name, filepath.Dir(b.File.OutPath))
 
 	return nil
 }
@@ -670,7 +625,7 @@ func (s *DefaultSite) Render404() error {
 
 // RenderNote renders the given markdown file
 func (s *DefaultSite) RenderNote(f *obsidian.File) error {
-	obsidian.SetNavbarNodeActive(s.NavbarRoot.Children, f.WebPath)
+	obsidian.SetNavbarNodeActive(s.navbar(f.Lang).Children, f.WebPath)
 
 	// Creates outfile
 	outFile, err := os.Create(f.OutPath)
@@ -688,8 +643,13 @@ func (s *DefaultSite) RenderNote(f *obsidian.File) error {
 		return err
 	}
 
-	// Creates breadcrumbs
-	breadcrumbs, err := s.Obsidian.GetBreadcrumbs(f)
+	// Creates breadcrumbs, the ones of its folder for folder notes
+	var breadcrumbs []obsidian.Breadcrumb
+	if f.NoteOf != nil {
+		breadcrumbs, err = s.Obsidian.GetFolderBreadcrumbs(f.NoteOf)
+	} else {
+		breadcrumbs, err = s.Obsidian.GetBreadcrumbs(f)
+	}
 	if err != nil {
 		return err
 	}
@@ -698,12 +658,16 @@ func (s *DefaultSite) RenderNote(f *obsidian.File) error {
 	pageData := DefaultSitePageData{
 		Site:        s,
 		Frontmatter: obsidianData.Frontmatter,
+		Image:       noteImage(s.Obsidian.Vault, f, obsidianData.Frontmatter),
 		Content:     template.HTML(obsidianData.Content),
 		TOC:         obsidianData.TOC,
 		File:        f,
 		Breadcrumbs: breadcrumbs,
 		IsNote:      true,
 	}
+	if f.NoteOf != nil {
+		pageData.Listing = s.folderListing(f.NoteOf, obsidianData.Frontmatter)
+	}
 
 	// Executes the template
 	templData := toTemplPageData(&pageData)
@@ -713,7 +677,10 @@ func (s *DefaultSite) RenderNote(f *obsidian.File) error {
 		return err
 	}
 
-	// Generate OG and Twitter images
+	// Generate OG and Twitter images, unless the note sets its own
+	if hasOwnOGImage(obsidianData.Frontmatter) || pageData.Image != "" {
+		return nil
+	}
 	title := f.Name
 	if t, ok := obsidianData.Frontmatter["title"].(string); ok && t != "" {
 		title = t
@@ -722,13 +689,14 @@ func (s *DefaultSite) RenderNote(f *obsidian.File) error {
 	if d, ok := obsidianData.Frontmatter["description"].(string); ok && d != "" {
 		description = d
 	}
-	s.GeneratePageOGImages(title, description, f.Name, filepath.Dir(f.OutPath))
+	card := noteCard(s.Obsidian.Vault, f, obsidianData.Frontmatter, title, description)
+	s.GeneratePageOGImages(card, f.Name, filepath.Dir(f.OutPath))
 
 	return nil
 }
 
 func (s *DefaultSite) RenderCanvas(f *obsidian.File) error {
-	obsidian.SetNavbarNodeActive(s.NavbarRoot.Children, f.WebPath)
+	obsidian.SetNavbarNodeActive(s.navbar(f.Lang).Children, f.WebPath)
 
 	l := s.log.With("path", f.RelPath)
 
@@ -739,8 +707,7 @@ func (s *DefaultSite) RenderCanvas(f *obsidian.File) error {
 	}
 
 	// Unmarshal JSON data
-	var canvasData CanvasData
-	err = json.Unmarshal(source, &canvasData)
+	canvasData, err := canvas.Parse(source)
 	if err != nil {
 		return err
 	}
@@ -796,6 +763,16 @@ func (s *DefaultSite) RenderCanvas(f *obsidian.File) error {
 					continue
 				}
 				canvasData.Nodes[i].HtmlContent = renderedNote
+				canvasData.Nodes[i].Excerpt = canvas.PlainText(string(noteContent))
+
+				relNotePath, err := filepath.Rel(InputDir, linkedFilePath)
+				if err != nil {
+					continue
+				}
+				webPath, err := s.Obsidian.GetPageWebPath(s.Obsidian.GetSlugPath(relNotePath), linkedExt)
+				if err == nil {
+					canvasData.Nodes[i].Href = webPath
+				}
 			}
 		}
 	}
@@ -817,10 +794,20 @@ func (s *DefaultSite) RenderCanvas(f *obsidian.File) error {
 		return err
 	}
 
+	// Static export, used as <noscript> fallback, download and print version
+	outDir := filepath.Dir(f.OutPath)
+	opts := s.canvasExportOptions(f.Name)
+	svg := canvas.RenderSVG(canvasData, opts)
+	svgName := f.Name + "-canvas.svg"
+	if err := os.WriteFile(filepath.Join(outDir, svgName), svg, 0644); err != nil {
+		l.Warn("Couldn't write canvas SVG", "error", err)
+	}
+
 	// Executes the template
 	pageData := DefaultSitePageData{
 		Site:          s,
 		CanvasContent: template.JS(string(source)),
+		CanvasSVG:     template.HTML(svg),
 		File:          f,
 		Breadcrumbs:   breadcrumbs,
 		IsCanvas:      true,
@@ -830,34 +817,90 @@ func (s *DefaultSite) RenderCanvas(f *obsidian.File) error {
 	component := s.Layout.TemplRender(templData)
 	component.Render(context.Background(), minifierWriter)
 
-	s.GeneratePageOGImages(f.Name, f.Name, f.Name, filepath.Dir(f.OutPath))
+	s.GenerateCanvasOGImages(canvasData, opts, f.Name, outDir)
 
 	return nil
 }
 
-// GeneratePageOGImages creates OG and Twitter Card images for a page.
-// The slug is used to derive unique filenames (e.g. "my-page-og.png") so that
-// sibling pages in the same directory don't overwrite each other's images.
-func (s *DefaultSite) GeneratePageOGImages(title, description, slug, outDir string) {
-	cfg := ogimage.ImageConfig{
-		Title:       title,
-		Description: description,
-		SiteName:    s.SiteName,
-		AccentColor: s.Theme.Dark.Accent,
-		BgColor:     s.Theme.Dark.Bg,
-		TextColor:   s.Theme.Dark.Text,
-		Face:        s.OGFontFace,
+// canvasExportOptions returns the palette and font used for the static canvas export.
+// The light palette is used, since the exported files are also meant for printing.
+func (s *DefaultSite) canvasExportOptions(title string) canvas.Options {
+	c := s.Theme.Light
+	return canvas.Options{
+		Palette: canvas.Palette{
+			Bg:     c.Bg,
+			Text:   c.Text,
+			Muted:  c.Comment,
+			Border: c.SidebarBorder,
+			Accent: c.Accent,
+			Colors: map[string]string{
+				"1": c.Red,
+				"2": c.Orange,
+				"3": c.Yellow,
+				"4": c.Green,
+				"5": c.Cyan,
+				"6": c.Purple,
+			},
+		},
+		FontFamily: string(s.Theme.Font.Family),
+		FontSize:   16,
+		Title:      title,
 	}
+}
 
-	ogName := slug + "-og.png"
-	twitterName := slug + "-twitter.png"
+// GenerateCanvasOGImages rasterises a canvas preview as its OG and Twitter Card images.
+// Falls back to the regular text card if the canvas is empty or can't be rendered.
+func (s *DefaultSite) GenerateCanvasOGImages(d *canvas.Data, opts canvas.Options, slug, outDir string) {
+	if len(d.Nodes) == 0 {
+		s.GeneratePageOGImages(ogimage.ImageConfig{Title: slug}, slug, outDir)
+		return
+	}
 
-	if err := ogimage.GenerateOGImage(cfg, filepath.Join(outDir, ogName)); err != nil {
-		s.log.Warn("Couldn't generate OG image", "error", err)
+	og := canvas.BuildScene(d, opts, 1200, 630, s.OGFontFace)
+	twitter := canvas.BuildScene(d, opts, 1200, 600, s.OGFontFace)
+
+	errOG := ogimage.GenerateSceneImage(og, filepath.Join(outDir, slug+"-og.png"))
+	errTwitter := ogimage.GenerateSceneImage(twitter, filepath.Join(outDir, slug+"-twitter.png"))
+	if errOG != nil || errTwitter != nil {
+		s.log.Warn("Couldn't generate canvas preview image", "og", errOG, "twitter", errTwitter)
+		s.GeneratePageOGImages(ogimage.ImageConfig{Title: slug}, slug, outDir)
 	}
+}
 
-	if err := ogimage.GenerateTwitterImage(cfg, filepath.Join(outDir, twitterName)); err != nil {
-		s.log.Warn("Couldn't generate Twitter image", "error", err)
+// GeneratePageOGImages creates OG and Twitter Card images for a page from
+// the page-specific fields of cfg; the site name, colors, style, logo and
+// fonts are filled in from the site.
+// The slug is used to derive unique filenames (e.g. "my-page-og.png") so that
+// sibling pages in the same directory don't overwrite each other's images.
+func (s *DefaultSite) GeneratePageOGImages(cfg ogimage.ImageConfig, slug, outDir string) {
+	cfg.SiteName = s.SiteName
+	cfg.AccentColor = s.Theme.Dark.Accent
+	cfg.BgColor = s.Theme.Dark.Bg
+	cfg.TextColor = s.Theme.Dark.Text
+	cfg.Style = OGOptions.StyleOrDefault()
+	cfg.LogoPath = s.OGLogo
+	cfg.Fonts = s.OGFonts
+
+	cards := []struct {
+		name   string
+		key    func() (string, error)
+		render func(ogimage.ImageConfig, string) error
+	}{
+		{slug + "-og.png", cfg.OGKey, ogimage.GenerateOGImage},
+		{slug + "-twitter.png", cfg.TwitterKey, ogimage.GenerateTwitterImage},
+	}
+	for _, card := range cards {
+		outPath := filepath.Join(outDir, card.name)
+		render := func(path string) error { return card.render(cfg, path) }
+		key, err := card.key()
+		if err == nil {
+			err = writeCard(key, outPath, render)
+		} else {
+			err = render(outPath)
+		}
+		if err != nil {
+			s.log.Warn("Couldn't generate OG image", "file", card.name, "error", err)
+		}
 	}
 }
 
@@ -881,16 +924,32 @@ type DefaultSite struct {
 	Layout            *Layout                    // Selected and loaded layout
 	NavbarRoot        *obsidian.NavbarNode       // The root node of the sidebar
 	Markdown          *markdown.ObsidianMarkdown // Handles the rendering of obsidian markdown
-	Minifier          *minify.M                  // Minifier to minify html pages
+	Minifier          *minify.M                  // Minifier of the pages, stylesheets and scripts
 	DisableLocalGraph bool                       // If set, disables the local graph
 	DisableTOC        bool                       // If set, disables the Table of contents
 	DisableBacklinks  bool                       // If set, disables the backlinks panel
 	FlatURLs          bool                       // If set, handles flat urls (used in canonical)
-	OGFontFace        font.Face                  // Font face for OG image text rendering
+	OGFontFace        font.Face                  // Font face for canvas preview labels
+	OGFonts           ogimage.Fonts              // Typefaces of the OG cards
+	OGLogo            string                     // Logo shown on the OG cards, empty for none
 	Lang              string                     // Language code for the site
+	DefaultLang       string                     // Language of the pages without a language
 	log               *slog.Logger
 	Obsidian          *obsidian.Obsidian
 	ImageResults      map[string]*imgopt.Result // Optimized image variants keyed by WebPath
+
+	Navbars   map[string]*obsidian.NavbarNode // Sidebar of every language, on multilingual sites
+	Overrides *templates.Overrides            // Partials and assets of the _layouts folder of the vault
+	Assets    map[string]string               // Fingerprinted names of the stylesheets and scripts, by name
+}
+
+// navbar returns the sidebar of a language, or the sidebar of the whole site
+// when the site has a single language.
+func (s *DefaultSite) navbar(lang string) *obsidian.NavbarNode {
+	if root, ok := s.Navbars[lang]; ok {
+		return root
+	}
+	return s.NavbarRoot
 }
 
 // DefaultSitePage represents a page to be generated
@@ -903,6 +962,7 @@ type DefaultSitePageData struct {
 	Folder        *obsidian.Folder      // Information about the folder
 	Tag           *obsidian.Tag         // Information about the folder
 	CanvasContent template.JS           // Raw JS content for canvas hydration
+	CanvasSVG     template.HTML         // Static SVG export of the canvas
 	IsGraph       bool                  // Is the page a graph page?
 	IsCanvas      bool                  // Is the page a canvas page?
 	IsBase        bool                  // Is the page a base page?
@@ -911,7 +971,10 @@ type DefaultSitePageData struct {
 	IsTag         bool                  // Is the page a tag page?
 	Is404         bool                  // Is the page a 404 page?
 	Frontmatter   map[string]any        // Frontmatter data
+	Image         string                // Social image of a note, from its "image" property
 	Base          BaseData
+
+	Listing *templates.Listing // Children of a folder page or of a folder note
 }
 
 // BaseData is the data of the base
@@ -923,27 +986,7 @@ type BaseData struct {
 }
 
 // CanvasData represents the top-level structure of an Obsidian Canvas file.
-type CanvasData struct {
-	Nodes []CanvasNode     `json:"nodes"`
-	Edges []map[string]any `json:"edges"`
-}
+type CanvasData = canvas.Data
 
 // CanvasNode represents a single element (card, file, group) within the Canvas JSON.
-type CanvasNode struct {
-	ID     string `json:"id"`
-	Type   string `json:"type"`
-	Text   string `json:"text,omitempty"`
-	File   string `json:"file,omitempty"`
-	Label  string `json:"label,omitempty"`
-	X      int    `json:"x"`
-	Y      int    `json:"y"`
-	Width  int    `json:"width"`
-	Height int    `json:"height"`
-	Color  string `json:"color,omitempty"`
-
-	// Fields injected during build time for the frontend:
-	HtmlContent string `json:"htmlContent,omitempty"` // Rendered HTML for markdown files
-	IsImage     bool   `json:"isImage,omitempty"`     // Flag for image nodes
-	Src         string `json:"src,omitempty"`         // Web path for image source
-	URL         string `json:"url,omitempty"`         // Link URL
-}
+type CanvasNode = canvas.Node
@@ -10,6 +10,7 @@ import (
 
 	"github.com/otaleghani/kiln/internal/obsidian"
 	"github.com/otaleghani/kiln/internal/obsidian/bases"
+	"github.com/otaleghani/kiln/internal/ogimage"
 )
 
 func TestGeneratePageOGImages(t *testing.T) {
@@ -27,13 +28,13 @@ func TestGeneratePageOGImages(t *testing.T) {
 		log: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})),
 	}
 
-	site.GeneratePageOGImages("Test", "Desc", "", outDir)
+	site.GeneratePageOGImages(ogimage.ImageConfig{Title: "Test", Description: "Desc"}, "page", outDir)
 
-	// Verify og.png exists and is a valid PNG
-	ogPath := filepath.Join(outDir, "og.png")
+	// Verify page-og.png exists and is a valid PNG
+	ogPath := filepath.Join(outDir, "page-og.png")
 	ogFile, err := os.Open(ogPath)
 	if err != nil {
-		t.Fatalf("og.png not created: %v", err)
+		t.Fatalf("page-og.png not created: %v", err)
 	}
 	defer ogFile.Close()
 
@@ -45,11 +46,11 @@ func TestGeneratePageOGImages(t *testing.T) {
 		t.Errorf("og.png expected 1200x630, got %dx%d", bounds.Dx(), bounds.Dy())
 	}
 
-	// Verify twitter.png exists and is a valid PNG
-	twPath := filepath.Join(outDir, "twitter.png")
+	// Verify page-twitter.png exists and is a valid PNG
+	twPath := filepath.Join(outDir, "page-twitter.png")
 	twFile, err := os.Open(twPath)
 	if err != nil {
-		t.Fatalf("twitter.png not created: %v", err)
+		t.Fatalf("page-twitter.png not created: %v", err)
 	}
 	defer twFile.Close()
 
@@ -155,12 +156,12 @@ func TestEmptyViewsDoNotPanic(t *testing.T) {
 		{Name: "note2.md", Ext: ".md"},
 	}
 
-	activeFiles := bases.FilterFiles(allFiles, base.Filters)
+	activeFiles := bases.FilterFiles(allFiles, base.Filters, nil)
 
 	var fileGroups []*bases.FileGroup
 	var columns []string
 	if len(base.Views) > 0 {
-		activeFiles = bases.FilterFiles(activeFiles, base.Views[0].Filters)
+		activeFiles = bases.FilterFiles(activeFiles, base.Views[0].Filters, nil)
 		if base.Views[0].GroupBy.Property != "" {
 			fileGroups = bases.GroupFiles(activeFiles, base.Views[0].GroupBy.Property)
 		}
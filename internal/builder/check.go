@@ -0,0 +1,106 @@
+// Comparison of the output of incremental builds with a full build. @feature:builder
+package builder
+
+import (
+	"bytes"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// buildDatePattern matches the build time of the RSS feed, the only part of
+// the output differing between two builds of the same vault.
+var buildDatePattern = regexp.MustCompile(`<lastBuildDate>[^<]*</lastBuildDate>`)
+
+// CheckIncremental builds the whole site again into a temporary directory
+// and returns the differences between the output directory, as left by the
+// incremental builds, and that build. The state kept for the next
+// incremental build is left untouched.
+func CheckIncremental(log *slog.Logger) ([]string, error) {
+	dir, err := os.MkdirTemp("", "kiln-check-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	saved, outputDir := last, OutputDir
+	last, OutputDir = buildState{}, dir
+	Build(log)
+	last, OutputDir = saved, outputDir
+
+	return compareOutputs(outputDir, dir)
+}
+
+// compareOutputs returns the files differing between the output directory
+// got and the expected one, by relative path, as "missing: ", "stale: " or
+// "differs: " followed by the path.
+func compareOutputs(got, want string) ([]string, error) {
+	gotFiles, err := outputFiles(got)
+	if err != nil {
+		return nil, err
+	}
+	wantFiles, err := outputFiles(want)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []string{}
+	for relPath := range wantFiles {
+		if !gotFiles[relPath] {
+			diffs = append(diffs, "missing: "+relPath)
+			continue
+		}
+		same, err := sameOutput(filepath.Join(got, relPath), filepath.Join(want, relPath))
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			diffs = append(diffs, "differs: "+relPath)
+		}
+	}
+	for relPath := range gotFiles {
+		if !wantFiles[relPath] {
+			diffs = append(diffs, "stale: "+relPath)
+		}
+	}
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// outputFiles returns the relative paths of the files under dir.
+func outputFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = true
+		return nil
+	})
+	return files, err
+}
+
+// sameOutput reports whether two output files hold the same content, build
+// time aside.
+func sameOutput(a, b string) (bool, error) {
+	aData, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	bData, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	if filepath.Ext(a) == ".xml" {
+		aData = buildDatePattern.ReplaceAll(aData, nil)
+		bData = buildDatePattern.ReplaceAll(bData, nil)
+	}
+	return bytes.Equal(aData, bData), nil
+}
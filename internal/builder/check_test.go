@@ -0,0 +1,143 @@
+// @feature:builder Tests comparing incremental builds with full builds.
+package builder
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/otaleghani/kiln/internal/watch"
+)
+
+func TestCompareOutputs(t *testing.T) {
+	got := writeVault(t, map[string]string{
+		"same.html":          "same",
+		"notes/changed.html": "old",
+		"stale.html":         "page",
+		"feed.xml":           "<lastBuildDate>Mon, 19 Oct 2026 01:00:00 +0000</lastBuildDate>",
+	})
+	want := writeVault(t, map[string]string{
+		"same.html":          "same",
+		"notes/changed.html": "new",
+		"missing.html":       "page",
+		"feed.xml":           "<lastBuildDate>Mon, 19 Oct 2026 01:00:05 +0000</lastBuildDate>",
+	})
+
+	diffs, err := compareOutputs(got, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"differs: notes/changed.html", "missing: missing.html", "stale: stale.html"}
+	if !slices.Equal(diffs, expected) {
+		t.Errorf("got %v, want %v", diffs, expected)
+	}
+}
+
+// TestIncrementalMatchesFull applies changes to a vault one after the other,
+// checking that the output of every incremental build equals a full build.
+func TestIncrementalMatchesFull(t *testing.T) {
+	vault := writeVault(t, map[string]string{
+		"index.md":             "Home, see [[Kiln]]",
+		"Projects/Kiln.md":     "# Kiln\nA static site generator. ![[Embedded]]",
+		"Projects/Embedded.md": "Embedded text with ![[Deep]]",
+		"Projects/Deep.md":     "Deep content #deep",
+		"Guides/Guides.md":     "---\nlisting: list\n---\nGuides intro",
+		"Guides/Setup.md":      "Setup",
+		"Recipes/Bread.md":     "---\ntags: [food]\n---\nBread, see [[Kiln]]",
+		"Recipes.base":         "filters:\n  and:\n    - file.hasTag(\"food\")\nviews:\n  - type: table\n    name: Food\n",
+		"Board.canvas":         `{"nodes":[{"id":"a","type":"file","file":"Recipes/Bread.md","x":0,"y":0,"width":200,"height":100}],"edges":[]}`,
+	})
+	InputDir, OutputDir = vault, t.TempDir()
+	Mode, ThemeName, FontName, LayoutName = "default", "default", "inter", "default"
+	SiteName, Lang, DateSource, CacheDir = "Test", "en", "filesystem", t.TempDir()
+	defer func() {
+		InputDir, OutputDir, Mode, ThemeName, FontName, LayoutName = "", "", "", "", "", ""
+		SiteName, Lang, DateSource, CacheDir = "", "", "", ""
+		last = buildState{}
+	}()
+	write := func(relPath, content string) { writeFiles(t, vault, map[string]string{relPath: content}) }
+
+	log := slog.New(slog.DiscardHandler)
+	Build(log)
+	graph := watch.NewDepGraph()
+	graph.BuildFromFiles(VaultFiles())
+
+	steps := []struct {
+		name    string
+		apply   func()
+		changes watch.Changes
+	}{
+		{"embedded note", func() { write("Projects/Deep.md", "Deep content changed #deep") },
+			watch.Changes{Changed: []string{"Projects/Deep.md"}}},
+		{"tags and base", func() { write("Recipes/Bread.md", "Bread, see [[Kiln]] #bakery") },
+			watch.Changes{Changed: []string{"Recipes/Bread.md"}}},
+		{"backlinks", func() { write("Guides/Setup.md", "Setup of [[Bread]]") },
+			watch.Changes{Changed: []string{"Guides/Setup.md"}}},
+		{"new note", func() { write("Recipes/Cake.md", "Cake #food") },
+			watch.Changes{Changed: []string{"Recipes/Cake.md"}}},
+		{"removed note", func() { os.Remove(filepath.Join(vault, "Projects/Deep.md")) },
+			watch.Changes{Removed: []string{"Projects/Deep.md"}}},
+		{"removed tag", func() { write("Recipes/Cake.md", "Cake") },
+			watch.Changes{Changed: []string{"Recipes/Cake.md"}}},
+		{"layout override", func() { write("_layouts/footer.html", "<footer>Custom</footer>") },
+			watch.Changes{Changed: []string{"_layouts/footer.html"}}},
+	}
+	for _, step := range steps {
+		step.apply()
+		cs := watch.ComputeChanges(step.changes, graph)
+		IncrementalBuild(log, cs.Changed, cs.Rebuild, cs.Remove)
+		graph.UpdateSources(VaultFiles(), cs.Rebuild)
+
+		diffs, err := CheckIncremental(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diffs) > 0 {
+			t.Errorf("%s: incremental build differs from a full build: %v", step.name, diffs)
+		}
+	}
+}
+
+// TestIncrementalKeepsLocalGraphs checks that incremental builds only write
+// the local graphs whose neighbourhood changed.
+func TestIncrementalKeepsLocalGraphs(t *testing.T) {
+	vault := writeVault(t, map[string]string{
+		"A.md": "Links to [[B]]",
+		"B.md": "B",
+		"C.md": "C",
+		"D.md": "D",
+	})
+	InputDir, OutputDir = vault, t.TempDir()
+	Mode, ThemeName, FontName, LayoutName = "default", "default", "inter", "default"
+	SiteName, Lang, DateSource, CacheDir = "Test", "en", "filesystem", t.TempDir()
+	defer func() {
+		InputDir, OutputDir, Mode, ThemeName, FontName, LayoutName = "", "", "", "", "", ""
+		SiteName, Lang, DateSource, CacheDir = "", "", "", ""
+		last = buildState{}
+	}()
+
+	log := slog.New(slog.DiscardHandler)
+	Build(log)
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := os.Chtimes(filepath.Join(OutputDir, localGraphDir, name+".json"), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// C now links to D: the graphs of C and D change, not those of A and B
+	writeFiles(t, vault, map[string]string{"C.md": "Links to [[D]]"})
+	IncrementalBuild(log, []string{"C.md"}, []string{"C.md"}, nil)
+	for name, changed := range map[string]bool{"a": false, "b": false, "c": true, "d": true} {
+		info, err := os.Stat(filepath.Join(OutputDir, localGraphDir, name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rewritten := !info.ModTime().Equal(old); rewritten != changed {
+			t.Errorf("%s.json rewritten: %v, want %v", name, rewritten, changed)
+		}
+	}
+}
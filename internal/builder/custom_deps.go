@@ -0,0 +1,204 @@
+// Dependencies between the pages, templates and data files of the custom mode, for incremental builds. @feature:builder-custom
+package builder
+
+import (
+	"html/template"
+	"path/filepath"
+	"strings"
+	"text/template/parse"
+)
+
+// customCache keeps the rendered notes of a custom mode build, so that the
+// incremental build after it only renders the changed ones again.
+type customCache struct {
+	content map[string]renderedContent // key = RelPath of the page
+}
+
+// renderedContent is the markdown of a page rendered to HTML.
+type renderedContent struct {
+	Content template.HTML
+	TOC     template.HTML
+}
+
+// pageTemplate returns the template of a page, its custom one or the layout
+// of its collection, and the name of the template to execute.
+func (s *CustomSite) pageTemplate(page *CustomPage) (*template.Template, string) {
+	if page.Template != nil {
+		return page.Template, strings.TrimSuffix(filepath.Base(page.Path), ".md") + ".html"
+	}
+	config := s.ConfigsLookup[page.Collection]
+	if config == nil {
+		return nil, ""
+	}
+	return config.Template, filepath.Base(config.LayoutPath)
+}
+
+// affectedPages returns the RelPaths of the pages an incremental build has
+// to render again, or nil when every page has to.
+func (s *CustomSite) affectedPages() map[string]bool {
+	if RebuildFilter == nil {
+		return nil
+	}
+
+	affected := make(map[string]bool)
+	// The pages linking the changed notes
+	for relPath := range RebuildFilter {
+		if _, ok := s.Pages[relPath]; ok {
+			affected[relPath] = true
+		}
+	}
+
+	uses := make(map[string]map[string]bool, len(s.Pages))
+	for relPath, page := range s.Pages {
+		tmpl, name := s.pageTemplate(page)
+		uses[relPath] = templateFuncs(tmpl, name)
+	}
+	addUsing := func(fn string) {
+		for relPath, funcs := range uses {
+			if funcs[fn] {
+				affected[relPath] = true
+			}
+		}
+	}
+	addIf := func(match func(page *CustomPage) bool) {
+		for relPath, page := range s.Pages {
+			if match(page) {
+				affected[relPath] = true
+			}
+		}
+	}
+	// referencing matches the pages with a field pointing to a page in dir,
+	// or to the page relPath when given.
+	referencing := func(dir, relPath string) func(page *CustomPage) bool {
+		points := func(ref *CustomPage) bool {
+			if relPath != "" {
+				return ref.RelPath == relPath
+			}
+			return getConfigDirectory(ref.RelPath) == dir
+		}
+		return func(page *CustomPage) bool {
+			for _, field := range page.Fields {
+				if field.Reference != nil && points(field.Reference) {
+					return true
+				}
+				for _, ref := range field.References {
+					if points(ref) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	}
+
+	for _, relPath := range append(append([]string{}, changedFiles...), removedFiles...) {
+		name := filepath.Base(relPath)
+		dir := getConfigDirectory(relPath)
+		inDir := func(page *CustomPage) bool { return getConfigDirectory(page.RelPath) == dir }
+
+		switch {
+		case filepath.Ext(name) == ".html" && strings.HasPrefix(name, "_"):
+			// Components are part of every template
+			return nil
+		case relPath == "env.json":
+			addUsing("env")
+		case name == "config.json":
+			addIf(inDir)
+			addIf(referencing(dir, ""))
+		case name == "layout.html":
+			addIf(func(page *CustomPage) bool { return inDir(page) && page.Template == nil })
+		case filepath.Ext(name) == ".html":
+			note := strings.TrimSuffix(relPath, ".html") + ".md"
+			if _, ok := s.Pages[note]; ok {
+				affected[note] = true
+			}
+		case filepath.Ext(name) == ".md":
+			if _, ok := s.Pages[relPath]; ok {
+				affected[relPath] = true
+			}
+			// The siblings, the pages referencing it and the lookups
+			addIf(inDir)
+			addIf(referencing(dir, relPath))
+			addUsing("page")
+			addUsing("tag")
+		default:
+			addIf(func(page *CustomPage) bool {
+				for _, field := range page.Fields {
+					if field.Image != nil && field.Image.RelPath == relPath {
+						return true
+					}
+				}
+				return false
+			})
+			addUsing("asset")
+		}
+	}
+	return affected
+}
+
+// templateFuncs returns the names of the functions called by the template
+// name of t, and by the templates it executes.
+func templateFuncs(t *template.Template, name string) map[string]bool {
+	funcs := make(map[string]bool)
+	if t == nil {
+		return funcs
+	}
+	visited := make(map[string]bool)
+	var visit func(name string)
+	var walk func(node parse.Node)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		if tmpl := t.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+			walk(tmpl.Tree.Root)
+		}
+	}
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IdentifierNode:
+			funcs[n.Ident] = true
+		case *parse.IfNode:
+			walkBranch(walk, &n.BranchNode)
+		case *parse.RangeNode:
+			walkBranch(walk, &n.BranchNode)
+		case *parse.WithNode:
+			walkBranch(walk, &n.BranchNode)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+			visit(n.Name)
+		}
+	}
+	visit(name)
+	return funcs
+}
+
+// walkBranch walks the pipeline and both lists of an if, range or with.
+func walkBranch(walk func(parse.Node), n *parse.BranchNode) {
+	walk(n.Pipe)
+	walk(n.List)
+	walk(n.ElseList)
+}
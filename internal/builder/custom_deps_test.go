@@ -0,0 +1,101 @@
+// @feature:builder-custom Tests for the dependencies of the custom mode pages.
+package builder
+
+import (
+	"html/template"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	site := &CustomSite{}
+	tmpl := template.New("base").Funcs(site.getFuncMap())
+	tmpl = template.Must(tmpl.Parse(`{{ define "card" }}{{ with . | get "author" }}{{ asset "a.png" }}{{ end }}{{ end }}`))
+	tmpl = template.Must(tmpl.Parse(`{{ define "unused" }}{{ tag "go" }}{{ end }}`))
+	tmpl = template.Must(tmpl.New("layout.html").Parse(
+		`{{ .Site | env "name" }}{{ range .Page | get "Siblings" }}{{ template "card" . }}{{ end }}`,
+	))
+
+	funcs := templateFuncs(tmpl, "layout.html")
+	for _, fn := range []string{"env", "get", "asset"} {
+		if !funcs[fn] {
+			t.Errorf("expected %q to be used, got %v", fn, funcs)
+		}
+	}
+	if funcs["tag"] {
+		t.Error("the functions of templates never executed must not be reported")
+	}
+}
+
+func TestAffectedPages(t *testing.T) {
+	parse := func(name, src string) *template.Template {
+		site := &CustomSite{}
+		tmpl := template.New("base").Funcs(site.getFuncMap())
+		return template.Must(tmpl.New(name).Parse(src))
+	}
+	ada := &CustomPage{RelPath: "authors/ada.md", Collection: "authors"}
+	first := &CustomPage{RelPath: "posts/first.md", Collection: "posts", Fields: map[string]*FieldContent{
+		"author": {Reference: ada},
+	}}
+	second := &CustomPage{RelPath: "posts/second.md", Collection: "posts", Fields: map[string]*FieldContent{
+		"cover": {Image: &Asset{RelPath: "posts/cover.png"}},
+	}}
+	about := &CustomPage{Path: "about.md", RelPath: "about.md", Template: parse("about.html", `{{ .Site | env "name" }}`)}
+
+	site := &CustomSite{
+		Pages: map[string]*CustomPage{
+			ada.RelPath: ada, first.RelPath: first, second.RelPath: second, about.RelPath: about,
+		},
+		ConfigsLookup: map[string]*Config{
+			"authors": {Template: parse("layout.html", `{{ .Page | get "name" }}`), LayoutPath: "authors/layout.html"},
+			"posts":   {Template: parse("layout.html", `{{ .Page | get "title" }}`), LayoutPath: "posts/layout.html"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		changed []string
+		removed []string
+		rebuild []string
+		want    []string // nil means every page
+	}{
+		{"page and siblings", []string{"posts/second.md"}, nil, nil, []string{"posts/first.md", "posts/second.md"}},
+		{"referenced page", []string{"authors/ada.md"}, nil, nil, []string{"authors/ada.md", "posts/first.md"}},
+		{"removed page", nil, []string{"authors/bob.md"}, nil, []string{"authors/ada.md"}},
+		{"link dependents", []string{"about.md"}, nil, []string{"about.md", "posts/first.md"}, []string{"about.md", "posts/first.md"}},
+		{"collection layout", []string{"authors/layout.html"}, nil, nil, []string{"authors/ada.md"}},
+		{"collection config", []string{"authors/config.json"}, nil, nil, []string{"authors/ada.md", "posts/first.md"}},
+		{"custom template", []string{"about.html"}, nil, nil, []string{"about.md"}},
+		{"environment", []string{"env.json"}, nil, nil, []string{"about.md"}},
+		{"image field", []string{"posts/cover.png"}, nil, nil, []string{"posts/second.md"}},
+		{"component", []string{"_card.html"}, nil, nil, nil},
+	}
+	defer func() { RebuildFilter, changedFiles, removedFiles = nil, nil, nil }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RebuildFilter = map[string]struct{}{}
+			for _, relPath := range append(tt.rebuild, tt.changed...) {
+				RebuildFilter[relPath] = struct{}{}
+			}
+			changedFiles, removedFiles = tt.changed, tt.removed
+
+			affected := site.affectedPages()
+			if tt.want == nil {
+				if affected != nil {
+					t.Fatalf("expected every page, got %v", affected)
+				}
+				return
+			}
+			got := slices.Sorted(maps.Keys(affected))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	RebuildFilter = nil
+	if site.affectedPages() != nil {
+		t.Error("a full build must render every page")
+	}
+}
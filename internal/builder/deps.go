@@ -0,0 +1,225 @@
+// Dependencies of the pages on the rest of the vault, for incremental builds. @feature:builder
+package builder
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/canvas"
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// siteDirs are the folders of the vault whose files are part of every page.
+var siteDirs = []string{LayoutsDir, i18n.Dir, "_themes"}
+
+// pageSignatures returns, by RelPath, what the page of every file shows of
+// the other files of the vault: its backlinks and mentions, its
+// translations and its breadcrumbs. A page whose signature changes after an
+// update of the vault has to be rendered again.
+func pageSignatures(o *obsidian.Obsidian) map[string]string {
+	sigs := make(map[string]string, len(o.Vault.Files))
+	for _, f := range o.Vault.Files {
+		var b strings.Builder
+		for _, bl := range f.Backlinks {
+			fmt.Fprintf(&b, "backlink %s\n", bl.RelPath)
+		}
+		for _, m := range f.BacklinkContexts {
+			fmt.Fprintf(&b, "context %s %q %q %q\n", m.Source.RelPath, m.Before, m.Text, m.After)
+		}
+		for _, m := range f.UnlinkedMentions {
+			fmt.Fprintf(&b, "mention %s %q %q %q\n", m.Source.RelPath, m.Before, m.Text, m.After)
+		}
+		for _, t := range f.Translations {
+			fmt.Fprintf(&b, "translation %s %s\n", t.Lang, t.WebPath)
+		}
+		var crumbs []obsidian.Breadcrumb
+		if f.NoteOf != nil {
+			crumbs, _ = o.GetFolderBreadcrumbs(f.NoteOf)
+		} else {
+			crumbs, _ = o.GetBreadcrumbs(f)
+		}
+		for _, c := range crumbs {
+			fmt.Fprintf(&b, "crumb %q %s\n", c.Label, c.Url)
+		}
+		sigs[f.RelPath] = b.String()
+	}
+	return sigs
+}
+
+// navbarSignature returns the structure of sidebars, without the active
+// page. Every page has to be rendered again when it changes.
+func navbarSignature(roots ...*obsidian.NavbarNode) string {
+	var b strings.Builder
+	var write func(n *obsidian.NavbarNode, depth int)
+	write = func(n *obsidian.NavbarNode, depth int) {
+		fmt.Fprintf(&b, "%d %q %s %t %t %t %t %g %t %q\n", depth, n.Name, n.Path,
+			n.IsFolder, n.IsCanvas, n.IsBase, n.IsNote, n.Weight, n.Weighted, n.Icon)
+		for _, child := range n.Children {
+			write(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		if root != nil {
+			write(root, 0)
+		}
+	}
+	return b.String()
+}
+
+// expandRebuild adds to RebuildFilter the pages depending on the changed
+// files beyond their links: every page when the sidebar or a file of the
+// site folders changed, the bases when a note changed, the canvases showing
+// a changed file, the folder notes listing one and the notes embedding a
+// page rendered again.
+func expandRebuild(o *obsidian.Obsidian, navbar string, log *slog.Logger) {
+	if RebuildFilter == nil {
+		return
+	}
+	changes := append(append([]string{}, changedFiles...), removedFiles...)
+	rebuild := func(f *obsidian.File) { RebuildFilter[f.RelPath] = struct{}{} }
+
+	if navbar != last.navbar || touchesSiteDirs(changes) {
+		log.Info("Navigation or layout changed, rendering every page")
+		for _, f := range o.Vault.Files {
+			if isPageExt(f.Ext) {
+				rebuild(f)
+			}
+		}
+		return
+	}
+
+	changedSet := make(map[string]bool, len(changes))
+	notesChanged := false
+	for _, relPath := range changes {
+		changedSet[filepath.ToSlash(relPath)] = true
+		notesChanged = notesChanged || filepath.Ext(relPath) == ".md"
+	}
+
+	for _, f := range o.Vault.Files {
+		switch f.Ext {
+		case ".base":
+			// The filters of a base may match any note
+			if notesChanged {
+				rebuild(f)
+			}
+		case ".canvas":
+			if canvasShows(f, changedSet) {
+				rebuild(f)
+			}
+		}
+	}
+
+	// The folder notes listing the changed files, up to the root
+	for _, relPath := range changes {
+		for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+			if folder := findFolder(o, dir); folder != nil && folder.Note != nil {
+				rebuild(folder.Note)
+			}
+		}
+	}
+
+	// The notes embedding a rebuilt one, until none is left
+	for added := true; added; {
+		added = false
+		for _, f := range o.Vault.Files {
+			if _, ok := RebuildFilter[f.RelPath]; ok || f.Ext != ".md" {
+				continue
+			}
+			for _, embed := range f.Embeds {
+				target := o.Vault.Links.Resolve(f, embed)
+				if target == nil {
+					continue
+				}
+				if _, ok := RebuildFilter[target.RelPath]; ok {
+					rebuild(f)
+					added = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// touchesSiteDirs reports whether a path is in one of the siteDirs.
+func touchesSiteDirs(paths []string) bool {
+	for _, relPath := range paths {
+		top, _, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+		for _, dir := range siteDirs {
+			if top == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// canvasShows reports whether one of the file nodes of a canvas points to
+// one of the given paths.
+func canvasShows(f *obsidian.File, paths map[string]bool) bool {
+	source, err := os.ReadFile(f.Path)
+	if err != nil {
+		return false
+	}
+	data, err := canvas.Parse(source)
+	if err != nil {
+		return false
+	}
+	for _, node := range data.Nodes {
+		if node.Type == "file" && paths[filepath.ToSlash(node.File)] {
+			return true
+		}
+	}
+	return false
+}
+
+// findFolder returns the folder of the vault at relPath, or nil.
+func findFolder(o *obsidian.Obsidian, relPath string) *obsidian.Folder {
+	for _, folder := range o.Vault.Folders {
+		if folder.RelPath == relPath {
+			return folder
+		}
+	}
+	return nil
+}
+
+// isPageExt reports whether files with the extension are rendered to pages.
+func isPageExt(ext string) bool {
+	return ext == ".md" || ext == ".base" || ext == ".canvas"
+}
+
+// addPageOutputs records the files written for a page: its HTML and its
+// social cards.
+func addPageOutputs(outputs map[string]struct{}, outPath, slug string) {
+	dir := filepath.Dir(outPath)
+	outputs[outPath] = struct{}{}
+	outputs[filepath.Join(dir, slug+"-og.png")] = struct{}{}
+	outputs[filepath.Join(dir, slug+"-twitter.png")] = struct{}{}
+}
+
+// removeStale deletes the folder and tag pages written by the previous
+// build but not by this one, unless a page of the vault took their place,
+// and keeps the current ones for the next.
+func removeStale(o *obsidian.Obsidian, derived map[string]struct{}) {
+	pages := make(map[string]struct{})
+	for _, f := range o.Vault.Files {
+		if isPageExt(f.Ext) {
+			addPageOutputs(pages, f.OutPath, f.Name)
+		}
+	}
+	for outPath := range last.derived {
+		_, written := derived[outPath]
+		_, taken := pages[outPath]
+		if written || taken {
+			continue
+		}
+		os.Remove(outPath)
+		if filepath.Base(outPath) == "index.html" {
+			os.Remove(filepath.Dir(outPath))
+		}
+	}
+	last.derived = derived
+}
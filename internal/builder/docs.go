@@ -0,0 +1,203 @@
+// Sections, versions and page order of the docs layout. @feature:layouts
+package builder
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/otaleghani/kiln/internal/templates"
+)
+
+// versionName matches the names of the folders holding a version of the
+// docs, e.g. "v2", "1.4" or "v2.1.3".
+var versionName = regexp.MustCompile(`^v?\d+(\.\d+)*$`)
+
+// docsData builds the section tabs, the version selector, the sidebar and
+// the page links of the docs layout for the page at pagePath, from the
+// navbar of its language. editPath is the path of the file of the page in
+// the vault, empty for pages without a file.
+func docsData(root *obsidian.NavbarNode, pagePath, editPath string) *templates.DocsData {
+	data := &templates.DocsData{EditURL: editURL(editPath)}
+	if root == nil {
+		return data
+	}
+	chain := navbarChain(root.Children, pagePath)
+
+	// The first folder on the way to the page whose siblings are all
+	// versions is the version of the page.
+	version := -1
+	for i, node := range chain {
+		parent := root
+		if i > 0 {
+			parent = chain[i-1]
+		}
+		if node.IsFolder && isVersionFolder(node) && allVersions(parent.Children) {
+			version = i
+			data.Versions = versionLinks(parent.Children, node, chain[i+1:])
+			break
+		}
+	}
+
+	if version != 0 {
+		for _, node := range root.Children {
+			if !node.IsFolder {
+				continue
+			}
+			data.Sections = append(data.Sections, templates.DocsLink{
+				Name:    node.Name,
+				URL:     node.Path,
+				Current: len(chain) > 0 && chain[0] == node,
+			})
+		}
+	}
+
+	var container *obsidian.NavbarNode
+	switch {
+	case version >= 0:
+		container = chain[version]
+	case len(chain) > 0 && chain[0].IsFolder:
+		container = chain[0]
+	}
+	if container != nil {
+		data.Sidebar = container.Children
+	} else {
+		for _, node := range root.Children {
+			if !node.IsFolder {
+				data.Sidebar = append(data.Sidebar, node)
+			}
+		}
+	}
+
+	pages := flattenPages(data.Sidebar)
+	current := slices.IndexFunc(pages, func(n *obsidian.NavbarNode) bool { return n.Path == pagePath })
+	switch {
+	case current >= 0:
+		if current > 0 {
+			data.Prev = pageLink(pages[current-1])
+		}
+		if current < len(pages)-1 {
+			data.Next = pageLink(pages[current+1])
+		}
+	case container != nil && container.Path == pagePath && len(pages) > 0:
+		data.Next = pageLink(pages[0])
+	}
+	return data
+}
+
+// navbarChain returns the nodes from the top of the navbar down to the node
+// of the page at pagePath, or nil when the page isn't in the navbar.
+func navbarChain(nodes []*obsidian.NavbarNode, pagePath string) []*obsidian.NavbarNode {
+	for _, node := range nodes {
+		if node.Path == pagePath {
+			return []*obsidian.NavbarNode{node}
+		}
+		if chain := navbarChain(node.Children, pagePath); chain != nil {
+			return append([]*obsidian.NavbarNode{node}, chain...)
+		}
+	}
+	return nil
+}
+
+// isVersionFolder reports whether the folder node is named like a version.
+func isVersionFolder(node *obsidian.NavbarNode) bool {
+	return versionName.MatchString(strings.ToLower(node.Name))
+}
+
+// allVersions reports whether the folders among nodes are at least two and
+// all named like versions.
+func allVersions(nodes []*obsidian.NavbarNode) bool {
+	folders := 0
+	for _, node := range nodes {
+		if !node.IsFolder {
+			continue
+		}
+		if !isVersionFolder(node) {
+			return false
+		}
+		folders++
+	}
+	return folders >= 2
+}
+
+// versionLinks lists the version folders among nodes, newest first. Each
+// links to the page at the same place as the current one in that version,
+// or to the closest folder above it that the version has.
+func versionLinks(nodes []*obsidian.NavbarNode, current *obsidian.NavbarNode, below []*obsidian.NavbarNode) []templates.DocsLink {
+	versions := []*obsidian.NavbarNode{}
+	for _, node := range nodes {
+		if node.IsFolder {
+			versions = append(versions, node)
+		}
+	}
+	slices.SortFunc(versions, func(a, b *obsidian.NavbarNode) int {
+		return compareVersions(b.Name, a.Name)
+	})
+
+	links := make([]templates.DocsLink, 0, len(versions))
+	for _, v := range versions {
+		target := v
+		for _, step := range below {
+			i := slices.IndexFunc(target.Children, func(n *obsidian.NavbarNode) bool { return n.Name == step.Name })
+			if i < 0 {
+				break
+			}
+			target = target.Children[i]
+		}
+		links = append(links, templates.DocsLink{Name: v.Name, URL: target.Path, Current: v == current})
+	}
+	return links
+}
+
+// compareVersions compares two version names part by part, numerically.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(strings.ToLower(a), "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(strings.ToLower(b), "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			return na - nb
+		}
+	}
+	return 0
+}
+
+// flattenPages lists the nodes with a page in navbar order: the files and
+// the folders with a folder note.
+func flattenPages(nodes []*obsidian.NavbarNode) []*obsidian.NavbarNode {
+	pages := []*obsidian.NavbarNode{}
+	for _, node := range nodes {
+		if !node.IsFolder || node.IsNote || node.IsCanvas || node.IsBase {
+			pages = append(pages, node)
+		}
+		pages = append(pages, flattenPages(node.Children)...)
+	}
+	return pages
+}
+
+// pageLink returns the link to the page of a navbar node.
+func pageLink(node *obsidian.NavbarNode) *templates.DocsLink {
+	return &templates.DocsLink{Name: node.Name, URL: node.Path}
+}
+
+// editURL returns the link to the source of the file at relPath, replacing
+// {path} in the EditURL pattern. It's empty without a pattern or a file.
+func editURL(relPath string) string {
+	if EditURL == "" || relPath == "" {
+		return ""
+	}
+	segments := strings.Split(strings.ReplaceAll(relPath, "\\", "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.ReplaceAll(EditURL, "{path}", strings.Join(segments, "/"))
+}
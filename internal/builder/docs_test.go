@@ -0,0 +1,91 @@
+// @feature:layouts Tests for the sections, versions and page links of the docs layout.
+package builder
+
+import (
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// docsNavbar returns a navbar with a versioned guide section and a blog.
+func docsNavbar() *obsidian.NavbarNode {
+	page := func(name, path string) *obsidian.NavbarNode {
+		return &obsidian.NavbarNode{Name: name, Path: path, IsNote: true}
+	}
+	folder := func(name, path string, children ...*obsidian.NavbarNode) *obsidian.NavbarNode {
+		return &obsidian.NavbarNode{Name: name, Path: path, IsFolder: true, Children: children}
+	}
+	return folder("Home", "/",
+		folder("blog", "/blog", page("hello", "/blog/hello")),
+		folder("guide", "/guide",
+			folder("v1.10", "/guide/v1.10",
+				page("intro", "/guide/v1.10/intro"),
+				folder("setup", "/guide/v1.10/setup", page("install", "/guide/v1.10/setup/install")),
+			),
+			folder("v1.9", "/guide/v1.9", page("intro", "/guide/v1.9/intro")),
+			folder("v2", "/guide/v2", page("intro", "/guide/v2/intro")),
+		),
+		page("index", "/index"),
+	)
+}
+
+func TestDocsData_SectionsAndVersions(t *testing.T) {
+	data := docsData(docsNavbar(), "/guide/v1.10/setup/install", "")
+
+	if len(data.Sections) != 2 || data.Sections[0].Current || !data.Sections[1].Current {
+		t.Errorf("Sections = %+v, want blog and the current guide", data.Sections)
+	}
+	want := []struct{ name, url string }{
+		{"v2", "/guide/v2"},
+		{"v1.10", "/guide/v1.10/setup/install"},
+		{"v1.9", "/guide/v1.9"},
+	}
+	if len(data.Versions) != len(want) {
+		t.Fatalf("Versions = %+v", data.Versions)
+	}
+	for i, w := range want {
+		if v := data.Versions[i]; v.Name != w.name || v.URL != w.url || v.Current != (w.name == "v1.10") {
+			t.Errorf("Versions[%d] = %+v, want %s linking to %s", i, v, w.name, w.url)
+		}
+	}
+	if len(data.Sidebar) != 2 || data.Sidebar[0].Name != "intro" {
+		t.Errorf("Sidebar = %+v, want the pages of v1.10", data.Sidebar)
+	}
+
+	other := docsData(docsNavbar(), "/guide/v2/intro", "")
+	if other.Versions[2].URL != "/guide/v1.9/intro" {
+		t.Errorf("the version selector doesn't link to the same page, got %+v", other.Versions[2])
+	}
+}
+
+func TestDocsData_PrevNext(t *testing.T) {
+	data := docsData(docsNavbar(), "/guide/v1.10/intro", "")
+	if data.Prev != nil || data.Next == nil || data.Next.URL != "/guide/v1.10/setup/install" {
+		t.Errorf("Prev = %+v, Next = %+v, want only the next page", data.Prev, data.Next)
+	}
+	data = docsData(docsNavbar(), "/guide/v1.10/setup/install", "")
+	if data.Next != nil || data.Prev == nil || data.Prev.URL != "/guide/v1.10/intro" {
+		t.Errorf("Prev = %+v, Next = %+v, want only the previous page", data.Prev, data.Next)
+	}
+	data = docsData(docsNavbar(), "/blog", "")
+	if data.Next == nil || data.Next.URL != "/blog/hello" || data.Versions != nil {
+		t.Errorf("the section page doesn't link to its first page, got %+v", data)
+	}
+	data = docsData(docsNavbar(), "/index", "")
+	if len(data.Sidebar) != 1 || data.Sidebar[0].Name != "index" {
+		t.Errorf("Sidebar = %+v, want the pages at the top of the vault", data.Sidebar)
+	}
+}
+
+func TestDocsData_EditURL(t *testing.T) {
+	EditURL = "https://github.com/me/notes/edit/main/{path}"
+	t.Cleanup(func() { EditURL = "" })
+
+	data := docsData(docsNavbar(), "/guide/v2/intro", "guide/v2/Get started.md")
+	if want := "https://github.com/me/notes/edit/main/guide/v2/Get%20started.md"; data.EditURL != want {
+		t.Errorf("EditURL = %q, want %q", data.EditURL, want)
+	}
+	if data := docsData(docsNavbar(), "/blog", ""); data.EditURL != "" {
+		t.Errorf("EditURL = %q on a page without a file", data.EditURL)
+	}
+}
@@ -0,0 +1,61 @@
+// Listing of the children of folder pages and folder notes. @feature:folders
+package builder
+
+import (
+	"github.com/otaleghani/kiln/internal/listing"
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/otaleghani/kiln/internal/templates"
+)
+
+// folderListing lists the sub-folders and pages of a folder with the
+// FolderOptions, overridden by the listing properties of the folder note.
+// Folder notes aren't listed among the pages: their folders are.
+func (s *DefaultSite) folderListing(f *obsidian.Folder, frontmatter map[string]any) *templates.Listing {
+	opts := FolderOptions.Resolve(frontmatter, s.log.With("folder", f.RelPath))
+	if opts.Style == listing.StyleNone {
+		return nil
+	}
+
+	items := []listing.Item{}
+	for _, sub := range f.Folders {
+		if len(sub.Files) == 0 && len(sub.Folders) == 0 {
+			continue
+		}
+		item := listing.Item{
+			Name:     s.Obsidian.DisplayName(sub.Name),
+			URL:      sub.WebPath,
+			IsFolder: true,
+			Created:  sub.Created,
+			Modified: sub.Modified,
+		}
+		if note := sub.Note; note != nil {
+			if title, ok := note.Frontmatter["nav_title"].(string); ok && title != "" {
+				item.Name = title
+			}
+			item.Weight, item.Weighted = note.NavWeight()
+			if opts.Excerpts && note.Ext == ".md" {
+				item.Excerpt = note.Excerpt()
+			}
+		}
+		items = append(items, item)
+	}
+	for _, file := range f.Files {
+		if file.NoteOf != nil {
+			continue
+		}
+		item := listing.Item{
+			Name:     s.Obsidian.NavName(file),
+			URL:      file.WebPath,
+			Created:  file.Created,
+			Modified: file.Modified,
+		}
+		item.Weight, item.Weighted = file.NavWeight()
+		if opts.Excerpts && file.Ext == ".md" {
+			item.Excerpt = file.Excerpt()
+		}
+		items = append(items, item)
+	}
+	opts.SortItems(items)
+
+	return &templates.Listing{Style: opts.Style, Items: items}
+}
@@ -0,0 +1,193 @@
+// Graph data generation: graph.json, per-page local graphs and attachment nodes. @feature:graph
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/graph"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// localGraphDir is the output folder holding the per-page local graph files.
+const localGraphDir = "_graph"
+
+// fileGraphNode returns the graph node of a rendered page.
+func fileGraphNode(f *obsidian.File) obsidian.GraphNode {
+	tags := make([]string, 0, len(f.Tags))
+	for t := range f.Tags {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return obsidian.GraphNode{
+		ID:      f.WebPath,
+		Label:   f.Name,
+		URL:     f.WebPath,
+		Val:     1,
+		Type:    f.Ext,
+		RelPath: f.RelPath,
+		Tags:    tags,
+	}
+}
+
+// attachmentGraph returns the nodes and links of the attachments embedded or linked by the notes.
+func (s *DefaultSite) attachmentGraph(notes []*obsidian.File) ([]obsidian.GraphNode, []obsidian.GraphLink) {
+	nodes := []obsidian.GraphNode{}
+	links := []obsidian.GraphLink{}
+	for _, note := range notes {
+		for _, raw := range note.Links {
+			target := linkTarget(raw)
+			if target == "" {
+				continue
+			}
+			file, _, err := s.Markdown.Resolver.FindFile([]byte(target))
+			if err != nil || file == nil || graph.IsPage(file.Ext) {
+				continue
+			}
+			nodes = append(nodes, obsidian.GraphNode{
+				ID:      file.WebPath,
+				Label:   file.FullName,
+				URL:     file.WebPath,
+				Val:     1,
+				Type:    file.Ext,
+				RelPath: file.RelPath,
+			})
+			links = append(links, obsidian.GraphLink{Source: note.WebPath, Target: file.WebPath, Kind: "attachment"})
+		}
+	}
+	return nodes, links
+}
+
+// linkTarget extracts the destination from a raw "[[target|alias]]" or "[text](target)" link.
+func linkTarget(raw string) string {
+	if strings.HasPrefix(raw, "[[") {
+		inner := strings.TrimSuffix(strings.TrimPrefix(raw, "[["), "]]")
+		inner, _, _ = strings.Cut(inner, "|")
+		return strings.TrimSpace(inner)
+	}
+	if i := strings.Index(raw, "]("); i != -1 {
+		target := strings.TrimSuffix(raw[i+2:], ")")
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		return strings.TrimSpace(target)
+	}
+	return ""
+}
+
+// localGraphRel returns the output path, relative to the output directory,
+// of the local graph file of the page with the given web path.
+func localGraphRel(webPath string) string {
+	prefix := ""
+	if u, err := url.Parse(BaseURL); err == nil {
+		prefix = strings.TrimSuffix(u.Path, "/")
+	}
+	rel := strings.Trim(strings.TrimPrefix(webPath, prefix), "/")
+	if rel == "" {
+		rel = "index"
+	}
+	return localGraphDir + "/" + rel + ".json"
+}
+
+// localGraphURL returns the public URL of the local graph file of a page.
+func localGraphURL(webPath string) string {
+	return strings.TrimSuffix(BaseURL, "/") + "/" + localGraphRel(webPath)
+}
+
+// graphLinks returns the links between pages found rendering the notes, in
+// their order. Incremental builds keep the links of the notes they don't
+// render again from the previous build.
+func (s *DefaultSite) graphLinks(notes []*obsidian.File) []obsidian.GraphLink {
+	found := make(map[string][]obsidian.GraphLink)
+	for _, link := range s.Markdown.Resolver.Links {
+		found[link.Source] = append(found[link.Source], link)
+	}
+
+	links := []obsidian.GraphLink{}
+	kept := make(map[string][]obsidian.GraphLink, len(notes))
+	for _, note := range notes {
+		noteLinks := found[note.WebPath]
+		if !shouldRebuild(note.RelPath) {
+			noteLinks = last.links[note.WebPath]
+		}
+		kept[note.WebPath] = noteLinks
+		links = append(links, noteLinks...)
+	}
+	last.links = kept
+	return links
+}
+
+// writeGraph computes the graph metrics and writes graph.json and, unless
+// the local graph is disabled, one local graph file per page.
+func (s *DefaultSite) writeGraph(nodes []obsidian.GraphNode, links []obsidian.GraphLink, log *slog.Logger) {
+	g := graph.Build(nodes, links, GraphOptions)
+	global := g.Global(GraphOptions)
+	log.Info(
+		"Generated graph",
+		"nodes", len(global.Nodes),
+		"links", len(global.Links),
+		"components", g.Components,
+		"orphans", g.Orphans,
+	)
+
+	jsonBytes, err := json.Marshal(global)
+	if err != nil {
+		log.Error("Couldn't marshal JSON", "error", err)
+		return
+	}
+	err = os.WriteFile(filepath.Join(OutputDir, "graph.json"), jsonBytes, 0644)
+	if err != nil {
+		log.Error("Couldn't create 'graph.json'", "error", err)
+	}
+
+	if DisableLocalGraph {
+		return
+	}
+	// Only the local graphs whose neighbourhood changed are written again,
+	// and those of the removed pages deleted. Full builds start from an
+	// empty output directory.
+	previous := last.graphs
+	if RebuildFilter == nil {
+		previous = nil
+	}
+	written := make(map[string][sha256.Size]byte, len(g.Nodes))
+	neighbourhoods := g.Neighbourhoods()
+	depth := GraphOptions.Depth()
+	for _, n := range g.Nodes {
+		if !graph.IsPage(n.Type) && n.Type != "folder" && n.Type != "tag" {
+			continue
+		}
+		out := filepath.Join(OutputDir, filepath.FromSlash(localGraphRel(n.ID)))
+		data, err := json.Marshal(neighbourhoods.Local(n.ID, depth))
+		if err != nil {
+			log.Warn("Couldn't marshal local graph", "node", n.ID, "error", err)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if prev, ok := previous[out]; ok && prev == sum {
+			written[out] = sum
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			log.Warn("Couldn't create local graph directory", "error", err)
+			continue
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			log.Warn("Couldn't write local graph", "node", n.ID, "error", err)
+			continue
+		}
+		written[out] = sum
+	}
+	for out := range previous {
+		if _, ok := written[out]; !ok {
+			os.Remove(out)
+		}
+	}
+	last.graphs = written
+}
@@ -0,0 +1,32 @@
+// @feature:builder Helpers writing the vaults of the tests.
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeVault writes the given files, by path relative to the vault, to a
+// temporary vault and returns its path.
+func writeVault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	return dir
+}
+
+// writeFiles writes the given files, by path relative to dir, creating
+// their folders.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
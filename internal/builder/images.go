@@ -0,0 +1,65 @@
+// Collects the image sizes requested by embeds before the variants are generated. @feature:imgopt
+package builder
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/imgopt"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// newImageCache returns the variant cache of a build, or nil when the cache
+// is disabled.
+func newImageCache() *imgopt.Cache {
+	if CacheDir == "" {
+		return nil
+	}
+	return imgopt.NewCache(filepath.Join(CacheDir, "images"))
+}
+
+// imageSizes returns the sizes requested by the image embeds of the notes,
+// such as ![[photo.png|300]] or ![Alt|300x200](photo.png), keyed by the
+// WebPath of the image.
+func imageSizes(vault *obsidian.Vault) map[string][]imgopt.Directives {
+	sizes := map[string][]imgopt.Directives{}
+	if vault.Links == nil {
+		return sizes
+	}
+	seen := map[string]bool{}
+	for _, file := range vault.Files {
+		for _, raw := range file.Embeds {
+			label := embedLabel(raw)
+			if label == "" {
+				continue
+			}
+			target := vault.Links.Resolve(file, raw)
+			if target == nil || !imgopt.IsOptimizable(target.Ext) {
+				continue
+			}
+			d, _ := imgopt.ParseDirectives(label)
+			key := target.WebPath + "\x00" + d.Key()
+			if !d.Sized() || seen[key] {
+				continue
+			}
+			seen[key] = true
+			sizes[target.WebPath] = append(sizes[target.WebPath], d)
+		}
+	}
+	return sizes
+}
+
+// embedLabel returns the part of a raw embed holding the alt text and the
+// directives: what follows the first pipe of a wikilink, or the alt text of
+// a markdown image when it contains a pipe.
+func embedLabel(raw string) string {
+	if strings.HasPrefix(raw, "[[") {
+		_, label, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(raw, "[["), "]]"), "|")
+		return label
+	}
+	end := strings.Index(raw, "](")
+	if end == -1 || !strings.Contains(raw[:end], "|") {
+		return ""
+	}
+	return raw[1:end]
+}
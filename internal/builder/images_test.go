@@ -0,0 +1,47 @@
+// @feature:imgopt Tests for collecting the image sizes requested by embeds.
+package builder
+
+import (
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+func TestEmbedLabel(t *testing.T) {
+	cases := map[string]string{
+		"[[photo.png]]":             "",
+		"[[photo.png|300]]":         "300",
+		"[[photo.png|Alt|300x200]]": "Alt|300x200",
+		"[Alt](photo.png)":          "",
+		"[Alt|300](photo.png)":      "Alt|300",
+		"[Alt|300](<my photo.png>)": "Alt|300",
+	}
+	for raw, want := range cases {
+		if got := embedLabel(raw); got != want {
+			t.Errorf("embedLabel(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestImageSizes(t *testing.T) {
+	photo := &obsidian.File{RelPath: "photo.png", Name: "photo", Ext: ".png", FullName: "photo.png", WebPath: "/photo.png"}
+	anim := &obsidian.File{RelPath: "anim.gif", Name: "anim", Ext: ".gif", FullName: "anim.gif", WebPath: "/anim.gif"}
+	note := &obsidian.File{RelPath: "note.md", Name: "note", Ext: ".md", FullName: "note.md", Embeds: []string{
+		"[[photo.png|300]]",
+		"[Alt|300](photo.png)",
+		"[[photo.png|Alt|300x300|crop]]",
+		"[[photo.png|Just alt]]",
+		"[[anim.gif|100]]",
+	}}
+	files := []*obsidian.File{photo, anim, note}
+	vault := &obsidian.Vault{Files: files, Links: obsidian.NewLinkIndex(files, nil)}
+
+	sizes := imageSizes(vault)
+	got := sizes["/photo.png"]
+	if len(got) != 2 || got[0].Key() != "300x0" || got[1].Key() != "300x300c50-50" {
+		t.Errorf("photo sizes = %+v, want 300x0 and a 300x300 crop", got)
+	}
+	if _, ok := sizes["/anim.gif"]; ok {
+		t.Error("images that cannot be resized should request no sizes")
+	}
+}
@@ -45,6 +45,16 @@ var layouts = map[string]*Layout{
 			return templates.SimpleLayout(d)
 		},
 	},
+
+	// The docs layout reuses the styles and scripts of the default one
+	"docs": {
+		Name:    "docs",
+		CssPath: "default_style.css",
+		JsPath:  "default_app.js",
+		TemplRender: func(d *templates.PageData) templ.Component {
+			return templates.DocsLayout(d)
+		},
+	},
 }
 
 // loadLayout loads the given layout files into memory
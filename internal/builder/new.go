@@ -0,0 +1,389 @@
+// Notes created by kiln new from the templates of the vault. @feature:scaffold
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"gopkg.in/yaml.v3"
+)
+
+// templateSettings are the settings of the Templates core plugin of
+// Obsidian, kept in .obsidian/templates.json.
+type templateSettings struct {
+	Folder     string `json:"folder"`
+	DateFormat string `json:"dateFormat"`
+	TimeFormat string `json:"timeFormat"`
+}
+
+// loadTemplateSettings reads the settings of the Templates plugin of the
+// vault, the missing ones taking their default value.
+func loadTemplateSettings() templateSettings {
+	var settings templateSettings
+	if data, err := os.ReadFile(filepath.Join(InputDir, ".obsidian", "templates.json")); err == nil {
+		json.Unmarshal(data, &settings)
+	}
+	if settings.Folder == "" {
+		settings.Folder = "Templates"
+	}
+	if settings.DateFormat == "" {
+		settings.DateFormat = "YYYY-MM-DD"
+	}
+	if settings.TimeFormat == "" {
+		settings.TimeFormat = "HH:mm"
+	}
+	return settings
+}
+
+// NewNote creates the note at relPath in the vault, adding ".md" when
+// missing, and returns its path. The note starts from the template called
+// name in the templates folder or, without a name, from the one named after
+// the folder of the note or else "Default", when they exist. In custom
+// mode, the frontmatter also gets the fields of the collection of the note
+// that the template lacks.
+func NewNote(relPath, name string, now time.Time, log *slog.Logger) (string, error) {
+	relPath = filepath.Clean(relPath)
+	if filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of the vault", relPath)
+	}
+	if filepath.Ext(relPath) != ".md" {
+		relPath += ".md"
+	}
+	path := filepath.Join(InputDir, relPath)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+
+	settings := loadTemplateSettings()
+	text, err := findTemplate(settings, relPath, name)
+	if err != nil {
+		return "", err
+	}
+	title := strings.TrimSuffix(filepath.Base(relPath), ".md")
+	text = expandPlaceholders(strings.ReplaceAll(text, "\r\n", "\n"), title, now, settings)
+
+	if Mode == "custom" {
+		fm, body, _ := splitFrontmatter(text)
+		values := make(map[string]any)
+		if err := yaml.Unmarshal([]byte(fm), &values); err != nil {
+			return "", fmt.Errorf("invalid frontmatter in the template: %w", err)
+		}
+		fields, err := collectionFrontmatter(relPath, title, values, now, log)
+		if err != nil {
+			return "", err
+		}
+		if fm != "" || fields != "" {
+			text = "---\n" + fm + fields + "---\n" + body
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// findTemplate returns the content of the template of a new note, or an
+// empty string when there is none to start from.
+func findTemplate(settings templateSettings, relPath, name string) (string, error) {
+	dir := filepath.Join(InputDir, settings.Folder)
+	if name != "" {
+		if filepath.Ext(name) != ".md" {
+			name += ".md"
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", fmt.Errorf("template %s not found in %s", name, dir)
+		}
+		return string(data), nil
+	}
+
+	candidates := []string{"Default.md"}
+	if folder := filepath.Dir(relPath); folder != "." {
+		candidates = append([]string{filepath.Base(folder) + ".md"}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if data, err := os.ReadFile(filepath.Join(dir, candidate)); err == nil {
+			return string(data), nil
+		}
+	}
+	return "", nil
+}
+
+// placeholderPattern matches the placeholders of the Templates plugin:
+// {{title}}, {{date}} and {{time}}, the last two with an optional format
+// such as {{date:YYYY-MM-DD}}.
+var placeholderPattern = regexp.MustCompile(`(?i)\{\{\s*(title|date|time)(?::([^}]*))?\s*\}\}`)
+
+// expandPlaceholders replaces the placeholders of a template with the title
+// of the note and the current date and time.
+func expandPlaceholders(text, title string, now time.Time, settings templateSettings) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := placeholderPattern.FindStringSubmatch(match)
+		format := m[2]
+		switch strings.ToLower(m[1]) {
+		case "title":
+			return title
+		case "date":
+			if format == "" {
+				format = settings.DateFormat
+			}
+		case "time":
+			if format == "" {
+				format = settings.TimeFormat
+			}
+		}
+		return formatMoment(now, format)
+	})
+}
+
+// momentTokens maps the tokens of the Moment.js formats used by Obsidian to
+// Go layouts, the longer tokens first.
+var momentTokens = []struct{ moment, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"},
+	{"DD", "02"}, {"D", "2"},
+	{"HH", "15"}, {"H", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"},
+	{"ss", "05"}, {"s", "5"},
+	{"A", "PM"}, {"a", "pm"},
+	{"ZZ", "-0700"}, {"Z", "-07:00"},
+}
+
+// formatMoment formats t with a Moment.js format. Text in square brackets
+// and characters that aren't tokens are kept as they are.
+func formatMoment(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+		matched := false
+		for _, token := range momentTokens {
+			if strings.HasPrefix(format[i:], token.moment) {
+				b.WriteString(t.Format(token.layout))
+				i += len(token.moment)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// splitFrontmatter returns the frontmatter of a note, without its
+// delimiters, and the rest of the note. ok is false when there is none.
+func splitFrontmatter(text string) (fm, body string, ok bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if strings.TrimRight(lines[0], "\n") != "---" {
+		return "", text, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\n") == "---" {
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], ""), true
+		}
+	}
+	return "", text, false
+}
+
+// collectionFrontmatter returns the frontmatter lines of the fields of the
+// collection of a new note that its values lack, in the order of the
+// config.json of the collection. Optional images, references and tags are
+// left out, as they can't be empty.
+func collectionFrontmatter(relPath, title string, values map[string]any, now time.Time, log *slog.Logger) (string, error) {
+	files, err := listVaultFiles()
+	if err != nil {
+		return "", err
+	}
+	site := &CustomSite{
+		Configs:       make(map[string]*Config),
+		ConfigsLookup: make(map[string]*Config),
+		Obsidian:      &obsidian.Obsidian{Vault: &obsidian.Vault{Files: files}},
+		log:           log,
+	}
+	if err := site.walk(); err != nil {
+		return "", err
+	}
+	if err := site.loadConfigFiles(); err != nil {
+		return "", err
+	}
+	if err := site.parseConfigs(); err != nil {
+		return "", err
+	}
+	config := site.Configs[getConfigDirectory(relPath)]
+	if config == nil {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(config.Path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, key := range jsonKeys(data) {
+		field, ok := config.Fields[key]
+		if _, set := values[key]; set || !ok {
+			continue
+		}
+		value, ok := site.fieldPlaceholder(key, field, title, now)
+		if !ok {
+			continue
+		}
+		if field.Required && (field.Type == TypeImage || field.Type == TypeReference || field.Type == TypeTag) {
+			log.Warn("Set the required field of the new note", "field", key, "value", value)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	return b.String(), nil
+}
+
+// listVaultFiles lists the files of the vault that a scan reads, with their
+// paths and names only. Unlike Scan, it leaves the output directory alone.
+func listVaultFiles() ([]*obsidian.File, error) {
+	var files []*obsidian.File
+	err := filepath.WalkDir(InputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(InputDir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		if obsidian.IgnoredPath(relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(relPath)
+		fullName := filepath.Base(relPath)
+		name := fullName
+		if ext == ".md" {
+			name = strings.TrimSuffix(fullName, ext)
+		}
+		files = append(files, &obsidian.File{Path: path, RelPath: relPath, Ext: ext, Name: name, FullName: fullName})
+		return nil
+	})
+	return files, err
+}
+
+// fieldPlaceholder returns the YAML value of a field in the frontmatter of a
+// new note, valid for its type, and false when the field is left out.
+// Required images and references point to the first image of the vault and
+// the first page of the referenced collection.
+func (s *CustomSite) fieldPlaceholder(key string, field FieldConfig, title string, now time.Time) (string, bool) {
+	switch field.Type {
+	case TypeString:
+		if key == "title" {
+			return yamlScalar(title), true
+		}
+		return `""`, true
+	case TypeDate:
+		return now.Format("2006-01-02"), true
+	case TypeDateTime:
+		return now.Format(time.RFC3339), true
+	case TypeBoolean:
+		return "false", true
+	case TypeInteger, TypeFloat:
+		// Numbers are read from strings
+		return `"0"`, true
+	case TypeEnum:
+		if len(field.AllowedValues) > 0 {
+			return yamlScalar(field.AllowedValues[0]), true
+		}
+		return `""`, true
+	case TypeTags, TypeReferences:
+		return "[]", true
+	case TypeCustom:
+		return `""`, true
+	}
+
+	if !field.Required {
+		return "", false
+	}
+	switch field.Type {
+	case TypeImage:
+		for _, file := range sortedFiles(s.Files.Static) {
+			if isImageExt(file.Ext) {
+				return yamlScalar("[[" + file.FullName + "]]"), true
+			}
+		}
+	case TypeReference:
+		if config := s.ConfigsLookup[field.Reference]; config != nil {
+			for _, file := range sortedFiles(s.Files.Markdown) {
+				if getConfigDirectory(file.RelPath) == config.ID && file.Name != "index" {
+					return yamlScalar("[[" + file.Name + "]]"), true
+				}
+			}
+		}
+	}
+	return `""`, true
+}
+
+// sortedFiles returns a copy of files sorted by path.
+func sortedFiles(files []*obsidian.File) []*obsidian.File {
+	sorted := slices.Clone(files)
+	slices.SortFunc(sorted, func(a, b *obsidian.File) int { return strings.Compare(a.RelPath, b.RelPath) })
+	return sorted
+}
+
+// yamlScalar returns s as a YAML scalar, quoted when needed.
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// jsonKeys returns the keys of a JSON object in the order they appear.
+func jsonKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := token.(string)
+		keys = append(keys, key)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
@@ -0,0 +1,153 @@
+// @feature:scaffold Tests for the notes created from templates.
+package builder
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatMoment(t *testing.T) {
+	now := time.Date(2026, time.March, 5, 14, 7, 9, 0, time.UTC)
+	cases := map[string]string{
+		"YYYY-MM-DD":           "2026-03-05",
+		"DD/MM/YY":             "05/03/26",
+		"dddd, MMMM D":         "Thursday, March 5",
+		"HH:mm:ss":             "14:07:09",
+		"h:mm A":               "2:07 PM",
+		"[Week of] YYYY-MM-DD": "Week of 2026-03-05",
+	}
+	for format, want := range cases {
+		if got := formatMoment(now, format); got != want {
+			t.Errorf("formatMoment(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	now := time.Date(2026, time.March, 5, 14, 7, 0, 0, time.UTC)
+	settings := templateSettings{DateFormat: "DD.MM.YYYY", TimeFormat: "HH:mm"}
+	got := expandPlaceholders("# {{title}}\n{{date}} {{ time }} {{date:YYYY}} {{Title}} {{other}}", "Note", now, settings)
+	want := "# Note\n05.03.2026 14:07 2026 Note {{other}}"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewNote(t *testing.T) {
+	InputDir, Mode = writeVault(t, map[string]string{
+		".obsidian/templates.json":     `{"folder": "_hidden_templates"}`,
+		"_hidden_templates/Recipes.md": "---\ncreated: {{date}}\n---\n# {{title}}\n",
+		"_hidden_templates/Default.md": "Default for {{title}}",
+		"_hidden_templates/Meeting.md": "Meeting at {{time}}",
+	}), "default"
+	defer func() { InputDir, Mode = "", "" }()
+
+	now := time.Date(2026, time.March, 5, 9, 30, 0, 0, time.UTC)
+	log := slog.New(slog.DiscardHandler)
+	cases := []struct{ relPath, template, file, want string }{
+		{"Recipes/Bread", "", "Recipes/Bread.md", "---\ncreated: 2026-03-05\n---\n# Bread\n"},
+		{"Notes/Idea.md", "", "Notes/Idea.md", "Default for Idea"},
+		{"Standup", "Meeting", "Standup.md", "Meeting at 09:30"},
+	}
+	for _, c := range cases {
+		path, err := NewNote(c.relPath, c.template, now, log)
+		if err != nil {
+			t.Fatalf("%s: %v", c.relPath, err)
+		}
+		if path != filepath.Join(InputDir, c.file) {
+			t.Errorf("%s: created %s", c.relPath, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != c.want {
+			t.Errorf("%s: got %q, want %q", c.relPath, data, c.want)
+		}
+	}
+
+	if _, err := NewNote("Recipes/Bread", "", now, log); err == nil {
+		t.Error("creating an existing note must fail")
+	}
+	if _, err := NewNote("../Outside", "", now, log); err == nil {
+		t.Error("creating a note outside of the vault must fail")
+	}
+	if _, err := NewNote("Other", "Missing", now, log); err == nil {
+		t.Error("a missing template must fail")
+	}
+}
+
+// TestNewNoteCollectionFields creates a post in the custom starter and
+// builds it, the build failing on frontmatter not matching the collection.
+func TestNewNoteCollectionFields(t *testing.T) {
+	InputDir, OutputDir = filepath.Join(t.TempDir(), "vault"), t.TempDir()
+	Mode, DateSource, CacheDir = "custom", "filesystem", t.TempDir()
+	defer func() {
+		InputDir, OutputDir, Mode, DateSource, CacheDir = "", "", "", "", ""
+		last = buildState{}
+	}()
+	if _, err := Scaffold("custom"); err != nil {
+		t.Fatal(err)
+	}
+	// A required reference
+	config := `{
+  "collection_name": "posts",
+  "title": { "type": "string", "required": true },
+  "date": { "type": "date", "required": true },
+  "summary": "string",
+  "author": { "type": "reference", "reference": "authors", "required": true },
+  "tags": "tags",
+  "featured": "boolean",
+  "views": "integer",
+  "kind": { "type": "enum", "values": ["article", "note"] },
+  "cover": "image"
+}`
+	writeFiles(t, InputDir, map[string]string{
+		"posts/config.json": config,
+		// A template setting one of the fields
+		"_hidden_templates/posts.md": "---\nsummary: \"Written on {{date}}\"\n---\nBody\n",
+		"posts/index.md":             "---\ntitle: Posts\ndate: 2026-01-01\nauthor: \"[[jane-doe]]\"\n---\n",
+	})
+
+	// Nothing but the note is written, in the working directory neither
+	wd := t.TempDir()
+	t.Chdir(wd)
+	now := time.Date(2026, time.March, 5, 9, 30, 0, 0, time.UTC)
+	path, err := NewNote("posts/second-post", "", now, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(wd); len(entries) > 0 {
+		t.Errorf("the working directory isn't clean: %v", entries)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"---",
+		`summary: "Written on 2026-03-05"`,
+		"title: second-post",
+		"date: 2026-03-05",
+		"author: '[[jane-doe]]'",
+		"tags: []",
+		"featured: false",
+		`views: "0"`,
+		"kind: article",
+		"---",
+		"Body",
+		"",
+	}, "\n")
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+
+	Build(slog.New(slog.DiscardHandler))
+	if _, err := os.Stat(filepath.Join(OutputDir, "posts", "second-post.html")); err != nil {
+		t.Errorf("new post not built: %v", err)
+	}
+}
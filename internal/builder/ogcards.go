@@ -0,0 +1,169 @@
+// Collects the inputs of the Open Graph cards and reuses them across builds. @feature:ogimage
+package builder
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/otaleghani/kiln/internal/imgopt"
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/otaleghani/kiln/internal/ogimage"
+	"github.com/otaleghani/kiln/internal/templates"
+)
+
+// ogLogoPath returns the logo shown on the cards: the one set in the og
+// section of kiln.yaml, or the favicon of the vault.
+func ogLogoPath(log *slog.Logger) string {
+	if OGOptions.Logo != "" {
+		logo := filepath.Join(InputDir, OGOptions.Logo)
+		if _, err := os.Stat(logo); err != nil {
+			log.Warn("Couldn't find the OG card logo", "path", OGOptions.Logo, "error", err)
+			return ""
+		}
+		return logo
+	}
+	for _, name := range []string{"favicon.png", "favicon.ico"} {
+		logo := filepath.Join(InputDir, name)
+		if _, err := os.Stat(logo); err == nil {
+			return logo
+		}
+	}
+	return ""
+}
+
+// hasOwnOGImage reports whether a note sets its own social image with the
+// "og:image" property, in which case no card is generated for it.
+func hasOwnOGImage(frontmatter map[string]any) bool {
+	s, _ := frontmatter["og:image"].(string)
+	return strings.TrimSpace(s) != ""
+}
+
+// noteCard returns the card inputs of a note: its sorted tags, its date and
+// its cover image, unless disabled in the og section of kiln.yaml.
+func noteCard(vault *obsidian.Vault, f *obsidian.File, frontmatter map[string]any, title, description string) ogimage.ImageConfig {
+	cfg := ogimage.ImageConfig{Title: title, Description: description}
+	if !OGOptions.HideTags {
+		for tag := range f.Tags {
+			cfg.Tags = append(cfg.Tags, strings.TrimPrefix(tag, "#"))
+		}
+		slices.Sort(cfg.Tags)
+	}
+	if !OGOptions.HideDate {
+		if date := noteDate(f, frontmatter); !date.IsZero() {
+			cfg.Date = templates.FormatDate(date)
+		}
+	}
+	cfg.CoverPath = noteCover(vault, f, frontmatter)
+	return cfg
+}
+
+// noteDate returns the "date" property of a note, or its creation time.
+func noteDate(f *obsidian.File, frontmatter map[string]any) time.Time {
+	if date := obsidian.ParseDate(frontmatter["date"]); !date.IsZero() {
+		return date
+	}
+	return f.Created
+}
+
+// noteCover returns the path of the cover image of a note: the vault image
+// set in its "cover" property, or else the first image it embeds. Remote
+// images are ignored, as cards are rendered offline.
+func noteCover(vault *obsidian.Vault, f *obsidian.File, frontmatter map[string]any) string {
+	if vault.Links == nil {
+		return ""
+	}
+	ref, _ := frontmatter["cover"].(string)
+	if target := findImage(vault, f, ref); target != nil {
+		return target.Path
+	}
+	for _, raw := range f.Embeds {
+		if target := vault.Links.Resolve(f, raw); target != nil && imgopt.IsMeasurable(target.Ext) {
+			return target.Path
+		}
+	}
+	return ""
+}
+
+// noteImage returns the web path or URL of the "image" property of a note,
+// which replaces its generated cards. Vault paths and wikilinks are resolved
+// to the published image; remote URLs and site paths are kept as they are.
+func noteImage(vault *obsidian.Vault, f *obsidian.File, frontmatter map[string]any) string {
+	ref, _ := frontmatter["image"].(string)
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ref
+	}
+	if target := findImage(vault, f, ref); target != nil {
+		return target.WebPath
+	}
+	if strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	return ""
+}
+
+// findImage resolves a vault path or a wikilink written in a property of f
+// to an image of the vault, or nil.
+func findImage(vault *obsidian.Vault, f *obsidian.File, ref string) *obsidian.File {
+	ref = strings.TrimSpace(ref)
+	if vault.Links == nil || ref == "" || strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return nil
+	}
+	var target *obsidian.File
+	if strings.HasPrefix(strings.TrimPrefix(ref, "!"), "[[") {
+		target = vault.Links.Resolve(f, ref)
+	} else {
+		target, _, _ = vault.Links.Find(f, strings.TrimPrefix(ref, "/"))
+	}
+	if target == nil || !imgopt.IsMeasurable(target.Ext) {
+		return nil
+	}
+	return target
+}
+
+// writeCard writes a card to outPath, copying it from the card cache when
+// an identical card was rendered by a previous build.
+func writeCard(key, outPath string, render func(string) error) error {
+	if CacheDir == "" {
+		return render(outPath)
+	}
+	cached := filepath.Join(CacheDir, "og", key+".png")
+	if data, err := os.ReadFile(cached); err == nil {
+		return os.WriteFile(outPath, data, 0o644)
+	}
+	if err := render(outPath); err != nil {
+		return err
+	}
+	// A failed store only costs the next build some time
+	_ = storeCard(outPath, cached)
+	return nil
+}
+
+// storeCard copies a rendered card into the cache through a temporary
+// file, so that concurrent builds never read a partial card.
+func storeCard(src, cached string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cached), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cached)
+}
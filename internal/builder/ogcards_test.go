@@ -0,0 +1,96 @@
+// @feature:ogimage Tests for the inputs and the cache of the Open Graph cards.
+package builder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+func TestNoteCover(t *testing.T) {
+	photo := &obsidian.File{Path: "/vault/img/photo.png", RelPath: "img/photo.png", Name: "photo", Ext: ".png", FullName: "photo.png", WebPath: "/img/photo.png"}
+	shot := &obsidian.File{Path: "/vault/shot.jpg", RelPath: "shot.jpg", Name: "shot", Ext: ".jpg", FullName: "shot.jpg", WebPath: "/shot.jpg"}
+	doc := &obsidian.File{Path: "/vault/doc.pdf", RelPath: "doc.pdf", Name: "doc", Ext: ".pdf", FullName: "doc.pdf", WebPath: "/doc.pdf"}
+	note := &obsidian.File{RelPath: "note.md", Name: "note", Ext: ".md", FullName: "note.md", Embeds: []string{"[[doc.pdf]]", "[[shot.jpg]]"}}
+	files := []*obsidian.File{photo, shot, doc, note}
+	vault := &obsidian.Vault{Files: files, Links: obsidian.NewLinkIndex(files, nil)}
+
+	tests := []struct {
+		name        string
+		frontmatter map[string]any
+		want        string
+	}{
+		{"cover path", map[string]any{"cover": "img/photo.png"}, photo.Path},
+		{"cover wikilink", map[string]any{"cover": "[[photo.png]]"}, photo.Path},
+		{"remote cover falls back to embeds", map[string]any{"cover": "https://example.com/a.png"}, shot.Path},
+		{"image is not a cover", map[string]any{"image": "img/photo.png"}, shot.Path},
+		{"first embedded image", nil, shot.Path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noteCover(vault, note, tt.frontmatter); got != tt.want {
+				t.Errorf("noteCover = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoteImage(t *testing.T) {
+	photo := &obsidian.File{Path: "/vault/img/photo.png", RelPath: "img/photo.png", Name: "photo", Ext: ".png", FullName: "photo.png", WebPath: "/img/photo.png"}
+	note := &obsidian.File{RelPath: "note.md", Name: "note", Ext: ".md", FullName: "note.md"}
+	files := []*obsidian.File{photo, note}
+	vault := &obsidian.Vault{Files: files, Links: obsidian.NewLinkIndex(files, nil)}
+
+	tests := map[string]string{
+		"":                              "",
+		"img/photo.png":                 "/img/photo.png",
+		"[[photo.png]]":                 "/img/photo.png",
+		"https://cdn.example.com/a.png": "https://cdn.example.com/a.png",
+		"/static/social.png":            "/static/social.png",
+		"missing.png":                   "",
+	}
+	for ref, want := range tests {
+		if got := noteImage(vault, note, map[string]any{"image": ref}); got != want {
+			t.Errorf("noteImage(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestNoteDate(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	f := &obsidian.File{Created: created}
+
+	if got := noteDate(f, map[string]any{"date": "2023-05-17"}); !got.Equal(time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("noteDate = %v, want 2023-05-17", got)
+	}
+	if got := noteDate(f, map[string]any{"date": "someday"}); !got.Equal(created) {
+		t.Errorf("noteDate = %v, want the creation time", got)
+	}
+}
+
+func TestWriteCard_ReusesCachedCards(t *testing.T) {
+	defer func(dir string) { CacheDir = dir }(CacheDir)
+	CacheDir = t.TempDir()
+	outDir := t.TempDir()
+
+	renders := 0
+	render := func(path string) error {
+		renders++
+		return os.WriteFile(path, []byte("card"), 0o644)
+	}
+	for _, name := range []string{"a-og.png", "b-og.png"} {
+		if err := writeCard("key", filepath.Join(outDir, name), render); err != nil {
+			t.Fatalf("writeCard: %v", err)
+		}
+	}
+	if renders != 1 {
+		t.Errorf("expected the second card to come from the cache, rendered %d times", renders)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outDir, "b-og.png")); !bytes.Equal(data, []byte("card")) {
+		t.Errorf("cached card content = %q", data)
+	}
+}
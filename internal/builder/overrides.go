@@ -0,0 +1,88 @@
+// Partials and assets of the vault overriding the default mode layouts. @feature:layouts
+package builder
+
+import (
+	"html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/otaleghani/kiln/internal/templates"
+)
+
+// LayoutsDir is the folder of the vault overriding components of the
+// layouts. It's published at the same path, for its stylesheets and scripts.
+const LayoutsDir = "_layouts"
+
+// loadOverrides parses the html/template partials of the _layouts folder of
+// the vault, named after the component they replace (footer.html), and lists
+// its stylesheets and scripts. Partials starting with "_" are helpers, to be
+// included by the others. It returns nil when the vault has no _layouts
+// folder, and overrides without partials when they don't parse.
+func loadOverrides(inputDir, baseURL string, log *slog.Logger) *templates.Overrides {
+	dir := filepath.Join(inputDir, LayoutsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	overrides := &templates.Overrides{}
+	partials := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		url := baseURL + "/" + LayoutsDir + "/" + name
+		switch filepath.Ext(name) {
+		case ".html":
+			component := strings.TrimSuffix(name, ".html")
+			if !strings.HasPrefix(component, "_") && !slices.Contains(templates.OverrideNames, component) {
+				log.Warn("Unknown layout override, prefix helper partials with _", "file", name, "known", strings.Join(templates.OverrideNames, ", "))
+			}
+			partials = append(partials, filepath.Join(dir, name))
+		case ".css":
+			overrides.CSS = append(overrides.CSS, url)
+		case ".js":
+			overrides.JS = append(overrides.JS, url)
+		}
+	}
+
+	if len(partials) > 0 {
+		tmpl, err := template.New(LayoutsDir).Funcs(templates.OverrideFuncs).ParseFiles(partials...)
+		if err != nil {
+			log.Error("Couldn't parse the layout overrides, using the built-in components", "error", err)
+		} else {
+			overrides.Partials = tmpl
+		}
+	}
+	log.Debug("Loaded layout overrides", "partials", len(partials), "css", len(overrides.CSS), "js", len(overrides.JS))
+	return overrides
+}
+
+// copyOverrideAssets copies the stylesheets and scripts of the _layouts
+// folder of the vault to the output.
+func copyOverrideAssets(inputDir, outputDir string, log *slog.Logger) {
+	entries, err := os.ReadDir(filepath.Join(inputDir, LayoutsDir))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".css" && ext != ".js") {
+			continue
+		}
+		src := filepath.Join(inputDir, LayoutsDir, entry.Name())
+		dst := filepath.Join(outputDir, LayoutsDir, entry.Name())
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			log.Error("Couldn't create the layouts folder", "error", err)
+			return
+		}
+		if err := obsidian.CopyFile(src, dst); err != nil {
+			log.Error("Couldn't copy layout asset", "file", entry.Name(), "error", err)
+		}
+	}
+}
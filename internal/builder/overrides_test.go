@@ -0,0 +1,56 @@
+// @feature:layouts Tests for the layout overrides of the vault.
+package builder
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	dir := writeVault(t, map[string]string{
+		"_layouts/footer.html":   `<footer>{{ template "_credits.html" . }}</footer>`,
+		"_layouts/_credits.html": `{{ .Site.SiteName }}`,
+		"_layouts/fotter.html":   `typo`,
+		"_layouts/brand.css":     `body {}`,
+		"_layouts/brand.js":      `console.log("brand")`,
+	})
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	overrides := loadOverrides(dir, "https://example.com", log)
+	if overrides == nil || overrides.Partials == nil {
+		t.Fatalf("overrides = %+v, want the parsed partials", overrides)
+	}
+	if overrides.Partials.Lookup("footer.html") == nil || overrides.Partials.Lookup("_credits.html") == nil {
+		t.Error("partials aren't named after their files")
+	}
+	if !slices.Equal(overrides.CSS, []string{"https://example.com/_layouts/brand.css"}) {
+		t.Errorf("CSS = %v", overrides.CSS)
+	}
+	if !slices.Equal(overrides.JS, []string{"https://example.com/_layouts/brand.js"}) {
+		t.Errorf("JS = %v", overrides.JS)
+	}
+	if !strings.Contains(logs.String(), "fotter.html") || strings.Contains(logs.String(), "_credits.html") {
+		t.Errorf("expected a warning for fotter.html only, got:\n%s", logs.String())
+	}
+
+	out := t.TempDir()
+	copyOverrideAssets(dir, out, log)
+	for _, name := range []string{"brand.css", "brand.js"} {
+		if _, err := os.Stat(filepath.Join(out, LayoutsDir, name)); err != nil {
+			t.Errorf("%s wasn't copied: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, LayoutsDir, "footer.html")); err == nil {
+		t.Error("partials are copied to the output")
+	}
+
+	if loadOverrides(t.TempDir(), "", log) != nil {
+		t.Error("a vault without _layouts has overrides")
+	}
+}
@@ -0,0 +1,62 @@
+// Starter vaults laid out by kiln init --template. @feature:scaffold
+package builder
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// starters holds one folder by starter: the files of its vault and the
+// settings of its kiln.yaml, where {{input}} stands for the vault directory.
+//
+//go:embed all:starters
+var starters embed.FS
+
+// Starters are the names of the starters of kiln init.
+var Starters = []string{"blog", "docs", "garden", "custom"}
+
+// Scaffold lays out the vault of a starter in InputDir, which must not
+// exist yet, and returns the settings of kiln.yaml for it.
+func Scaffold(name string) ([]byte, error) {
+	if !slices.Contains(Starters, name) {
+		return nil, fmt.Errorf("unknown template %q, choose between %s", name, strings.Join(Starters, ", "))
+	}
+	if _, err := os.Stat(InputDir); err == nil {
+		return nil, errors.New("vault directory already exists")
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	root := path.Join("starters", name, "vault")
+	err := fs.WalkDir(starters, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(InputDir, filepath.FromSlash(strings.TrimPrefix(p, root)))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := starters.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := starters.ReadFile(path.Join("starters", name, "kiln.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.ReplaceAll(settings, []byte("{{input}}"), []byte(InputDir)), nil
+}
@@ -0,0 +1,71 @@
+// @feature:scaffold Tests for the starter vaults of kiln init.
+package builder
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	defer func() { InputDir = "" }()
+
+	for _, name := range Starters {
+		InputDir = filepath.Join(t.TempDir(), "vault")
+		settings, err := Scaffold(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(string(settings), "input: "+InputDir+"\n") {
+			t.Errorf("%s: settings don't point to the vault:\n%s", name, settings)
+		}
+		if _, err := os.Stat(filepath.Join(InputDir, "index.md")); err != nil {
+			t.Errorf("%s: no index.md: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(InputDir, ".obsidian", "templates.json")); err != nil {
+			t.Errorf("%s: no templates settings: %v", name, err)
+		}
+
+		if _, err := Scaffold(name); err == nil {
+			t.Errorf("%s: scaffolding over an existing vault must fail", name)
+		}
+	}
+
+	InputDir = filepath.Join(t.TempDir(), "vault")
+	if _, err := Scaffold("wiki"); err == nil {
+		t.Error("an unknown starter must fail")
+	}
+}
+
+// TestScaffoldCustomBuilds builds the custom starter, which fails on any
+// frontmatter not matching its collections.
+func TestScaffoldCustomBuilds(t *testing.T) {
+	InputDir, OutputDir = filepath.Join(t.TempDir(), "vault"), t.TempDir()
+	Mode, DateSource, CacheDir = "custom", "filesystem", t.TempDir()
+	defer func() {
+		InputDir, OutputDir, Mode, DateSource, CacheDir = "", "", "", "", ""
+		last = buildState{}
+	}()
+
+	if _, err := Scaffold("custom"); err != nil {
+		t.Fatal(err)
+	}
+	Build(slog.New(slog.DiscardHandler))
+
+	for _, page := range []string{"index.html", "posts/index.html", "posts/hello-world.html", "authors/jane-doe.html"} {
+		if _, err := os.Stat(filepath.Join(OutputDir, page)); err != nil {
+			t.Errorf("page %s not written: %v", page, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(OutputDir, "posts/hello-world.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1>Hello World</h1>", `href="/authors/jane-doe"`, "#welcome"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("post misses %q", want)
+		}
+	}
+}
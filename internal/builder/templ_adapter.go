@@ -3,8 +3,9 @@ package builder
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
-	"strings"
 
 	"github.com/otaleghani/kiln/internal/i18n"
 	"github.com/otaleghani/kiln/internal/obsidian"
@@ -13,10 +14,12 @@ import (
 
 // toTemplPageData maps a DefaultSitePageData to the templ-compatible PageData.
 func toTemplPageData(p *DefaultSitePageData) *templates.PageData {
+	lang := pageLang(p)
 	data := &templates.PageData{
 		Content:     string(p.Content),
 		TOC:         string(p.TOC),
 		CanvasJSON:  string(p.CanvasContent),
+		CanvasSVG:   string(p.CanvasSVG),
 		Breadcrumbs: p.Breadcrumbs,
 		File:        p.File,
 		Folder:      p.Folder,
@@ -29,18 +32,36 @@ func toTemplPageData(p *DefaultSitePageData) *templates.PageData {
 		IsTag:       p.IsTag,
 		Is404:       p.Is404,
 		Frontmatter: p.Frontmatter,
+		Image:       p.Image,
 		Site: &templates.SiteData{
 			BaseURL:           p.Site.BaseURL,
 			SiteName:          p.Site.SiteName,
 			Theme:             toTemplTheme(p.Site.Theme),
-			NavbarRoot:        p.Site.NavbarRoot,
+			NavbarRoot:        p.Site.navbar(lang),
 			DisableLocalGraph: p.Site.DisableLocalGraph,
 			DisableTOC:        p.Site.DisableTOC,
 			DisableBacklinks:  p.Site.DisableBacklinks,
 			FlatURLs:          p.Site.FlatURLs,
-			Lang:              p.Site.Lang,
-			Labels:            i18n.Resolve(p.Site.Lang),
+			Lang:              lang,
+			DefaultLang:       p.Site.DefaultLang,
+			Labels:            i18n.Resolve(lang),
+			SEO:               SEOOptions,
+			Overrides:         p.Site.Overrides,
+			Assets:            p.Site.Assets,
 		},
+		Languages: languageLinks(p, lang),
+		Listing:   p.Listing,
+	}
+
+	if !p.Site.DisableLocalGraph {
+		switch {
+		case p.IsFolder && p.Folder != nil:
+			data.LocalGraphURL = localGraphURL(p.Folder.WebPath)
+		case p.IsTag && p.Tag != nil:
+			data.LocalGraphURL = localGraphURL(p.Tag.WebPath)
+		case p.File != nil:
+			data.LocalGraphURL = localGraphURL(p.File.WebPath)
+		}
 	}
 
 	if p.IsNote && p.File != nil {
@@ -60,16 +81,33 @@ func toTemplPageData(p *DefaultSitePageData) *templates.PageData {
 
 		if !p.Site.DisableBacklinks && len(p.File.Backlinks) > 0 {
 			for _, bl := range p.File.Backlinks {
-				name := strings.TrimPrefix(bl, "[[")
-				name = strings.TrimSuffix(name, "]]")
-				if files, ok := p.Site.Obsidian.Vault.FileIndex[name]; ok && len(files) > 0 {
-					data.Backlinks = append(data.Backlinks, templates.Backlink{
-						Name:    name,
-						WebPath: files[0].WebPath,
-					})
+				excerpts := []templates.Excerpt{}
+				for _, m := range p.File.BacklinkContexts {
+					if m.Source == bl {
+						excerpts = append(excerpts, toExcerpt(m))
+					}
 				}
+				data.Backlinks = append(data.Backlinks, templates.Backlink{
+					Name:     bl.Name,
+					WebPath:  bl.WebPath,
+					Excerpts: excerpts,
+				})
 			}
 		}
+		if !p.Site.DisableBacklinks {
+			data.Mentions = groupMentions(p.File.UnlinkedMentions)
+		}
+	}
+
+	if p.Site.Layout != nil && p.Site.Layout.Name == "docs" {
+		pagePath, editPath := "", ""
+		switch {
+		case p.IsFolder && p.Folder != nil:
+			pagePath = p.Folder.WebPath
+		case !p.IsTag && p.File != nil:
+			pagePath, editPath = p.File.WebPath, p.File.RelPath
+		}
+		data.Docs = docsData(data.Site.NavbarRoot, pagePath, editPath)
 	}
 
 	if p.IsBase && p.Base.File != nil && len(p.Base.File.Views) > 0 {
@@ -90,6 +128,77 @@ func toTemplPageData(p *DefaultSitePageData) *templates.PageData {
 	return data
 }
 
+// pageLang returns the language of a page: the language of its note or
+// folder, or the language of the site.
+func pageLang(p *DefaultSitePageData) string {
+	switch {
+	case p.IsFolder && p.Folder != nil && p.Folder.Lang != "":
+		return p.Folder.Lang
+	case !p.IsFolder && !p.IsTag && p.File != nil && p.File.Lang != "":
+		return p.File.Lang
+	}
+	return p.Site.Lang
+}
+
+// languageLinks lists the entries of the language switcher of a page on
+// multilingual sites. Each language links to the translation of the page,
+// or to its home page when the page isn't translated.
+func languageLinks(p *DefaultSitePageData, lang string) []templates.LanguageLink {
+	obs := p.Site.Obsidian
+	if obs == nil || !obs.IsMultilingual() {
+		return nil
+	}
+	translations := map[string]string{}
+	switch {
+	case p.IsFolder && p.Folder != nil:
+		translations[lang] = p.Folder.WebPath
+	case p.IsTag && p.Tag != nil:
+		translations[lang] = p.Tag.WebPath
+	case p.File != nil:
+		translations[lang] = p.File.WebPath
+		for _, t := range p.File.Translations {
+			translations[t.Lang] = t.WebPath
+		}
+	}
+
+	links := make([]templates.LanguageLink, 0, len(obs.Vault.Languages))
+	for _, l := range obs.Vault.Languages {
+		url, ok := translations[l]
+		if !ok {
+			url = obs.LanguageHome(l)
+		}
+		links = append(links, templates.LanguageLink{
+			Lang:    l,
+			Name:    i18n.NativeName(l),
+			URL:     url,
+			Current: l == lang,
+		})
+	}
+	return links
+}
+
+// toExcerpt maps an obsidian mention to its template representation.
+func toExcerpt(m obsidian.Mention) templates.Excerpt {
+	return templates.Excerpt{Before: m.Before, Text: m.Text, After: m.After}
+}
+
+// groupMentions groups the mentions by the note containing them, keeping
+// the order in which each note first appears.
+func groupMentions(mentions []obsidian.Mention) []templates.Backlink {
+	groups := []templates.Backlink{}
+	index := map[*obsidian.File]int{}
+	for _, m := range mentions {
+		i, ok := index[m.Source]
+		if !ok {
+			i = len(groups)
+			index[m.Source] = i
+			groups = append(groups, templates.Backlink{Name: m.Source.Name, WebPath: m.Source.WebPath})
+		}
+		groups[i].Excerpts = append(groups[i].Excerpts, toExcerpt(m))
+	}
+	return groups
+}
+
 // toTemplTheme maps a builder Theme to the templ-compatible ThemeData.
 func toTemplTheme(t *Theme) *templates.ThemeData {
 	return &templates.ThemeData{
@@ -97,7 +206,24 @@ func toTemplTheme(t *Theme) *templates.ThemeData {
 		Dark:        toTemplThemeColors(t.Dark),
 		FontFamily:  string(t.Font.Family),
 		FontFaceCSS: string(t.Font.FontFaceReplaced),
+		LightVars:   toTemplCSSVars(t.CSSVars.Light, cssVars.Light),
+		DarkVars:    toTemplCSSVars(t.CSSVars.Dark, cssVars.Dark),
+	}
+}
+
+// toTemplCSSVars merges the CSS custom properties of a theme with the ones
+// set on top of any theme, which win, sorted by name.
+func toTemplCSSVars(themeVars, globalVars map[string]string) []templates.CSSVar {
+	merged := maps.Clone(themeVars)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, globalVars)
+	vars := []templates.CSSVar{}
+	for _, name := range slices.Sorted(maps.Keys(merged)) {
+		vars = append(vars, templates.CSSVar{Name: name, Value: merged[name]})
 	}
+	return vars
 }
 
 func toTemplThemeColors(c *ThemeColors) *templates.ThemeColors {
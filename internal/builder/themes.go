@@ -2,6 +2,8 @@
 package builder
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"log/slog"
@@ -10,6 +12,8 @@ import (
 	"strings"
 
 	"github.com/otaleghani/kiln/assets"
+	"github.com/otaleghani/kiln/internal/appearance"
+	"github.com/otaleghani/kiln/internal/ogimage"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 )
@@ -42,15 +46,22 @@ type Theme struct {
 	Light *ThemeColors
 	Dark  *ThemeColors
 	Font  *FontData
+
+	User    bool               // Defined in the vault or in kiln.yaml
+	CSSVars appearance.CSSVars // CSS custom properties set by a user theme
 }
 
 // FontData holds the metadata and CSS required to render a specific font family.
 type FontData struct {
 	Family           template.CSS // The CSS font-family string (e.g., "'Inter', sans-serif")
-	Files            []string     // List of filenames (e.g., .woff2) that need to be extracted
+	Files            []string     // List of filenames (e.g., .woff2) that need to be extracted, relative to the output
 	FontFace         template.CSS // The raw CSS @font-face declaration to inject into the stylesheet
 	FontFaceReplaced template.CSS
 	TtfFile          string // Embedded TTF filename for OG image rendering (empty = use default)
+
+	Paths       map[string]string // Paths on disk of the files of a user font, by output path
+	TtfPath     string            // Path on disk of the TTF of a user font
+	TtfBoldPath string            // Path on disk of the bold TTF of a user font
 }
 
 // fonts is a registry of available font configurations supported by the builder.
@@ -348,12 +359,17 @@ var themes = map[string]*Theme{
 	},
 }
 
-// ResolveTheme looks up a theme by name.
+// ResolveTheme looks up a theme by name, among the themes registered by
+// RegisterAppearance first.
 // If the theme is not found, it defaults to "default" and logs a warning.
-// It also resolves the associated font using resolveFont and optionally
-// overrides the accent color from the theme palette.
+// It also resolves the associated font using resolveFont, optionally
+// overrides the accent color from the theme palette and checks the contrast
+// of the colors.
 func ResolveTheme(themeName, fontName, accentColorName string, log *slog.Logger) *Theme {
-	theme, ok := themes[strings.ToLower(themeName)]
+	theme, ok := userThemes[strings.ToLower(themeName)]
+	if !ok {
+		theme, ok = themes[strings.ToLower(themeName)]
+	}
 	if !ok {
 		log.Warn("Theme not found. Using default theme.", "name", themeName)
 		theme = themes["default"]
@@ -362,6 +378,7 @@ func ResolveTheme(themeName, fontName, accentColorName string, log *slog.Logger)
 	if accentColorName != "" {
 		overrideAccentColor(theme, accentColorName, log)
 	}
+	theme.checkContrast(log.With("theme", themeName))
 	return theme
 }
 
@@ -404,7 +421,10 @@ func overrideAccentColor(theme *Theme, colorName string, log *slog.Logger) {
 // resolveFont looks up font data by name.
 // If the font is not found, it defaults to "inter" and logs a warning.
 func resolveFont(name string, log *slog.Logger) *FontData {
-	font, ok := fonts[strings.ToLower(name)]
+	font, ok := userFonts[strings.ToLower(name)]
+	if !ok {
+		font, ok = fonts[strings.ToLower(name)]
+	}
 	if !ok {
 		log.Warn("Font not found. Using default theme.", "name", name)
 		return fonts["inter"]
@@ -415,42 +435,90 @@ func resolveFont(name string, log *slog.Logger) *FontData {
 // LoadFontFace loads the embedded TTF and returns a font.Face for OG image rendering.
 // Falls back to Inter-Regular.ttf if the font has no TTF or loading fails.
 func (fd *FontData) LoadFontFace(size float64, log *slog.Logger) font.Face {
+	parsed, ttfName, _ := fd.loadTTF(log)
+	if parsed == nil {
+		return nil
+	}
+
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		log.Error(fmt.Sprintf("Couldn't create font face from %s", ttfName), "error", err)
+		return nil
+	}
+
+	return face
+}
+
+// LoadOGFonts returns the typefaces of the Open Graph cards. Titles use the
+// bold TTF of a user font, or else the bold Go font, as only regular TTFs
+// are embedded. The key of the fonts hashes the content of their files.
+func (fd *FontData) LoadOGFonts(log *slog.Logger) ogimage.Fonts {
+	parsed, _, ttfBytes := fd.loadTTF(log)
+	if parsed == nil {
+		return ogimage.Fonts{}
+	}
+	fonts := ogimage.Fonts{Regular: parsed}
+	hash := sha256.New()
+	hash.Write(ttfBytes)
+
+	if fd.TtfBoldPath != "" {
+		boldBytes, err := os.ReadFile(fd.TtfBoldPath)
+		if err == nil {
+			fonts.Bold, err = opentype.Parse(boldBytes)
+		}
+		if err != nil {
+			log.Warn("Couldn't load the bold TTF for OG images, using the bold Go font", "file", fd.TtfBoldPath, "error", err)
+			fonts.Bold = nil
+		} else {
+			hash.Write(boldBytes)
+		}
+	}
+	fonts.Key = hex.EncodeToString(hash.Sum(nil))
+	return fonts
+}
+
+// loadTTF parses the embedded TTF of the font, or the TTF on disk of a user
+// font, falling back to Inter-Regular.ttf. It returns the parsed font with
+// the name and content of its file, or nil if no font could be parsed.
+func (fd *FontData) loadTTF(log *slog.Logger) (*opentype.Font, string, []byte) {
 	ttfName := fd.TtfFile
 	if ttfName == "" {
 		ttfName = "Inter-Regular.ttf"
 	}
 
-	ttfBytes, err := assets.TemplateFS.ReadFile(ttfName)
+	var ttfBytes []byte
+	var err error
+	if fd.TtfPath != "" {
+		ttfName = fd.TtfPath
+		ttfBytes, err = os.ReadFile(fd.TtfPath)
+	} else {
+		ttfBytes, err = assets.TemplateFS.ReadFile(ttfName)
+	}
 	if err != nil {
 		log.Warn("Couldn't read TTF for OG images, falling back to Inter", "file", ttfName, "error", err)
-		ttfBytes, err = assets.TemplateFS.ReadFile("Inter-Regular.ttf")
+		ttfName = "Inter-Regular.ttf"
+		ttfBytes, err = assets.TemplateFS.ReadFile(ttfName)
 		if err != nil {
 			log.Error("Couldn't read fallback TTF", "error", err)
-			return nil
+			return nil, "", nil
 		}
 	}
 
 	parsed, err := opentype.Parse(ttfBytes)
 	if err != nil {
 		log.Error("Couldn't parse TTF for OG images", "file", ttfName, "error", err)
-		return nil
-	}
-
-	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
-		Size:    size,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		log.Error(fmt.Sprintf("Couldn't create font face from %s", ttfName), "error", err)
-		return nil
+		return nil, "", nil
 	}
-
-	return face
+	return parsed, ttfName, ttfBytes
 }
 
 // extractFonts writes the font files associated with the given FontData to disk.
-// It reads the files from the embedded assets filesystem and writes them to fontsDir.
+// It reads the files from the embedded assets filesystem, or from disk for a
+// user font, and writes them to fontsDir.
 func (t *Theme) extractFonts(fontsDir string, log *slog.Logger) {
 	// If the font has no associated files (e.g., System fonts), return immediately.
 	if len(t.Font.Files) == 0 {
@@ -464,13 +532,23 @@ func (t *Theme) extractFonts(fontsDir string, log *slog.Logger) {
 
 	for _, fileName := range t.Font.Files {
 		// Read the binary content from the embedded assets FS.
-		content, err := assets.TemplateFS.ReadFile(fileName)
+		var content []byte
+		var err error
+		if path, ok := t.Font.Paths[fileName]; ok {
+			content, err = os.ReadFile(path)
+		} else {
+			content, err = assets.TemplateFS.ReadFile(fileName)
+		}
 		if err != nil {
 			log.Error("Failed to read embed font", "error", err)
 		}
 
-		// Write the binary content to the user's filesystem.
-		destPath := filepath.Join(fontsDir, fileName)
+		// Write the binary content to the user's filesystem. The files of
+		// user fonts live in a folder of their own.
+		destPath := filepath.Join(fontsDir, filepath.FromSlash(fileName))
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			log.Error("Failed to create fonts directory", "error", err)
+		}
 		if err := os.WriteFile(destPath, content, 0644); err != nil {
 			log.Error("Failed to write font", "error", err)
 		}
@@ -0,0 +1,211 @@
+// Themes and fonts defined in the vault and in kiln.yaml. @feature:themes
+package builder
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/appearance"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+var (
+	userThemes = map[string]*Theme{}    // Themes registered by RegisterAppearance, by name
+	userFonts  = map[string]*FontData{} // Fonts registered by RegisterAppearance, by name
+	cssVars    appearance.CSSVars       // CSS custom properties set on top of any theme
+)
+
+// RegisterAppearance registers the themes of the _themes folder of the vault
+// and the themes and fonts of the appearance section of kiln.yaml, making
+// them available to ResolveTheme. A user theme or font named after a
+// built-in one replaces it. RegisterAppearance replaces the themes and fonts
+// of the previous call.
+func RegisterAppearance(inputDir string, opts appearance.Options, log *slog.Logger) {
+	userThemes = map[string]*Theme{}
+	userFonts = map[string]*FontData{}
+
+	defs := appearance.LoadDefinitions(inputDir, opts, log)
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		userThemes[name] = buildUserTheme(defs[name], log.With("theme", name))
+	}
+	for name, def := range opts.Fonts {
+		name = strings.ToLower(name)
+		if font := buildUserFont(inputDir, name, def, log.With("font", name)); font != nil {
+			userFonts[name] = font
+		}
+	}
+	cssVars = appearance.CSSVars{
+		Light: appearance.CheckVars(opts.CSSVars.Light, log),
+		Dark:  appearance.CheckVars(opts.CSSVars.Dark, log),
+	}
+	if len(userThemes)+len(userFonts) > 0 {
+		log.Debug("Registered user themes and fonts", "themes", len(userThemes), "fonts", len(userFonts))
+	}
+}
+
+// buildUserTheme builds a theme from its definition, on top of the colours
+// of the built-in theme it extends.
+func buildUserTheme(def appearance.Definition, log *slog.Logger) *Theme {
+	baseName := def.Extends
+	if baseName == "" {
+		baseName = "default"
+	}
+	base, ok := themes[strings.ToLower(baseName)]
+	if !ok {
+		log.Warn("Extended theme isn't a built-in theme, using default", "extends", def.Extends)
+		base = themes["default"]
+	}
+
+	t := &Theme{
+		Light: applyColors(base.Light, def.Light, log.With("mode", "light")),
+		Dark:  applyColors(base.Dark, def.Dark, log.With("mode", "dark")),
+		User:  true,
+		CSSVars: appearance.CSSVars{
+			Light: appearance.CheckVars(def.CSSVars.Light, log),
+			Dark:  appearance.CheckVars(def.CSSVars.Dark, log),
+		},
+	}
+	if def.Extends == "" {
+		for _, mode := range []string{"light", "dark"} {
+			colors := def.Light
+			if mode == "dark" {
+				colors = def.Dark
+			}
+			missing := []string{}
+			for _, key := range appearance.ColorKeys {
+				if _, ok := colors[key]; !ok {
+					missing = append(missing, key)
+				}
+			}
+			if len(missing) > 0 {
+				log.Warn("Missing theme colours, using the ones of the default theme", "mode", mode, "keys", strings.Join(missing, ", "))
+			}
+		}
+	}
+	return t
+}
+
+// applyColors returns a copy of the base colours with the valid colours of
+// a theme mode applied.
+func applyColors(base *ThemeColors, colors map[string]string, log *slog.Logger) *ThemeColors {
+	c := *base
+	fields := c.fields()
+	for key, value := range appearance.CheckColors(colors, log) {
+		*fields[key] = value
+	}
+	return &c
+}
+
+// fields returns the colours by the keys of appearance.ColorKeys.
+func (c *ThemeColors) fields() map[string]*string {
+	return map[string]*string{
+		"bg": &c.Bg, "text": &c.Text, "sidebar-bg": &c.SidebarBg, "sidebar-border": &c.SidebarBorder,
+		"accent": &c.Accent, "hover": &c.Hover, "comment": &c.Comment,
+		"red": &c.Red, "orange": &c.Orange, "yellow": &c.Yellow, "green": &c.Green,
+		"blue": &c.Blue, "purple": &c.Purple, "cyan": &c.Cyan,
+	}
+}
+
+// Colors returns the colours keyed as in appearance.ColorKeys.
+func (c *ThemeColors) Colors() map[string]string {
+	colors := map[string]string{}
+	for key, field := range c.fields() {
+		colors[key] = *field
+	}
+	return colors
+}
+
+// checkContrast logs the text and background pairs of the theme failing
+// WCAG AA, as warnings for user themes and debug messages for the built-in
+// ones.
+func (t *Theme) checkContrast(log *slog.Logger) {
+	level := slog.LevelDebug
+	if t.User {
+		level = slog.LevelWarn
+	}
+	for _, mode := range []string{"light", "dark"} {
+		colors := t.Light
+		if mode == "dark" {
+			colors = t.Dark
+		}
+		for _, issue := range appearance.CheckContrast(colors.Colors()) {
+			log.Log(context.Background(), level, "Theme colours fail the WCAG AA contrast ratio",
+				"mode", mode,
+				"text", issue.Foreground,
+				"background", issue.Background,
+				"ratio", fmt.Sprintf("%.2f:1", issue.Ratio),
+				"min", fmt.Sprintf("%.1f:1", appearance.MinContrast),
+			)
+		}
+	}
+}
+
+// buildUserFont builds a font from its definition, whose files are read from
+// the vault and served under fonts/<name>/, so that the files of different
+// fonts never overwrite each other. It returns nil if none of the files exist.
+func buildUserFont(inputDir, name string, def appearance.Font, log *slog.Logger) *FontData {
+	family := strings.Trim(strings.TrimSpace(def.Family), `'"`)
+	if family == "" || strings.ContainsAny(family, `'";{}<>`) {
+		log.Warn("Fonts need a family name without quotes or braces", "family", def.Family)
+		return nil
+	}
+
+	fd := &FontData{
+		Family: template.CSS(fmt.Sprintf("'%s', %s", family, def.FallbackOrDefault())),
+		Paths:  map[string]string{},
+	}
+	var face strings.Builder
+	for _, file := range def.Files {
+		path := filepath.Join(inputDir, file.Path)
+		format, ok := fontFormats[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			log.Warn("Unsupported font file, use woff2, woff, ttf or otf", "path", file.Path)
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			log.Warn("Couldn't find font file", "path", file.Path, "error", err)
+			continue
+		}
+		out := "fonts/" + obsidian.Slugify(name) + "/" + filepath.Base(path)
+		fd.Files = append(fd.Files, out)
+		fd.Paths[out] = path
+		fmt.Fprintf(&face, `
+			@font-face {
+				font-family: '%s';
+				font-style: %s;
+				font-weight: %d;
+				font-display: swap;
+				src: url('{{.Site.BaseURL}}/%s') format('%s');
+			}`, family, file.StyleOrDefault(), file.WeightOrDefault(), out, format)
+	}
+	if len(fd.Files) == 0 {
+		log.Warn("Font without usable files, skipping it")
+		return nil
+	}
+	fd.FontFace = template.CSS(face.String())
+
+	if def.TTF != "" {
+		fd.TtfPath = filepath.Join(inputDir, def.TTF)
+	} else {
+		log.Warn("Font without a TTF file, Open Graph cards use Inter")
+	}
+	if def.TTFBold != "" {
+		fd.TtfBoldPath = filepath.Join(inputDir, def.TTFBold)
+	}
+	return fd
+}
+
+// fontFormats maps the extensions of web font files to their CSS format.
+var fontFormats = map[string]string{
+	".woff2": "woff2",
+	".woff":  "woff",
+	".ttf":   "truetype",
+	".otf":   "opentype",
+}
@@ -0,0 +1,125 @@
+// @feature:themes Tests for the themes and fonts defined in the vault and in kiln.yaml.
+package builder
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/otaleghani/kiln/assets"
+	"github.com/otaleghani/kiln/internal/appearance"
+)
+
+func TestRegisterAppearance_Themes(t *testing.T) {
+	dir := writeVault(t, map[string]string{
+		"_themes/brand.yaml": "extends: nord\nlight:\n  accent: \"#123456\"\ncss-vars:\n  light:\n    --sidebar-width: 300px\n",
+	})
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+	RegisterAppearance(dir, appearance.Options{
+		Themes: map[string]appearance.Definition{
+			"low": {Light: map[string]string{"bg": "#ffffff", "text": "#aaaaaa"}},
+		},
+		CSSVars: appearance.CSSVars{Light: map[string]string{"--sidebar-width": "320px", "--radius": "4px"}},
+	}, log)
+	t.Cleanup(func() { RegisterAppearance(t.TempDir(), appearance.Options{}, log) })
+
+	brand := ResolveTheme("Brand", "system", "", log)
+	if brand.Light.Accent != "#123456" {
+		t.Errorf("Light.Accent = %q, want the user colour", brand.Light.Accent)
+	}
+	if brand.Light.Bg != themes["nord"].Light.Bg || brand.Dark.Bg != themes["nord"].Dark.Bg {
+		t.Error("missing colours aren't taken from the extended theme")
+	}
+	if themes["nord"].Light.Accent == "#123456" {
+		t.Error("the user theme modified the built-in one")
+	}
+	data := toTemplTheme(brand)
+	want := "--radius=4px --sidebar-width=320px"
+	got := []string{}
+	for _, v := range data.LightVars {
+		got = append(got, v.Name+"="+v.Value)
+	}
+	if strings.Join(got, " ") != want {
+		t.Errorf("LightVars = %v, want %s (kiln.yaml winning over the theme)", got, want)
+	}
+
+	ResolveTheme("low", "system", "", log)
+	for _, want := range []string{"Missing theme colours", "WCAG AA", "text=text background=bg"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected the logs to contain %q, got:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestRegisterAppearance_Fonts(t *testing.T) {
+	ttf, err := assets.TemplateFS.ReadFile("Inter-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	boldTTF, err := assets.TemplateFS.ReadFile("Lato-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := writeVault(t, map[string]string{
+		"fonts/Brand-Regular.woff2": "regular",
+		"fonts/Brand-Italic.woff2":  "italic",
+		"fonts/Brand-Regular.ttf":   string(ttf),
+		"fonts/Brand-Bold.ttf":      string(boldTTF),
+		"other/Brand-Italic.woff2":  "other italic",
+	})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	RegisterAppearance(dir, appearance.Options{Fonts: map[string]appearance.Font{
+		"brand": {
+			Family: "Brand Sans",
+			Files: []appearance.FontFile{
+				{Path: "fonts/Brand-Regular.woff2"},
+				{Path: "fonts/Brand-Italic.woff2", Style: "italic"},
+				{Path: "fonts/Missing.woff2"},
+			},
+			TTF:     "fonts/Brand-Regular.ttf",
+			TTFBold: "fonts/Brand-Bold.ttf",
+		},
+		"other": {
+			Family: "Other",
+			Files:  []appearance.FontFile{{Path: "other/Brand-Italic.woff2", Style: "italic"}},
+		},
+	}}, log)
+	t.Cleanup(func() { RegisterAppearance(t.TempDir(), appearance.Options{}, log) })
+
+	font := resolveFont("brand", log)
+	if font.Family != "'Brand Sans', sans-serif" {
+		t.Errorf("Family = %q", font.Family)
+	}
+	if len(font.Files) != 2 {
+		t.Errorf("Files = %v, want the existing ones", font.Files)
+	}
+	face := string(font.FontFace)
+	if !strings.Contains(face, "font-style: italic;") || !strings.Contains(face, "url('{{.Site.BaseURL}}/fonts/brand/Brand-Italic.woff2') format('woff2')") {
+		t.Errorf("FontFace = %s", face)
+	}
+	og := font.LoadOGFonts(log)
+	if og.Regular == nil || og.Bold == nil {
+		t.Errorf("OG fonts = %+v, want the user TTFs", og)
+	}
+	// Same regular file as Inter, so only the bold file tells the keys apart
+	if og.Key == "" || og.Key == fonts["inter"].LoadOGFonts(log).Key {
+		t.Errorf("OG fonts key = %q, want it to hash the bold TTF", og.Key)
+	}
+
+	out := t.TempDir()
+	(&Theme{Font: font}).extractFonts(out, log)
+	content, err := os.ReadFile(filepath.Join(out, "fonts", "brand", "Brand-Italic.woff2"))
+	if err != nil || string(content) != "italic" {
+		t.Errorf("extracted font = %q, %v", content, err)
+	}
+	// Files named alike in two fonts don't overwrite each other
+	(&Theme{Font: resolveFont("other", log)}).extractFonts(out, log)
+	if content, _ := os.ReadFile(filepath.Join(out, "fonts", "brand", "Brand-Italic.woff2")); string(content) != "italic" {
+		t.Errorf("extracted font = %q, overwritten by the other font", content)
+	}
+}
@@ -53,6 +53,20 @@ func CleanOutputDir(log *slog.Logger) {
 	}
 }
 
+// CleanCache removes the build cache, forcing the next build to process
+// every image again.
+func CleanCache(log *slog.Logger) {
+	if CacheDir == "" {
+		return
+	}
+	err := os.RemoveAll(CacheDir)
+	if err != nil {
+		log.Error("Couldn't remove cache directory", "error", err)
+	} else {
+		log.Info("Cleaned cache", "path", CacheDir)
+	}
+}
+
 func shouldRebuild(relPath string) bool {
 	if RebuildFilter == nil {
 		return true
@@ -0,0 +1,520 @@
+// @feature:canvas Parsing and static SVG/PNG export of Obsidian canvas files.
+package canvas
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/ogimage"
+	"golang.org/x/image/font"
+)
+
+// Data represents the top-level structure of an Obsidian Canvas file.
+type Data struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node represents a single element (card, file, group) within the Canvas JSON.
+type Node struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`
+	File   string `json:"file,omitempty"`
+	Label  string `json:"label,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Color  string `json:"color,omitempty"`
+
+	// Fields injected during build time for the frontend:
+	HtmlContent string `json:"htmlContent,omitempty"` // Rendered HTML for markdown files
+	IsImage     bool   `json:"isImage,omitempty"`     // Flag for image nodes
+	Src         string `json:"src,omitempty"`         // Web path for image source
+	URL         string `json:"url,omitempty"`         // Link URL
+
+	// Fields used only by the static export:
+	Href    string `json:"-"` // Web path of the linked note, if any
+	Excerpt string `json:"-"` // Plain-text excerpt of the linked note
+}
+
+// Edge represents a connection between two nodes.
+type Edge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	FromSide string `json:"fromSide,omitempty"`
+	FromEnd  string `json:"fromEnd,omitempty"`
+	ToNode   string `json:"toNode"`
+	ToSide   string `json:"toSide,omitempty"`
+	ToEnd    string `json:"toEnd,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+// Palette holds the colors used by the static export.
+// Colors maps Obsidian's preset colors ("1" to "6") to hex values.
+type Palette struct {
+	Bg     string
+	Text   string
+	Muted  string
+	Border string
+	Accent string
+	Colors map[string]string
+}
+
+// Options configures the static export.
+type Options struct {
+	Palette    Palette
+	FontFamily string
+	FontSize   int
+	Title      string
+}
+
+const (
+	padding    = 40 // Space around the canvas bounds, in canvas units
+	curvature  = 100
+	lineHeight = 1.4
+	charWidth  = 0.55 // Approximate glyph width relative to the font size
+	edgeColor  = "#999999"
+)
+
+var (
+	reWikilink = regexp.MustCompile(`!?\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	reMdLink   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	reEmphasis = regexp.MustCompile("[*_`~=]{1,3}")
+	reHeading  = regexp.MustCompile(`(?m)^\s{0,3}(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	reFence    = regexp.MustCompile("(?s)```.*?```")
+	reTags     = regexp.MustCompile(`<[^>]+>`)
+	reFront    = regexp.MustCompile(`(?s)\A---\r?\n.*?\r?\n---\r?\n`)
+)
+
+// Parse decodes the JSON source of a .canvas file.
+func Parse(source []byte) (*Data, error) {
+	var d Data
+	if err := json.Unmarshal(source, &d); err != nil {
+		return nil, fmt.Errorf("canvas: parse: %w", err)
+	}
+	return &d, nil
+}
+
+// Bounds returns the rectangle enclosing every node, without padding.
+func (d *Data) Bounds() image.Rectangle {
+	if len(d.Nodes) == 0 {
+		return image.Rect(0, 0, 0, 0)
+	}
+	r := image.Rect(d.Nodes[0].X, d.Nodes[0].Y, d.Nodes[0].X+d.Nodes[0].Width, d.Nodes[0].Y+d.Nodes[0].Height)
+	for _, n := range d.Nodes[1:] {
+		r = r.Union(image.Rect(n.X, n.Y, n.X+n.Width, n.Y+n.Height))
+	}
+	return r
+}
+
+// node returns the node with the given id, or nil.
+func (d *Data) node(id string) *Node {
+	for i := range d.Nodes {
+		if d.Nodes[i].ID == id {
+			return &d.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// PlainText strips the most common markdown syntax from s, leaving readable text.
+func PlainText(s string) string {
+	s = reFront.ReplaceAllString(s, "")
+	s = reFence.ReplaceAllString(s, "")
+	s = reTags.ReplaceAllString(s, "")
+	s = reWikilink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := reWikilink.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return sub[2]
+		}
+		return sub[1]
+	})
+	s = reMdLink.ReplaceAllString(s, "$1")
+	s = reHeading.ReplaceAllString(s, "")
+	s = reEmphasis.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// wrap splits text into lines of at most width characters, preserving paragraph breaks.
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := ""
+		for _, w := range words {
+			switch {
+			case line == "":
+				line = w
+			case len([]rune(line))+1+len([]rune(w)) <= width:
+				line += " " + w
+			default:
+				lines = append(lines, line)
+				line = w
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitLines wraps text to the box and drops the lines that would overflow it,
+// ending the last visible line with an ellipsis.
+func fitLines(text string, boxWidth, boxHeight, fontSize int) []string {
+	cols := int(float64(boxWidth) / (float64(fontSize) * charWidth))
+	rows := int(float64(boxHeight) / (float64(fontSize) * lineHeight))
+	lines := wrap(text, cols)
+	if rows < 1 {
+		return nil
+	}
+	if len(lines) > rows {
+		lines = lines[:rows]
+		lines[rows-1] = strings.TrimRight(lines[rows-1], " ") + "…"
+	}
+	return lines
+}
+
+// resolveColor maps an Obsidian preset ("1".."6") or a hex color to a hex value.
+func (p Palette) resolveColor(c, fallback string) string {
+	if c == "" {
+		return fallback
+	}
+	if v, ok := p.Colors[c]; ok {
+		return v
+	}
+	if strings.HasPrefix(c, "#") {
+		return c
+	}
+	return fallback
+}
+
+// anchor returns the connection point on the given side of a node.
+func anchor(n *Node, side string) (float64, float64) {
+	x := float64(n.X) + float64(n.Width)/2
+	y := float64(n.Y) + float64(n.Height)/2
+	switch side {
+	case "top":
+		y = float64(n.Y)
+	case "bottom":
+		y = float64(n.Y + n.Height)
+	case "left":
+		x = float64(n.X)
+	case "right":
+		x = float64(n.X + n.Width)
+	}
+	return x, y
+}
+
+// control returns the bezier control point for an anchor, matching canvas.js.
+func control(x, y float64, side string) (float64, float64) {
+	switch side {
+	case "top":
+		y -= curvature
+	case "bottom":
+		y += curvature
+	case "left":
+		x -= curvature
+	case "right":
+		x += curvature
+	}
+	return x, y
+}
+
+// bezier evaluates a cubic bezier at t.
+func bezier(p0, p1, p2, p3, t float64) float64 {
+	u := 1 - t
+	return u*u*u*p0 + 3*u*u*t*p1 + 3*u*t*t*p2 + t*t*t*p3
+}
+
+// edgeCurve returns the four bezier points of an edge, or ok=false if a node is missing.
+func (d *Data) edgeCurve(e Edge) (pts [4][2]float64, ok bool) {
+	from, to := d.node(e.FromNode), d.node(e.ToNode)
+	if from == nil || to == nil {
+		return pts, false
+	}
+	sx, sy := anchor(from, e.FromSide)
+	ex, ey := anchor(to, e.ToSide)
+	c1x, c1y := control(sx, sy, e.FromSide)
+	c2x, c2y := control(ex, ey, e.ToSide)
+	return [4][2]float64{{sx, sy}, {c1x, c1y}, {c2x, c2y}, {ex, ey}}, true
+}
+
+// RenderSVG renders the canvas as a self-contained SVG document that needs no JavaScript.
+func RenderSVG(d *Data, opts Options) []byte {
+	if opts.FontSize == 0 {
+		opts.FontSize = 16
+	}
+	if opts.FontFamily == "" {
+		opts.FontFamily = "sans-serif"
+	}
+	p := opts.Palette
+	b := d.Bounds()
+	minX, minY := b.Min.X-padding, b.Min.Y-padding
+	w, h := b.Dx()+2*padding, b.Dy()+2*padding
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb,
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="%d %d %d %d" width="%d" height="%d" font-family="%s" font-size="%d" role="img">`,
+		minX, minY, w, h, w, h, esc(opts.FontFamily), opts.FontSize,
+	)
+	if opts.Title != "" {
+		fmt.Fprintf(&sb, `<title>%s</title>`, esc(opts.Title))
+	}
+	fmt.Fprintf(&sb,
+		`<defs><marker id="canvas-arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="context-stroke"/></marker></defs>`,
+	)
+	fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, minX, minY, w, h, esc(p.Bg))
+
+	// Groups sit behind everything else
+	for _, n := range d.Nodes {
+		if n.Type != "group" {
+			continue
+		}
+		stroke := p.resolveColor(n.Color, p.Border)
+		fmt.Fprintf(&sb,
+			`<g class="canvas-group"><rect x="%d" y="%d" width="%d" height="%d" rx="12" fill="%s" fill-opacity="0.08" stroke="%s" stroke-width="2"/>`,
+			n.X, n.Y, n.Width, n.Height, esc(stroke), esc(stroke),
+		)
+		if n.Label != "" {
+			fmt.Fprintf(&sb, `<text x="%d" y="%d" fill="%s" font-weight="bold">%s</text>`,
+				n.X+4, n.Y-8, esc(p.Text), esc(n.Label))
+		}
+		sb.WriteString(`</g>`)
+	}
+
+	// Edges
+	for _, e := range d.Edges {
+		pts, ok := d.edgeCurve(e)
+		if !ok {
+			continue
+		}
+		stroke := p.resolveColor(e.Color, edgeColor)
+		markers := ` marker-end="url(#canvas-arrow)"`
+		if e.ToEnd == "none" {
+			markers = ""
+		}
+		if e.FromEnd == "arrow" {
+			markers += ` marker-start="url(#canvas-arrow)"`
+		}
+		fmt.Fprintf(&sb,
+			`<path d="M %s %s C %s %s, %s %s, %s %s" fill="none" stroke="%s" stroke-width="2"%s/>`,
+			num(pts[0][0]), num(pts[0][1]), num(pts[1][0]), num(pts[1][1]),
+			num(pts[2][0]), num(pts[2][1]), num(pts[3][0]), num(pts[3][1]),
+			esc(stroke), markers,
+		)
+		if e.Label != "" {
+			mx := bezier(pts[0][0], pts[1][0], pts[2][0], pts[3][0], 0.5)
+			my := bezier(pts[0][1], pts[1][1], pts[2][1], pts[3][1], 0.5)
+			fmt.Fprintf(&sb,
+				`<text x="%s" y="%s" text-anchor="middle" fill="%s" stroke="%s" stroke-width="4" paint-order="stroke">%s</text>`,
+				num(mx), num(my), esc(p.Muted), esc(p.Bg), esc(e.Label),
+			)
+		}
+	}
+
+	// Cards
+	for _, n := range d.Nodes {
+		if n.Type == "group" {
+			continue
+		}
+		writeNode(&sb, n, p, opts.FontSize)
+	}
+
+	sb.WriteString(`</svg>`)
+	return []byte(sb.String())
+}
+
+// writeNode renders a single text, file or link card.
+func writeNode(sb *strings.Builder, n Node, p Palette, fontSize int) {
+	stroke := p.resolveColor(n.Color, p.Border)
+
+	sb.WriteString(`<g class="canvas-node">`)
+	fmt.Fprintf(sb,
+		`<rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="%s" stroke="%s" stroke-width="2"/>`,
+		n.X, n.Y, n.Width, n.Height, esc(p.Bg), esc(stroke),
+	)
+	if n.Color != "" {
+		// Tinted background, like the .color-N classes in shared.css
+		fmt.Fprintf(sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="%s" fill-opacity="0.1"/>`,
+			n.X, n.Y, n.Width, n.Height, esc(stroke))
+	}
+
+	inner := 12
+	x, y := n.X+inner, n.Y+inner
+	bw, bh := n.Width-2*inner, n.Height-2*inner
+
+	switch n.Type {
+	case "text":
+		writeLines(sb, fitLines(PlainText(n.Text), bw, bh, fontSize), x, y, fontSize, p.Text)
+	case "file":
+		title := strings.TrimSuffix(baseName(n.File), ".md")
+		if n.IsImage && n.Src != "" {
+			fmt.Fprintf(sb,
+				`<image href="%s" xlink:href="%s" x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet"/>`,
+				esc(n.Src), esc(n.Src), n.X, n.Y, n.Width, n.Height,
+			)
+			break
+		}
+		titleLine := fmt.Sprintf(`<text x="%d" y="%d" fill="%s" font-weight="bold">%s</text>`,
+			x, y+fontSize, esc(p.Accent), esc(title))
+		if n.Href != "" {
+			fmt.Fprintf(sb, `<a href="%s">%s</a>`, esc(n.Href), titleLine)
+		} else {
+			sb.WriteString(titleLine)
+		}
+		top := int(float64(fontSize) * lineHeight * 1.5)
+		writeLines(sb, fitLines(n.Excerpt, bw, bh-top, fontSize), x, y+top, fontSize, p.Text)
+	case "link":
+		fmt.Fprintf(sb, `<a href="%s"><text x="%d" y="%d" fill="%s" text-decoration="underline">%s</text></a>`,
+			esc(n.URL), x, y+fontSize, esc(p.Accent), esc(truncate(n.URL, int(float64(bw)/(float64(fontSize)*charWidth)))))
+	}
+	sb.WriteString(`</g>`)
+}
+
+// writeLines writes a <text> element with one <tspan> per line.
+func writeLines(sb *strings.Builder, lines []string, x, y, fontSize int, fill string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, `<text x="%d" y="%d" fill="%s">`, x, y, esc(fill))
+	for i, line := range lines {
+		dy := num(float64(fontSize) * lineHeight)
+		if i == 0 {
+			dy = fmt.Sprintf("%d", fontSize)
+		}
+		fmt.Fprintf(sb, `<tspan x="%d" dy="%s">%s</tspan>`, x, dy, esc(line))
+	}
+	sb.WriteString(`</text>`)
+}
+
+// BuildScene lays the canvas out on a width x height raster scene, scaled to fit,
+// for use as an OG/social preview image.
+func BuildScene(d *Data, opts Options, width, height int, face font.Face) ogimage.Scene {
+	p := opts.Palette
+	scene := ogimage.Scene{Width: width, Height: height, BgColor: p.Bg, Face: face}
+	b := d.Bounds()
+	if b.Empty() {
+		return scene
+	}
+
+	const margin = 40
+	scale := math.Min(
+		float64(width-2*margin)/float64(b.Dx()),
+		float64(height-2*margin)/float64(b.Dy()),
+	)
+	scale = math.Min(scale, 1)
+	offX := (float64(width) - float64(b.Dx())*scale) / 2
+	offY := (float64(height) - float64(b.Dy())*scale) / 2
+	pt := func(x, y float64) image.Point {
+		return image.Pt(
+			int(offX+(x-float64(b.Min.X))*scale),
+			int(offY+(y-float64(b.Min.Y))*scale),
+		)
+	}
+	rect := func(n Node) image.Rectangle {
+		return image.Rectangle{
+			Min: pt(float64(n.X), float64(n.Y)),
+			Max: pt(float64(n.X+n.Width), float64(n.Y+n.Height)),
+		}
+	}
+
+	for _, n := range d.Nodes {
+		if n.Type != "group" {
+			continue
+		}
+		c := p.resolveColor(n.Color, p.Border)
+		scene.Rects = append(scene.Rects, ogimage.SceneRect{
+			Rect: rect(n), Fill: c, FillOpacity: 0.08, Stroke: c, StrokeWidth: 2,
+		})
+	}
+
+	for _, e := range d.Edges {
+		pts, ok := d.edgeCurve(e)
+		if !ok {
+			continue
+		}
+		line := ogimage.SceneLine{Color: p.resolveColor(e.Color, edgeColor), Width: 2}
+		for i := 0; i <= 16; i++ {
+			t := float64(i) / 16
+			line.Points = append(line.Points, pt(
+				bezier(pts[0][0], pts[1][0], pts[2][0], pts[3][0], t),
+				bezier(pts[0][1], pts[1][1], pts[2][1], pts[3][1], t),
+			))
+		}
+		scene.Lines = append(scene.Lines, line)
+	}
+
+	for _, n := range d.Nodes {
+		if n.Type == "group" {
+			continue
+		}
+		r := rect(n)
+		c := p.resolveColor(n.Color, p.Border)
+		scene.Rects = append(scene.Rects, ogimage.SceneRect{Rect: r, Fill: p.Bg, Stroke: c, StrokeWidth: 2})
+		if n.Color != "" {
+			scene.Rects = append(scene.Rects, ogimage.SceneRect{Rect: r, Fill: c, FillOpacity: 0.1})
+		}
+
+		label := ""
+		switch n.Type {
+		case "text":
+			lines := wrap(PlainText(n.Text), 200)
+			if len(lines) > 0 {
+				label = lines[0]
+			}
+		case "file":
+			label = strings.TrimSuffix(baseName(n.File), ".md")
+		case "link":
+			label = n.URL
+		}
+		if label == "" || face == nil {
+			continue
+		}
+		ascent := face.Metrics().Ascent.Ceil()
+		if r.Dy() < ascent+8 {
+			continue
+		}
+		scene.Labels = append(scene.Labels, ogimage.SceneLabel{
+			X: r.Min.X + 8, Y: r.Min.Y + 6 + ascent, Text: label, Color: p.Text, MaxWidth: r.Dx() - 16,
+		})
+	}
+
+	return scene
+}
+
+func baseName(p string) string {
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		return p[i+1:]
+	}
+	return p
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 1 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func num(f float64) string {
+	return fmt.Sprintf("%.1f", f)
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
@@ -0,0 +1,139 @@
+// @feature:canvas Tests for canvas parsing and static export.
+package canvas
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const sample = `{
+	"nodes": [
+		{"id": "a", "type": "text", "text": "# Hello\n**bold** & [[Target|alias]]", "x": 0, "y": 0, "width": 200, "height": 100, "color": "1"},
+		{"id": "b", "type": "file", "file": "Notes/Page.md", "x": 300, "y": 0, "width": 200, "height": 100},
+		{"id": "c", "type": "link", "url": "https://example.com/?a=1&b=2", "x": 0, "y": 200, "width": 200, "height": 60},
+		{"id": "g", "type": "group", "label": "Group <1>", "x": -20, "y": -40, "width": 540, "height": 320}
+	],
+	"edges": [
+		{"id": "e1", "fromNode": "a", "fromSide": "right", "toNode": "b", "toSide": "left", "label": "links to"},
+		{"id": "e2", "fromNode": "a", "toNode": "missing"}
+	]
+}`
+
+func testOptions() Options {
+	return Options{
+		Palette: Palette{
+			Bg:     "#ffffff",
+			Text:   "#000000",
+			Muted:  "#666666",
+			Border: "#cccccc",
+			Accent: "#0000ff",
+			Colors: map[string]string{"1": "#ff0000"},
+		},
+		Title: "My Canvas",
+	}
+}
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if len(d.Nodes) != 4 || len(d.Edges) != 2 {
+		t.Fatalf("expected 4 nodes and 2 edges, got %d and %d", len(d.Nodes), len(d.Edges))
+	}
+	if d.Edges[0].FromSide != "right" || d.Edges[0].Label != "links to" {
+		t.Errorf("edge fields not decoded: %+v", d.Edges[0])
+	}
+
+	if _, err := Parse([]byte("{not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestBounds(t *testing.T) {
+	d, _ := Parse([]byte(sample))
+	b := d.Bounds()
+	if b.Min.X != -20 || b.Min.Y != -40 || b.Max.X != 520 || b.Max.Y != 280 {
+		t.Errorf("unexpected bounds: %v", b)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := PlainText("---\ntitle: x\n---\n## Title\n*a* [[Note|Alias]] [link](http://x) [[Other]]")
+	want := "Title\na Alias link Other"
+	if got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	d, _ := Parse([]byte(sample))
+	d.Nodes[1].Href = "/notes/page"
+	d.Nodes[1].Excerpt = "Some excerpt"
+
+	svg := string(RenderSVG(d, testOptions()))
+
+	// Must be well-formed XML
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+	}
+
+	checks := []string{
+		`<title>My Canvas</title>`,
+		`viewBox="-60 -80 620 400"`,
+		`stroke="#ff0000"`,
+		`Hello`,
+		`bold &amp; alias`,
+		`<a href="/notes/page">`,
+		`Some excerpt`,
+		`https://example.com/?a=1&amp;b=2`,
+		`Group &lt;1&gt;`,
+		`links to`,
+		`marker-end="url(#canvas-arrow)"`,
+	}
+	for _, c := range checks {
+		if !strings.Contains(svg, c) {
+			t.Errorf("expected SVG to contain %q", c)
+		}
+	}
+	if strings.Count(svg, `fill="none" stroke=`) != 1 {
+		t.Errorf("expected exactly one edge path (edge to missing node skipped)")
+	}
+	if strings.Contains(svg, "<script") {
+		t.Error("static export must not contain scripts")
+	}
+}
+
+func TestFitLines(t *testing.T) {
+	lines := fitLines(strings.Repeat("word ", 100), 100, 50, 10)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), lines)
+	}
+	if !strings.HasSuffix(lines[2], "…") {
+		t.Errorf("expected last line to end with an ellipsis, got %q", lines[2])
+	}
+}
+
+func TestBuildScene(t *testing.T) {
+	d, _ := Parse([]byte(sample))
+	scene := BuildScene(d, testOptions(), 1200, 630, nil)
+
+	if scene.Width != 1200 || scene.Height != 630 {
+		t.Errorf("unexpected scene size %dx%d", scene.Width, scene.Height)
+	}
+	if len(scene.Lines) != 1 {
+		t.Errorf("expected 1 edge line, got %d", len(scene.Lines))
+	}
+	for _, r := range scene.Rects {
+		if r.Rect.Min.X < 0 || r.Rect.Max.X > 1200 || r.Rect.Min.Y < 0 || r.Rect.Max.Y > 630 {
+			t.Errorf("rect %v outside of scene", r.Rect)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 // It is useful for ensuring a fresh build state or removing old files before a new generation.
 var cmdClean = &cobra.Command{
 	Use:   "clean",
-	Short: "Removes the public output directory",
+	Short: "Removes the public output directory, and the build cache with --cache",
 	Run:   runClean,
 }
 
@@ -22,6 +22,10 @@ func init() {
 		StringVarP(&outputDir, FlagOutputDir, FlagOutputDirShort, DefaultOutputDir, "Name of the output directory (defaults to ./public)")
 	cmdClean.Flags().
 		StringVarP(&logger, FlagLog, FlagLogShort, DefaultLog, "Logging level. Choose between 'debug' or 'info'. Defaults to 'info'.")
+	cmdClean.Flags().
+		StringVar(&cacheDir, FlagCacheDir, DefaultCacheDir, "Directory of the build cache (defaults to ./.kiln-cache)")
+	cmdClean.Flags().
+		BoolVar(&cleanCache, FlagCache, false, "Also remove the build cache, so the next build processes every image again")
 }
 
 // runClean executes the cleanup logic.
@@ -29,9 +33,14 @@ func runClean(cmd *cobra.Command, args []string) {
 	cfg := loadConfig(cmd)
 	applyStringFlag(cmd, FlagOutputDir, &outputDir, cfg, DefaultOutputDir)
 	applyStringFlag(cmd, FlagLog, &logger, cfg, DefaultLog)
+	applyStringFlag(cmd, FlagCacheDir, &cacheDir, cfg, DefaultCacheDir)
 
 	builder.OutputDir = outputDir
+	builder.CacheDir = cacheDir
 
 	log := getLogger()
 	builder.CleanOutputDir(log)
+	if cleanCache {
+		builder.CleanCache(log)
+	}
 }
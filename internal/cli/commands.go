@@ -16,74 +16,102 @@ import (
 
 // Default configuration constants for the build process.
 const (
-	DefaultOutputDir         = "./public" // The target directory for the generated static site
-	DefaultInputDir          = "./vault"  // The default source directory containing Obsidian markdown files
-	DefaultSiteName          = "My Notes"
-	DefaultBaseURL           = ""
-	DefaultThemeName         = "default"
-	DefaultFontName          = "inter"
-	DefaultFlatURLS          = false
-	DefaultMode              = "default"
-	DefaultPort              = "8080"
-	DefaultLog               = "info"
-	DefaultLayout            = "default"
-	DefaultDisableTOC        = false
-	DefaultDisableLocalGraph = false
-	DefaultDisableBacklinks  = false
-	DefaultLang              = "en"
-	DefaultAccentColor       = "" // Empty means use the theme's built-in accent
+	DefaultOutputDir          = "./public" // The target directory for the generated static site
+	DefaultInputDir           = "./vault"  // The default source directory containing Obsidian markdown files
+	DefaultSiteName           = "My Notes"
+	DefaultBaseURL            = ""
+	DefaultThemeName          = "default"
+	DefaultFontName           = "inter"
+	DefaultFlatURLS           = false
+	DefaultMode               = "default"
+	DefaultPort               = "8080"
+	DefaultHost               = "" // Empty means every interface
+	DefaultLog                = "info"
+	DefaultLayout             = "default"
+	DefaultDisableTOC         = false
+	DefaultDisableLocalGraph  = false
+	DefaultDisableBacklinks   = false
+	DefaultUnlinkedMentions   = false
+	DefaultLang               = "en"
+	DefaultAccentColor        = ""              // Empty means use the theme's built-in accent
+	DefaultCacheDir           = "./.kiln-cache" // Kept between builds, outside the output directory
+	DefaultDateSource         = "filesystem"
+	DefaultStripPrefixes      = false
+	DefaultEditURL            = "" // Empty means no "edit this page" link
+	DefaultDisablePrecompress = false
+	DefaultCheckIncremental   = false
+	DefaultTemplate           = "" // Empty means a bare vault for init, the templates of the vault for new
 )
 
 // Flag names
 const (
-	FlagTheme             = "theme"
-	FlagThemeShort        = "t"
-	FlagFont              = "font"
-	FlagFontShort         = "f"
-	FlagUrl               = "url"
-	FlagUrlShort          = "u"
-	FlagSiteName          = "name"
-	FlagSiteNameShort     = "n"
-	FlagInputDir          = "input"
-	FlagInputDirShort     = "i"
-	FlagOutputDir         = "output"
-	FlagOutputDirShort    = "o"
-	FlagMode              = "mode"
-	FlagModeShort         = "m"
-	FlagPort              = "port"
-	FlagPortShort         = "p"
-	FlagLog               = "log"
-	FlagLogShort          = "l"
-	FlagLayout            = "layout"
-	FlagLayoutShort       = "L"
-	FlagFlatURLS          = "flat-urls"
-	FlagDisableTOC        = "disable-toc"
-	FlagDisableLocalGraph = "disable-local-graph"
-	FlagDisableBacklinks  = "disable-backlinks"
-	FlagLang              = "lang"
-	FlagLangShort         = "g"
-	FlagAccentColor       = "accent-color"
-	FlagAccentColorShort  = "a"
+	FlagTheme              = "theme"
+	FlagThemeShort         = "t"
+	FlagFont               = "font"
+	FlagFontShort          = "f"
+	FlagUrl                = "url"
+	FlagUrlShort           = "u"
+	FlagSiteName           = "name"
+	FlagSiteNameShort      = "n"
+	FlagInputDir           = "input"
+	FlagInputDirShort      = "i"
+	FlagOutputDir          = "output"
+	FlagOutputDirShort     = "o"
+	FlagMode               = "mode"
+	FlagModeShort          = "m"
+	FlagPort               = "port"
+	FlagPortShort          = "p"
+	FlagHost               = "host"
+	FlagLog                = "log"
+	FlagLogShort           = "l"
+	FlagLayout             = "layout"
+	FlagLayoutShort        = "L"
+	FlagFlatURLS           = "flat-urls"
+	FlagDisableTOC         = "disable-toc"
+	FlagDisableLocalGraph  = "disable-local-graph"
+	FlagDisableBacklinks   = "disable-backlinks"
+	FlagUnlinkedMentions   = "unlinked-mentions"
+	FlagLang               = "lang"
+	FlagLangShort          = "g"
+	FlagAccentColor        = "accent-color"
+	FlagAccentColorShort   = "a"
+	FlagCacheDir           = "cache-dir"
+	FlagDateSource         = "date-source"
+	FlagEditURL            = "edit-url"
+	FlagStripPrefixes      = "strip-prefixes"
+	FlagDisablePrecompress = "disable-precompress"
+	FlagCheckIncremental   = "check-incremental"
+	FlagCache              = "cache"
+	FlagTemplate           = "template"
 )
 
 // Global variables to store the values of command-line flags.
 // These are populated by Cobra when the command is executed.
 var (
-	themeName         string // The visual theme (e.g., "dracula")
-	fontName          string // The font family to use (e.g., "inter")
-	baseURL           string // The base URL for SEO and sitemap generation
-	siteName          string // The display name of the generated site
-	inputDir          string // Custom path to the source vault
-	outputDir         string // Custom path for the build output
-	mode              string // Choose the mode of generation
-	flatUrls          bool   // Choose between pretty (e.g. note/index.html) or flat URLs (e.g. note.html)
-	logger            string // Choose the level of logging
-	layout            string // The chosen layout
-	disableTOC        bool   // Disable the Table of contents
-	disableLocalGraph bool   // Disable the local graph
-	disableBacklinks  bool   // Disable the backlinks panel
-	lang              string // Language code for the site
-	accentColor       string // Accent color override from theme palette
+	themeName          string // The visual theme (e.g., "dracula")
+	fontName           string // The font family to use (e.g., "inter")
+	baseURL            string // The base URL for SEO and sitemap generation
+	siteName           string // The display name of the generated site
+	inputDir           string // Custom path to the source vault
+	outputDir          string // Custom path for the build output
+	mode               string // Choose the mode of generation
+	flatUrls           bool   // Choose between pretty (e.g. note/index.html) or flat URLs (e.g. note.html)
+	logger             string // Choose the level of logging
+	layout             string // The chosen layout
+	disableTOC         bool   // Disable the Table of contents
+	disableLocalGraph  bool   // Disable the local graph
+	disableBacklinks   bool   // Disable the backlinks panel
+	unlinkedMentions   bool   // Collect plain-text mentions of notes
+	lang               string // Language code for the site
+	accentColor        string // Accent color override from theme palette
+	cacheDir           string // Directory of the build cache
+	dateSource         string // Where the dates of the notes come from
+	editURL            string // Pattern of the links to the source of the pages
+	stripPrefixes      bool   // Remove numeric prefixes from the names and URLs of the pages
+	disablePrecompress bool   // Skip the brotli and gzip versions of the text files
+	checkIncremental   bool   // Compare every incremental build of dev with a full build
+	cleanCache         bool   // Also remove the build cache when cleaning
+	templateName       string // Starter of init, or template of new
 )
 
 // Init constructs and returns the root command for the application.
@@ -101,9 +129,12 @@ It supports wikilinks, callouts, mermaid diagrams, and graph visualization.`,
 	rootCmd.AddCommand(cmdGenerate) // Builds the static site
 	rootCmd.AddCommand(cmdServe)    // Starts a local preview server
 	rootCmd.AddCommand(cmdInit)     // Initializes a new vault structure
+	rootCmd.AddCommand(cmdNew)      // Creates a note from a template
 	rootCmd.AddCommand(cmdClean)    // Removes generated artifacts
 	rootCmd.AddCommand(cmdDoctor)   // Checks for common issues
 	rootCmd.AddCommand(cmdStats)    // Displays vault statistics
+	rootCmd.AddCommand(cmdSEO)      // Reports missing or duplicate titles and descriptions
+	rootCmd.AddCommand(cmdI18n)     // Extracts the UI labels to translate
 	rootCmd.AddCommand(cmdVersion)  // Version of the program
 	rootCmd.AddCommand(cmdDev)      // Build, watch, and serve
 
@@ -3,11 +3,11 @@ package cli
 
 import (
 	"context"
+	"log/slog"
 	"os/signal"
 	"syscall"
 
 	"github.com/otaleghani/kiln/internal/builder"
-	"github.com/otaleghani/kiln/internal/obsidian"
 	"github.com/otaleghani/kiln/internal/server"
 	"github.com/otaleghani/kiln/internal/watch"
 	"github.com/spf13/cobra"
@@ -46,12 +46,26 @@ func init() {
 		BoolVar(&disableLocalGraph, FlagDisableLocalGraph, DefaultDisableLocalGraph, "Disables the Local graph.")
 	cmdDev.Flags().
 		BoolVar(&disableBacklinks, FlagDisableBacklinks, DefaultDisableBacklinks, "Disables the Backlinks panel on the right sidebar.")
+	cmdDev.Flags().
+		BoolVar(&unlinkedMentions, FlagUnlinkedMentions, DefaultUnlinkedMentions, "Lists plain-text mentions of each note below its backlinks.")
 	cmdDev.Flags().
 		StringVarP(&lang, FlagLang, FlagLangShort, DefaultLang, "Language code for the site (e.g. en, it, fr)")
+	cmdDev.Flags().
+		StringVar(&cacheDir, FlagCacheDir, DefaultCacheDir, "Directory of the build cache, reused between builds (defaults to ./.kiln-cache)")
+	cmdDev.Flags().
+		StringVar(&dateSource, FlagDateSource, DefaultDateSource, "Source of the created and modified dates of the notes (filesystem, frontmatter, git)")
+	cmdDev.Flags().
+		StringVar(&editURL, FlagEditURL, DefaultEditURL, "Pattern of the \"edit this page\" links of the docs layout, {path} being the file in the vault")
+	cmdDev.Flags().
+		BoolVar(&stripPrefixes, FlagStripPrefixes, DefaultStripPrefixes, "Removes numeric prefixes such as \"01 \" from the names and URLs of the pages.")
 	cmdDev.Flags().
 		StringVarP(&accentColor, FlagAccentColor, FlagAccentColorShort, DefaultAccentColor, "Accent color from theme palette (red, orange, yellow, green, blue, purple, cyan)")
 	cmdDev.Flags().
 		StringVarP(&port, FlagPort, FlagPortShort, DefaultPort, "Port to serve on")
+	cmdDev.Flags().
+		StringVar(&host, FlagHost, DefaultHost, "Interface to listen on, e.g. 127.0.0.1 (defaults to every interface)")
+	cmdDev.Flags().
+		BoolVar(&checkIncremental, FlagCheckIncremental, DefaultCheckIncremental, "Compares the output of every rebuild with a full build, logging the files that differ.")
 }
 
 func runDev(cmd *cobra.Command, args []string) {
@@ -69,9 +83,15 @@ func runDev(cmd *cobra.Command, args []string) {
 	applyBoolFlag(cmd, FlagDisableTOC, &disableTOC, cfg, DefaultDisableTOC)
 	applyBoolFlag(cmd, FlagDisableLocalGraph, &disableLocalGraph, cfg, DefaultDisableLocalGraph)
 	applyBoolFlag(cmd, FlagDisableBacklinks, &disableBacklinks, cfg, DefaultDisableBacklinks)
+	applyBoolFlag(cmd, FlagUnlinkedMentions, &unlinkedMentions, cfg, DefaultUnlinkedMentions)
 	applyStringFlag(cmd, FlagLang, &lang, cfg, DefaultLang)
 	applyStringFlag(cmd, FlagAccentColor, &accentColor, cfg, DefaultAccentColor)
+	applyStringFlag(cmd, FlagCacheDir, &cacheDir, cfg, DefaultCacheDir)
+	applyStringFlag(cmd, FlagDateSource, &dateSource, cfg, DefaultDateSource)
+	applyStringFlag(cmd, FlagEditURL, &editURL, cfg, DefaultEditURL)
+	applyBoolFlag(cmd, FlagStripPrefixes, &stripPrefixes, cfg, DefaultStripPrefixes)
 	applyStringFlag(cmd, FlagPort, &port, cfg, DefaultPort)
+	applyStringFlag(cmd, FlagHost, &host, cfg, DefaultHost)
 
 	builder.OutputDir = outputDir
 	builder.InputDir = inputDir
@@ -85,8 +105,20 @@ func runDev(cmd *cobra.Command, args []string) {
 	builder.DisableTOC = disableTOC
 	builder.DisableLocalGraph = disableLocalGraph
 	builder.DisableBacklinks = disableBacklinks
+	builder.UnlinkedMentions = unlinkedMentions
 	builder.Lang = lang
 	builder.AccentColorName = accentColor
+	builder.CacheDir = cacheDir
+	builder.DateSource = dateSource
+	builder.EditURL = editURL
+	builder.StripPrefixes = stripPrefixes
+	builder.GraphOptions = cfg.Graph
+	builder.ImageOptions = cfg.Images
+	builder.OGOptions = cfg.OG
+	builder.SEOOptions = cfg.SEO
+	builder.I18nOptions = cfg.I18n
+	builder.AppearanceOptions = cfg.Appearance
+	builder.FolderOptions = cfg.Folders
 
 	log := getLogger()
 
@@ -94,60 +126,30 @@ func runDev(cmd *cobra.Command, args []string) {
 	log.Info("Running initial build")
 	builder.Build(log)
 
-	// Populate mtime baseline
-	mtimeStore := watch.NewMtimeStore()
-	if _, _, err := mtimeStore.Update(inputDir); err != nil {
-		log.Error("failed to populate mtime baseline", "err", err)
-		return
-	}
-
-	// Build dependency graph from vault scan
+	// Build dependency graph from the vault model of the build
 	graph := watch.NewDepGraph()
-	vault := obsidian.New(
-		obsidian.WithInputDir(inputDir),
-		obsidian.WithOutputDir(outputDir),
-		obsidian.WithBaseURL(baseURL),
-		obsidian.WithFlatURLs(flatUrls),
-		obsidian.WithLogger(log),
-	)
-	if err := vault.Scan(); err != nil {
-		log.Error("failed to scan vault for dependency graph", "err", err)
-		return
-	}
-	graph.BuildFromFiles(vault.Vault.Files)
+	graph.BuildFromFiles(builder.VaultFiles())
 
 	// Set up watcher with rebuild callback
 	watcher := &watch.Watcher{
 		InputDir: inputDir,
 		Log:      log,
-		OnRebuild: func() error {
-			changed, removed, err := mtimeStore.Update(inputDir)
-			if err != nil {
-				log.Error("mtime update failed", "err", err)
-				return err
-			}
-
-			cs := watch.ComputeChangeSet(changed, removed, graph)
+		OnRebuild: func(changes watch.Changes) error {
+			cs := watch.ComputeChanges(changes, graph)
 			log.Info("changeset",
 				"rebuild", len(cs.Rebuild),
 				"remove", len(cs.Remove),
+				"renamed", len(changes.Renamed),
 			)
 
-			builder.IncrementalBuild(log, cs.Rebuild, cs.Remove)
+			builder.IncrementalBuild(log, cs.Changed, cs.Rebuild, cs.Remove)
 
-			// Refresh dependency graph for changed files
-			vault := obsidian.New(
-				obsidian.WithInputDir(inputDir),
-				obsidian.WithOutputDir(outputDir),
-				obsidian.WithBaseURL(baseURL),
-				obsidian.WithFlatURLs(flatUrls),
-				obsidian.WithLogger(log),
-			)
-			if err := vault.Scan(); err != nil {
-				log.Error("failed to rescan vault", "err", err)
-				return nil
+			// Refresh dependency graph for the rebuilt files
+			graph.UpdateSources(builder.VaultFiles(), cs.Rebuild)
+
+			if checkIncremental {
+				checkRebuild(log)
 			}
-			graph.UpdateFiles(vault.Vault.Files)
 
 			return nil
 		},
@@ -166,5 +168,29 @@ func runDev(cmd *cobra.Command, args []string) {
 
 	// Serve on main goroutine
 	localBaseURL := "http://localhost:" + port
-	server.Serve(ctx, port, builder.OutputDir, localBaseURL, log)
+	// Pages change on every save, so they aren't cached
+	server.Serve(ctx, server.Options{
+		Host:      host,
+		Port:      port,
+		OutputDir: builder.OutputDir,
+		BaseURL:   localBaseURL,
+		Rules:     true,
+	}, log)
+}
+
+// checkRebuild compares the output of the last rebuild with a full build,
+// logging the files that differ.
+func checkRebuild(log *slog.Logger) {
+	diffs, err := builder.CheckIncremental(slog.New(slog.DiscardHandler))
+	if err != nil {
+		log.Error("Couldn't check the rebuild", "error", err)
+		return
+	}
+	if len(diffs) == 0 {
+		log.Info("Rebuild matches a full build")
+		return
+	}
+	for _, diff := range diffs {
+		log.Warn("Rebuild differs from a full build", "file", diff)
+	}
 }
@@ -43,8 +43,20 @@ func init() {
 		BoolVar(&disableLocalGraph, FlagDisableLocalGraph, DefaultDisableLocalGraph, "Disables the Local graph. If the table of contents is disabled too, hides the right sidebar.")
 	cmdGenerate.Flags().
 		BoolVar(&disableBacklinks, FlagDisableBacklinks, DefaultDisableBacklinks, "Disables the Backlinks panel on the right sidebar.")
+	cmdGenerate.Flags().
+		BoolVar(&unlinkedMentions, FlagUnlinkedMentions, DefaultUnlinkedMentions, "Lists plain-text mentions of each note below its backlinks.")
 	cmdGenerate.Flags().
 		StringVarP(&lang, FlagLang, FlagLangShort, DefaultLang, "Language code for the site (e.g. en, it, fr)")
+	cmdGenerate.Flags().
+		StringVar(&cacheDir, FlagCacheDir, DefaultCacheDir, "Directory of the build cache, reused between builds (defaults to ./.kiln-cache)")
+	cmdGenerate.Flags().
+		StringVar(&dateSource, FlagDateSource, DefaultDateSource, "Source of the created and modified dates of the notes (filesystem, frontmatter, git)")
+	cmdGenerate.Flags().
+		StringVar(&editURL, FlagEditURL, DefaultEditURL, "Pattern of the \"edit this page\" links of the docs layout, {path} being the file in the vault")
+	cmdGenerate.Flags().
+		BoolVar(&stripPrefixes, FlagStripPrefixes, DefaultStripPrefixes, "Removes numeric prefixes such as \"01 \" from the names and URLs of the pages.")
+	cmdGenerate.Flags().
+		BoolVar(&disablePrecompress, FlagDisablePrecompress, DefaultDisablePrecompress, "Skips the .br and .gz versions of the HTML, CSS, JS and JSON files.")
 	cmdGenerate.Flags().
 		StringVarP(&accentColor, FlagAccentColor, FlagAccentColorShort, DefaultAccentColor, "Accent color from theme palette (red, orange, yellow, green, blue, purple, cyan)")
 }
@@ -65,8 +77,14 @@ func runGenerate(cmd *cobra.Command, args []string) {
 	applyBoolFlag(cmd, FlagDisableTOC, &disableTOC, cfg, DefaultDisableTOC)
 	applyBoolFlag(cmd, FlagDisableLocalGraph, &disableLocalGraph, cfg, DefaultDisableLocalGraph)
 	applyBoolFlag(cmd, FlagDisableBacklinks, &disableBacklinks, cfg, DefaultDisableBacklinks)
+	applyBoolFlag(cmd, FlagUnlinkedMentions, &unlinkedMentions, cfg, DefaultUnlinkedMentions)
 	applyStringFlag(cmd, FlagLang, &lang, cfg, DefaultLang)
 	applyStringFlag(cmd, FlagAccentColor, &accentColor, cfg, DefaultAccentColor)
+	applyStringFlag(cmd, FlagCacheDir, &cacheDir, cfg, DefaultCacheDir)
+	applyStringFlag(cmd, FlagDateSource, &dateSource, cfg, DefaultDateSource)
+	applyStringFlag(cmd, FlagEditURL, &editURL, cfg, DefaultEditURL)
+	applyBoolFlag(cmd, FlagStripPrefixes, &stripPrefixes, cfg, DefaultStripPrefixes)
+	applyBoolFlag(cmd, FlagDisablePrecompress, &disablePrecompress, cfg, DefaultDisablePrecompress)
 
 	builder.OutputDir = outputDir
 	builder.InputDir = inputDir
@@ -80,8 +98,21 @@ func runGenerate(cmd *cobra.Command, args []string) {
 	builder.DisableTOC = disableTOC
 	builder.DisableLocalGraph = disableLocalGraph
 	builder.DisableBacklinks = disableBacklinks
+	builder.UnlinkedMentions = unlinkedMentions
 	builder.Lang = lang
 	builder.AccentColorName = accentColor
+	builder.CacheDir = cacheDir
+	builder.DateSource = dateSource
+	builder.EditURL = editURL
+	builder.StripPrefixes = stripPrefixes
+	builder.Precompress = !disablePrecompress
+	builder.GraphOptions = cfg.Graph
+	builder.ImageOptions = cfg.Images
+	builder.OGOptions = cfg.OG
+	builder.SEOOptions = cfg.SEO
+	builder.I18nOptions = cfg.I18n
+	builder.AppearanceOptions = cfg.Appearance
+	builder.FolderOptions = cfg.Folders
 
 	log := getLogger()
 	builder.Build(log)
@@ -0,0 +1,41 @@
+// Cobra i18n command that helps translating the UI labels. @feature:cli
+package cli
+
+import (
+	"os"
+
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// cmdI18n groups the commands working on the UI labels.
+var cmdI18n = &cobra.Command{
+	Use:   "i18n",
+	Short: "Helps translating the UI labels",
+}
+
+// cmdI18nExtract prints the labels of a language as a translation file.
+// Its output is the starting point of a new _i18n/<lang>.yaml file.
+var cmdI18nExtract = &cobra.Command{
+	Use:   "extract",
+	Short: "Prints the UI labels as a translation file to start from",
+	Run:   runI18nExtract,
+}
+
+func init() {
+	// Register flags for the extract command.
+	cmdI18nExtract.Flags().
+		StringVarP(&lang, FlagLang, FlagLangShort, DefaultLang, "Language of the labels to print (defaults to en)")
+	cmdI18n.AddCommand(cmdI18nExtract)
+}
+
+// runI18nExtract prints the labels of the requested language to stdout.
+func runI18nExtract(cmd *cobra.Command, args []string) {
+	log := getLogger()
+	out, err := i18n.Template(lang)
+	if err != nil {
+		log.Error("Couldn't extract the UI labels", "error", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out)
+}
@@ -10,7 +10,8 @@ import (
 )
 
 // cmdInit represents the command to scaffold a new project structure.
-// It checks for the existence of a vault directory and creates a welcome note if one isn't found.
+// It checks for the existence of a vault directory and creates a welcome note if one isn't found,
+// or the vault of a starter with --template.
 var cmdInit = &cobra.Command{
 	Use:   "init",
 	Short: "Initializes a new Kiln project",
@@ -24,6 +25,8 @@ func init() {
 		StringVarP(&inputDir, FlagInputDir, FlagInputDirShort, DefaultInputDir, "Name of the input directory (defaults to ./vault)")
 	cmdInit.Flags().
 		StringVarP(&logger, FlagLog, FlagLogShort, DefaultLog, "Logging level. Choose between 'debug' or 'info'. Defaults to 'info'.")
+	cmdInit.Flags().
+		StringVar(&templateName, FlagTemplate, DefaultTemplate, "Starter vault to create: 'blog', 'docs', 'garden' or 'custom'. Defaults to a bare vault")
 }
 
 // runInit executes the initialization logic.
@@ -35,11 +38,38 @@ func runInit(cmd *cobra.Command, args []string) {
 	builder.InputDir = inputDir
 
 	log := getLogger()
-	builder.Init(log)
+
+	// The settings of the starter come first in kiln.yaml
+	var settings []byte
+	if templateName != "" {
+		var err error
+		settings, err = builder.Scaffold(templateName)
+		if err != nil {
+			log.Error("Couldn't create the vault", "template", templateName, "error", err)
+			return
+		}
+		settings = append(settings, '\n')
+		log.Info("Created vault from template", "template", templateName)
+	} else {
+		builder.Init(log)
+	}
 
 	// Scaffold a kiln.yaml in the current directory if one doesn't exist.
 	if _, err := os.Stat(config.DefaultFilename); os.IsNotExist(err) {
-		content := `# Kiln configuration file
+		content := append(settings, defaultConfigFile...)
+		if err := os.WriteFile(config.DefaultFilename, content, 0o644); err != nil {
+			log.Error("Couldn't create config file", "error", err)
+			return
+		}
+		log.Info("Created kiln.yaml configuration file")
+	} else if settings != nil {
+		log.Warn("A kiln.yaml already exists, add the settings of the template to it", "settings", string(settings))
+	}
+}
+
+// defaultConfigFile is the kiln.yaml written by init, every option commented
+// out with its default value.
+const defaultConfigFile = `# Kiln configuration file
 # Uncomment and edit the options below to set defaults for your project.
 # CLI flags will override these values.
 
@@ -55,11 +85,99 @@ func runInit(cmd *cobra.Command, args []string) {
 # disable-toc: false
 # disable-local-graph: false
 # disable-backlinks: false
+# unlinked-mentions: false
+# cache-dir: ./.kiln-cache  # image variants reused between builds
+# date-source: filesystem   # filesystem, frontmatter or git
+# edit-url: ""              # e.g. https://github.com/me/notes/edit/main/{path}
+# strip-prefixes: false     # "01 Intro" shows as "Intro" at /intro
+# disable-precompress: false # skip the .br and .gz files of kiln generate
+# port: "8080"
+# host: ""                  # interface kiln serve and kiln dev listen on, e.g. 127.0.0.1
+
+# Graph view settings
+# graph:
+#   size: degree          # degree, pagerank or uniform
+#   tags: true            # show tag nodes
+#   folders: true         # show folder nodes
+#   attachments: false    # show images and files linked from notes
+#   orphans: true         # show pages without links
+#   include-tags: []
+#   exclude-tags: []
+#   include-folders: []
+#   exclude-folders: []
+#   color-by: ""          # folder or tag
+#   groups:
+#     - query: "tag:#project"
+#       color: red
+#   local-depth: 1
+
+# Responsive image settings
+# images:
+#   breakpoints: [1200, 800, 400]
+#   formats: [avif, webp]  # generated besides the original format
+#   quality:
+#     jpeg: 80
+#     webp: 80
+#     avif: 80
+#   max-width: 0           # widest variant, 0 for no limit
+#   strip-metadata: false  # remove EXIF and text metadata from copied images
+#   placeholder: ""        # "lqip" or "blurhash" to show a preview while images load
+
+# Open Graph card settings
+# og:
+#   style: default         # default, minimal, split or cover
+#   logo: ""               # vault path of the logo, defaults to favicon.png or favicon.ico
+#   hide-tags: false
+#   hide-date: false
+
+# Structured data and metadata
+# seo:
+#   publisher:
+#     type: organization   # organization or person
+#     name: ""             # defaults to the site name
+#     url: ""
+#     image: ""            # logo of an organization, photo of a person
+#   author: ""             # author id of notes without an "author" property
+#   authors:
+#     jane:
+#       name: Jane Doe
+#       url: https://janedoe.com
+#       same-as: [https://github.com/janedoe]
+#   faq-callout: faq       # callout type read as questions and answers
+
+# Multilingual sites
+# i18n:
+#   languages: [en, it]    # top-level folders named after a language hold its pages
+#   labels:                # UI labels by language, see kiln i18n extract
+#     it:
+#       back-to-top: Su
+
+# Custom themes, fonts and CSS variables, selected with theme and font
+# appearance:
+#   themes:                # also read from _themes/<name>.yaml in the vault
+#     brand:
+#       extends: default   # built-in theme providing the missing colours
+#       light:
+#         accent: "#0055aa"  # quote hex colours
+#       dark:
+#         accent: "#7fb4ff"
+#   fonts:
+#     brand:
+#       family: Brand Sans
+#       files:             # vault paths
+#         - path: fonts/BrandSans-Regular.woff2
+#         - path: fonts/BrandSans-Bold.woff2
+#           weight: 700
+#       ttf: fonts/BrandSans-Regular.ttf    # used by the Open Graph cards
+#       ttf-bold: fonts/BrandSans-Bold.ttf  # titles of the Open Graph cards
+#   css-vars:
+#     light:
+#       --sidebar-width: 300px
+
+# Children listed on folder pages, below the folder note
+# folders:
+#   style: list            # list, cards or none
+#   sort: name             # name, created, modified or order
+#   reverse: false         # e.g. newest first with sort: created
+#   excerpts: false        # description or first paragraph of every page
 `
-		if err := os.WriteFile(config.DefaultFilename, []byte(content), 0o644); err != nil {
-			log.Error("Couldn't create config file", "error", err)
-			return
-		}
-		log.Info("Created kiln.yaml configuration file")
-	}
-}
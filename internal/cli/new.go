@@ -0,0 +1,50 @@
+// Cobra new command that creates a note from the templates of the vault. @feature:cli
+package cli
+
+import (
+	"os"
+	"time"
+
+	"github.com/otaleghani/kiln/internal/builder"
+	"github.com/spf13/cobra"
+)
+
+// cmdNew creates a note in the vault, pre-filled from the Obsidian templates folder.
+// In custom mode, its frontmatter also holds the fields of its collection.
+var cmdNew = &cobra.Command{
+	Use:   "new <path>",
+	Short: "Creates a note from a template",
+	Args:  cobra.ExactArgs(1),
+	Run:   runNew,
+}
+
+func init() {
+	// Register flags for the new command.
+	cmdNew.Flags().
+		StringVarP(&inputDir, FlagInputDir, FlagInputDirShort, DefaultInputDir, "Name of the input directory (defaults to ./vault)")
+	cmdNew.Flags().
+		StringVarP(&mode, FlagMode, FlagModeShort, DefaultMode, "The mode of the vault. In 'custom' mode, the note gets the fields of its collection (defaults to 'default')")
+	cmdNew.Flags().
+		StringVar(&templateName, FlagTemplate, DefaultTemplate, "Template to start from. Defaults to the one named after the folder of the note, or 'Default'")
+	cmdNew.Flags().
+		StringVarP(&logger, FlagLog, FlagLogShort, DefaultLog, "Logging level. Choose between 'debug' or 'info'. Defaults to 'info'.")
+}
+
+// runNew creates the note at the path given relative to the vault.
+func runNew(cmd *cobra.Command, args []string) {
+	cfg := loadConfig(cmd)
+	applyStringFlag(cmd, FlagInputDir, &inputDir, cfg, DefaultInputDir)
+	applyStringFlag(cmd, FlagMode, &mode, cfg, DefaultMode)
+	applyStringFlag(cmd, FlagLog, &logger, cfg, DefaultLog)
+
+	builder.InputDir = inputDir
+	builder.Mode = mode
+
+	log := getLogger()
+	path, err := builder.NewNote(args[0], templateName, time.Now(), log)
+	if err != nil {
+		log.Error("Couldn't create the note", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Created note", "path", path)
+}
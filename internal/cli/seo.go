@@ -0,0 +1,49 @@
+// Cobra seo command that reports empty and duplicate titles and missing descriptions. @feature:cli
+package cli
+
+import (
+	"os"
+
+	"github.com/otaleghani/kiln/internal/builder"
+	"github.com/otaleghani/kiln/internal/linter"
+	"github.com/otaleghani/kiln/internal/obsidian"
+	"github.com/spf13/cobra"
+)
+
+// cmdSEO represents the SEO report command.
+// It lists the notes that are missing a description, or whose title is empty or shared.
+var cmdSEO = &cobra.Command{
+	Use:   "seo",
+	Short: "Reports empty or duplicate titles and missing descriptions",
+	Run:   runSEO,
+}
+
+func init() {
+	// Register flags for the seo command.
+	// Allows running the report on a custom vault location.
+	cmdSEO.Flags().
+		StringVarP(&inputDir, FlagInputDir, FlagInputDirShort, DefaultInputDir, "Name of the input directory (defaults to ./vault)")
+	cmdSEO.Flags().
+		StringVarP(&logger, FlagLog, FlagLogShort, DefaultLog, "Logging level. Choose between 'debug' or 'info'. Defaults to 'info'.")
+}
+
+// runSEO scans the vault and reports the notes with SEO issues.
+func runSEO(cmd *cobra.Command, args []string) {
+	cfg := loadConfig(cmd)
+	applyStringFlag(cmd, FlagInputDir, &inputDir, cfg, DefaultInputDir)
+	applyStringFlag(cmd, FlagLog, &logger, cfg, DefaultLog)
+
+	builder.InputDir = inputDir
+
+	log := getLogger()
+
+	obs := obsidian.New(
+		obsidian.WithInputDir(builder.InputDir),
+		obsidian.WithLogger(log),
+	)
+	if err := obs.Scan(); err != nil {
+		log.Error("Error scanning vault", "error", err)
+		os.Exit(1)
+	}
+	linter.SEO(obs.Vault.Files, log)
+}
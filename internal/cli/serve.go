@@ -1,4 +1,4 @@
-// Cobra serve command that starts a local development server. @feature:cli
+// Cobra serve command that previews the generated site as the CDN serves it. @feature:cli
 package cli
 
 import (
@@ -11,8 +11,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// cmdServe represents the command to start a local development server.
-// It allows users to preview their generated static site before deploying it.
+// cmdServe represents the command to start a local server.
+// It allows users to preview their generated static site before deploying it,
+// with the compression, caching headers, redirects and headers of the CDN.
 var cmdServe = &cobra.Command{
 	Use:   "serve",
 	Short: "Serves the generated site locally",
@@ -22,10 +23,14 @@ var cmdServe = &cobra.Command{
 // port stores the port number specified by the user (default: 8080).
 var port string
 
+// host stores the interface to listen on (default: every interface).
+var host string
+
 func init() {
 	// Register flags for the serve command.
 	// Users can customize the listening port and the directory being served.
 	cmdServe.Flags().StringVarP(&port, FlagPort, FlagPortShort, DefaultPort, "Port to serve on")
+	cmdServe.Flags().StringVar(&host, FlagHost, DefaultHost, "Interface to listen on, e.g. 127.0.0.1 (defaults to every interface)")
 	cmdServe.Flags().
 		StringVarP(&outputDir, FlagOutputDir, FlagOutputDirShort, DefaultOutputDir, "Name of the output directory to serve(defaults to ./public)")
 	cmdServe.Flags().
@@ -36,6 +41,7 @@ func init() {
 func runServe(cmd *cobra.Command, args []string) {
 	cfg := loadConfig(cmd)
 	applyStringFlag(cmd, FlagPort, &port, cfg, DefaultPort)
+	applyStringFlag(cmd, FlagHost, &host, cfg, DefaultHost)
 	applyStringFlag(cmd, FlagOutputDir, &outputDir, cfg, DefaultOutputDir)
 	applyStringFlag(cmd, FlagLog, &logger, cfg, DefaultLog)
 
@@ -47,5 +53,14 @@ func runServe(cmd *cobra.Command, args []string) {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	server.Serve(ctx, port, builder.OutputDir, localBaseURL, log)
+	server.Serve(ctx, server.Options{
+		Host:      host,
+		Port:      port,
+		OutputDir: builder.OutputDir,
+		BaseURL:   localBaseURL,
+		Compress:  true,
+		Cache:     true,
+		Rules:     true,
+		AccessLog: true,
+	}, log)
 }
@@ -7,6 +7,13 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/otaleghani/kiln/internal/appearance"
+	"github.com/otaleghani/kiln/internal/graph"
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/imgopt"
+	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/listing"
+	"github.com/otaleghani/kiln/internal/ogimage"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,22 +21,38 @@ import (
 const DefaultFilename = "kiln.yaml"
 
 type Config struct {
-	Theme             string `yaml:"theme"`
-	Font              string `yaml:"font"`
-	URL               string `yaml:"url"`
-	Name              string `yaml:"name"`
-	Input             string `yaml:"input"`
-	Output            string `yaml:"output"`
-	Mode              string `yaml:"mode"`
-	Layout            string `yaml:"layout"`
-	FlatURLs          bool   `yaml:"flat-urls"`
-	DisableTOC        bool   `yaml:"disable-toc"`
-	DisableLocalGraph bool   `yaml:"disable-local-graph"`
-	DisableBacklinks  bool   `yaml:"disable-backlinks"`
-	Port              string `yaml:"port"`
-	Log               string `yaml:"log"`
-	Lang              string `yaml:"lang"`
-	AccentColor       string `yaml:"accent-color"`
+	Theme              string `yaml:"theme"`
+	Font               string `yaml:"font"`
+	URL                string `yaml:"url"`
+	Name               string `yaml:"name"`
+	Input              string `yaml:"input"`
+	Output             string `yaml:"output"`
+	Mode               string `yaml:"mode"`
+	Layout             string `yaml:"layout"`
+	FlatURLs           bool   `yaml:"flat-urls"`
+	DisableTOC         bool   `yaml:"disable-toc"`
+	DisableLocalGraph  bool   `yaml:"disable-local-graph"`
+	DisableBacklinks   bool   `yaml:"disable-backlinks"`
+	UnlinkedMentions   bool   `yaml:"unlinked-mentions"`
+	Port               string `yaml:"port"`
+	Host               string `yaml:"host"`
+	Log                string `yaml:"log"`
+	Lang               string `yaml:"lang"`
+	AccentColor        string `yaml:"accent-color"`
+	CacheDir           string `yaml:"cache-dir"`
+	DateSource         string `yaml:"date-source"`
+	EditURL            string `yaml:"edit-url"`
+	StripPrefixes      bool   `yaml:"strip-prefixes"`
+	DisablePrecompress bool   `yaml:"disable-precompress"`
+
+	Graph  graph.Options   `yaml:"graph"`  // Global and local graph settings
+	Images imgopt.Options  `yaml:"images"` // Responsive image variants settings
+	OG     ogimage.Options `yaml:"og"`     // Open Graph card settings
+	SEO    jsonld.Options  `yaml:"seo"`    // Publisher, authors and FAQ callout
+	I18n   i18n.Options    `yaml:"i18n"`   // Languages of a multilingual site
+
+	Appearance appearance.Options `yaml:"appearance"` // User-defined themes, fonts and CSS variables
+	Folders    listing.Options    `yaml:"folders"`    // Listing of the children of folder pages
 }
 
 // Load reads a kiln.yaml file from the given path.
@@ -93,12 +116,20 @@ func (c *Config) ValueOr(field, fallback string) string {
 		val = c.Layout
 	case "port":
 		val = c.Port
+	case "host":
+		val = c.Host
 	case "log":
 		val = c.Log
 	case "lang":
 		val = c.Lang
 	case "accent-color":
 		val = c.AccentColor
+	case "cache-dir":
+		val = c.CacheDir
+	case "date-source":
+		val = c.DateSource
+	case "edit-url":
+		val = c.EditURL
 	}
 	if val != "" {
 		return val
@@ -117,6 +148,12 @@ func (c *Config) BoolOr(field string, fallback bool) bool {
 		return c.DisableLocalGraph
 	case "disable-backlinks":
 		return c.DisableBacklinks
+	case "unlinked-mentions":
+		return c.UnlinkedMentions
+	case "strip-prefixes":
+		return c.StripPrefixes
+	case "disable-precompress":
+		return c.DisablePrecompress
 	}
 	return fallback
 }
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/otaleghani/kiln/internal/listing"
 )
 
 func TestLoad_ValidFile(t *testing.T) {
@@ -22,8 +24,13 @@ flat-urls: true
 disable-toc: true
 disable-local-graph: true
 port: "3000"
+host: 0.0.0.0
+disable-precompress: true
 log: debug
 accent-color: blue
+cache-dir: ./.cache/kiln
+date-source: git
+edit-url: https://github.com/me/notes/edit/main/{path}
 `
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		t.Fatalf("write kiln.yaml: %v", err)
@@ -73,12 +80,27 @@ accent-color: blue
 	if cfg.Port != "3000" {
 		t.Errorf("Port = %q, want %q", cfg.Port, "3000")
 	}
+	if !cfg.BoolOr("disable-precompress", false) {
+		t.Error("BoolOr(disable-precompress) = false, want true")
+	}
+	if got := cfg.ValueOr("host", ""); got != "0.0.0.0" {
+		t.Errorf("ValueOr(host) = %q, want %q", got, "0.0.0.0")
+	}
 	if cfg.Log != "debug" {
 		t.Errorf("Log = %q, want %q", cfg.Log, "debug")
 	}
 	if cfg.AccentColor != "blue" {
 		t.Errorf("AccentColor = %q, want %q", cfg.AccentColor, "blue")
 	}
+	if got := cfg.ValueOr("cache-dir", "./.kiln-cache"); got != "./.cache/kiln" {
+		t.Errorf("ValueOr(cache-dir) = %q, want %q", got, "./.cache/kiln")
+	}
+	if got := cfg.ValueOr("date-source", "filesystem"); got != "git" {
+		t.Errorf("ValueOr(date-source) = %q, want %q", got, "git")
+	}
+	if got := cfg.ValueOr("edit-url", ""); got != "https://github.com/me/notes/edit/main/{path}" {
+		t.Errorf("ValueOr(edit-url) = %q", got)
+	}
 }
 
 func TestLoad_PartialFile(t *testing.T) {
@@ -253,3 +275,253 @@ func TestValueOr_OnlyOverridesEmpty(t *testing.T) {
 		t.Errorf("ValueOr(accent-color) = %q, want empty", got)
 	}
 }
+
+func TestLoad_GraphSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `graph:
+  size: pagerank
+  tags: false
+  orphans: false
+  exclude-folders: [Archive]
+  color-by: folder
+  groups:
+    - query: "tag:#project"
+      color: red
+  local-depth: 2
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	g := cfg.Graph
+	if g.Size != "pagerank" || g.ShowTags() || g.ShowOrphans() || !g.ShowFolders() {
+		t.Errorf("unexpected graph options: %+v", g)
+	}
+	if len(g.ExcludeFolders) != 1 || g.ExcludeFolders[0] != "Archive" {
+		t.Errorf("ExcludeFolders = %v", g.ExcludeFolders)
+	}
+	if len(g.Groups) != 1 || g.Groups[0].Color != "red" {
+		t.Errorf("Groups = %+v", g.Groups)
+	}
+	if g.Depth() != 2 {
+		t.Errorf("Depth = %d, want 2", g.Depth())
+	}
+}
+
+func TestLoad_OGSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `og:
+  style: split
+  logo: assets/logo.png
+  hide-tags: true
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	og := cfg.OG
+	if og.StyleOrDefault() != "split" {
+		t.Errorf("Style = %q, want split", og.Style)
+	}
+	if og.Logo != "assets/logo.png" {
+		t.Errorf("Logo = %q, want assets/logo.png", og.Logo)
+	}
+	if !og.HideTags || og.HideDate {
+		t.Errorf("HideTags = %v, HideDate = %v", og.HideTags, og.HideDate)
+	}
+}
+
+func TestLoad_SEOSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `seo:
+  publisher:
+    type: person
+    name: Jane Doe
+  author: jane
+  authors:
+    jane:
+      name: Jane Doe
+      same-as: [https://github.com/janedoe]
+  faq-callout: question
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	seo := cfg.SEO
+	if seo.Publisher.Type != "person" || seo.Publisher.Name != "Jane Doe" {
+		t.Errorf("Publisher = %+v", seo.Publisher)
+	}
+	if authors := seo.ResolveAuthors(nil); len(authors) != 1 || len(authors[0].SameAs) != 1 {
+		t.Errorf("default author = %+v", authors)
+	}
+	if seo.FAQCalloutOrDefault() != "question" {
+		t.Errorf("FAQCallout = %q, want question", seo.FAQCallout)
+	}
+}
+
+func TestLoad_I18nSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `lang: en
+i18n:
+  languages: [en, it, pt-BR]
+  labels:
+    it:
+      back-to-top: Su
+      words:
+        one: parola
+        other: parole
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	langs := cfg.I18n.Languages
+	if len(langs) != 3 || langs[0] != "en" || langs[1] != "it" || langs[2] != "pt-BR" {
+		t.Errorf("Languages = %v, want [en it pt-BR]", langs)
+	}
+	it := cfg.I18n.Labels["it"]
+	if it["back-to-top"] != "Su" {
+		t.Errorf("back-to-top = %v, want Su", it["back-to-top"])
+	}
+	if words, ok := it["words"].(map[string]any); !ok || words["one"] != "parola" {
+		t.Errorf("words = %v, want the plural forms", it["words"])
+	}
+}
+
+func TestLoad_AppearanceSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `theme: brand
+font: brand
+appearance:
+  themes:
+    brand:
+      extends: nord
+      light:
+        accent: "#0055aa"
+      dark:
+        accent: "#88c0d0"
+  fonts:
+    brand:
+      family: Brand Sans
+      fallback: serif
+      files:
+        - path: fonts/Brand-Regular.woff2
+        - path: fonts/Brand-Bold.woff2
+          weight: 700
+      ttf: fonts/Brand-Regular.ttf
+  css-vars:
+    light:
+      --sidebar-width: 320px
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	brand := cfg.Appearance.Themes["brand"]
+	if brand.Extends != "nord" || brand.Light["accent"] != "#0055aa" || brand.Dark["accent"] != "#88c0d0" {
+		t.Errorf("theme = %+v", brand)
+	}
+	font := cfg.Appearance.Fonts["brand"]
+	if font.Family != "Brand Sans" || font.FallbackOrDefault() != "serif" || font.TTF != "fonts/Brand-Regular.ttf" {
+		t.Errorf("font = %+v", font)
+	}
+	if len(font.Files) != 2 || font.Files[0].WeightOrDefault() != 400 || font.Files[1].Weight != 700 {
+		t.Errorf("font files = %+v", font.Files)
+	}
+	if got := cfg.Appearance.CSSVars.Light["--sidebar-width"]; got != "320px" {
+		t.Errorf("--sidebar-width = %q, want 320px", got)
+	}
+}
+
+func TestLoad_FoldersSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `folders:
+  style: cards
+  sort: created
+  reverse: true
+  excerpts: true
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := listing.Options{Style: "cards", Sort: "created", Reverse: true, Excerpts: true}
+	if cfg.Folders != want {
+		t.Errorf("Folders = %+v, want %+v", cfg.Folders, want)
+	}
+}
+
+func TestLoad_ImagesSection(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kiln.yaml")
+	content := `images:
+  breakpoints: [1600, 800]
+  formats: [webp]
+  quality:
+    jpeg: 90
+  max-width: 1200
+  strip-metadata: true
+  placeholder: blurhash
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write kiln.yaml: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	img := cfg.Images
+	if widths := img.Widths(); len(widths) != 2 || widths[0] != 1200 || widths[1] != 800 {
+		t.Errorf("Widths = %v, want [1200 800]", widths)
+	}
+	if img.Enabled("avif") || !img.Enabled("webp") {
+		t.Errorf("Formats = %v", img.Formats)
+	}
+	if img.QualityFor("jpeg") != 90 || img.QualityFor("webp") != 80 {
+		t.Errorf("Quality = %+v", img.Quality)
+	}
+	if !img.StripMetadata {
+		t.Error("StripMetadata = false, want true")
+	}
+	if img.Placeholder != "blurhash" {
+		t.Errorf("Placeholder = %q, want blurhash", img.Placeholder)
+	}
+}
@@ -0,0 +1,433 @@
+// @feature:graph Filtering, metrics and local views of the vault graph.
+package graph
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// Node sizing strategies
+const (
+	SizeDegree   = "degree"
+	SizePageRank = "pagerank"
+	SizeUniform  = "uniform"
+)
+
+// Options configures the global graph. It maps to the "graph" section of kiln.yaml.
+type Options struct {
+	Size           string   `yaml:"size"`            // Node sizing: "degree" (default), "pagerank" or "uniform"
+	Tags           *bool    `yaml:"tags"`            // Show tag nodes (default true)
+	Folders        *bool    `yaml:"folders"`         // Show folder nodes (default true)
+	Attachments    bool     `yaml:"attachments"`     // Show attachments (images, pdfs...) linked from notes
+	Orphans        *bool    `yaml:"orphans"`         // Show pages without links to other pages (default true)
+	IncludeTags    []string `yaml:"include-tags"`    // If set, only pages with one of these tags are shown
+	ExcludeTags    []string `yaml:"exclude-tags"`    // Pages with one of these tags are hidden
+	IncludeFolders []string `yaml:"include-folders"` // If set, only pages inside these folders are shown
+	ExcludeFolders []string `yaml:"exclude-folders"` // Pages inside these folders are hidden
+	ColorBy        string   `yaml:"color-by"`        // Automatic color groups: "folder", "tag" or empty
+	Groups         []Group  `yaml:"groups"`          // Color groups, the first matching group wins
+	LocalDepth     int      `yaml:"local-depth"`     // Depth of the per-page local graph (default 1)
+}
+
+// Group assigns a color to every node matching the query.
+//
+// Queries follow Obsidian's search syntax: "tag:#name", "path:folder",
+// "file:name" or plain text matched against the node label.
+type Group struct {
+	Query string `yaml:"query"`
+	Color string `yaml:"color"` // Palette name (red, orange...) or hex color
+}
+
+// palette is used to assign automatic colors with ColorBy.
+var palette = []string{"red", "orange", "yellow", "green", "cyan", "blue", "purple"}
+
+// ShowTags reports whether tag nodes are part of the graph.
+func (o Options) ShowTags() bool { return o.Tags == nil || *o.Tags }
+
+// ShowFolders reports whether folder nodes are part of the graph.
+func (o Options) ShowFolders() bool { return o.Folders == nil || *o.Folders }
+
+// ShowOrphans reports whether orphan pages are part of the global graph.
+func (o Options) ShowOrphans() bool { return o.Orphans == nil || *o.Orphans }
+
+// Depth returns the local graph depth, defaulting to 1.
+func (o Options) Depth() int {
+	if o.LocalDepth < 1 {
+		return 1
+	}
+	return o.LocalDepth
+}
+
+// Graph is the serialized form of graph.json.
+type Graph struct {
+	Nodes      []obsidian.GraphNode `json:"nodes"`
+	Links      []obsidian.GraphLink `json:"links"`
+	Components int                  `json:"components"` // Number of connected components
+	Orphans    int                  `json:"orphans"`    // Number of orphan pages
+}
+
+// newGraph returns an empty graph that serializes to empty arrays rather than null.
+func newGraph() *Graph {
+	return &Graph{Nodes: []obsidian.GraphNode{}, Links: []obsidian.GraphLink{}}
+}
+
+// IsPage reports whether the node type is a rendered page.
+func IsPage(nodeType string) bool {
+	return nodeType == ".md" || nodeType == ".canvas" || nodeType == ".base"
+}
+
+// Build filters the nodes and links according to the options and computes
+// node sizes, connected components, orphans and color groups.
+//
+// Orphans are kept in the returned graph (flagged with Orphan), so that local
+// graphs can still be generated for them. Use Global for the global graph.
+func Build(nodes []obsidian.GraphNode, links []obsidian.GraphLink, opts Options) *Graph {
+	g := newGraph()
+
+	seen := make(map[string]struct{}, len(nodes))
+	visited := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		if _, dup := visited[n.ID]; dup {
+			continue
+		}
+		visited[n.ID] = struct{}{}
+		if !opts.keep(n) {
+			continue
+		}
+		seen[n.ID] = struct{}{}
+		g.Nodes = append(g.Nodes, n)
+	}
+
+	type edge struct{ s, t string }
+	seenLinks := make(map[edge]struct{}, len(links))
+	for _, l := range links {
+		if l.Source == l.Target {
+			continue
+		}
+		if _, ok := seen[l.Source]; !ok {
+			continue
+		}
+		if _, ok := seen[l.Target]; !ok {
+			continue
+		}
+		e := edge{l.Source, l.Target}
+		if _, dup := seenLinks[e]; dup {
+			continue
+		}
+		seenLinks[e] = struct{}{}
+		g.Links = append(g.Links, l)
+	}
+
+	g.computeMetrics(opts.Size)
+	g.assignColors(opts)
+	return g
+}
+
+// Global returns the graph to publish as graph.json, dropping orphans if requested.
+func (g *Graph) Global(opts Options) *Graph {
+	if opts.ShowOrphans() {
+		return g
+	}
+	out := newGraph()
+	out.Components, out.Orphans = g.Components, g.Orphans
+	kept := make(map[string]struct{}, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.Orphan {
+			continue
+		}
+		kept[n.ID] = struct{}{}
+		out.Nodes = append(out.Nodes, n)
+	}
+	for _, l := range g.Links {
+		_, s := kept[l.Source]
+		_, t := kept[l.Target]
+		if s && t {
+			out.Links = append(out.Links, l)
+		}
+	}
+	return out
+}
+
+// Local returns the neighbourhood of the given node up to depth hops,
+// following links in both directions. Returns nil if the node is not in the graph.
+// Use Neighbourhoods to compute the local graphs of many nodes.
+func (g *Graph) Local(id string, depth int) *Graph {
+	return g.Neighbourhoods().Local(id, depth)
+}
+
+// Neighbourhoods computes the local graphs of the nodes of a graph, sharing
+// one index of its nodes and links between them.
+type Neighbourhoods struct {
+	g        *Graph
+	index    map[string]int      // Position of every node in Nodes
+	adj      map[string][]string // Undirected neighbours of every node
+	outLinks map[string][]int    // Position in Links of the links leaving every node
+}
+
+// Neighbourhoods indexes the graph to compute local graphs.
+func (g *Graph) Neighbourhoods() *Neighbourhoods {
+	outLinks := make(map[string][]int, len(g.Nodes))
+	for i, l := range g.Links {
+		outLinks[l.Source] = append(outLinks[l.Source], i)
+	}
+	return &Neighbourhoods{g: g, index: g.index(), adj: g.adjacency(), outLinks: outLinks}
+}
+
+// Local returns the neighbourhood of the given node up to depth hops,
+// following links in both directions, with the nodes and links in the order
+// of the graph. Returns nil if the node is not in the graph.
+func (nb *Neighbourhoods) Local(id string, depth int) *Graph {
+	if _, ok := nb.index[id]; !ok {
+		return nil
+	}
+
+	dist := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if dist[cur] == depth {
+			continue
+		}
+		for _, next := range nb.adj[cur] {
+			if _, ok := dist[next]; ok {
+				continue
+			}
+			dist[next] = dist[cur] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	nodes := make([]int, 0, len(dist))
+	links := []int{}
+	for n := range dist {
+		nodes = append(nodes, nb.index[n])
+		for _, i := range nb.outLinks[n] {
+			if _, ok := dist[nb.g.Links[i].Target]; ok {
+				links = append(links, i)
+			}
+		}
+	}
+	sort.Ints(nodes)
+	sort.Ints(links)
+
+	out := newGraph()
+	for _, i := range nodes {
+		out.Nodes = append(out.Nodes, nb.g.Nodes[i])
+	}
+	for _, i := range links {
+		out.Links = append(out.Links, nb.g.Links[i])
+	}
+	out.Components = 1
+	return out
+}
+
+// keep applies the type, tag and folder filters to a node.
+func (o Options) keep(n obsidian.GraphNode) bool {
+	switch {
+	case n.Type == "tag":
+		if !o.ShowTags() {
+			return false
+		}
+		tag := normalizeTag(n.Label)
+		if len(o.IncludeTags) > 0 && !containsTag(o.IncludeTags, tag) {
+			return false
+		}
+		return !containsTag(o.ExcludeTags, tag)
+	case n.Type == "folder":
+		if !o.ShowFolders() {
+			return false
+		}
+		return o.keepPath(n.RelPath, true)
+	case IsPage(n.Type):
+		if !o.keepPath(n.RelPath, false) {
+			return false
+		}
+		if len(o.IncludeTags) > 0 && !hasAnyTag(n.Tags, o.IncludeTags) {
+			return false
+		}
+		return !hasAnyTag(n.Tags, o.ExcludeTags)
+	default:
+		return o.Attachments && o.keepPath(n.RelPath, false)
+	}
+}
+
+// keepPath applies the folder filters to a vault relative path.
+// Folders are kept if they contain, or are contained by, an included folder.
+func (o Options) keepPath(relPath string, isFolder bool) bool {
+	relPath = path.Clean(strings.ReplaceAll(relPath, "\\", "/"))
+	for _, ex := range o.ExcludeFolders {
+		if cleanFolder(ex) != "." && inFolder(relPath, ex) {
+			return false
+		}
+	}
+	if len(o.IncludeFolders) == 0 {
+		return true
+	}
+	for _, in := range o.IncludeFolders {
+		if inFolder(relPath, in) {
+			return true
+		}
+		if isFolder && inFolder(cleanFolder(in), relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether p is the folder itself or lies inside it.
+func inFolder(p, folder string) bool {
+	folder = cleanFolder(folder)
+	if folder == "" || folder == "." {
+		return true
+	}
+	return p == folder || strings.HasPrefix(p, folder+"/")
+}
+
+func cleanFolder(f string) string {
+	return strings.Trim(path.Clean(strings.ReplaceAll(f, "\\", "/")), "/")
+}
+
+func normalizeTag(t string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), "#"))
+}
+
+func containsTag(list []string, tag string) bool {
+	return slices.ContainsFunc(list, func(t string) bool { return normalizeTag(t) == tag })
+}
+
+func hasAnyTag(tags, list []string) bool {
+	for _, t := range tags {
+		if containsTag(list, normalizeTag(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignColors applies the color groups, falling back to automatic colors.
+func (g *Graph) assignColors(opts Options) {
+	keys := make([]string, len(g.Nodes))
+	seen := map[string]struct{}{}
+	for i, n := range g.Nodes {
+		if c := matchGroup(n, opts.Groups); c != "" {
+			g.Nodes[i].Color = c
+			continue
+		}
+		keys[i] = autoKey(n, opts.ColorBy)
+		if keys[i] != "" {
+			seen[keys[i]] = struct{}{}
+		}
+	}
+
+	// Stable palette assignment, independent of the node order
+	sorted := make([]string, 0, len(seen))
+	for k := range seen {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	colors := make(map[string]string, len(sorted))
+	for i, k := range sorted {
+		colors[k] = palette[i%len(palette)]
+	}
+	for i, k := range keys {
+		if k != "" {
+			g.Nodes[i].Color = colors[k]
+		}
+	}
+}
+
+// autoKey returns the value used to group a node when coloring by folder or tag.
+func autoKey(n obsidian.GraphNode, colorBy string) string {
+	switch colorBy {
+	case "folder":
+		if n.Type != "tag" {
+			return topFolder(n.RelPath, n.Type == "folder")
+		}
+	case "tag":
+		if n.Type == "tag" {
+			return normalizeTag(n.Label)
+		}
+		if len(n.Tags) > 0 {
+			tags := make([]string, len(n.Tags))
+			for j, t := range n.Tags {
+				tags[j] = normalizeTag(t)
+			}
+			sort.Strings(tags)
+			return tags[0]
+		}
+	}
+	return ""
+}
+
+// topFolder returns the first path segment of the node's folder.
+func topFolder(relPath string, isFolder bool) string {
+	relPath = strings.ReplaceAll(relPath, "\\", "/")
+	if !isFolder {
+		relPath = path.Dir(relPath)
+	}
+	if relPath == "." || relPath == "" {
+		return ""
+	}
+	return strings.SplitN(relPath, "/", 2)[0]
+}
+
+// matchGroup returns the color of the first group whose query matches the node.
+func matchGroup(n obsidian.GraphNode, groups []Group) string {
+	for _, g := range groups {
+		if g.Color != "" && Match(n, g.Query) {
+			return g.Color
+		}
+	}
+	return ""
+}
+
+// Match reports whether a node matches a group query.
+func Match(n obsidian.GraphNode, query string) bool {
+	q := strings.TrimSpace(query)
+	lower := strings.ToLower(q)
+	switch {
+	case q == "":
+		return false
+	case strings.HasPrefix(lower, "tag:"):
+		tag := normalizeTag(q[len("tag:"):])
+		if n.Type == "tag" {
+			return normalizeTag(n.Label) == tag
+		}
+		for _, t := range n.Tags {
+			if t := normalizeTag(t); t == tag || strings.HasPrefix(t, tag+"/") {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(lower, "path:"):
+		return n.Type != "tag" && inFolder(path.Clean(strings.ReplaceAll(n.RelPath, "\\", "/")), q[len("path:"):])
+	case strings.HasPrefix(lower, "file:"):
+		return strings.Contains(strings.ToLower(n.Label), strings.TrimSpace(lower[len("file:"):]))
+	default:
+		return strings.Contains(strings.ToLower(n.Label), lower)
+	}
+}
+
+// index maps node ids to their position in Nodes.
+func (g *Graph) index() map[string]int {
+	idx := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		idx[n.ID] = i
+	}
+	return idx
+}
+
+// adjacency returns the undirected neighbour list of every node.
+func (g *Graph) adjacency() map[string][]string {
+	adj := make(map[string][]string, len(g.Nodes))
+	for _, l := range g.Links {
+		adj[l.Source] = append(adj[l.Source], l.Target)
+		adj[l.Target] = append(adj[l.Target], l.Source)
+	}
+	return adj
+}
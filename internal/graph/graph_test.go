@@ -0,0 +1,229 @@
+// @feature:graph Tests for graph filtering, metrics and local views.
+package graph
+
+import (
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+// fixture:
+//
+//	a -> b -> c, a -> c (pages in notes/), d orphan page in archive/,
+//	folder "notes" linked to a, b, c; tag #go linked to b; image linked from a.
+func fixture() ([]obsidian.GraphNode, []obsidian.GraphLink) {
+	nodes := []obsidian.GraphNode{
+		{ID: "/notes/a", Label: "a", Type: ".md", RelPath: "notes/a.md", Tags: []string{"#draft"}},
+		{ID: "/notes/b", Label: "b", Type: ".md", RelPath: "notes/b.md", Tags: []string{"go"}},
+		{ID: "/notes/c", Label: "c", Type: ".md", RelPath: "notes/c.md"},
+		{ID: "/archive/d", Label: "d", Type: ".md", RelPath: "archive/d.md"},
+		{ID: "/notes", Label: "notes", Type: "folder", RelPath: "notes"},
+		{ID: "/tags/go", Label: "#go", Type: "tag"},
+		{ID: "/notes/img.png", Label: "img.png", Type: ".png", RelPath: "notes/img.png"},
+		{ID: "/notes/a", Label: "a", Type: ".md", RelPath: "notes/a.md"}, // duplicate
+	}
+	links := []obsidian.GraphLink{
+		{Source: "/notes/a", Target: "/notes/b"},
+		{Source: "/notes/b", Target: "/notes/c"},
+		{Source: "/notes/a", Target: "/notes/c"},
+		{Source: "/notes/a", Target: "/notes/c"}, // duplicate
+		{Source: "/notes/a", Target: "/notes/a"}, // self link
+		{Source: "/notes/a", Target: "/missing"},
+		{Source: "/notes", Target: "/notes/a", Kind: "folder"},
+		{Source: "/notes", Target: "/notes/b", Kind: "folder"},
+		{Source: "/notes", Target: "/notes/c", Kind: "folder"},
+		{Source: "/tags/go", Target: "/notes/b", Kind: "tag"},
+		{Source: "/notes/a", Target: "/notes/img.png", Kind: "attachment"},
+	}
+	return nodes, links
+}
+
+func nodeByID(g *Graph, id string) *obsidian.GraphNode {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func TestBuild_DefaultsAndMetrics(t *testing.T) {
+	nodes, links := fixture()
+	g := Build(nodes, links, Options{})
+
+	// Attachments are hidden by default, duplicates are dropped
+	if len(g.Nodes) != 6 {
+		t.Fatalf("expected 6 nodes, got %d", len(g.Nodes))
+	}
+	if nodeByID(g, "/notes/img.png") != nil {
+		t.Error("attachments should be hidden by default")
+	}
+	// 3 page links + 3 folder links + 1 tag link
+	if len(g.Links) != 7 {
+		t.Errorf("expected 7 links, got %d", len(g.Links))
+	}
+
+	a := nodeByID(g, "/notes/a")
+	if a.Degree != 3 || a.Val != 3 {
+		t.Errorf("a: degree=%d val=%v, want 3", a.Degree, a.Val)
+	}
+
+	d := nodeByID(g, "/archive/d")
+	if !d.Orphan {
+		t.Error("d should be an orphan")
+	}
+	if nodeByID(g, "/notes").Orphan {
+		t.Error("folders are never orphans")
+	}
+	if g.Orphans != 1 {
+		t.Errorf("Orphans = %d, want 1", g.Orphans)
+	}
+
+	if g.Components != 2 {
+		t.Errorf("Components = %d, want 2", g.Components)
+	}
+	if a.Component != 0 || d.Component != 1 {
+		t.Errorf("largest component should be 0: a=%d d=%d", a.Component, d.Component)
+	}
+}
+
+func TestBuild_Filters(t *testing.T) {
+	nodes, links := fixture()
+
+	g := Build(nodes, links, Options{
+		Tags:        boolPtr(false),
+		Folders:     boolPtr(false),
+		Attachments: true,
+	})
+	for _, n := range g.Nodes {
+		if n.Type == "tag" || n.Type == "folder" {
+			t.Errorf("unexpected %s node %s", n.Type, n.ID)
+		}
+	}
+	if nodeByID(g, "/notes/img.png") == nil {
+		t.Error("expected attachment node")
+	}
+
+	g = Build(nodes, links, Options{ExcludeFolders: []string{"archive"}})
+	if nodeByID(g, "/archive/d") != nil {
+		t.Error("archive/d should be excluded")
+	}
+
+	g = Build(nodes, links, Options{IncludeFolders: []string{"archive"}})
+	if nodeByID(g, "/notes/a") != nil || nodeByID(g, "/archive/d") == nil {
+		t.Error("only archive pages should be included")
+	}
+
+	g = Build(nodes, links, Options{ExcludeTags: []string{"draft"}})
+	if nodeByID(g, "/notes/a") != nil {
+		t.Error("pages tagged #draft should be excluded")
+	}
+
+	g = Build(nodes, links, Options{IncludeTags: []string{"#go"}})
+	if nodeByID(g, "/notes/b") == nil || nodeByID(g, "/notes/c") != nil {
+		t.Error("only pages tagged #go should be included")
+	}
+}
+
+func TestGlobal_Orphans(t *testing.T) {
+	nodes, links := fixture()
+	opts := Options{Orphans: boolPtr(false)}
+	g := Build(nodes, links, opts)
+
+	global := g.Global(opts)
+	if nodeByID(global, "/archive/d") != nil {
+		t.Error("orphans should be hidden from the global graph")
+	}
+	if nodeByID(g, "/archive/d") == nil {
+		t.Error("orphans should stay in the full graph for local views")
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	nodes, links := fixture()
+	g := Build(nodes, links, Options{Size: SizePageRank, Tags: boolPtr(false), Folders: boolPtr(false)})
+
+	// c is linked by both a and b and should rank highest
+	c := nodeByID(g, "/notes/c")
+	for _, n := range g.Nodes {
+		if n.Val > c.Val {
+			t.Errorf("%s (%v) ranks higher than c (%v)", n.ID, n.Val, c.Val)
+		}
+	}
+	if c.Val != float64(c.Degree) {
+		t.Errorf("top node should be scaled to the max degree, got %v", c.Val)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	nodes, links := fixture()
+	g := Build(nodes, links, Options{Tags: boolPtr(false), Folders: boolPtr(false)})
+
+	local := g.Local("/notes/b", 1)
+	if len(local.Nodes) != 3 {
+		t.Errorf("expected b, a and c at depth 1, got %d nodes", len(local.Nodes))
+	}
+
+	g = Build(nodes, links, Options{})
+	local = g.Local("/tags/go", 1)
+	if len(local.Nodes) != 2 {
+		t.Errorf("expected tag and b at depth 1, got %d nodes", len(local.Nodes))
+	}
+	local = g.Local("/tags/go", 2)
+	if nodeByID(local, "/notes/a") == nil || nodeByID(local, "/notes") == nil {
+		t.Error("expected second level neighbours at depth 2")
+	}
+
+	if g.Local("/missing", 1) != nil {
+		t.Error("expected nil for unknown node")
+	}
+}
+
+func TestColors(t *testing.T) {
+	nodes, links := fixture()
+	g := Build(nodes, links, Options{
+		ColorBy: "folder",
+		Groups: []Group{
+			{Query: "tag:#go", Color: "#ff0000"},
+			{Query: "file:C", Color: "green"},
+		},
+	})
+
+	if c := nodeByID(g, "/notes/b").Color; c != "#ff0000" {
+		t.Errorf("b color = %q, want group color", c)
+	}
+	if c := nodeByID(g, "/tags/go").Color; c != "#ff0000" {
+		t.Errorf("tag color = %q, want group color", c)
+	}
+	if c := nodeByID(g, "/notes/c").Color; c != "green" {
+		t.Errorf("c color = %q, want green", c)
+	}
+	// Automatic folder colors: archive < notes
+	if c := nodeByID(g, "/archive/d").Color; c != "red" {
+		t.Errorf("d color = %q, want red", c)
+	}
+	if a, f := nodeByID(g, "/notes/a").Color, nodeByID(g, "/notes").Color; a != "orange" || f != "orange" {
+		t.Errorf("notes colors = %q, %q, want orange", a, f)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	n := obsidian.GraphNode{Label: "My Note", Type: ".md", RelPath: "Projects/Sub/My Note.md", Tags: []string{"#area/work"}}
+	cases := map[string]bool{
+		"tag:#area":        true,
+		"tag:area/work":    true,
+		"tag:#work":        false,
+		"path:Projects":    true,
+		"path:Projects/Su": false,
+		"file:note":        true,
+		"my":               true,
+		"":                 false,
+	}
+	for q, want := range cases {
+		if got := Match(n, q); got != want {
+			t.Errorf("Match(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
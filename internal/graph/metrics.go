@@ -0,0 +1,164 @@
+// @feature:graph Degree, PageRank, connected components and orphan detection.
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	damping       = 0.85
+	pageRankIters = 50
+	pageRankEps   = 1e-9
+)
+
+// computeMetrics sets Degree, Component, Orphan and Val on every node.
+func (g *Graph) computeMetrics(size string) {
+	idx := g.index()
+
+	// Degree and orphans. A page is an orphan if it has no links to or from
+	// other pages: folder, tag and attachment links don't count.
+	linked := make([]bool, len(g.Nodes))
+	for _, l := range g.Links {
+		s, t := idx[l.Source], idx[l.Target]
+		g.Nodes[s].Degree++
+		g.Nodes[t].Degree++
+		if l.Kind == "" {
+			linked[s], linked[t] = true, true
+		}
+	}
+	g.Orphans = 0
+	for i := range g.Nodes {
+		g.Nodes[i].Orphan = IsPage(g.Nodes[i].Type) && !linked[i]
+		if g.Nodes[i].Orphan {
+			g.Orphans++
+		}
+	}
+
+	g.Components = g.computeComponents(idx)
+
+	switch size {
+	case SizeUniform:
+		for i := range g.Nodes {
+			g.Nodes[i].Val = 1
+		}
+	case SizePageRank:
+		ranks := g.pageRank(idx)
+		// Scale so that the biggest node matches the biggest degree,
+		// keeping sizes comparable with the degree strategy.
+		maxRank, maxDegree := 0.0, 1
+		for i, r := range ranks {
+			maxRank = math.Max(maxRank, r)
+			maxDegree = max(maxDegree, g.Nodes[i].Degree)
+		}
+		for i, r := range ranks {
+			val := 0.0
+			if maxRank > 0 {
+				val = r / maxRank * float64(maxDegree)
+			}
+			g.Nodes[i].Val = math.Round(val*100) / 100
+		}
+	default:
+		for i := range g.Nodes {
+			g.Nodes[i].Val = float64(g.Nodes[i].Degree)
+		}
+	}
+}
+
+// computeComponents labels the undirected connected components, numbering
+// them by decreasing size (0 is the largest), and returns their count.
+func (g *Graph) computeComponents(idx map[string]int) int {
+	parent := make([]int, len(g.Nodes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, l := range g.Links {
+		a, b := find(idx[l.Source]), find(idx[l.Target])
+		if a != b {
+			parent[a] = b
+		}
+	}
+
+	sizes := map[int]int{}
+	first := map[int]int{} // Lowest node index of each root, for a stable order
+	for i := range g.Nodes {
+		r := find(i)
+		if _, ok := sizes[r]; !ok {
+			first[r] = i
+		}
+		sizes[r]++
+	}
+	roots := make([]int, 0, len(sizes))
+	for r := range sizes {
+		roots = append(roots, r)
+	}
+	sort.Slice(roots, func(a, b int) bool {
+		if sizes[roots[a]] != sizes[roots[b]] {
+			return sizes[roots[a]] > sizes[roots[b]]
+		}
+		return first[roots[a]] < first[roots[b]]
+	})
+	label := make(map[int]int, len(roots))
+	for i, r := range roots {
+		label[r] = i
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].Component = label[find(i)]
+	}
+	return len(roots)
+}
+
+// pageRank computes the PageRank of every node over the directed links.
+// Dangling nodes spread their rank evenly across the graph.
+func (g *Graph) pageRank(idx map[string]int) []float64 {
+	n := len(g.Nodes)
+	if n == 0 {
+		return nil
+	}
+	out := make([][]int, n)
+	for _, l := range g.Links {
+		s := idx[l.Source]
+		out[s] = append(out[s], idx[l.Target])
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < pageRankIters; iter++ {
+		dangling := 0.0
+		for i := range rank {
+			if len(out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i, targets := range out {
+			share := damping * rank[i] / float64(len(targets))
+			for _, t := range targets {
+				next[t] += share
+			}
+		}
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < pageRankEps {
+			break
+		}
+	}
+	return rank
+}
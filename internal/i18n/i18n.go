@@ -1,32 +1,48 @@
 // @feature:layouts Localization label registry for UI strings.
 package i18n
 
-// Labels holds all user-facing UI strings for a given language.
+import (
+	"fmt"
+	"strings"
+)
+
+// Labels holds all user-facing UI strings for a given language. The yaml
+// keys are the ones of the translation files of the vault and of kiln.yaml.
 type Labels struct {
-	SearchPlaceholder string
-	ToggleTheme       string
-	BackToTop         string
-	OnThisPage        string
-	TableOfContents   string
-	LocalGraph        string
-	Backlinks         string
-	GeneratedWith     string
-	PageNotFound      string
-	GoBackHome        string
-	Folder            string
-	Tag               string
-	Updated           string
-	Created           string
-	Words             string
-	MinRead           string
-	Copy              string
-	NoResults         string
-	Search            string
-	Navbar            string
-	Expand            string
-	LastModified      string
+	SearchPlaceholder string `yaml:"search-placeholder"`
+	ToggleTheme       string `yaml:"toggle-theme"`
+	BackToTop         string `yaml:"back-to-top"`
+	OnThisPage        string `yaml:"on-this-page"`
+	TableOfContents   string `yaml:"table-of-contents"`
+	LocalGraph        string `yaml:"local-graph"`
+	Backlinks         string `yaml:"backlinks"`
+	UnlinkedMentions  string `yaml:"unlinked-mentions"`
+	GeneratedWith     string `yaml:"generated-with"`
+	PageNotFound      string `yaml:"page-not-found"`
+	GoBackHome        string `yaml:"go-back-home"`
+	Folder            string `yaml:"folder"`
+	Tag               string `yaml:"tag"`
+	Updated           string `yaml:"updated"`
+	Created           string `yaml:"created"`
+	Words             string `yaml:"words"`
+	MinRead           string `yaml:"min-read"`
+	Copy              string `yaml:"copy"`
+	NoResults         string `yaml:"no-results"`
+	Search            string `yaml:"search"`
+	Navbar            string `yaml:"navbar"`
+	Expand            string `yaml:"expand"`
+	LastModified      string `yaml:"last-modified"`
+	Language          string `yaml:"language"`
+	EditPage          string `yaml:"edit-page"`
+	Previous          string `yaml:"previous"`
+	Next              string `yaml:"next"`
+	Version           string `yaml:"version"`
+
+	lang    string            // Language of the labels, selects the plural forms
+	plurals map[string]Plural // Plural forms of the labels depending on a count, by yaml key
 }
 
+// languages are the labels built into kiln.
 var languages = map[string]*Labels{
 	"en": {
 		SearchPlaceholder: "Search notes...",
@@ -36,6 +52,7 @@ var languages = map[string]*Labels{
 		TableOfContents:   "Table of contents",
 		LocalGraph:        "Local Graph",
 		Backlinks:         "Backlinks",
+		UnlinkedMentions:  "Unlinked mentions",
 		GeneratedWith:     "Generated with",
 		PageNotFound:      "Page not found",
 		GoBackHome:        "Go back home",
@@ -51,6 +68,15 @@ var languages = map[string]*Labels{
 		Navbar:            "Navbar",
 		Expand:            "Expand",
 		LastModified:      "Last Modified",
+		Language:          "Language",
+		EditPage:          "Edit this page",
+		Previous:          "Previous",
+		Next:              "Next",
+		Version:           "Version",
+		lang:              "en",
+		plurals: map[string]Plural{
+			"words": {"one": "word", "other": "words"},
+		},
 	},
 	"it": {
 		SearchPlaceholder: "Cerca appunti...",
@@ -60,6 +86,7 @@ var languages = map[string]*Labels{
 		TableOfContents:   "Indice",
 		LocalGraph:        "Grafo locale",
 		Backlinks:         "Backlinks",
+		UnlinkedMentions:  "Menzioni non collegate",
 		GeneratedWith:     "Generato con",
 		PageNotFound:      "Pagina non trovata",
 		GoBackHome:        "Torna alla home",
@@ -75,13 +102,93 @@ var languages = map[string]*Labels{
 		Navbar:            "Navbar",
 		Expand:            "Espandi",
 		LastModified:      "Ultima modifica",
+		Language:          "Lingua",
+		EditPage:          "Modifica questa pagina",
+		Previous:          "Precedente",
+		Next:              "Successiva",
+		Version:           "Versione",
+		lang:              "it",
+		plurals: map[string]Plural{
+			"words": {"one": "parola", "other": "parole"},
+		},
 	},
 }
 
-// Resolve returns the Labels for the given language code, falling back to English.
+// nativeNames are the names of common languages, written in the language itself.
+var nativeNames = map[string]string{
+	"ar": "العربية",
+	"de": "Deutsch",
+	"en": "English",
+	"es": "Español",
+	"fr": "Français",
+	"it": "Italiano",
+	"ja": "日本語",
+	"ko": "한국어",
+	"nl": "Nederlands",
+	"pl": "Polski",
+	"pt": "Português",
+	"ru": "Русский",
+	"sv": "Svenska",
+	"tr": "Türkçe",
+	"uk": "Українська",
+	"zh": "中文",
+}
+
+// NativeName returns the name of a language in the language itself, used by
+// the language switcher. Regional variants like "pt-BR" use the name of
+// their language. Unknown codes are returned uppercased.
+func NativeName(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	if name, ok := nativeNames[base]; ok {
+		return name
+	}
+	return strings.ToUpper(lang)
+}
+
+// Resolve returns the Labels for the given language code: the labels loaded
+// from the vault or kiln.yaml, or else the built-in ones. Regional variants
+// like "pt-BR" fall back to the labels of their language, and unknown
+// languages to English.
 func Resolve(lang string) *Labels {
-	if l, ok := languages[lang]; ok {
+	if l := lookup(lang); l != nil {
 		return l
 	}
 	return languages["en"]
 }
+
+// lookup returns the labels of a language or of its base language, or nil.
+func lookup(lang string) *Labels {
+	base, _, _ := strings.Cut(lang, "-")
+	for _, code := range []string{lang, base} {
+		if l, ok := custom[code]; ok {
+			return l
+		}
+		if l, ok := languages[code]; ok {
+			return l
+		}
+	}
+	return nil
+}
+
+// WordsFor returns the Words label for a count of n words.
+func (l *Labels) WordsFor(n int) string {
+	return l.plural("words", l.Words, n)
+}
+
+// MinReadFor returns the MinRead label formatted for a reading time of the
+// given minutes.
+func (l *Labels) MinReadFor(minutes int) string {
+	return fmt.Sprintf(l.plural("min-read", l.MinRead, minutes), minutes)
+}
+
+// plural returns the form of a label matching the count n, or the label
+// itself when it has no plural forms.
+func (l *Labels) plural(key, label string, n int) string {
+	if form := l.plurals[key][PluralCategory(l.lang, n)]; form != "" {
+		return form
+	}
+	if form := l.plurals[key]["other"]; form != "" {
+		return form
+	}
+	return label
+}
@@ -61,3 +61,17 @@ func TestResolve_AllFieldsPopulated(t *testing.T) {
 		}
 	}
 }
+
+func TestNativeName(t *testing.T) {
+	cases := map[string]string{
+		"it":    "Italiano",
+		"en":    "English",
+		"pt-BR": "Português",
+		"xx":    "XX",
+	}
+	for code, want := range cases {
+		if got := NativeName(code); got != want {
+			t.Errorf("NativeName(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// @feature:i18n Labels loaded from the translation files of the vault and from kiln.yaml.
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is the folder of the vault holding the translation files, one
+// <lang>.yaml file per language.
+const Dir = "_i18n"
+
+// pluralKeys are the labels that accept plural forms.
+var pluralKeys = []string{"words", "min-read"}
+
+// custom are the labels loaded by Load, by language code.
+var custom = map[string]*Labels{}
+
+// Load reads the labels of the translation files in the _i18n folder of
+// the vault and of the "labels" of the i18n section of kiln.yaml, which win
+// over the files. Labels of a language built into kiln override the
+// built-in ones. Labels of a new language are checked for missing keys,
+// which fall back to English. Problems are logged as warnings. Load replaces
+// the labels of the previous call.
+func Load(inputDir string, opts Options, log *slog.Logger) {
+	custom = map[string]*Labels{}
+	values := map[string]map[string]any{}
+
+	paths, _ := filepath.Glob(filepath.Join(inputDir, Dir, "*.y*ml"))
+	for _, path := range paths {
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn("Couldn't read translation file", "path", path, "error", err)
+			continue
+		}
+		file := map[string]any{}
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			log.Warn("Couldn't parse translation file", "path", path, "error", err)
+			continue
+		}
+		lang := strings.TrimSuffix(filepath.Base(path), ext)
+		values[lang] = file
+	}
+	for lang, labels := range opts.Labels {
+		if values[lang] == nil {
+			values[lang] = map[string]any{}
+		}
+		maps.Copy(values[lang], labels)
+	}
+
+	for _, lang := range slices.Sorted(maps.Keys(values)) {
+		custom[lang] = parseLabels(lang, values[lang], log.With("lang", lang))
+	}
+	if len(custom) > 0 {
+		log.Debug("Loaded UI labels", "languages", len(custom))
+	}
+}
+
+// parseLabels builds the labels of a language from the values of its
+// translation file, on top of the built-in labels of the language, or of
+// English for a new language.
+func parseLabels(lang string, values map[string]any, log *slog.Logger) *Labels {
+	base := lookup(lang)
+	known := base != nil
+	if !known {
+		base = languages["en"]
+	}
+	labels := *base
+	labels.lang = lang
+	labels.plurals = map[string]Plural{}
+	for key, forms := range base.plurals {
+		labels.plurals[key] = maps.Clone(forms)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		v := values[key]
+		field, ok := labelField(&labels, key)
+		if !ok {
+			log.Warn("Unknown UI label, see kiln i18n extract for the known ones", "key", key)
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			field.SetString(v)
+			delete(labels.plurals, key)
+		case map[string]any:
+			if !slices.Contains(pluralKeys, key) {
+				log.Warn("UI label doesn't take plural forms", "key", key)
+				continue
+			}
+			forms := Plural{}
+			for category, form := range v {
+				if !slices.Contains(PluralCategories, category) {
+					log.Warn("Unknown plural category", "key", key, "category", category, "known", strings.Join(PluralCategories, ", "))
+					continue
+				}
+				forms[category] = fmt.Sprint(form)
+			}
+			if forms["other"] == "" {
+				log.Warn("Plural forms without an \"other\" form", "key", key)
+			}
+			labels.plurals[key] = forms
+		default:
+			log.Warn("UI label isn't a string", "key", key, "value", v)
+		}
+	}
+	setPluralDefaults(&labels)
+	validateMinRead(&labels, log)
+
+	if !known {
+		missing := []string{}
+		for _, key := range Keys() {
+			if _, ok := values[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			log.Warn("Missing UI labels, using the English ones", "keys", strings.Join(missing, ", "))
+		}
+	}
+	return &labels
+}
+
+// Keys returns the yaml keys of the labels, in the order of the Labels struct.
+func Keys() []string {
+	keys := []string{}
+	typ := reflect.TypeFor[Labels]()
+	for i := range typ.NumField() {
+		if key := typ.Field(i).Tag.Get("yaml"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// labelField returns the field of the labels with the given yaml key.
+func labelField(l *Labels, key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(l).Elem()
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("yaml") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setPluralDefaults sets the Words and MinRead labels to the "other" form
+// of their plurals, used where the count isn't known.
+func setPluralDefaults(l *Labels) {
+	if other := l.plurals["words"]["other"]; other != "" {
+		l.Words = other
+	}
+	if other := l.plurals["min-read"]["other"]; other != "" {
+		l.MinRead = other
+	}
+}
+
+// validateMinRead warns about reading time labels without the %d verb
+// standing for the minutes.
+func validateMinRead(l *Labels, log *slog.Logger) {
+	forms := []string{l.MinRead}
+	for _, form := range l.plurals["min-read"] {
+		forms = append(forms, form)
+	}
+	for _, form := range forms {
+		if strings.Count(form, "%d") != 1 || strings.Count(form, "%") != 1 {
+			log.Warn("The min-read label should hold %d once, standing for the minutes", "label", form)
+			return
+		}
+	}
+}
+
+// Template returns a translation file holding every label of a language,
+// or of English for a language without labels, as a starting point for a
+// new translation.
+func Template(lang string) ([]byte, error) {
+	labels := Resolve(lang)
+	doc := &yaml.Node{
+		Kind:        yaml.MappingNode,
+		HeadComment: "UI labels of kiln. Save as " + Dir + "/<lang>.yaml in the vault and translate the values.",
+	}
+	v := reflect.ValueOf(labels).Elem()
+	for i := range v.NumField() {
+		key := v.Type().Field(i).Tag.Get("yaml")
+		if key == "" {
+			continue
+		}
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: v.Field(i).String()}
+		if forms, ok := labels.plurals[key]; ok {
+			value = &yaml.Node{Kind: yaml.MappingNode}
+			for _, category := range PluralCategories {
+				if form, ok := forms[category]; ok {
+					value.Content = append(value.Content,
+						&yaml.Node{Kind: yaml.ScalarNode, Value: category},
+						&yaml.Node{Kind: yaml.ScalarNode, Value: form},
+					)
+				}
+			}
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("i18n: couldn't write the labels template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
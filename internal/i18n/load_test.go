@@ -0,0 +1,185 @@
+// @feature:i18n Tests for the labels loaded from the vault and kiln.yaml, and the plural forms.
+package i18n
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadLabels writes the translation files to a temporary vault, loads them
+// and returns the warnings logged. The loaded labels are dropped at the end
+// of the test.
+func loadLabels(t *testing.T, files map[string]string, opts Options) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, Dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, Dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var logs bytes.Buffer
+	Load(dir, opts, slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { custom = map[string]*Labels{} })
+	return logs.String()
+}
+
+func TestLoad_NewLanguage(t *testing.T) {
+	logs := loadLabels(t, map[string]string{
+		"fr.yaml": `search-placeholder: Rechercher...
+words:
+  one: mot
+  other: mots
+min-read: "%d min de lecture"
+unknown-key: nope
+`,
+	}, Options{})
+
+	fr := Resolve("fr")
+	if fr.SearchPlaceholder != "Rechercher..." {
+		t.Errorf("SearchPlaceholder = %q", fr.SearchPlaceholder)
+	}
+	if fr.BackToTop != "Back to top" {
+		t.Errorf("BackToTop = %q, want the English label", fr.BackToTop)
+	}
+	if got := fr.WordsFor(0); got != "mot" {
+		t.Errorf("WordsFor(0) = %q, want mot (French counts 0 as one)", got)
+	}
+	if got := fr.WordsFor(12); got != "mots" {
+		t.Errorf("WordsFor(12) = %q, want mots", got)
+	}
+	if fr.Words != "mots" {
+		t.Errorf("Words = %q, want the other form", fr.Words)
+	}
+	if got := fr.MinReadFor(3); got != "3 min de lecture" {
+		t.Errorf("MinReadFor(3) = %q", got)
+	}
+	if Resolve("fr-CA") != fr {
+		t.Error("fr-CA should fall back to the French labels")
+	}
+
+	for _, want := range []string{"Missing UI labels", "back-to-top", "Unknown UI label", "unknown-key"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected the logs to contain %q, got:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "search-placeholder,") {
+		t.Errorf("search-placeholder reported as missing:\n%s", logs)
+	}
+}
+
+func TestLoad_OverridesBuiltIn(t *testing.T) {
+	logs := loadLabels(t, map[string]string{
+		"it.yaml": "back-to-top: Su\ncopy: Copia il codice\n",
+	}, Options{Labels: map[string]map[string]any{
+		"it": {"copy": "Copia!"},
+	}})
+
+	it := Resolve("it")
+	if it.BackToTop != "Su" {
+		t.Errorf("BackToTop = %q, want the vault label", it.BackToTop)
+	}
+	if it.Copy != "Copia!" {
+		t.Errorf("Copy = %q, want the kiln.yaml label", it.Copy)
+	}
+	if it.PageNotFound != "Pagina non trovata" {
+		t.Errorf("PageNotFound = %q, want the built-in label", it.PageNotFound)
+	}
+	if got := it.WordsFor(1); got != "parola" {
+		t.Errorf("WordsFor(1) = %q, want the built-in plural", got)
+	}
+	if strings.Contains(logs, "Missing UI labels") {
+		t.Errorf("overrides of a built-in language shouldn't report missing labels:\n%s", logs)
+	}
+	if languages["it"].BackToTop != "Torna su" {
+		t.Error("Load modified the built-in labels")
+	}
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	logs := loadLabels(t, map[string]string{
+		"de.yaml": `copy:
+  one: Kopie
+words:
+  single: Wort
+min-read: "Lesezeit"
+`,
+	}, Options{})
+
+	for _, want := range []string{"doesn't take plural forms", "Unknown plural category", "without an \\\"other\\\" form", "should hold %d once"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected the logs to contain %q, got:\n%s", want, logs)
+		}
+	}
+}
+
+func TestLoad_ReplacesPreviousLabels(t *testing.T) {
+	loadLabels(t, map[string]string{"fr.yaml": "copy: Copier\n"}, Options{})
+	Load(t.TempDir(), Options{}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	if Resolve("fr") != Resolve("en") {
+		t.Error("labels of a previous Load are still resolved")
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	out, err := Template("it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]any{}
+	if err := yaml.Unmarshal(out, &values); err != nil {
+		t.Fatalf("template isn't valid yaml: %v\n%s", err, out)
+	}
+	if len(values) != len(Keys()) {
+		t.Errorf("template has %d keys, want %d", len(values), len(Keys()))
+	}
+	if values["back-to-top"] != "Torna su" {
+		t.Errorf("back-to-top = %v", values["back-to-top"])
+	}
+	words, ok := values["words"].(map[string]any)
+	if !ok || words["one"] != "parola" || words["other"] != "parole" {
+		t.Errorf("words = %v, want the plural forms", values["words"])
+	}
+
+	// The template loads back without warnings
+	logs := loadLabels(t, map[string]string{"xx.yaml": string(out)}, Options{})
+	if logs != "" {
+		t.Errorf("loading the template logged:\n%s", logs)
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "one"},
+		{"en", 0, "other"},
+		{"en-GB", 2, "other"},
+		{"fr", 0, "one"},
+		{"fr", 2, "other"},
+		{"ru", 21, "one"},
+		{"ru", 3, "few"},
+		{"ru", 12, "many"},
+		{"pl", 22, "few"},
+		{"pl", 25, "many"},
+		{"cs", 3, "few"},
+		{"ja", 1, "other"},
+		{"ar", 2, "two"},
+		{"ar", 11, "many"},
+	}
+	for _, tt := range tests {
+		if got := PluralCategory(tt.lang, tt.n); got != tt.want {
+			t.Errorf("PluralCategory(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
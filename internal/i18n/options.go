@@ -0,0 +1,9 @@
+// @feature:i18n Languages of a multilingual site.
+package i18n
+
+// Options configures the languages of the site. It maps to the "i18n"
+// section of kiln.yaml.
+type Options struct {
+	Languages []string                  `yaml:"languages"` // Language codes of the site. Top-level folders named after one of them hold its notes
+	Labels    map[string]map[string]any `yaml:"labels"`    // UI labels by language code, overriding the built-in ones and the _i18n files
+}
@@ -0,0 +1,71 @@
+// @feature:i18n Plural forms of the labels depending on a count.
+package i18n
+
+import "strings"
+
+// Plural holds the forms of a label depending on a count, keyed by CLDR
+// plural category: "zero", "one", "two", "few", "many" and "other".
+type Plural map[string]string
+
+// PluralCategories are the CLDR plural categories, in their usual order.
+var PluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// PluralCategory returns the CLDR plural category of the count n in a
+// language. It covers the rules of the common languages, and uses the
+// English rule ("one" for 1, "other" for the rest) for the others.
+func PluralCategory(lang string, n int) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	if n < 0 {
+		n = -n
+	}
+	mod10, mod100 := n%10, n%100
+	switch base {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms":
+		return "other"
+	case "fr", "pt":
+		if n <= 1 {
+			return "one"
+		}
+	case "ru", "uk", "be":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,251 @@
+// @feature:imgopt Variant cache that reuses encoded images across builds.
+package imgopt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+)
+
+// cacheVersion is part of every cache key. Bump it whenever the variants
+// generated for the same source and settings change.
+const cacheVersion = 1
+
+// cacheEntryFile describes the cached variants inside an entry directory.
+const cacheEntryFile = "entry.json"
+
+// Cache stores the variants of processed images in a directory outside the
+// output, keyed by a hash of the source bytes and of every setting that
+// affects the result. A nil *Cache disables caching.
+type Cache struct {
+	dir    string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry is the Result of an image, without its paths.
+type cacheEntry struct {
+	Width       int                        `json:"width"`
+	Height      int                        `json:"height"`
+	BlurHash    string                     `json:"blurhash,omitempty"`
+	Placeholder string                     `json:"placeholder,omitempty"`
+	Variants    []cachedVariant            `json:"variants"`
+	Sized       map[string][]cachedVariant `json:"sized,omitempty"`
+}
+
+type cachedVariant struct {
+	Width  int    `json:"width"`
+	Suffix string `json:"suffix"`
+	Format string `json:"format"`
+}
+
+// file is the name of the variant inside its entry directory.
+func (v cachedVariant) file() string {
+	return "v" + v.Suffix + "." + v.Format
+}
+
+// NewCache returns a cache storing its entries in dir.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Stats returns the number of images served from the cache and the number
+// of images processed since the cache was created.
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	return int(c.hits.Load()), int(c.misses.Load())
+}
+
+// ProcessImage returns the variants of the job from the cache, copying them
+// to the output directory, or runs ProcessImage and stores its variants.
+// Images without variants are never cached, as they are only measured.
+func (c *Cache) ProcessImage(job ImageJob, opts Options) (*Result, error) {
+	if c == nil || !IsOptimizable(filepath.Ext(job.SrcPath)) {
+		return ProcessImage(job.SrcPath, job.OutDir, job.WebDir, job.BaseName, opts, job.Sizes...)
+	}
+
+	key, err := cacheKey(job.SrcPath, opts, job.Sizes)
+	if err != nil {
+		return nil, err
+	}
+	entryDir := filepath.Join(c.dir, key[:2], key)
+
+	if result, err := restoreEntry(entryDir, job); err == nil {
+		c.hits.Add(1)
+		return result, nil
+	}
+
+	c.misses.Add(1)
+	result, err := ProcessImage(job.SrcPath, job.OutDir, job.WebDir, job.BaseName, opts, job.Sizes...)
+	if err != nil {
+		return nil, err
+	}
+	// A failed store only costs the next build some time
+	_ = c.store(entryDir, result)
+	return result, nil
+}
+
+// cacheKey hashes the source image together with the settings and the
+// encoders that shape its variants.
+func cacheKey(srcPath string, opts Options, sizes []Directives) (string, error) {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("imgopt: open %s: %w", srcPath, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("imgopt: read %s: %w", srcPath, err)
+	}
+
+	keys := []string{}
+	for _, d := range sizes {
+		if d.Sized() {
+			keys = append(keys, d.Key())
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	fmt.Fprintf(h, "\nv%d widths=%v avif=%t webp=%t", cacheVersion, opts.Widths(), opts.Enabled(FormatAVIF), opts.Enabled(FormatWebP))
+	fmt.Fprintf(h, " quality=%d,%d,%d", opts.QualityFor("jpeg"), opts.QualityFor(FormatWebP), opts.QualityFor(FormatAVIF))
+	fmt.Fprintf(h, " placeholder=%s sizes=%v", opts.Placeholder, keys)
+	// Installing or removing an encoder changes the generated formats
+	fmt.Fprintf(h, " avifenc=%t cwebp=%t", hasEncoder("avifenc"), hasEncoder("cwebp"))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hasEncoder(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// restoreEntry copies the variants of a cache entry to the output directory
+// of the job and returns its Result.
+func restoreEntry(entryDir string, job ImageJob) (*Result, error) {
+	data, err := os.ReadFile(filepath.Join(entryDir, cacheEntryFile))
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	restore := func(cached []cachedVariant) ([]Variant, error) {
+		variants := make([]Variant, 0, len(cached))
+		for _, cv := range cached {
+			data, err := os.ReadFile(filepath.Join(entryDir, cv.file()))
+			if err != nil {
+				return nil, err
+			}
+			v, err := writeVariant(data, job.OutDir, job.WebDir, job.BaseName, cv.Suffix, cv.Format, cv.Width)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, v)
+		}
+		return variants, nil
+	}
+
+	result := &Result{
+		Original:    job.SrcPath,
+		Width:       entry.Width,
+		Height:      entry.Height,
+		BlurHash:    entry.BlurHash,
+		Placeholder: entry.Placeholder,
+	}
+	if result.Variants, err = restore(entry.Variants); err != nil {
+		return nil, err
+	}
+	for key, cached := range entry.Sized {
+		variants, err := restore(cached)
+		if err != nil {
+			return nil, err
+		}
+		if result.Sized == nil {
+			result.Sized = map[string][]Variant{}
+		}
+		result.Sized[key] = variants
+	}
+	return result, nil
+}
+
+// store copies the variants of result into a new cache entry. The entry is
+// written to a temporary directory first, so that a crash never leaves a
+// partial entry behind.
+func (c *Cache) store(entryDir string, result *Result) error {
+	if err := os.MkdirAll(filepath.Dir(entryDir), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(entryDir), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	save := func(variants []Variant) ([]cachedVariant, error) {
+		cached := make([]cachedVariant, 0, len(variants))
+		for _, v := range variants {
+			cv := cachedVariant{Width: v.Width, Suffix: v.Suffix, Format: v.Format}
+			data, err := os.ReadFile(v.OutPath)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(filepath.Join(tmp, cv.file()), data, 0o644); err != nil {
+				return nil, err
+			}
+			cached = append(cached, cv)
+		}
+		return cached, nil
+	}
+
+	entry := cacheEntry{
+		Width:       result.Width,
+		Height:      result.Height,
+		BlurHash:    result.BlurHash,
+		Placeholder: result.Placeholder,
+	}
+	if entry.Variants, err = save(result.Variants); err != nil {
+		return err
+	}
+	for key, variants := range result.Sized {
+		cached, err := save(variants)
+		if err != nil {
+			return err
+		}
+		if entry.Sized == nil {
+			entry.Sized = map[string][]cachedVariant{}
+		}
+		entry.Sized[key] = cached
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, cacheEntryFile), data, 0o644); err != nil {
+		return err
+	}
+	// Another worker may have stored the same image meanwhile
+	if err := os.Rename(tmp, entryDir); err != nil && !dirExists(entryDir) {
+		return err
+	}
+	return nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,75 @@
+// @feature:imgopt
+package imgopt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCache_ReusesVariants(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "photo.png")
+	savePNG(t, srcPath, newTestImage(900, 600))
+
+	cache := NewCache(filepath.Join(dir, "cache"))
+	opts := Options{Breakpoints: []int{400}, Formats: []string{}, Placeholder: PlaceholderLQIP}
+	sized, _ := ParseDirectives("200")
+	job := func(outDir string) ImageJob {
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return ImageJob{SrcPath: srcPath, OutDir: outDir, WebDir: "/img", BaseName: "photo", WebPath: "/img/photo.png", Sizes: []Directives{sized}}
+	}
+
+	first, err := cache.ProcessImage(job(filepath.Join(dir, "out1")), opts)
+	if err != nil {
+		t.Fatalf("first build: %v", err)
+	}
+	second, err := cache.ProcessImage(job(filepath.Join(dir, "out2")), opts)
+	if err != nil {
+		t.Fatalf("second build: %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Fatalf("Stats = %d hits, %d misses, want 1 and 1", hits, misses)
+	}
+
+	if second.Width != first.Width || second.Placeholder != first.Placeholder || len(second.Variants) != len(first.Variants) {
+		t.Errorf("cached result %+v differs from %+v", second, first)
+	}
+	for _, v := range append(second.Variants, second.Sized[sized.Key()]...) {
+		if filepath.Dir(v.OutPath) != filepath.Join(dir, "out2") {
+			t.Errorf("variant restored to %s", v.OutPath)
+		}
+		if _, err := os.Stat(v.OutPath); err != nil {
+			t.Errorf("variant missing: %v", err)
+		}
+	}
+
+	// Different settings and different bytes miss the cache
+	if _, err := cache.ProcessImage(job(filepath.Join(dir, "out3")), Options{Breakpoints: []int{300}, Formats: []string{}}); err != nil {
+		t.Fatal(err)
+	}
+	savePNG(t, srcPath, newTestImage(900, 601))
+	if _, err := cache.ProcessImage(job(filepath.Join(dir, "out4")), opts); err != nil {
+		t.Fatal(err)
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 3 {
+		t.Errorf("Stats = %d hits, %d misses, want 1 and 3", hits, misses)
+	}
+}
+
+func TestCache_Nil(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "photo.png")
+	savePNG(t, srcPath, newTestImage(500, 300))
+
+	var cache *Cache
+	result, err := cache.ProcessImage(ImageJob{SrcPath: srcPath, OutDir: dir, WebDir: "/img", BaseName: "photo"}, Options{Breakpoints: []int{200}})
+	if err != nil || len(result.Variants) == 0 {
+		t.Fatalf("ProcessImage = %+v, %v", result, err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 0 {
+		t.Errorf("nil cache Stats = %d, %d", hits, misses)
+	}
+}
@@ -0,0 +1,140 @@
+// @feature:imgopt Per-image directives written after the pipes of an image embed.
+package imgopt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Obsidian's size syntax: "300" or "300x200"
+	sizeRegex = regexp.MustCompile(`^(\d+)(?:x(\d+))?$`)
+	// Focal point as percentages: "30,70" or "30%,70%"
+	focusRegex = regexp.MustCompile(`^(\d{1,3})%?,\s*(\d{1,3})%?$`)
+)
+
+// focusKeywords maps named focal points to their position, in percent.
+var focusKeywords = map[string][2]int{
+	"center":       {50, 50},
+	"top":          {50, 0},
+	"bottom":       {50, 100},
+	"left":         {0, 50},
+	"right":        {100, 50},
+	"top-left":     {0, 0},
+	"top-right":    {100, 0},
+	"bottom-left":  {0, 100},
+	"bottom-right": {100, 100},
+}
+
+// Directives are the processing options of a single image, written after
+// the pipes of an embed: ![[photo.png|Alt text|300x200|crop=top|eager|caption]].
+type Directives struct {
+	Width   int    // Requested display width, 0 when unset
+	Height  int    // Requested display height, 0 when unset
+	Crop    bool   // Crop to Width x Height instead of scaling
+	FocusX  int    // Horizontal focal point of the crop, in percent
+	FocusY  int    // Vertical focal point of the crop, in percent
+	Loading string // "lazy" or "eager", empty for the default
+	Caption string // Figure caption, empty for none
+}
+
+// ParseDirectives splits the label of an image embed into its directives
+// and its alt text. Parts that are not directives form the alt text.
+//
+// Supported directives: "300" and "300x200" sizes, "crop" (optionally
+// "crop=<focus>"), "focus=<focus>" where focus is a keyword such as "top"
+// or "top-left" or percentages like "30,70", "lazy", "eager", "caption"
+// (the alt text becomes the caption) and "caption=<text>".
+func ParseDirectives(label string) (Directives, string) {
+	d := Directives{FocusX: 50, FocusY: 50}
+	alt := []string{}
+	captionFromAlt := false
+
+	for _, part := range strings.Split(label, "|") {
+		part = strings.TrimSpace(part)
+		key, value, hasValue := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch {
+		case sizeRegex.MatchString(part):
+			m := sizeRegex.FindStringSubmatch(part)
+			d.Width, _ = strconv.Atoi(m[1])
+			d.Height, _ = strconv.Atoi(m[2])
+		case key == "lazy" || key == "eager":
+			d.Loading = key
+		case key == "crop":
+			d.Crop = true
+			if hasValue {
+				d.setFocus(value)
+			}
+		case key == "focus" && hasValue:
+			d.setFocus(value)
+		case key == "caption":
+			if hasValue {
+				d.Caption = value
+			} else {
+				captionFromAlt = true
+			}
+		case part != "":
+			alt = append(alt, part)
+		}
+	}
+
+	altText := strings.Join(alt, "|")
+	if captionFromAlt {
+		d.Caption = altText
+	}
+	// Cropping needs both dimensions
+	if d.Width == 0 || d.Height == 0 {
+		d.Crop = false
+	}
+	return d, altText
+}
+
+// setFocus parses a focal point keyword or percentage pair.
+func (d *Directives) setFocus(value string) {
+	if pos, ok := focusKeywords[strings.ToLower(value)]; ok {
+		d.FocusX, d.FocusY = pos[0], pos[1]
+		return
+	}
+	if m := focusRegex.FindStringSubmatch(value); m != nil {
+		x, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		d.FocusX, d.FocusY = min(x, 100), min(y, 100)
+	}
+}
+
+// Sized reports whether the directives request a specific size.
+func (d Directives) Sized() bool {
+	return d.Width > 0 || d.Height > 0
+}
+
+// Key identifies the variants generated for these directives, e.g.
+// "300x0" or "300x200c50-0".
+func (d Directives) Key() string {
+	key := fmt.Sprintf("%dx%d", d.Width, d.Height)
+	if d.Crop {
+		key += fmt.Sprintf("c%d-%d", d.FocusX, d.FocusY)
+	}
+	return key
+}
+
+// Dimensions returns the display size for an image of the given intrinsic
+// size, filling a missing dimension from the aspect ratio.
+func (d Directives) Dimensions(width, height int) (int, int) {
+	w, h := d.Width, d.Height
+	switch {
+	case w > 0 && h > 0:
+		return w, h
+	case width <= 0 || height <= 0:
+		return w, h
+	case w > 0:
+		return w, (w*height + width/2) / width
+	case h > 0:
+		return (h*width + height/2) / height, h
+	}
+	return width, height
+}
@@ -0,0 +1,55 @@
+// @feature:imgopt
+package imgopt
+
+import "testing"
+
+func TestParseDirectives(t *testing.T) {
+	cases := []struct {
+		label string
+		want  Directives
+		alt   string
+	}{
+		{"", Directives{FocusX: 50, FocusY: 50}, ""},
+		{"A photo", Directives{FocusX: 50, FocusY: 50}, "A photo"},
+		{"300", Directives{Width: 300, FocusX: 50, FocusY: 50}, ""},
+		{"A photo|300x200", Directives{Width: 300, Height: 200, FocusX: 50, FocusY: 50}, "A photo"},
+		{"300x200|crop=top-left|eager", Directives{Width: 300, Height: 200, Crop: true, Loading: "eager"}, ""},
+		{"300x200|crop|focus=30,70", Directives{Width: 300, Height: 200, Crop: true, FocusX: 30, FocusY: 70}, ""},
+		{"300|crop", Directives{Width: 300, FocusX: 50, FocusY: 50}, ""},
+		{"A cat|caption|lazy", Directives{FocusX: 50, FocusY: 50, Loading: "lazy", Caption: "A cat"}, "A cat"},
+		{"Alt|caption=A = B", Directives{FocusX: 50, FocusY: 50, Caption: "A = B"}, "Alt"},
+	}
+	for _, c := range cases {
+		got, alt := ParseDirectives(c.label)
+		if got != c.want || alt != c.alt {
+			t.Errorf("ParseDirectives(%q) = %+v, %q; want %+v, %q", c.label, got, alt, c.want, c.alt)
+		}
+	}
+}
+
+func TestDirectives_KeyAndDimensions(t *testing.T) {
+	d, _ := ParseDirectives("300")
+	if d.Key() != "300x0" {
+		t.Errorf("Key = %q, want 300x0", d.Key())
+	}
+	if w, h := d.Dimensions(1200, 800); w != 300 || h != 200 {
+		t.Errorf("Dimensions = %dx%d, want 300x200", w, h)
+	}
+
+	d, _ = ParseDirectives("x|0x100")
+	if w, h := d.Dimensions(1200, 800); w != 150 || h != 100 {
+		t.Errorf("Dimensions from height = %dx%d, want 150x100", w, h)
+	}
+
+	d, _ = ParseDirectives("300x300|crop=bottom")
+	if d.Key() != "300x300c50-100" {
+		t.Errorf("crop Key = %q", d.Key())
+	}
+	if w, h := d.Dimensions(1200, 800); w != 300 || h != 300 {
+		t.Errorf("crop Dimensions = %dx%d, want 300x300", w, h)
+	}
+
+	if w, h := (Directives{}).Dimensions(640, 480); w != 640 || h != 480 {
+		t.Errorf("unsized Dimensions = %dx%d, want 640x480", w, h)
+	}
+}
@@ -2,20 +2,24 @@
 package imgopt
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"image"
-	_ "image/jpeg"
+	_ "image/gif"
+	"image/jpeg"
 	"image/png"
-	_ "image/png"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
 	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
 )
 
 var ErrNoEncoder = errors.New("imgopt: encoder not available")
@@ -29,8 +33,13 @@ type Variant struct {
 }
 
 type Result struct {
-	Original string
-	Variants []Variant
+	Original    string
+	Variants    []Variant            // Breakpoint variants, grouped by format
+	Sized       map[string][]Variant // Variants of the sizes requested by directives, keyed by Directives.Key
+	Width       int                  // Intrinsic width of the original
+	Height      int                  // Intrinsic height of the original
+	BlurHash    string               // BlurHash of the image, with the blurhash placeholder
+	Placeholder string               // Data URI shown while the image loads
 }
 
 // DefaultBreakpoints returns the default responsive image widths.
@@ -38,6 +47,101 @@ func DefaultBreakpoints() []int {
 	return []int{1200, 800, 400}
 }
 
+// DefaultQuality is the encoding quality used when none is configured.
+const DefaultQuality = 80
+
+// Modern formats generated next to the original format
+const (
+	FormatAVIF = "avif"
+	FormatWebP = "webp"
+)
+
+// Options configures image optimization. It maps to the "images" section of kiln.yaml.
+type Options struct {
+	Breakpoints   []int    `yaml:"breakpoints"`    // Variant widths (default 1200, 800, 400)
+	Formats       []string `yaml:"formats"`        // Formats generated besides the original: "avif", "webp" (default both)
+	Quality       Quality  `yaml:"quality"`        // Encoding quality per format, from 1 to 100
+	MaxWidth      int      `yaml:"max-width"`      // Widest variant, larger breakpoints are ignored (default none)
+	StripMetadata bool     `yaml:"strip-metadata"` // Remove EXIF, XMP and text chunks from the copied originals
+	Placeholder   string   `yaml:"placeholder"`    // Shown while loading: "lqip", "blurhash" or empty for none
+}
+
+// Quality holds the encoding quality of each lossy format.
+type Quality struct {
+	JPEG int `yaml:"jpeg"`
+	WebP int `yaml:"webp"`
+	AVIF int `yaml:"avif"`
+}
+
+// Widths returns the variant widths, largest first. When MaxWidth is set,
+// wider breakpoints are replaced by MaxWidth itself.
+func (o Options) Widths() []int {
+	widths := o.Breakpoints
+	if len(widths) == 0 {
+		widths = DefaultBreakpoints()
+	}
+	out := []int{}
+	for _, w := range widths {
+		if o.MaxWidth > 0 && w > o.MaxWidth {
+			w = o.MaxWidth
+		}
+		if w > 0 && !slices.Contains(out, w) {
+			out = append(out, w)
+		}
+	}
+	slices.SortFunc(out, func(a, b int) int { return b - a })
+	return out
+}
+
+// Enabled reports whether the given modern format should be generated.
+func (o Options) Enabled(format string) bool {
+	if o.Formats == nil {
+		return true
+	}
+	return slices.ContainsFunc(o.Formats, func(f string) bool {
+		return strings.EqualFold(f, format)
+	})
+}
+
+// QualityFor returns the configured quality of format, or DefaultQuality.
+func (o Options) QualityFor(format string) int {
+	q := 0
+	switch format {
+	case "jpeg":
+		q = o.Quality.JPEG
+	case FormatWebP:
+		q = o.Quality.WebP
+	case FormatAVIF:
+		q = o.Quality.AVIF
+	}
+	if q < 1 || q > 100 {
+		return DefaultQuality
+	}
+	return q
+}
+
+// reported keeps track of the missing encoders already logged.
+var reported sync.Map
+
+// ReportEncoders logs the enabled formats whose encoder is not installed.
+// Each missing encoder is reported once per process.
+func ReportEncoders(opts Options, log *slog.Logger) {
+	if opts.Enabled(FormatAVIF) {
+		if _, err := exec.LookPath("avifenc"); err != nil {
+			if _, done := reported.LoadOrStore("avifenc", true); !done {
+				log.Warn("avifenc not found in PATH, AVIF variants are skipped. Install libavif to enable them")
+			}
+		}
+	}
+	if opts.Enabled(FormatWebP) {
+		if _, err := exec.LookPath("cwebp"); err != nil {
+			if _, done := reported.LoadOrStore("cwebp", true); !done {
+				log.Warn("cwebp not found in PATH, WebP variants use the built-in lossless encoder and are skipped for images it would make larger, such as photos. Install libwebp to get them")
+			}
+		}
+	}
+}
+
 // IsOptimizable returns true for image extensions we can process.
 func IsOptimizable(ext string) bool {
 	switch strings.ToLower(ext) {
@@ -47,6 +151,16 @@ func IsOptimizable(ext string) bool {
 	return false
 }
 
+// IsMeasurable returns true for image extensions whose dimensions can be
+// read. Only optimizable ones get variants.
+func IsMeasurable(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".gif", ".webp":
+		return true
+	}
+	return IsOptimizable(ext)
+}
+
 // DecodeImage opens a file and decodes it as an image.
 func DecodeImage(path string) (image.Image, string, error) {
 	f, err := os.Open(path)
@@ -74,13 +188,33 @@ func Resize(src image.Image, maxWidth int) image.Image {
 	}
 
 	dstW := maxWidth
-	dstH := srcH * maxWidth / srcW
+	dstH := max(1, srcH*maxWidth/srcW)
 
 	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
 	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
 	return dst
 }
 
+// Crop cuts src to the aspect ratio of width x height, keeping the focal
+// point (in percent) as close as possible to the center of the result.
+func Crop(src image.Image, width, height, focusX, focusY int) image.Image {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	cropW, cropH := srcW, srcW*height/width
+	if cropH > srcH {
+		cropW, cropH = srcH*width/height, srcH
+	}
+	if cropW == srcW && cropH == srcH {
+		return src
+	}
+	x0 := max(0, min(srcW-cropW, srcW*focusX/100-cropW/2))
+	y0 := max(0, min(srcH-cropH, srcH*focusY/100-cropH/2))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, cropW, cropH))
+	draw.Copy(dst, image.Point{}, src, image.Rect(b.Min.X+x0, b.Min.Y+y0, b.Min.X+x0+cropW, b.Min.Y+y0+cropH), draw.Src, nil)
+	return dst
+}
+
 // EncodeWebP encodes img as WebP by shelling out to cwebp.
 // Returns ErrNoEncoder if cwebp is not on PATH.
 func EncodeWebP(w io.Writer, img image.Image, quality int) error {
@@ -167,104 +301,197 @@ func EncodeAVIF(w io.Writer, img image.Image, quality int) error {
 	return err
 }
 
-// ProcessImage decodes srcPath, resizes at each breakpoint smaller than the
-// original width, encodes to the original format and optionally WebP, and
-// writes files to outDir. WebPaths are computed relative to webDir.
-func ProcessImage(srcPath, outDir, webDir, baseName string, breakpoints []int) (*Result, error) {
+// ProcessImage decodes srcPath, resizes it at each configured width smaller
+// than the original, encodes every size to the enabled modern formats and to
+// the original format, and writes the files to outDir. WebPaths are computed
+// relative to webDir.
+//
+// Each sized directive gets its own variants, at the requested width and
+// twice that width for high density screens, cropped when requested.
+// Images that cannot be optimized only get their dimensions.
+//
+// When cwebp is missing, WebP variants fall back to EncodeWebPLossless and
+// are kept only if smaller than the original format.
+func ProcessImage(srcPath, outDir, webDir, baseName string, opts Options, sizes ...Directives) (*Result, error) {
+	if !IsOptimizable(filepath.Ext(srcPath)) {
+		return measureImage(srcPath)
+	}
+
 	img, format, err := DecodeImage(srcPath)
 	if err != nil {
 		return nil, err
 	}
 
-	srcW := img.Bounds().Dx()
-	result := &Result{Original: srcPath}
+	bounds := img.Bounds()
+	srcW := bounds.Dx()
+	result := &Result{
+		Original: srcPath,
+		Width:    srcW,
+		Height:   bounds.Dy(),
+	}
+	result.BlurHash, result.Placeholder = Placeholder(img, opts.Placeholder)
 
-	for _, bp := range breakpoints {
+	for _, bp := range opts.Widths() {
 		if bp >= srcW {
 			continue
 		}
+		variants, err := encodeVariants(Resize(img, bp), outDir, webDir, baseName, fmt.Sprintf("-%dw", bp), format, opts)
+		if err != nil {
+			return nil, fmt.Errorf("imgopt: %s: %w", srcPath, err)
+		}
+		result.Variants = append(result.Variants, variants...)
+	}
 
-		resized := Resize(img, bp)
-		suffix := fmt.Sprintf("-%dw", bp)
-
-		// Try AVIF first (best compression).
-		avifName := baseName + suffix + ".avif"
-		avifPath := filepath.Join(outDir, avifName)
-		if err := writeEncoded(avifPath, resized, EncodeAVIF, 80); err == nil {
-			result.Variants = append(result.Variants, Variant{
-				Width:   bp,
-				Suffix:  suffix,
-				Format:  "avif",
-				OutPath: avifPath,
-				WebPath: webDir + "/" + avifName,
-			})
+	for _, d := range sizes {
+		if !d.Sized() {
+			continue
+		}
+		key := d.Key()
+		if _, done := result.Sized[key]; done {
+			continue
+		}
+		if result.Sized == nil {
+			result.Sized = map[string][]Variant{}
 		}
 
-		// Try WebP second.
-		webpName := baseName + suffix + ".webp"
-		webpPath := filepath.Join(outDir, webpName)
-		if err := writeEncoded(webpPath, resized, EncodeWebP, 80); err == nil {
-			result.Variants = append(result.Variants, Variant{
-				Width:   bp,
-				Suffix:  suffix,
-				Format:  "webp",
-				OutPath: webpPath,
-				WebPath: webDir + "/" + webpName,
-			})
+		src := img
+		width, height := d.Dimensions(srcW, bounds.Dy())
+		prefix := "-"
+		if d.Crop {
+			src = Crop(img, width, height, d.FocusX, d.FocusY)
+			prefix = "-" + key + "-"
+		}
+		cropW := src.Bounds().Dx()
+
+		sized := []Variant{}
+		for _, w := range []int{width, 2 * width} {
+			if w > cropW {
+				// A crop is always generated, at most at the size of the source
+				if !d.Crop || len(sized) > 0 {
+					break
+				}
+				w = cropW
+			}
+			suffix := fmt.Sprintf("%s%dw", prefix, w)
+			// Scaled sizes matching a breakpoint reuse its variants
+			if existing := variantsWithSuffix(result.Variants, suffix); len(existing) > 0 {
+				sized = append(sized, existing...)
+				continue
+			}
+			variants, err := encodeVariants(Resize(src, w), outDir, webDir, baseName, suffix, format, opts)
+			if err != nil {
+				return nil, fmt.Errorf("imgopt: %s: %w", srcPath, err)
+			}
+			sized = append(sized, variants...)
 		}
+		result.Sized[key] = sized
+	}
+
+	return result, nil
+}
+
+// encodeVariants encodes img to the enabled modern formats and to the
+// original format, in order of preference, and writes the files.
+func encodeVariants(img image.Image, outDir, webDir, baseName, suffix, format string, opts Options) ([]Variant, error) {
+	width := img.Bounds().Dx()
+	variants := []Variant{}
 
-		// Encode original format last (fallback).
-		origExt := "." + format
-		origName := baseName + suffix + origExt
-		origPath := filepath.Join(outDir, origName)
+	var orig bytes.Buffer
+	if err := encodeImage(&orig, img, format, opts.QualityFor(format)); err != nil {
+		return nil, err
+	}
 
-		if err := writeImage(origPath, resized, format); err != nil {
-			return nil, fmt.Errorf("imgopt: write %s: %w", origPath, err)
+	// Try AVIF first (best compression).
+	if opts.Enabled(FormatAVIF) {
+		var buf bytes.Buffer
+		if err := EncodeAVIF(&buf, img, opts.QualityFor(FormatAVIF)); err == nil {
+			v, err := writeVariant(buf.Bytes(), outDir, webDir, baseName, suffix, FormatAVIF, width)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, v)
 		}
+	}
 
-		result.Variants = append(result.Variants, Variant{
-			Width:   bp,
-			Suffix:  suffix,
-			Format:  format,
-			OutPath: origPath,
-			WebPath: webDir + "/" + origName,
-		})
+	// Try WebP second.
+	if opts.Enabled(FormatWebP) {
+		var buf bytes.Buffer
+		err := EncodeWebP(&buf, img, opts.QualityFor(FormatWebP))
+		if errors.Is(err, ErrNoEncoder) {
+			buf.Reset()
+			if err = EncodeWebPLossless(&buf, img, 0); err == nil && buf.Len() >= orig.Len() {
+				err = ErrNoEncoder
+			}
+		}
+		if err == nil {
+			v, err := writeVariant(buf.Bytes(), outDir, webDir, baseName, suffix, FormatWebP, width)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, v)
+		}
 	}
 
-	return result, nil
+	// Encode original format last (fallback).
+	v, err := writeVariant(orig.Bytes(), outDir, webDir, baseName, suffix, format, width)
+	if err != nil {
+		return nil, err
+	}
+	return append(variants, v), nil
+}
+
+// variantsWithSuffix returns the variants generated with the given suffix.
+func variantsWithSuffix(variants []Variant, suffix string) []Variant {
+	out := []Variant{}
+	for _, v := range variants {
+		if v.Suffix == suffix {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
-func writeImage(path string, img image.Image, format string) error {
-	f, err := os.Create(path)
+// measureImage returns a Result holding only the dimensions of the image.
+func measureImage(srcPath string) (*Result, error) {
+	f, err := os.Open(srcPath)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("imgopt: open %s: %w", srcPath, err)
 	}
 	defer f.Close()
 
-	switch format {
-	case "png":
-		return png.Encode(f, img)
-	case "jpeg":
-		// Use png as fallback for simplicity; JPEG encoding would need
-		// "image/jpeg" Encode which we can add later.
-		return png.Encode(f, img)
-	default:
-		return fmt.Errorf("unsupported format: %s", format)
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("imgopt: decode %s: %w", srcPath, err)
 	}
+	return &Result{Original: srcPath, Width: cfg.Width, Height: cfg.Height}, nil
 }
 
-func writeEncoded(path string, img image.Image, encode func(io.Writer, image.Image, int) error, quality int) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// writeVariant writes the encoded data of a variant and describes it.
+func writeVariant(data []byte, outDir, webDir, baseName, suffix, format string, width int) (Variant, error) {
+	name := baseName + suffix + "." + format
+	path := filepath.Join(outDir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return Variant{}, fmt.Errorf("imgopt: write %s: %w", path, err)
 	}
-	defer f.Close()
+	return Variant{
+		Width:   width,
+		Suffix:  suffix,
+		Format:  format,
+		OutPath: path,
+		WebPath: webDir + "/" + name,
+	}, nil
+}
 
-	if err := encode(f, img, quality); err != nil {
-		os.Remove(path)
-		return err
+// encodeImage encodes img in the given format ("png" or "jpeg").
+func encodeImage(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "png":
+		return png.Encode(w, img)
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
 	}
-	return nil
 }
 
 type ImageJob struct {
@@ -273,9 +500,13 @@ type ImageJob struct {
 	WebDir   string
 	BaseName string
 	WebPath  string
+	Sizes    []Directives // Sizes requested by the embeds of the image
 }
 
-func ProcessImages(jobs []ImageJob, breakpoints []int, maxWorkers int) map[string]*Result {
+// ProcessImages runs ProcessImage on every job with maxWorkers goroutines
+// and returns the results keyed by the WebPath of the job. Variants are
+// reused from cache when possible; a nil cache processes every image.
+func ProcessImages(jobs []ImageJob, opts Options, cache *Cache, maxWorkers int) map[string]*Result {
 	if maxWorkers < 1 {
 		maxWorkers = 1
 	}
@@ -294,7 +525,7 @@ func ProcessImages(jobs []ImageJob, breakpoints []int, maxWorkers int) map[strin
 		go func() {
 			defer wg.Done()
 			for job := range jobCh {
-				r, err := ProcessImage(job.SrcPath, job.OutDir, job.WebDir, job.BaseName, breakpoints)
+				r, err := cache.ProcessImage(job, opts)
 				if err != nil {
 					continue
 				}
@@ -2,13 +2,20 @@
 package imgopt
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -82,7 +89,7 @@ func TestProcessImage(t *testing.T) {
 		t.Fatalf("mkdir: %v", err)
 	}
 
-	result, err := ProcessImage(srcPath, outDir, "/images", "photo", []int{800, 400})
+	result, err := ProcessImage(srcPath, outDir, "/images", "photo", Options{Breakpoints: []int{800, 400}})
 	if err != nil {
 		t.Fatalf("ProcessImage: %v", err)
 	}
@@ -205,7 +212,7 @@ func TestProcessImages(t *testing.T) {
 		})
 	}
 
-	results := ProcessImages(jobs, DefaultBreakpoints(), 4)
+	results := ProcessImages(jobs, Options{}, nil, 4)
 	if len(results) != 4 {
 		t.Fatalf("expected 4 results, got %d", len(results))
 	}
@@ -215,3 +222,135 @@ func TestProcessImages(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessImage_JPEGVariantsAreJPEG(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "photo.jpg")
+	f, err := os.Create(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(f, newTestImage(900, 600), nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	opts := Options{Breakpoints: []int{400}, Formats: []string{}, Quality: Quality{JPEG: 60}}
+	result, err := ProcessImage(srcPath, dir, "/images", "photo", opts)
+	if err != nil {
+		t.Fatalf("ProcessImage: %v", err)
+	}
+	if len(result.Variants) != 1 {
+		t.Fatalf("expected only the original format, got %+v", result.Variants)
+	}
+	_, format, err := DecodeImage(result.Variants[0].OutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" {
+		t.Errorf("expected a JPEG encoded variant, got %s", format)
+	}
+}
+
+func TestProcessImage_Sizes(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "photo.png")
+	savePNG(t, srcPath, newTestImage(1200, 800))
+
+	scaled, _ := ParseDirectives("400")
+	cropped, _ := ParseDirectives("300x300|crop=left")
+	opts := Options{Breakpoints: []int{800}, Formats: []string{}}
+	result, err := ProcessImage(srcPath, dir, "/images", "photo", opts, scaled, cropped, scaled)
+	if err != nil {
+		t.Fatalf("ProcessImage: %v", err)
+	}
+	if result.Width != 1200 || result.Height != 800 {
+		t.Errorf("dimensions = %dx%d, want 1200x800", result.Width, result.Height)
+	}
+
+	// 400w is generated, 800w reuses the breakpoint variant
+	got := []string{}
+	for _, v := range result.Sized[scaled.Key()] {
+		got = append(got, v.Suffix)
+	}
+	if !slices.Equal(got, []string{"-400w", "-800w"}) {
+		t.Errorf("scaled suffixes = %v, want [-400w -800w]", got)
+	}
+
+	crops := result.Sized[cropped.Key()]
+	if len(crops) != 2 {
+		t.Fatalf("expected 2 cropped variants, got %+v", crops)
+	}
+	img, _, err := DecodeImage(crops[1].OutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 600 || b.Dy() != 600 {
+		t.Errorf("cropped 2x variant is %dx%d, want 600x600", b.Dx(), b.Dy())
+	}
+	if !strings.Contains(crops[0].WebPath, "-300x300c0-50-300w") {
+		t.Errorf("unexpected cropped WebPath %s", crops[0].WebPath)
+	}
+}
+
+func TestProcessImage_MeasuresOtherFormats(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "anim.gif")
+	f, err := os.Create(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(f, newTestImage(120, 80), nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	result, err := ProcessImage(srcPath, dir, "/images", "anim", Options{})
+	if err != nil {
+		t.Fatalf("ProcessImage: %v", err)
+	}
+	if result.Width != 120 || result.Height != 80 || len(result.Variants) != 0 {
+		t.Errorf("expected only the dimensions, got %+v", result)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	opts := Options{Breakpoints: []int{400, 2000, 1000}, MaxWidth: 1500}
+	if got := opts.Widths(); !slices.Equal(got, []int{1500, 1000, 400}) {
+		t.Errorf("Widths() = %v", got)
+	}
+	if got := (Options{}).Widths(); !slices.Equal(got, DefaultBreakpoints()) {
+		t.Errorf("default Widths() = %v", got)
+	}
+
+	if !(Options{}).Enabled(FormatAVIF) {
+		t.Error("formats should be enabled by default")
+	}
+	webpOnly := Options{Formats: []string{"WebP"}}
+	if webpOnly.Enabled(FormatAVIF) || !webpOnly.Enabled(FormatWebP) {
+		t.Errorf("unexpected enabled formats for %v", webpOnly.Formats)
+	}
+
+	q := Options{Quality: Quality{JPEG: 90, WebP: 0, AVIF: 150}}
+	if q.QualityFor("jpeg") != 90 || q.QualityFor(FormatWebP) != DefaultQuality || q.QualityFor(FormatAVIF) != DefaultQuality {
+		t.Errorf("unexpected qualities %+v", q.Quality)
+	}
+}
+
+func TestReportEncoders_Once(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	reported = sync.Map{}
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	ReportEncoders(Options{}, log)
+	ReportEncoders(Options{}, log)
+
+	out := buf.String()
+	if strings.Count(out, "avifenc not found") != 1 || strings.Count(out, "cwebp not found") != 1 {
+		t.Errorf("expected each missing encoder reported once, got %q", out)
+	}
+	if !strings.Contains(out, "skipped for images it would make larger") {
+		t.Errorf("expected the warning to tell lossless WebP variants may be skipped, got %q", out)
+	}
+}
@@ -0,0 +1,126 @@
+// @feature:imgopt Lossless removal of EXIF, XMP and text metadata from copied images.
+package imgopt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errMalformed = errors.New("imgopt: malformed image")
+
+// CopyStripped copies the JPEG or PNG image at src to dst without its
+// metadata. The image data is left untouched. Other files, and images that
+// cannot be parsed, are copied as they are.
+//
+// JPEG files lose their EXIF (including the orientation tag), XMP, IPTC and
+// comment segments. PNG files lose their text, EXIF and time chunks. Color
+// profiles are kept.
+func CopyStripped(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("imgopt: read %s: %w", src, err)
+	}
+
+	var stripped []byte
+	switch strings.ToLower(filepath.Ext(src)) {
+	case ".jpg", ".jpeg":
+		stripped, err = stripJPEG(data)
+	case ".png":
+		stripped, err = stripPNG(data)
+	default:
+		stripped = data
+	}
+	if err != nil {
+		stripped = data
+	}
+
+	if err := os.WriteFile(dst, stripped, 0o644); err != nil {
+		return fmt.Errorf("imgopt: write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// stripJPEG drops the APP1 (EXIF, XMP), APP13 (IPTC), other unknown APPn
+// and COM segments, keeping JFIF (APP0), ICC profiles (APP2) and Adobe
+// (APP14) markers.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xff {
+			return nil, errMalformed
+		}
+		// Fill bytes before a marker
+		if data[i+1] == 0xff {
+			i++
+			continue
+		}
+		marker := data[i+1]
+		// Start of scan: the rest is entropy-coded data
+		if marker == 0xda {
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errMalformed
+		}
+
+		keep := true
+		switch {
+		case marker == 0xfe: // COM
+			keep = false
+		case marker >= 0xe1 && marker <= 0xef:
+			keep = marker == 0xe2 || marker == 0xee
+		}
+		if keep {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+}
+
+// pngDroppedChunks are the metadata chunks removed from PNG files.
+var pngDroppedChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// stripPNG drops the text, EXIF and time chunks of a PNG file.
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errMalformed
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.WriteString(signature)
+
+	for i := len(signature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length // Length, type, data and CRC
+		if length < 0 || end > len(data) {
+			return nil, errMalformed
+		}
+		if !pngDroppedChunks[string(data[i+4:i+8])] {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
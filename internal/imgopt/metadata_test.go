@@ -0,0 +1,82 @@
+// @feature:imgopt
+package imgopt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// jpegSegment returns a JPEG marker segment with the given payload.
+func jpegSegment(marker byte, payload string) []byte {
+	seg := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// pngChunk returns a PNG chunk with a valid CRC.
+func pngChunk(typ, payload string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(typ+payload)))
+}
+
+func TestCopyStripped_JPEG(t *testing.T) {
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, newTestImage(16, 16), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Insert EXIF, ICC and comment segments right after SOI
+	data := append([]byte{}, enc.Bytes()[:2]...)
+	data = append(data, jpegSegment(0xe1, "Exif\x00\x00GPS")...)
+	data = append(data, jpegSegment(0xe2, "ICC_PROFILE\x00")...)
+	data = append(data, jpegSegment(0xfe, "secret comment")...)
+	data = append(data, enc.Bytes()[2:]...)
+
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "in.jpg"), filepath.Join(dir, "out.jpg")
+	os.WriteFile(src, data, 0o644)
+	if err := CopyStripped(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := os.ReadFile(dst)
+	if bytes.Contains(out, []byte("GPS")) || bytes.Contains(out, []byte("secret")) {
+		t.Error("EXIF and comments should be removed")
+	}
+	if !bytes.Contains(out, []byte("ICC_PROFILE")) {
+		t.Error("color profiles should be kept")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped JPEG should decode: %v", err)
+	}
+}
+
+func TestCopyStripped_PNG(t *testing.T) {
+	var enc bytes.Buffer
+	if err := png.Encode(&enc, newTestImage(16, 16)); err != nil {
+		t.Fatal(err)
+	}
+	// Insert a text chunk after IHDR (signature 8 bytes + IHDR 25 bytes)
+	data := append([]byte{}, enc.Bytes()[:33]...)
+	data = append(data, pngChunk("tEXt", "Author\x00Jane")...)
+	data = append(data, enc.Bytes()[33:]...)
+
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.png")
+	os.WriteFile(src, data, 0o644)
+	if err := CopyStripped(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := os.ReadFile(dst)
+	if !bytes.Equal(out, enc.Bytes()) {
+		t.Error("expected the text chunk to be removed and the rest untouched")
+	}
+}
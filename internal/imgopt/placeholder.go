@@ -0,0 +1,204 @@
+// @feature:imgopt Low quality placeholders and BlurHash strings shown while images load.
+package imgopt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Placeholder kinds of Options.Placeholder
+const (
+	PlaceholderNone     = ""
+	PlaceholderLQIP     = "lqip"
+	PlaceholderBlurHash = "blurhash"
+)
+
+const (
+	// placeholderWidth is the width of the inlined placeholder images.
+	placeholderWidth = 16
+	// blurHashSampleWidth is the width the image is reduced to before hashing.
+	blurHashSampleWidth = 64
+	// Number of BlurHash components on each axis
+	blurHashX, blurHashY = 4, 3
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Placeholder returns the BlurHash of img, when requested, and a small PNG
+// data URI to show while the image loads. Images with transparency get no
+// placeholder, as it would show through once loaded.
+func Placeholder(img image.Image, kind string) (hash, dataURI string) {
+	if kind != PlaceholderLQIP && kind != PlaceholderBlurHash {
+		return "", ""
+	}
+	if o, ok := img.(interface{ Opaque() bool }); ok && !o.Opaque() {
+		return "", ""
+	}
+
+	small := Resize(img, placeholderWidth)
+	if kind == PlaceholderBlurHash {
+		hash = BlurHash(Resize(img, blurHashSampleWidth), blurHashX, blurHashY)
+		b := small.Bounds()
+		small = DecodeBlurHash(hash, b.Dx(), b.Dy())
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, small); err != nil {
+		return hash, ""
+	}
+	return hash, "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// BlurHash encodes img with the given number of components on each axis
+// (from 1 to 9), following https://blurha.sh.
+func BlurHash(img image.Image, xComponents, yComponents int) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := range yComponents {
+		for i := range xComponents {
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			var f [3]float64
+			for y := range h {
+				for x := range w {
+					basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(w)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(h))
+					c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+					f[0] += basis * srgbToLinear(c.R)
+					f[1] += basis * srgbToLinear(c.G)
+					f[2] += basis * srgbToLinear(c.B)
+				}
+			}
+			scale := norm / float64(w*h)
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(encode83((xComponents-1)+(yComponents-1)*9, 1))
+
+	maxValue := 1.0
+	if len(factors) > 1 {
+		actualMax := 0.0
+		for _, f := range factors[1:] {
+			actualMax = max(actualMax, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantisedMax := int(max(0, min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		sb.WriteString(encode83(quantisedMax, 1))
+	} else {
+		sb.WriteString(encode83(0, 1))
+	}
+
+	dc := factors[0]
+	sb.WriteString(encode83(linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4))
+	for _, f := range factors[1:] {
+		q := func(v float64) int {
+			return int(max(0, min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		sb.WriteString(encode83(q(f[0])*19*19+q(f[1])*19+q(f[2]), 2))
+	}
+	return sb.String()
+}
+
+// DecodeBlurHash renders a BlurHash string as a width x height image. An
+// invalid hash renders as a gray image.
+func DecodeBlurHash(hash string, width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if len(hash) < 6 {
+		return fill(img, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	}
+	size := decode83(hash[:1])
+	nx, ny := size%9+1, size/9+1
+	if len(hash) != 4+2*nx*ny {
+		return fill(img, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	}
+	maxValue := float64(decode83(hash[1:2])+1) / 166
+
+	colors := make([][3]float64, nx*ny)
+	dc := decode83(hash[2:6])
+	colors[0] = [3]float64{srgbToLinear(uint8(dc >> 16)), srgbToLinear(uint8(dc >> 8)), srgbToLinear(uint8(dc))}
+	for i := 1; i < len(colors); i++ {
+		v := decode83(hash[4+2*i : 6+2*i])
+		colors[i] = [3]float64{
+			signPow(float64(v/(19*19)-9)/9, 2) * maxValue,
+			signPow(float64(v/19%19-9)/9, 2) * maxValue,
+			signPow(float64(v%19-9)/9, 2) * maxValue,
+		}
+	}
+
+	for y := range height {
+		for x := range width {
+			var c [3]float64
+			for j := range ny {
+				for i := range nx {
+					basis := math.Cos(math.Pi*float64(x)*float64(i)/float64(width)) *
+						math.Cos(math.Pi*float64(y)*float64(j)/float64(height))
+					for k := range 3 {
+						c[k] += colors[i+j*nx][k] * basis
+					}
+				}
+			}
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(linearToSRGB(c[0])),
+				G: uint8(linearToSRGB(c[1])),
+				B: uint8(linearToSRGB(c[2])),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+func fill(img *image.NRGBA, c color.NRGBA) image.Image {
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func encode83(v, length int) string {
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = base83Chars[v%83]
+		v /= 83
+	}
+	return string(out)
+}
+
+func decode83(s string) int {
+	v := 0
+	for i := 0; i < len(s); i++ {
+		v = v*83 + max(0, strings.IndexByte(base83Chars, s[i]))
+	}
+	return v
+}
+
+func srgbToLinear(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = max(0, min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
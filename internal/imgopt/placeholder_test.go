@@ -0,0 +1,56 @@
+// @feature:imgopt
+package imgopt
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestBlurHash_RoundTrip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 200, 40, 40, 255
+	}
+
+	hash := BlurHash(img, 4, 3)
+	if len(hash) != 4+2*4*3 {
+		t.Fatalf("hash %q has length %d, want %d", hash, len(hash), 4+2*4*3)
+	}
+
+	c := color.NRGBAModel.Convert(DecodeBlurHash(hash, 8, 8).At(4, 4)).(color.NRGBA)
+	if diff(c.R, 200) > 4 || diff(c.G, 40) > 4 || diff(c.B, 40) > 4 {
+		t.Errorf("decoded color %v, want close to {200 40 40}", c)
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	img := newTestImage(320, 240)
+
+	if hash, uri := Placeholder(img, PlaceholderNone); hash != "" || uri != "" {
+		t.Errorf("no placeholder expected, got %q %q", hash, uri)
+	}
+
+	hash, uri := Placeholder(img, PlaceholderLQIP)
+	if hash != "" || !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Errorf("lqip = %q %q", hash, uri)
+	}
+
+	hash, uri = Placeholder(img, PlaceholderBlurHash)
+	if hash == "" || !strings.HasPrefix(uri, "data:image/png;base64,") {
+		t.Errorf("blurhash = %q %q", hash, uri)
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	if hash, uri := Placeholder(transparent, PlaceholderBlurHash); hash != "" || uri != "" {
+		t.Error("transparent images should get no placeholder")
+	}
+}
+
+func diff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,640 @@
+// @feature:imgopt Pure-Go lossless WebP encoder, used when cwebp is not installed.
+package imgopt
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"slices"
+)
+
+// The encoder writes the VP8L bitstream described in
+// https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification
+// with the subtract green and predictor transforms and LZ77 backward
+// references. It favors simplicity and deterministic output over ratio.
+
+const (
+	vp8lMaxSize       = 1 << 14
+	vp8lPredictorBits = 4 // Predictor tiles of 16x16 pixels
+	vp8lMinMatch      = 3
+	vp8lMaxMatch      = 4096
+	vp8lMaxDistance   = 1<<20 - 120
+	vp8lHashBits      = 16
+
+	nLiteralCodes  = 256
+	nLengthCodes   = 24
+	nDistanceCodes = 40
+)
+
+// vp8lCodeLengthOrder is the order in which the code length code lengths are written.
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// vp8lPredictors are the predictor modes tried for every tile.
+var vp8lPredictors = []uint8{1, 2, 7, 11, 12, 13}
+
+// EncodeWebPLossless encodes img as a lossless WebP image without any
+// external tool. The quality argument is ignored.
+func EncodeWebPLossless(w io.Writer, img image.Image, _ int) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxSize || height > vp8lMaxSize {
+		return errors.New("imgopt: webp: image size out of range")
+	}
+
+	pix, alpha := nrgbaPixels(img)
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(boolBit(alpha), 1)
+	bw.write(0, 3) // Version
+
+	// Subtract green transform
+	bw.write(1, 1)
+	bw.write(2, 2)
+	subtractGreen(pix)
+
+	// Predictor transform
+	bw.write(1, 1)
+	bw.write(0, 2)
+	bw.write(vp8lPredictorBits-2, 3)
+	modes := choosePredictors(pix, width, height)
+	tiles := tileCount(width)
+	modePix := make([]uint8, 0, 4*len(modes))
+	for _, m := range modes {
+		modePix = append(modePix, 0, m, 0, 0xff)
+	}
+	writeEntropyImage(bw, modePix, tiles, false)
+	residuals := applyPredictors(pix, width, height, modes)
+
+	bw.write(0, 1) // No more transforms
+	writeEntropyImage(bw, residuals, width, true)
+
+	data := bw.bytes()
+	size := len(data)
+	pad := size & 1
+
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+size+pad))
+	copy(header[8:], "WEBP")
+	copy(header[12:], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(size))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if pad == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// nrgbaPixels returns the non-premultiplied RGBA bytes of img, and whether
+// any pixel is not fully opaque.
+func nrgbaPixels(img image.Image) ([]uint8, bool) {
+	b := img.Bounds()
+	var pix []uint8
+	if n, ok := img.(*image.NRGBA); ok && n.Stride == 4*b.Dx() {
+		pix = slices.Clone(n.Pix[:4*b.Dx()*b.Dy()])
+	} else {
+		pix = make([]uint8, 0, 4*b.Dx()*b.Dy())
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				pix = append(pix, c.R, c.G, c.B, c.A)
+			}
+		}
+	}
+	alpha := false
+	for p := 3; p < len(pix); p += 4 {
+		if pix[p] != 0xff {
+			alpha = true
+			break
+		}
+	}
+	return pix, alpha
+}
+
+// subtractGreen removes the green value from the red and blue channels.
+func subtractGreen(pix []uint8) {
+	for p := 0; p < len(pix); p += 4 {
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+}
+
+// tileCount returns the number of predictor tiles needed to cover size pixels.
+func tileCount(size int) int {
+	return (size + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+}
+
+// predict returns the prediction of the pixel at p for the given mode,
+// where top is the offset of the pixel above.
+func predict(pix []uint8, mode uint8, p, top int) [4]uint8 {
+	var out [4]uint8
+	if mode == 11 {
+		var pl, pt int
+		for c := range 4 {
+			pl += absInt(int(pix[top-4+c]) - int(pix[top+c]))
+			pt += absInt(int(pix[top-4+c]) - int(pix[p-4+c]))
+		}
+		if pl < pt {
+			copy(out[:], pix[p-4:p])
+		} else {
+			copy(out[:], pix[top:top+4])
+		}
+		return out
+	}
+	for c := range 4 {
+		l, t, tl := pix[p-4+c], pix[top+c], pix[top-4+c]
+		switch mode {
+		case 1:
+			out[c] = l
+		case 2:
+			out[c] = t
+		case 7:
+			out[c] = avg2(l, t)
+		case 12:
+			out[c] = clampByte(int(l) + int(t) - int(tl))
+		case 13:
+			a := avg2(l, t)
+			out[c] = clampByte(int(a) + (int(a)-int(tl))/2)
+		}
+	}
+	return out
+}
+
+// choosePredictors picks, for every tile, the predictor mode producing the
+// smallest residuals.
+func choosePredictors(pix []uint8, width, height int) []uint8 {
+	tilesX, tilesY := tileCount(width), tileCount(height)
+	modes := make([]uint8, tilesX*tilesY)
+	for ty := range tilesY {
+		for tx := range tilesX {
+			best, bestCost := vp8lPredictors[0], -1
+			for _, mode := range vp8lPredictors {
+				cost := 0
+				for y := max(ty<<vp8lPredictorBits, 1); y < min((ty+1)<<vp8lPredictorBits, height); y++ {
+					for x := max(tx<<vp8lPredictorBits, 1); x < min((tx+1)<<vp8lPredictorBits, width); x++ {
+						p := 4 * (y*width + x)
+						pred := predict(pix, mode, p, p-4*width)
+						for c := range 4 {
+							cost += absInt(int(int8(pix[p+c] - pred[c])))
+						}
+					}
+				}
+				if bestCost == -1 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[ty*tilesX+tx] = best
+		}
+	}
+	return modes
+}
+
+// applyPredictors returns the residuals of pix. The first pixel is predicted
+// as opaque black, the first row from the left and the first column from the
+// top, as required by the format.
+func applyPredictors(pix []uint8, width, height int, modes []uint8) []uint8 {
+	out := make([]uint8, len(pix))
+	tilesX := tileCount(width)
+	for y := range height {
+		for x := range width {
+			p := 4 * (y*width + x)
+			var pred [4]uint8
+			switch {
+			case x == 0 && y == 0:
+				pred[3] = 0xff
+			case y == 0:
+				copy(pred[:], pix[p-4:p])
+			case x == 0:
+				copy(pred[:], pix[p-4*width:p-4*width+4])
+			default:
+				mode := modes[(y>>vp8lPredictorBits)*tilesX+x>>vp8lPredictorBits]
+				pred = predict(pix, mode, p, p-4*width)
+			}
+			for c := range 4 {
+				out[p+c] = pix[p+c] - pred[c]
+			}
+		}
+	}
+	return out
+}
+
+// token is a literal pixel or a backward reference.
+type token struct {
+	pixel  [4]uint8 // RGBA literal, when length is 0
+	length int
+	dist   int
+}
+
+// tokenize splits pix into literals and backward references, looking for
+// matches with the previous pixel, the pixel above and the last pixel with
+// the same hash.
+func tokenize(pix []uint8, width int) []token {
+	n := len(pix) / 4
+	at := func(i int) uint32 {
+		return uint32(pix[4*i]) | uint32(pix[4*i+1])<<8 | uint32(pix[4*i+2])<<16 | uint32(pix[4*i+3])<<24
+	}
+	hash := func(i int) uint32 {
+		return ((at(i)*0x1e35a7bd ^ at(i+1)) * 0x1e35a7bd) >> (32 - vp8lHashBits)
+	}
+	matchLen := func(i, dist int) int {
+		l := 0
+		for i+l < n && l < vp8lMaxMatch && at(i+l) == at(i+l-dist) {
+			l++
+		}
+		return l
+	}
+
+	last := make([]int32, 1<<vp8lHashBits)
+	for i := range last {
+		last[i] = -1
+	}
+	tokens := []token{}
+	for i := 0; i < n; {
+		bestLen, bestDist := 0, 0
+		candidates := [3]int{1, width, 0}
+		if i+1 < n {
+			if j := last[hash(i)]; j >= 0 {
+				candidates[2] = i - int(j)
+			}
+		}
+		for _, dist := range candidates {
+			if dist <= 0 || dist > i || dist > vp8lMaxDistance {
+				continue
+			}
+			if l := matchLen(i, dist); l > bestLen {
+				bestLen, bestDist = l, dist
+			}
+		}
+
+		step := 1
+		if bestLen >= vp8lMinMatch {
+			tokens = append(tokens, token{length: bestLen, dist: bestDist})
+			step = bestLen
+		} else {
+			p := 4 * i
+			tokens = append(tokens, token{pixel: [4]uint8{pix[p], pix[p+1], pix[p+2], pix[p+3]}})
+		}
+		for k := i; k < i+step && k+1 < n; k++ {
+			last[hash(k)] = int32(k)
+		}
+		i += step
+	}
+	return tokens
+}
+
+// prefixCode returns the prefix symbol, the number of extra bits and the
+// extra bits value for an LZ77 length or distance code.
+func prefixCode(v int) (symbol int, nBits uint, bits uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	h := 0
+	for d>>(h+1) != 0 {
+		h++
+	}
+	second := (d >> (h - 1)) & 1
+	nBits = uint(h - 1)
+	return 2*h + second, nBits, uint32(d) & (1<<nBits - 1)
+}
+
+// writeEntropyImage writes pix (RGBA bytes) as an entropy-coded image with
+// a single set of prefix codes and no color cache.
+func writeEntropyImage(bw *bitWriter, pix []uint8, width int, topLevel bool) {
+	bw.write(0, 1) // No color cache
+	if topLevel {
+		bw.write(0, 1) // No meta prefix codes
+	}
+
+	tokens := tokenize(pix, width)
+	green := make([]uint32, nLiteralCodes+nLengthCodes)
+	red := make([]uint32, nLiteralCodes)
+	blue := make([]uint32, nLiteralCodes)
+	alpha := make([]uint32, nLiteralCodes)
+	dist := make([]uint32, nDistanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			red[t.pixel[0]]++
+			green[t.pixel[1]]++
+			blue[t.pixel[2]]++
+			alpha[t.pixel[3]]++
+			continue
+		}
+		ls, _, _ := prefixCode(t.length)
+		ds, _, _ := prefixCode(t.dist + 120)
+		green[nLiteralCodes+ls]++
+		dist[ds]++
+	}
+
+	codes := [5]*prefixTable{}
+	for i, freq := range [][]uint32{green, red, blue, alpha, dist} {
+		codes[i] = writePrefixCode(bw, freq)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].write(bw, int(t.pixel[1]))
+			codes[1].write(bw, int(t.pixel[0]))
+			codes[2].write(bw, int(t.pixel[2]))
+			codes[3].write(bw, int(t.pixel[3]))
+			continue
+		}
+		ls, lBits, lExtra := prefixCode(t.length)
+		codes[0].write(bw, nLiteralCodes+ls)
+		bw.write(lExtra, lBits)
+		ds, dBits, dExtra := prefixCode(t.dist + 120)
+		codes[4].write(bw, ds)
+		bw.write(dExtra, dBits)
+	}
+}
+
+// prefixTable holds the bit-reversed canonical codes of an alphabet.
+type prefixTable struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+func (t *prefixTable) write(bw *bitWriter, symbol int) {
+	bw.write(t.codes[symbol], uint(t.lengths[symbol]))
+}
+
+// writePrefixCode writes the prefix code built from the symbol frequencies
+// and returns it.
+func writePrefixCode(bw *bitWriter, freq []uint32) *prefixTable {
+	used := []int{}
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, s)
+		}
+	}
+
+	// Simple code: zero or one symbol, coded with zero bits
+	if len(used) <= 1 && (len(used) == 0 || used[0] < 256) {
+		symbol := 0
+		if len(used) == 1 {
+			symbol = used[0]
+		}
+		bw.write(1, 1)
+		bw.write(0, 1) // One symbol
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+		return newPrefixTable(make([]uint8, len(freq)))
+	}
+
+	lengths := huffmanLengths(freq, 15)
+	bw.write(0, 1)
+
+	// Run-length encode the code lengths, as in deflate
+	type clToken struct {
+		symbol int
+		extra  uint32
+		nBits  uint
+	}
+	clTokens := []clToken{}
+	for i := 0; i < len(lengths); {
+		v := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == v {
+			run++
+		}
+		i += run
+		if v == 0 {
+			for run >= 11 {
+				r := min(run, 138)
+				clTokens = append(clTokens, clToken{18, uint32(r - 11), 7})
+				run -= r
+			}
+			if run >= 3 {
+				clTokens = append(clTokens, clToken{17, uint32(run - 3), 3})
+				run = 0
+			}
+		} else {
+			clTokens = append(clTokens, clToken{int(v), 0, 0})
+			run--
+			for run >= 3 {
+				r := min(run, 6)
+				clTokens = append(clTokens, clToken{16, uint32(r - 3), 2})
+				run -= r
+			}
+		}
+		for ; run > 0; run-- {
+			clTokens = append(clTokens, clToken{int(v), 0, 0})
+		}
+	}
+
+	clFreq := make([]uint32, 19)
+	for _, t := range clTokens {
+		clFreq[t.symbol]++
+	}
+	// Keep the code length code a complete tree of at least two symbols
+	if nonZero(clFreq) < 2 {
+		if clFreq[0] == 0 {
+			clFreq[0] = 1
+		} else {
+			clFreq[1] = 1
+		}
+	}
+	clLengths := huffmanLengths(clFreq, 7)
+	n := len(vp8lCodeLengthOrder)
+	for n > 4 && clLengths[vp8lCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	bw.write(uint32(n-4), 4)
+	for _, s := range vp8lCodeLengthOrder[:n] {
+		bw.write(uint32(clLengths[s]), 3)
+	}
+	bw.write(0, 1) // Code lengths for the whole alphabet
+
+	clTable := newPrefixTable(clLengths)
+	for _, t := range clTokens {
+		clTable.write(bw, t.symbol)
+		bw.write(t.extra, t.nBits)
+	}
+	// A single used symbol is read with zero bits
+	if len(used) == 1 {
+		return newPrefixTable(make([]uint8, len(freq)))
+	}
+	return newPrefixTable(lengths)
+}
+
+// newPrefixTable computes the canonical codes of the given code lengths.
+func newPrefixTable(lengths []uint8) *prefixTable {
+	var count [16]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [16]uint32
+	code := uint32(0)
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	t := &prefixTable{codes: make([]uint32, len(lengths)), lengths: lengths}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		// Codes are written most significant bit first
+		rev := uint32(0)
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | (c>>i)&1
+		}
+		t.codes[s] = rev
+	}
+	return t
+}
+
+// huffmanLengths returns Huffman code lengths for the given frequencies,
+// limited to maxBits. Frequencies are flattened until the limit holds.
+func huffmanLengths(freq []uint32, maxBits int) []uint8 {
+	freq = slices.Clone(freq)
+	for {
+		lengths := buildHuffman(freq)
+		if slices.Max(lengths) <= uint8(maxBits) {
+			return lengths
+		}
+		for i, f := range freq {
+			if f > 1 {
+				freq[i] = (f + 1) / 2
+			}
+		}
+	}
+}
+
+// buildHuffman returns the code lengths of a Huffman tree over the symbols
+// with a non-zero frequency. Ties are broken by symbol for deterministic output.
+func buildHuffman(freq []uint32) []uint8 {
+	type node struct {
+		weight uint64
+		parent int
+		order  int
+	}
+	nodes := []node{}
+	leaves := []int{}
+	for s, f := range freq {
+		if f > 0 {
+			nodes = append(nodes, node{weight: uint64(f), parent: -1, order: s})
+			leaves = append(leaves, s)
+		}
+	}
+	lengths := make([]uint8, len(freq))
+	if len(nodes) == 1 {
+		lengths[leaves[0]] = 1
+		return lengths
+	}
+
+	// Two queues: sorted leaves and internal nodes, created in increasing weight
+	queue := make([]int, len(nodes))
+	for i := range queue {
+		queue[i] = i
+	}
+	slices.SortStableFunc(queue, func(a, b int) int {
+		if nodes[a].weight != nodes[b].weight {
+			if nodes[a].weight < nodes[b].weight {
+				return -1
+			}
+			return 1
+		}
+		return nodes[a].order - nodes[b].order
+	})
+	internal := []int{}
+	pop := func() int {
+		if len(internal) == 0 || (len(queue) > 0 && nodes[queue[0]].weight <= nodes[internal[0]].weight) {
+			i := queue[0]
+			queue = queue[1:]
+			return i
+		}
+		i := internal[0]
+		internal = internal[1:]
+		return i
+	}
+	for len(queue)+len(internal) > 1 {
+		a, b := pop(), pop()
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+		parent := len(nodes) - 1
+		nodes[a].parent, nodes[b].parent = parent, parent
+		internal = append(internal, parent)
+	}
+
+	for i, s := range leaves {
+		depth := uint8(0)
+		for p := nodes[i].parent; p != -1; p = nodes[p].parent {
+			depth++
+		}
+		lengths[s] = depth
+	}
+	return lengths
+}
+
+// bitWriter packs bits least significant bit first.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (b *bitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v) << b.nBits
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nBits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nBits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nBits = 0, 0
+	}
+	return b.buf
+}
+
+func nonZero(freq []uint32) int {
+	n := 0
+	for _, f := range freq {
+		if f > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func boolBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func avg2(a, b uint8) uint8 {
+	return uint8((int(a) + int(b)) / 2)
+}
+
+func clampByte(v int) uint8 {
+	return uint8(max(0, min(255, v)))
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
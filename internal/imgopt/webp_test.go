@@ -0,0 +1,74 @@
+// @feature:imgopt
+package imgopt
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebPLossless_RoundTrip(t *testing.T) {
+	gradient := newTestImage(97, 61)
+
+	flat := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for i := range flat.Pix {
+		flat.Pix[i] = 200
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+	for y := range 17 {
+		for x := range 33 {
+			transparent.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x ^ y), A: uint8(x * y)})
+		}
+	}
+
+	for name, img := range map[string]image.Image{
+		"gradient":    gradient,
+		"flat":        flat,
+		"transparent": transparent,
+		"single":      image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeWebPLossless(&buf, img, 0); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			b := img.Bounds()
+			if got.Bounds().Dx() != b.Dx() || got.Bounds().Dy() != b.Dy() {
+				t.Fatalf("size %v, want %v", got.Bounds(), b)
+			}
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					want := color.NRGBAModel.Convert(img.At(x, y))
+					if have := color.NRGBAModel.Convert(got.At(x, y)); have != want {
+						t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, have, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPLossless_Deterministic(t *testing.T) {
+	img := newTestImage(120, 80)
+	var a, b bytes.Buffer
+	if err := EncodeWebPLossless(&a, img, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeWebPLossless(&b, img, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("encoding the same image twice should produce the same bytes")
+	}
+	if a.Len() >= 120*80*4 {
+		t.Errorf("expected some compression, got %d bytes", a.Len())
+	}
+}
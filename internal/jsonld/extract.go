@@ -0,0 +1,113 @@
+// @feature:jsonld Extraction of questions and steps from the markdown of a note.
+package jsonld
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	calloutStartRegex = regexp.MustCompile(`^>\s*\[!([\w-]+)\][+-]?\s*(.*)$`)
+	orderedItemRegex  = regexp.MustCompile(`^\d+[.)]\s+(.+)$`)
+	wikilinkTextRegex = regexp.MustCompile(`!?\[\[([^\]|]*)(?:\|([^\]]*))?\]\]`)
+	mdLinkTextRegex   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	emphasisReplacer  = strings.NewReplacer("**", "", "__", "", "==", "", "`", "")
+)
+
+// ExtractFAQ returns the questions and answers of a note, written as
+// callouts of the given type whose title is the question:
+//
+//	> [!faq] How do I install Kiln?
+//	> Download the binary from the releases page.
+//
+// Callouts without a title or an answer are skipped.
+func ExtractFAQ(markdown []byte, calloutType string) []FAQItem {
+	items := []FAQItem{}
+	var current *FAQItem
+	var answer []string
+	flush := func() {
+		if current != nil && len(answer) > 0 {
+			current.Answer = strings.Join(answer, " ")
+			items = append(items, *current)
+		}
+		current, answer = nil, nil
+	}
+
+	inFence := false
+	scanner := bufio.NewScanner(bytes.NewReader(markdown))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+		}
+		if inFence {
+			flush()
+			continue
+		}
+		if m := calloutStartRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			if strings.EqualFold(m[1], calloutType) && strings.TrimSpace(m[2]) != "" {
+				current = &FAQItem{Question: PlainText(m[2])}
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if !strings.HasPrefix(line, ">") {
+			flush()
+			continue
+		}
+		if text := PlainText(strings.TrimSpace(strings.TrimPrefix(line, ">"))); text != "" {
+			answer = append(answer, text)
+		}
+	}
+	flush()
+	return items
+}
+
+// ExtractSteps returns the items of the first top-level ordered list of a
+// note, used as the steps of a HowTo.
+func ExtractSteps(markdown []byte) []string {
+	steps := []string{}
+	inFence := false
+	scanner := bufio.NewScanner(bytes.NewReader(markdown))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		// Only items starting at the margin belong to the top-level list
+		if m := orderedItemRegex.FindStringSubmatch(raw); m != nil {
+			steps = append(steps, PlainText(m[1]))
+			continue
+		}
+		// The list ends at the first line that is neither blank nor indented
+		if len(steps) > 0 && line != "" && raw == line {
+			break
+		}
+	}
+	return steps
+}
+
+// PlainText strips the inline markdown of a line: links keep their text and
+// emphasis markers are removed.
+func PlainText(s string) string {
+	s = wikilinkTextRegex.ReplaceAllStringFunc(s, func(m string) string {
+		parts := wikilinkTextRegex.FindStringSubmatch(m)
+		if parts[2] != "" {
+			return parts[2]
+		}
+		return parts[1]
+	})
+	s = mdLinkTextRegex.ReplaceAllString(s, "$1")
+	s = emphasisReplacer.Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
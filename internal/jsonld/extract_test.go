@@ -0,0 +1,128 @@
+// @feature:jsonld Tests for extracting questions and steps from markdown.
+package jsonld
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// decode parses a JSON-LD string into a map.
+func decode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return m
+}
+
+func TestExtractFAQ(t *testing.T) {
+	md := []byte(`# Questions
+
+> [!faq] How do I install **Kiln**?
+> Download it from the [releases page](https://example.com).
+> It is a single binary.
+
+> [!FAQ]- Does it support [[Canvas|canvases]]?
+> Yes.
+
+> [!note] Not a question
+> Ignored.
+
+> [!faq] Unanswered?
+
+` + "```" + `
+> [!faq] In a code block?
+> Ignored.
+` + "```")
+
+	got := ExtractFAQ(md, "faq")
+	want := []FAQItem{
+		{Question: "How do I install Kiln?", Answer: "Download it from the releases page. It is a single binary."},
+		{Question: "Does it support canvases?", Answer: "Yes."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractFAQ = %+v, want %+v", got, want)
+	}
+
+	if got := ExtractFAQ(md, "question"); len(got) != 0 {
+		t.Errorf("expected no items for another callout type, got %+v", got)
+	}
+}
+
+func TestExtractSteps(t *testing.T) {
+	md := []byte(`Intro paragraph.
+
+1. Install **Kiln**
+2. Run ` + "`kiln init`" + `
+   - a nested detail
+3) Run [[Generate|kiln generate]]
+
+Closing paragraph.
+
+1. A second list`)
+
+	got := ExtractSteps(md)
+	want := []string{"Install Kiln", "Run kiln init", "Run kiln generate"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractSteps = %q, want %q", got, want)
+	}
+}
+
+func TestBuildFAQJSON(t *testing.T) {
+	got := BuildFAQJSON([]FAQItem{{Question: "Why?", Answer: "Because."}})
+	m := decode(t, got)
+	if m["@type"] != "FAQPage" {
+		t.Errorf("@type = %v, want FAQPage", m["@type"])
+	}
+	questions, _ := m["mainEntity"].([]any)
+	if len(questions) != 1 {
+		t.Fatalf("mainEntity = %v", m["mainEntity"])
+	}
+	q := questions[0].(map[string]any)
+	if q["name"] != "Why?" || q["acceptedAnswer"].(map[string]any)["text"] != "Because." {
+		t.Errorf("question = %v", q)
+	}
+	if BuildFAQJSON(nil) != "" {
+		t.Error("expected empty string without items")
+	}
+}
+
+func TestBuildHowToJSON(t *testing.T) {
+	m := decode(t, BuildHowToJSON("Deploy", "How to deploy", "", []string{"Build", "Upload"}))
+	if m["@type"] != "HowTo" || m["name"] != "Deploy" {
+		t.Errorf("unexpected HowTo: %v", m)
+	}
+	steps, _ := m["step"].([]any)
+	if len(steps) != 2 || steps[1].(map[string]any)["text"] != "Upload" {
+		t.Errorf("step = %v", m["step"])
+	}
+	if BuildHowToJSON("Deploy", "", "", nil) != "" {
+		t.Error("expected empty string without steps")
+	}
+}
+
+func TestBuildCollectionPageJSON(t *testing.T) {
+	m := decode(t, BuildCollectionPageJSON("Blog", "https://example.com/blog/", []CollectionItem{
+		{Name: "First", URL: "https://example.com/blog/first"},
+	}))
+	if m["@type"] != "CollectionPage" || m["url"] != "https://example.com/blog/" {
+		t.Errorf("unexpected collection: %v", m)
+	}
+	list := m["mainEntity"].(map[string]any)
+	if list["numberOfItems"] != float64(1) {
+		t.Errorf("numberOfItems = %v", list["numberOfItems"])
+	}
+}
+
+func TestBuildWebSiteJSON(t *testing.T) {
+	m := decode(t, BuildWebSiteJSON("https://example.com", "My Site", Entity{}))
+	action := m["potentialAction"].(map[string]any)
+	if action["target"] != "https://example.com/?q={search_term_string}" {
+		t.Errorf("target = %v", action["target"])
+	}
+	if m["publisher"].(map[string]any)["name"] != "My Site" {
+		t.Errorf("publisher = %v", m["publisher"])
+	}
+}
@@ -1,8 +1,9 @@
-// @feature:jsonld JSON-LD structured data generation for Article and BreadcrumbList schemas.
+// @feature:jsonld JSON-LD structured data generation for articles, collections, the site and its breadcrumbs.
 package jsonld
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,9 @@ type ArticleParams struct {
 	DateCreated  time.Time
 	DateModified time.Time
 	ImageURL     string
+	Authors      []Entity // Take precedence over Author
+	Publisher    Entity   // Defaults to an organization named after the site
+	Keywords     []string
 }
 
 // BreadcrumbItem represents one breadcrumb for JSON-LD generation.
@@ -38,10 +42,7 @@ func BuildArticleJSON(p ArticleParams) string {
 		"headline":      p.Title,
 		"datePublished": p.DateCreated.Format(time.RFC3339),
 		"dateModified":  p.DateModified.Format(time.RFC3339),
-		"publisher": map[string]any{
-			"@type": "Organization",
-			"name":  p.SiteName,
-		},
+		"publisher":     Options{Publisher: p.Publisher}.PublisherOrDefault(p.SiteName).schema(TypeOrganization),
 		"mainEntityOfPage": map[string]any{
 			"@type": "WebPage",
 			"@id":   p.BaseURL + p.WebPath,
@@ -51,21 +52,29 @@ func BuildArticleJSON(p ArticleParams) string {
 	if p.Description != "" {
 		schema["description"] = p.Description
 	}
-	if p.Author != "" {
+	switch {
+	case len(p.Authors) == 1:
+		schema["author"] = p.Authors[0].schema(TypePerson)
+	case len(p.Authors) > 1:
+		authors := make([]map[string]any, 0, len(p.Authors))
+		for _, a := range p.Authors {
+			authors = append(authors, a.schema(TypePerson))
+		}
+		schema["author"] = authors
+	case p.Author != "":
 		schema["author"] = map[string]any{
 			"@type": "Person",
 			"name":  p.Author,
 		}
 	}
+	if len(p.Keywords) > 0 {
+		schema["keywords"] = strings.Join(p.Keywords, ", ")
+	}
 	if p.ImageURL != "" {
 		schema["image"] = p.ImageURL
 	}
 
-	data, err := json.Marshal(schema)
-	if err != nil {
-		return ""
-	}
-	return string(data)
+	return marshal(schema)
 }
 
 // BuildBreadcrumbJSON returns a JSON-LD string for schema.org/BreadcrumbList.
@@ -94,6 +103,139 @@ func BuildBreadcrumbJSON(baseURL string, items []BreadcrumbItem) string {
 		"itemListElement": elements,
 	}
 
+	return marshal(schema)
+}
+
+// BuildWebSiteJSON returns a JSON-LD string for schema.org/WebSite, with a
+// SearchAction pointing at the site search. Returns empty string if
+// siteName is empty.
+func BuildWebSiteJSON(baseURL, siteName string, publisher Entity) string {
+	if siteName == "" {
+		return ""
+	}
+	schema := map[string]any{
+		"@context":  "https://schema.org",
+		"@type":     "WebSite",
+		"name":      siteName,
+		"url":       baseURL + "/",
+		"publisher": Options{Publisher: publisher}.PublisherOrDefault(siteName).schema(TypeOrganization),
+		"potentialAction": map[string]any{
+			"@type":       "SearchAction",
+			"target":      baseURL + "/?q={search_term_string}",
+			"query-input": "required name=search_term_string",
+		},
+	}
+	return marshal(schema)
+}
+
+// BuildEntityJSON returns a JSON-LD string for schema.org/Person or
+// schema.org/Organization. Returns empty string if the entity has no name.
+func BuildEntityJSON(e Entity, defaultType string) string {
+	if e.Name == "" {
+		return ""
+	}
+	schema := e.schema(defaultType)
+	schema["@context"] = "https://schema.org"
+	return marshal(schema)
+}
+
+// FAQItem is a question and its answer.
+type FAQItem struct {
+	Question string
+	Answer   string
+}
+
+// BuildFAQJSON returns a JSON-LD string for schema.org/FAQPage.
+// Returns empty string if items is empty.
+func BuildFAQJSON(items []FAQItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	questions := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		questions = append(questions, map[string]any{
+			"@type": "Question",
+			"name":  item.Question,
+			"acceptedAnswer": map[string]any{
+				"@type": "Answer",
+				"text":  item.Answer,
+			},
+		})
+	}
+	schema := map[string]any{
+		"@context":   "https://schema.org",
+		"@type":      "FAQPage",
+		"mainEntity": questions,
+	}
+	return marshal(schema)
+}
+
+// BuildHowToJSON returns a JSON-LD string for schema.org/HowTo.
+// Returns empty string if name or steps are empty.
+func BuildHowToJSON(name, description, imageURL string, steps []string) string {
+	if name == "" || len(steps) == 0 {
+		return ""
+	}
+	elements := make([]map[string]any, 0, len(steps))
+	for i, step := range steps {
+		elements = append(elements, map[string]any{
+			"@type":    "HowToStep",
+			"position": i + 1,
+			"text":     step,
+		})
+	}
+	schema := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "HowTo",
+		"name":     name,
+		"step":     elements,
+	}
+	if description != "" {
+		schema["description"] = description
+	}
+	if imageURL != "" {
+		schema["image"] = imageURL
+	}
+	return marshal(schema)
+}
+
+// CollectionItem is a page listed by a collection.
+type CollectionItem struct {
+	Name string
+	URL  string
+}
+
+// BuildCollectionPageJSON returns a JSON-LD string for
+// schema.org/CollectionPage, listing items in an ItemList.
+// Returns empty string if name is empty.
+func BuildCollectionPageJSON(name, url string, items []CollectionItem) string {
+	if name == "" {
+		return ""
+	}
+	elements := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		elements = append(elements, map[string]any{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     item.Name,
+			"url":      item.URL,
+		})
+	}
+	schema := map[string]any{
+		"@context": "https://schema.org",
+		"@type":    "CollectionPage",
+		"name":     name,
+		"url":      url,
+		"mainEntity": map[string]any{
+			"@type":           "ItemList",
+			"numberOfItems":   len(items),
+			"itemListElement": elements,
+		},
+	}
+	return marshal(schema)
+}
+
+func marshal(schema map[string]any) string {
 	data, err := json.Marshal(schema)
 	if err != nil {
 		return ""
@@ -265,3 +265,29 @@ func TestBuildBreadcrumbJSON_HashURLOmitsItem(t *testing.T) {
 		t.Errorf("position = %v, want 3", lastItem["position"])
 	}
 }
+
+func TestBuildArticleJSON_AuthorsAndKeywords(t *testing.T) {
+	got := BuildArticleJSON(ArticleParams{
+		Title:     "Post",
+		Author:    "Ignored",
+		Authors:   []Entity{{Name: "Jane"}, {Name: "Acme", Type: "organization"}},
+		Publisher: Entity{Type: "person", Name: "Jane"},
+		Keywords:  []string{"go", "seo"},
+		SiteName:  "Site",
+	})
+	m := decode(t, got)
+
+	authors, ok := m["author"].([]any)
+	if !ok || len(authors) != 2 {
+		t.Fatalf("author = %v, want two authors", m["author"])
+	}
+	if authors[1].(map[string]any)["@type"] != "Organization" {
+		t.Errorf("second author = %v", authors[1])
+	}
+	if p := m["publisher"].(map[string]any); p["@type"] != "Person" || p["name"] != "Jane" {
+		t.Errorf("publisher = %v", p)
+	}
+	if m["keywords"] != "go, seo" {
+		t.Errorf("keywords = %v", m["keywords"])
+	}
+}
@@ -0,0 +1,105 @@
+// @feature:jsonld Site-level publisher and author registry used by the structured data.
+package jsonld
+
+import "strings"
+
+// DefaultFAQCallout is the callout type read as questions and answers.
+const DefaultFAQCallout = "faq"
+
+// Entity types
+const (
+	TypePerson       = "person"
+	TypeOrganization = "organization"
+)
+
+// Options configures the metadata of the pages. It maps to the "seo"
+// section of kiln.yaml.
+type Options struct {
+	Publisher  Entity            `yaml:"publisher"`   // Organization or person publishing the site, defaults to an organization named after the site
+	Authors    map[string]Entity `yaml:"authors"`     // Authors referenced by id in the "author" property
+	Author     string            `yaml:"author"`      // Author id of notes without an "author" property
+	FAQCallout string            `yaml:"faq-callout"` // Callout type holding questions and answers (default "faq")
+}
+
+// Entity is a person or an organization.
+type Entity struct {
+	Type   string   `yaml:"type"`    // "person" or "organization"
+	Name   string   `yaml:"name"`    // Display name
+	URL    string   `yaml:"url"`     // Home page
+	Image  string   `yaml:"image"`   // Photo of a person or logo of an organization
+	SameAs []string `yaml:"same-as"` // Profiles on other sites
+}
+
+// FAQCalloutOrDefault returns the configured FAQ callout type, lowercased.
+func (o Options) FAQCalloutOrDefault() string {
+	if o.FAQCallout == "" {
+		return DefaultFAQCallout
+	}
+	return strings.ToLower(o.FAQCallout)
+}
+
+// ResolveAuthors maps the ids of the "author" property to the entities of
+// the registry. Unknown ids are taken as the names of people. Without ids,
+// the default author is used.
+func (o Options) ResolveAuthors(ids []string) []Entity {
+	if len(ids) == 0 && o.Author != "" {
+		ids = []string{o.Author}
+	}
+	authors := make([]Entity, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		author, ok := o.Authors[id]
+		if !ok {
+			author = Entity{Name: id}
+		}
+		if author.Name == "" {
+			author.Name = id
+		}
+		authors = append(authors, author)
+	}
+	return authors
+}
+
+// PublisherOrDefault returns the configured publisher, or an organization
+// named after the site.
+func (o Options) PublisherOrDefault(siteName string) Entity {
+	p := o.Publisher
+	if p.Name == "" {
+		p.Name = siteName
+	}
+	if p.Type == "" {
+		p.Type = TypeOrganization
+	}
+	return p
+}
+
+// schema returns the schema.org object of the entity. Entities without a
+// type get defaultType.
+func (e Entity) schema(defaultType string) map[string]any {
+	kind := strings.ToLower(e.Type)
+	if kind != TypePerson && kind != TypeOrganization {
+		kind = defaultType
+	}
+	obj := map[string]any{"name": e.Name}
+	if kind == TypeOrganization {
+		obj["@type"] = "Organization"
+		if e.Image != "" {
+			obj["logo"] = map[string]any{"@type": "ImageObject", "url": e.Image}
+		}
+	} else {
+		obj["@type"] = "Person"
+		if e.Image != "" {
+			obj["image"] = e.Image
+		}
+	}
+	if e.URL != "" {
+		obj["url"] = e.URL
+	}
+	if len(e.SameAs) > 0 {
+		obj["sameAs"] = e.SameAs
+	}
+	return obj
+}
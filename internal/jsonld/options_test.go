@@ -0,0 +1,78 @@
+// @feature:jsonld Tests for the author registry and the publisher.
+package jsonld
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptions_ResolveAuthors(t *testing.T) {
+	opts := Options{
+		Authors: map[string]Entity{
+			"jane": {Name: "Jane Doe", URL: "https://janedoe.com"},
+			"acme": {Type: "organization"},
+		},
+		Author: "jane",
+	}
+
+	got := opts.ResolveAuthors([]string{"jane", "John Smith", "acme"})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 authors, got %d", len(got))
+	}
+	if got[0].Name != "Jane Doe" || got[0].URL != "https://janedoe.com" {
+		t.Errorf("registry author = %+v", got[0])
+	}
+	if got[1].Name != "John Smith" {
+		t.Errorf("unknown ids should be names, got %+v", got[1])
+	}
+	if got[2].Name != "acme" {
+		t.Errorf("authors without a name should use their id, got %+v", got[2])
+	}
+
+	if def := opts.ResolveAuthors(nil); len(def) != 1 || def[0].Name != "Jane Doe" {
+		t.Errorf("expected the default author, got %+v", def)
+	}
+	if none := (Options{}).ResolveAuthors(nil); len(none) != 0 {
+		t.Errorf("expected no authors, got %+v", none)
+	}
+}
+
+func TestOptions_PublisherOrDefault(t *testing.T) {
+	p := Options{}.PublisherOrDefault("My Site")
+	if p.Name != "My Site" || p.Type != TypeOrganization {
+		t.Errorf("default publisher = %+v", p)
+	}
+	p = Options{Publisher: Entity{Type: "person", Name: "Jane"}}.PublisherOrDefault("My Site")
+	if p.Name != "Jane" || p.Type != TypePerson {
+		t.Errorf("configured publisher = %+v", p)
+	}
+}
+
+func TestBuildEntityJSON(t *testing.T) {
+	got := BuildEntityJSON(Entity{Type: "Person", Name: "Jane", Image: "https://janedoe.com/me.jpg", SameAs: []string{"https://github.com/jane"}}, TypeOrganization)
+	var m map[string]any
+	if err := json.Unmarshal([]byte(got), &m); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if m["@type"] != "Person" || m["image"] != "https://janedoe.com/me.jpg" {
+		t.Errorf("unexpected person: %v", m)
+	}
+	if same, _ := m["sameAs"].([]any); len(same) != 1 {
+		t.Errorf("sameAs = %v", m["sameAs"])
+	}
+
+	got = BuildEntityJSON(Entity{Name: "Acme", Image: "https://acme.com/logo.png"}, TypeOrganization)
+	if err := json.Unmarshal([]byte(got), &m); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if m["@type"] != "Organization" {
+		t.Errorf("@type = %v, want Organization", m["@type"])
+	}
+	if logo, _ := m["logo"].(map[string]any); logo["url"] != "https://acme.com/logo.png" {
+		t.Errorf("logo = %v", m["logo"])
+	}
+
+	if BuildEntityJSON(Entity{}, TypePerson) != "" {
+		t.Error("expected empty string for an entity without a name")
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
 )
 
-// CollectNotes scans the input directory and creates an index of all valid files.
-func CollectNotes(inputDir string) map[string]bool {
-	validFiles := make(map[string]bool)
+// CollectNotes scans the input directory and returns every file of the vault.
+func CollectNotes(inputDir string) []*obsidian.File {
+	files := []*obsidian.File{}
 
 	filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -29,82 +31,48 @@ func CollectNotes(inputDir string) map[string]bool {
 
 		if !d.IsDir() {
 			rel, _ := filepath.Rel(inputDir, path)
-
-			// Index full relative path (e.g., "folder/note.md")
-			validFiles[rel] = true
-
-			// Index relative path without extension (e.g., "folder/note")
-			noExtRel := strings.TrimSuffix(rel, filepath.Ext(rel))
-			validFiles[noExtRel] = true
-
-			// Index the filename itself (e.g., "note.md")
-			// This allows [[note.md]] to match, even if it's inside a folder.
-			validFiles[d.Name()] = true
-
-			// Index the filename without extension (e.g., "note")
-			// This allows [[note]] to match, even if it's inside a folder.
-			if filepath.Ext(d.Name()) == ".md" || filepath.Ext(d.Name()) == ".canvas" {
-				noExtName := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
-				validFiles[noExtName] = true
-			}
+			name, ext := obsidian.SplitExt(rel)
+			files = append(files, &obsidian.File{
+				Path:     path,
+				RelPath:  rel,
+				Ext:      ext,
+				Name:     name,
+				FullName: d.Name(),
+			})
 		}
 		return nil
 	})
-	return validFiles
-}
-
-func checkCandidates(rawLink string, notes map[string]bool) bool {
-	candidates := []string{
-		rawLink,
-		rawLink + ".md",
-		rawLink + ".png",
-		rawLink + ".jpg",
-		rawLink + ".jpeg",
-		rawLink + ".canvas",
-	}
-	for _, c := range candidates {
-		if notes[c] {
-			return true
-		}
-	}
-	return false
+	return files
 }
 
 // BrokenLinks iterates through all Markdown files in the directory and validates their links.
-func BrokenLinks(inputDir string, notes map[string]bool, log *slog.Logger) {
+// Links are resolved with the same rules as the site generator, so ambiguous
+// links are reported too.
+func BrokenLinks(inputDir string, notes []*obsidian.File, log *slog.Logger) {
 	wikiLinkRegex := regexp.MustCompile(`\[\[(.*?)\]\]`)
 	mdLinkRegex := regexp.MustCompile(`\[([^\]]*)\]\(([^)]+)\)`)
 	issuesFound := 0
+	links := obsidian.NewLinkIndex(notes, log)
 
-	filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
-		// Skip hidden folders
-		if strings.HasPrefix(d.Name(), ".") && d.IsDir() {
-			return filepath.SkipDir
-		}
-
+	for _, note := range notes {
 		// Only scan .md files for links
-		if err != nil || d.IsDir() || filepath.Ext(path) != ".md" {
-			return nil
+		if note.Ext != ".md" {
+			continue
 		}
 
-		content, _ := os.ReadFile(path)
-		relPath, _ := filepath.Rel(inputDir, path)
+		content, _ := os.ReadFile(note.Path)
+		relPath := note.RelPath
 
 		// Check wikilinks
 		for _, match := range wikiLinkRegex.FindAllStringSubmatch(string(content), -1) {
-			rawLink := match[1]
+			rawLink, _, _ := strings.Cut(match[1], "|")
 
-			if strings.Contains(rawLink, "|") {
-				rawLink = strings.Split(rawLink, "|")[0]
-			}
-			if strings.Contains(rawLink, "#") {
-				rawLink = strings.Split(rawLink, "#")[0]
-			}
-			if rawLink == "" {
+			// Links to a heading of the same note
+			if target, _, _ := strings.Cut(rawLink, "#"); strings.TrimSpace(target) == "" {
 				continue
 			}
 
-			if !checkCandidates(rawLink, notes) {
+			if _, _, err := links.Find(note, rawLink); err != nil {
 				log.Warn("Found broken link", "path", relPath, "link", rawLink)
 				issuesFound++
 			}
@@ -123,22 +91,12 @@ func BrokenLinks(inputDir string, notes map[string]bool, log *slog.Logger) {
 				continue
 			}
 
-			// Strip anchor
-			if idx := strings.Index(linkPath, "#"); idx != -1 {
-				linkPath = linkPath[:idx]
-			}
-
-			// Resolve relative to the directory of the current file
-			resolved := filepath.Clean(filepath.Join(filepath.Dir(relPath), linkPath))
-
-			if !checkCandidates(resolved, notes) {
+			if links.Resolve(note, match[0]) == nil {
 				log.Warn("Found broken link", "path", relPath, "link", linkPath)
 				issuesFound++
 			}
 		}
-
-		return nil
-	})
+	}
 
 	if issuesFound == 0 {
 		log.Info("No broken links found")
@@ -156,3 +156,55 @@ func TestMixedWikiAndMarkdownLinks(t *testing.T) {
 		t.Errorf("expected warning to mention nonexistent.md, got: %s", out)
 	}
 }
+
+func TestWikilinkCaseInsensitiveAndAttachments(t *testing.T) {
+	dir := setupVault(t, map[string]string{
+		"note.md":            `[[TARGET]] [[images/Photo.PNG]] [[photo.png|300]]`,
+		"target.md":          `hello`,
+		"images/photo.png":   `png`,
+		"sub/folder/deep.md": `[[folder/deep#section]]`,
+	})
+	out := runBrokenLinks(t, dir)
+	if out != "" {
+		t.Errorf("expected no warnings, got: %s", out)
+	}
+}
+
+func TestWikilinkWrongFolderBroken(t *testing.T) {
+	dir := setupVault(t, map[string]string{
+		"note.md":          `[[other/target]]`,
+		"folder/target.md": `hello`,
+	})
+	out := runBrokenLinks(t, dir)
+	if !bytes.Contains([]byte(out), []byte("broken link")) {
+		t.Errorf("expected a broken link warning for a wrong folder, got: %s", out)
+	}
+}
+
+func TestAmbiguousWikilink(t *testing.T) {
+	dir := setupVault(t, map[string]string{
+		"note.md":     `[[target]]`,
+		"a/target.md": `a`,
+		"b/target.md": `b`,
+	})
+	out := runBrokenLinks(t, dir)
+	if !bytes.Contains([]byte(out), []byte("Ambiguous link")) ||
+		!bytes.Contains([]byte(out), []byte("a/target.md, b/target.md")) {
+		t.Errorf("expected an ambiguity warning listing the candidates, got: %s", out)
+	}
+	if bytes.Contains([]byte(out), []byte("broken link")) {
+		t.Errorf("ambiguous links are not broken, got: %s", out)
+	}
+}
+
+func TestRootNoteIsNotAmbiguous(t *testing.T) {
+	dir := setupVault(t, map[string]string{
+		"note.md":     `[[target]]`,
+		"target.md":   `root`,
+		"a/target.md": `a`,
+	})
+	out := runBrokenLinks(t, dir)
+	if out != "" {
+		t.Errorf("expected no warnings when a root note owns the name, got: %s", out)
+	}
+}
@@ -0,0 +1,68 @@
+// Report of the notes whose titles and descriptions hurt search results. @feature:linter
+package linter
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// SEO reports the notes without a "description" property, and the notes
+// whose title is empty or shared with another note. The title is resolved as
+// the site does: the "title" property, falling back to the filename.
+func SEO(notes []*obsidian.File, log *slog.Logger) {
+	issuesFound := 0
+	titles := map[string][]string{}
+	keys := []string{}
+
+	sorted := slices.Clone(notes)
+	slices.SortFunc(sorted, func(a, b *obsidian.File) int { return strings.Compare(a.RelPath, b.RelPath) })
+
+	for _, note := range sorted {
+		if note.Ext != ".md" {
+			continue
+		}
+		relPath := filepath.ToSlash(note.RelPath)
+
+		title := frontmatterString(note.Frontmatter, "title")
+		if title == "" {
+			title = strings.TrimSpace(note.Name)
+		}
+		if title == "" {
+			log.Warn("Empty title", "path", relPath)
+			issuesFound++
+			continue
+		}
+		if frontmatterString(note.Frontmatter, "description") == "" {
+			log.Warn("Missing description", "path", relPath)
+			issuesFound++
+		}
+
+		key := strings.ToLower(title)
+		if _, ok := titles[key]; !ok {
+			keys = append(keys, key)
+		}
+		titles[key] = append(titles[key], relPath)
+	}
+
+	for _, key := range keys {
+		if paths := titles[key]; len(paths) > 1 {
+			log.Warn("Duplicate title", "title", key, "paths", strings.Join(paths, ", "))
+			issuesFound++
+		}
+	}
+
+	if issuesFound == 0 {
+		log.Info("No SEO issues found")
+	} else {
+		log.Error("Found SEO issues", "number", issuesFound)
+	}
+}
+
+func frontmatterString(frontmatter map[string]any, key string) string {
+	s, _ := frontmatter[key].(string)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,56 @@
+// @feature:linter Tests for the report of empty and duplicate titles and missing descriptions.
+package linter
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+func runSEO(notes []*obsidian.File) string {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	SEO(notes, logger)
+	return buf.String()
+}
+
+func TestSEO_ReportsIssues(t *testing.T) {
+	notes := []*obsidian.File{
+		{RelPath: "a.md", Name: "a", Ext: ".md", Frontmatter: map[string]any{"title": "Intro", "description": "First"}},
+		{RelPath: "b.md", Name: "b", Ext: ".md", Frontmatter: map[string]any{"title": "intro"}},
+		{RelPath: "c.md", Name: "c", Ext: ".md", Frontmatter: map[string]any{"description": "Third"}},
+		{RelPath: "docs/intro.md", Name: "Intro", Ext: ".md", Frontmatter: map[string]any{"description": "Fourth"}},
+		{RelPath: "e/.md", Name: "", Ext: ".md", Frontmatter: map[string]any{"title": " ", "description": "Fifth"}},
+		{RelPath: "photo.png", Name: "photo", Ext: ".png"},
+	}
+
+	out := runSEO(notes)
+	for _, want := range []string{
+		`msg="Missing description" path=b.md`,
+		`msg="Empty title" path=e/.md`,
+		`msg="Duplicate title" title=intro paths="a.md, b.md, docs/intro.md"`,
+		`msg="Found SEO issues" number=3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "photo.png") {
+		t.Error("only notes should be reported")
+	}
+	if strings.Contains(out, "path=c.md") {
+		t.Error("notes without a title property fall back to their filename")
+	}
+}
+
+func TestSEO_NoIssues(t *testing.T) {
+	notes := []*obsidian.File{
+		{RelPath: "a.md", Name: "a", Ext: ".md", Frontmatter: map[string]any{"title": "A", "description": "First"}},
+	}
+	if out := runSEO(notes); !strings.Contains(out, "No SEO issues found") {
+		t.Errorf("expected no issues, got:\n%s", out)
+	}
+}
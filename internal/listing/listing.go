@@ -0,0 +1,136 @@
+// @feature:folders Style, order and excerpts of the listings of the children of folder pages.
+package listing
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Listing styles
+const (
+	StyleList  = "list"
+	StyleCards = "cards"
+	StyleNone  = "none"
+)
+
+// Sort orders
+const (
+	SortName     = "name"
+	SortCreated  = "created"
+	SortModified = "modified"
+	SortOrder    = "order"
+)
+
+// Options configures the listing of the children of folder pages. It maps to
+// the "folders" section of kiln.yaml, and folder notes override it with
+// their listing properties.
+type Options struct {
+	Style    string `yaml:"style"`    // "list" (default), "cards" or "none"
+	Sort     string `yaml:"sort"`     // "name" (default), "created", "modified" or "order"
+	Reverse  bool   `yaml:"reverse"`  // Reverses the order, e.g. newest first
+	Excerpts bool   `yaml:"excerpts"` // Shows the description or first paragraph of every page
+}
+
+// Item is a child of a folder page.
+type Item struct {
+	Name     string
+	URL      string
+	IsFolder bool
+	Created  time.Time
+	Modified time.Time
+	Excerpt  string  // Description or first paragraph, when excerpts are on
+	Weight   float64 // Position set by the "nav_order", "weight" or "order" property
+	Weighted bool    // Whether the page sets its position
+}
+
+// Resolve returns the options with the listing properties of a folder note
+// applied, "listing", "listing_sort", "listing_reverse" and
+// "listing_excerpts", replacing unknown styles and orders with the defaults.
+func (o Options) Resolve(frontmatter map[string]any, log *slog.Logger) Options {
+	if s, ok := frontmatter["listing"].(string); ok {
+		o.Style = s
+	}
+	if s, ok := frontmatter["listing_sort"].(string); ok {
+		o.Sort = s
+	}
+	if b, ok := boolProperty(frontmatter["listing_reverse"]); ok {
+		o.Reverse = b
+	}
+	if b, ok := boolProperty(frontmatter["listing_excerpts"]); ok {
+		o.Excerpts = b
+	}
+
+	o.Style = strings.ToLower(strings.TrimSpace(o.Style))
+	if !slices.Contains([]string{"", StyleList, StyleCards, StyleNone}, o.Style) {
+		log.Warn("Unknown folder listing style, using list", "style", o.Style)
+		o.Style = ""
+	}
+	if o.Style == "" {
+		o.Style = StyleList
+	}
+	o.Sort = strings.ToLower(strings.TrimSpace(o.Sort))
+	if !slices.Contains([]string{"", SortName, SortCreated, SortModified, SortOrder}, o.Sort) {
+		log.Warn("Unknown folder listing order, using name", "sort", o.Sort)
+		o.Sort = ""
+	}
+	if o.Sort == "" {
+		o.Sort = SortName
+	}
+	return o
+}
+
+// SortItems sorts the items in place, folders first. Dates sort oldest first and
+// the order sorts the items with a position first, as in the navbar.
+func (o Options) SortItems(items []Item) {
+	slices.SortStableFunc(items, func(a, b Item) int {
+		if a.IsFolder != b.IsFolder {
+			if a.IsFolder {
+				return -1
+			}
+			return 1
+		}
+		c := 0
+		switch o.Sort {
+		case SortCreated:
+			c = a.Created.Compare(b.Created)
+		case SortModified:
+			c = a.Modified.Compare(b.Modified)
+		case SortOrder:
+			switch {
+			case a.Weighted != b.Weighted:
+				if a.Weighted {
+					c = -1
+				} else {
+					c = 1
+				}
+			case a.Weight < b.Weight:
+				c = -1
+			case a.Weight > b.Weight:
+				c = 1
+			}
+		}
+		if c == 0 {
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+		if o.Reverse {
+			return -c
+		}
+		return c
+	})
+}
+
+// boolProperty reads a boolean frontmatter property, written as a boolean
+// or a string.
+func boolProperty(v any) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	return false, false
+}
@@ -0,0 +1,79 @@
+// @feature:folders Tests for the options of folder listings.
+package listing
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Resolve(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	defaults := Options{}.Resolve(nil, log)
+	if defaults != (Options{Style: StyleList, Sort: SortName}) {
+		t.Errorf("defaults = %+v", defaults)
+	}
+
+	site := Options{Style: "Cards", Sort: "created", Excerpts: true}
+	got := site.Resolve(map[string]any{
+		"listing_sort":     "modified",
+		"listing_reverse":  "true",
+		"listing_excerpts": false,
+	}, log)
+	want := Options{Style: StyleCards, Sort: SortModified, Reverse: true}
+	if got != want {
+		t.Errorf("Resolve() = %+v, want %+v", got, want)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("unexpected warnings:\n%s", logs.String())
+	}
+
+	got = site.Resolve(map[string]any{"listing": "grid", "listing_sort": "size"}, log)
+	if got.Style != StyleList || got.Sort != SortName {
+		t.Errorf("unknown values resolved to %+v, want the defaults", got)
+	}
+	if !strings.Contains(logs.String(), "grid") || !strings.Contains(logs.String(), "size") {
+		t.Errorf("expected warnings for grid and size, got:\n%s", logs.String())
+	}
+}
+
+func TestOptions_SortItems(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	items := func() []Item {
+		return []Item{
+			{Name: "beta", Created: day(3), Modified: day(1)},
+			{Name: "Sub", IsFolder: true, Created: day(9)},
+			{Name: "alpha", Created: day(2), Modified: day(5), Weight: 2, Weighted: true},
+			{Name: "gamma", Created: day(1), Modified: day(3), Weight: 1, Weighted: true},
+		}
+	}
+	names := func(items []Item) []string {
+		out := []string{}
+		for _, it := range items {
+			out = append(out, it.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		opts Options
+		want []string
+	}{
+		{Options{Sort: SortName}, []string{"Sub", "alpha", "beta", "gamma"}},
+		{Options{Sort: SortCreated}, []string{"Sub", "gamma", "alpha", "beta"}},
+		{Options{Sort: SortModified, Reverse: true}, []string{"Sub", "alpha", "gamma", "beta"}},
+		{Options{Sort: SortOrder}, []string{"Sub", "gamma", "alpha", "beta"}},
+	}
+	for _, tt := range tests {
+		got := items()
+		tt.opts.SortItems(got)
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("%+v: order = %v, want %v", tt.opts, names(got), tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package bases
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -56,7 +57,8 @@ func GroupFiles(files []*obsidian.File, field string) []*FileGroup {
 }
 
 // FilterFiles filters a list of files based on "and", "or", and "not" conditions.
-func FilterFiles(files []*obsidian.File, filters map[string][]string) []*obsidian.File {
+// Links given to hasLink are resolved with links, the index of the vault.
+func FilterFiles(files []*obsidian.File, filters map[string][]string, links *obsidian.LinkIndex) []*obsidian.File {
 	// Optimization: If no filters exist, return original slice
 	if len(filters) == 0 {
 		return files
@@ -71,11 +73,12 @@ func FilterFiles(files []*obsidian.File, filters map[string][]string) []*obsidia
 
 	for _, file := range files {
 		keep := true
+		ctx := &evalContext{file: file, links: links}
 
 		// 1. AND: All conditions must be true
 		if len(andConds) > 0 {
 			for _, query := range andConds {
-				if !evalQuery(file, query) {
+				if !evalQuery(ctx, query) {
 					keep = false
 					break
 				}
@@ -88,7 +91,7 @@ func FilterFiles(files []*obsidian.File, filters map[string][]string) []*obsidia
 		// 2. NOT: None of the conditions must be true (Reject if ANY is true)
 		if len(notConds) > 0 {
 			for _, query := range notConds {
-				if evalQuery(file, query) {
+				if evalQuery(ctx, query) {
 					keep = false
 					break
 				}
@@ -102,7 +105,7 @@ func FilterFiles(files []*obsidian.File, filters map[string][]string) []*obsidia
 		if len(orConds) > 0 {
 			orMatch := false
 			for _, query := range orConds {
-				if evalQuery(file, query) {
+				if evalQuery(ctx, query) {
 					orMatch = true
 					break
 				}
@@ -121,7 +124,7 @@ func FilterFiles(files []*obsidian.File, filters map[string][]string) []*obsidia
 }
 
 // evalQuery helps reuse the parsing logic for AND/OR/NOT loops
-func evalQuery(file *obsidian.File, rawQuery string) bool {
+func evalQuery(ctx *evalContext, rawQuery string) bool {
 	// 1. Parse
 	p := newParser(rawQuery)
 	n, err := p.parse()
@@ -131,7 +134,7 @@ func evalQuery(file *obsidian.File, rawQuery string) bool {
 	}
 
 	// 2. Evaluate
-	res := n.eval(file)
+	res := n.eval(ctx)
 
 	// 3. Check Truthiness
 	return isTrue(res)
@@ -141,22 +144,29 @@ func evalQuery(file *obsidian.File, rawQuery string) bool {
 // 3. The AST (Abstract Syntax Tree)
 // ==========================================
 
+// evalContext holds the file a query is evaluated on and the index
+// resolving the links of the query.
+type evalContext struct {
+	file  *obsidian.File
+	links *obsidian.LinkIndex
+}
+
 type node interface {
-	eval(ctx *obsidian.File) any
+	eval(ctx *evalContext) any
 }
 
 type literalNode struct {
 	Value any
 }
 
-func (n *literalNode) eval(ctx *obsidian.File) any { return n.Value }
+func (n *literalNode) eval(ctx *evalContext) any { return n.Value }
 
 type fieldNode struct {
 	Name string
 }
 
-func (n *fieldNode) eval(ctx *obsidian.File) any {
-	return getValue(ctx, n.Name)
+func (n *fieldNode) eval(ctx *evalContext) any {
+	return getValue(ctx.file, n.Name)
 }
 
 type unaryNode struct {
@@ -164,7 +174,7 @@ type unaryNode struct {
 	Operand  node
 }
 
-func (n *unaryNode) eval(ctx *obsidian.File) any {
+func (n *unaryNode) eval(ctx *evalContext) any {
 	if n.Operand == nil {
 		return false
 	} // Safety check
@@ -182,7 +192,7 @@ type methodNode struct {
 	Args   []node // Changed to slice to support multiple args
 }
 
-func (n *methodNode) eval(ctx *obsidian.File) any {
+func (n *methodNode) eval(ctx *evalContext) any {
 	obj := n.Object.eval(ctx)
 
 	// Check before
@@ -208,36 +218,13 @@ func (n *methodNode) eval(ctx *obsidian.File) any {
 			return false
 		}
 
-		// 2. Process the Target (User Input)
-		// Input: "books/fantasy/Harry Potter.md"
-		rawTarget := toString(argValues[0])
-
-		// Remove folders: "Harry Potter.md"
-		// We use LastIndex of "/" to manually strip path to ensure cross-platform safety for Obsidian paths
-		if idx := strings.LastIndex(rawTarget, "/"); idx != -1 {
-			rawTarget = rawTarget[idx+1:]
+		// 2. Resolve the target like a link, then compare it against the
+		// resolved outgoing links of the file
+		target, ok := argValues[0].(*obsidian.File)
+		if !ok && ctx.links != nil {
+			target, _, _ = ctx.links.Find(nil, strings.TrimPrefix(cleanLink(toString(argValues[0])), "/"))
 		}
-
-		// Remove extension: "Harry Potter"
-		target := strings.TrimSuffix(rawTarget, ".md")
-
-		// Lowercase for fuzzy matching
-		target = strings.ToLower(target)
-
-		// 3. Search in File Links
-		for _, rawLink := range file.Links {
-			// Clean the link: "[[Harry Potter|HP]]" -> "Harry Potter"
-			clean := cleanLink(rawLink)
-			cleanLower := strings.ToLower(clean)
-
-			// Check for containment
-			// "harry potter" contains "harry potter" -> True
-			// "harry potter and the stone" contains "harry potter" -> True
-			if strings.Contains(cleanLower, target) {
-				return true
-			}
-		}
-		return false
+		return target != nil && slices.Contains(file.Outlinks, target)
 	}
 
 	// <--- ADD THIS BLOCK
@@ -411,7 +398,7 @@ type binaryNode struct {
 	Right    node
 }
 
-func (n *binaryNode) eval(ctx *obsidian.File) any {
+func (n *binaryNode) eval(ctx *evalContext) any {
 	if n.Left == nil {
 		return false
 	}
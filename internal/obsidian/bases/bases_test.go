@@ -0,0 +1,33 @@
+// Tests for the filters of bases. @feature:bases
+package bases
+
+import (
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+func TestFilterFiles_HasLink(t *testing.T) {
+	first := &obsidian.File{Name: "Note", FullName: "Note.md", Ext: ".md", RelPath: "a/Note.md", WebPath: "/a/note"}
+	second := &obsidian.File{Name: "Note", FullName: "Note.md", Ext: ".md", RelPath: "b/Note.md", WebPath: "/b/note"}
+	source := &obsidian.File{Name: "Source", FullName: "Source.md", Ext: ".md", RelPath: "Source.md", WebPath: "/source", Outlinks: []*obsidian.File{second}}
+	files := []*obsidian.File{first, second, source}
+	links := obsidian.NewLinkIndex(files, nil)
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`file.hasLink("b/Note")`, true},
+		{`file.hasLink("B/note.md")`, true},
+		{`file.hasLink("[[b/Note#Heading|Alias]]")`, true},
+		{`file.hasLink("a/Note")`, false},
+		{`file.hasLink("Missing")`, false},
+	}
+	for _, tt := range tests {
+		got := FilterFiles(files, map[string][]string{"and": {tt.query}}, links)
+		if kept := len(got) == 1 && got[0] == source; kept != tt.want {
+			t.Errorf("%s kept %v, want the source kept: %v", tt.query, got, tt.want)
+		}
+	}
+}
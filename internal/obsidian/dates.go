@@ -0,0 +1,73 @@
+// @feature:dates Creation and modification dates of the vault files.
+package obsidian
+
+import (
+	"strings"
+	"time"
+)
+
+// Date sources
+const (
+	DateSourceFilesystem  = "filesystem"  // File birth and modification times
+	DateSourceFrontmatter = "frontmatter" // "created" and "updated" properties only
+	DateSourceGit         = "git"         // First and last commit of each file
+)
+
+// DateLayouts are the date formats accepted in date properties.
+var DateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// ParseDate converts a frontmatter value to a time. It returns the zero
+// time when the value is missing or isn't a date.
+func ParseDate(v any) time.Time {
+	switch v := v.(type) {
+	case time.Time:
+		return v
+	case string:
+		for _, layout := range DateLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// resolveDates sets the Created and Modified dates of the files from the
+// date source. The "created" and "updated" properties of a note win over
+// every source. Files the source knows nothing about keep their filesystem
+// times.
+func (o *Obsidian) resolveDates() {
+	source := strings.ToLower(o.DateSource)
+	switch source {
+	case "", DateSourceFilesystem, DateSourceFrontmatter, DateSourceGit:
+	default:
+		o.log.Warn("Unknown date source, using filesystem dates", "source", o.DateSource,
+			"allowed", strings.Join([]string{DateSourceFilesystem, DateSourceFrontmatter, DateSourceGit}, ", "))
+	}
+
+	for _, f := range o.Vault.Files {
+		if source == DateSourceGit {
+			if d, ok := o.gitHistory()[f.RelPath]; ok {
+				f.Created, f.Modified = d.First, d.Last
+			}
+		}
+
+		created := ParseDate(f.Frontmatter["created"])
+		updated := ParseDate(f.Frontmatter["updated"])
+		if source == DateSourceFrontmatter {
+			// A single property stands for both dates
+			if created.IsZero() {
+				created = updated
+			}
+			if updated.IsZero() {
+				updated = created
+			}
+		}
+		if !created.IsZero() {
+			f.Created = created
+		}
+		if !updated.IsZero() {
+			f.Modified = updated
+		}
+	}
+}
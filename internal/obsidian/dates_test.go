@@ -0,0 +1,146 @@
+// @feature:dates Tests for the date sources and the git history walk.
+package obsidian
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		in   any
+		want time.Time
+	}{
+		"time":     {day, day},
+		"date":     {"2024-05-01", day},
+		"datetime": {"2024-05-01 10:30", day.Add(10*time.Hour + 30*time.Minute)},
+		"rfc3339":  {"2024-05-01T00:00:00Z", day},
+		"invalid":  {"soon", time.Time{}},
+		"missing":  {nil, time.Time{}},
+	}
+	for name, c := range cases {
+		if got := ParseDate(c.in); !got.Equal(c.want) {
+			t.Errorf("%s: ParseDate(%v) = %v, want %v", name, c.in, got, c.want)
+		}
+	}
+}
+
+func TestResolveDates(t *testing.T) {
+	created := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	mtime := time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC)
+	newFiles := func() (both, onlyCreated, none *File) {
+		both = &File{Frontmatter: map[string]any{"created": "2023-01-02", "updated": "2024-03-04"}, Created: mtime, Modified: mtime}
+		onlyCreated = &File{Frontmatter: map[string]any{"created": "2023-01-02"}, Created: mtime, Modified: mtime}
+		none = &File{Created: mtime, Modified: mtime}
+		return
+	}
+	resolve := func(source string, files ...*File) {
+		o := New(WithDateSource(source), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		o.Vault = &Vault{Files: files}
+		o.resolveDates()
+	}
+
+	both, onlyCreated, none := newFiles()
+	resolve(DateSourceFilesystem, both, onlyCreated, none)
+	if !both.Created.Equal(created) || !both.Modified.Equal(updated) {
+		t.Errorf("properties should win: got %v / %v", both.Created, both.Modified)
+	}
+	if !onlyCreated.Created.Equal(created) || !onlyCreated.Modified.Equal(mtime) {
+		t.Errorf("filesystem: missing updated should keep the mtime, got %v / %v", onlyCreated.Created, onlyCreated.Modified)
+	}
+	if !none.Created.Equal(mtime) || !none.Modified.Equal(mtime) {
+		t.Errorf("filesystem: notes without properties keep their times, got %v / %v", none.Created, none.Modified)
+	}
+
+	both, onlyCreated, none = newFiles()
+	resolve(DateSourceFrontmatter, both, onlyCreated, none)
+	if !onlyCreated.Modified.Equal(created) {
+		t.Errorf("frontmatter: missing updated should use created, got %v", onlyCreated.Modified)
+	}
+	if !none.Created.Equal(mtime) {
+		t.Errorf("frontmatter: notes without properties fall back to the filesystem, got %v", none.Created)
+	}
+}
+
+func TestParseGitLog(t *testing.T) {
+	out := []byte("\x002024-03-02T10:00:00+01:00\n\nnotes/a.md\n\n\x002024-01-01T00:00:00Z\n\nnotes/a.md\nb.md\n")
+	dates := parseGitLog(out)
+	a := dates[filepath.FromSlash("notes/a.md")]
+	if !a.Last.Equal(time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("a.md last = %v, want its latest commit", a.Last)
+	}
+	if !a.First.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("a.md first = %v, want its first commit", a.First)
+	}
+	if b := dates["b.md"]; !b.First.Equal(b.Last) {
+		t.Errorf("b.md = %+v, want a single commit", b)
+	}
+}
+
+func TestScan_GitDates(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	vault := filepath.Join(dir, "vault")
+	if err := os.MkdirAll(vault, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git := func(date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(vault, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("", "init", "-q")
+	write("a.md", "first")
+	git("2022-01-01T10:00:00Z", "add", ".")
+	git("2022-01-01T10:00:00Z", "commit", "-q", "-m", "add a")
+	write("a.md", "second")
+	write("b.md", "---\nupdated: 2030-01-01\n---\nB")
+	git("2023-06-01T10:00:00Z", "add", ".")
+	git("2023-06-01T10:00:00Z", "commit", "-q", "-m", "edit a, add b")
+	write("c.md", "untracked")
+
+	o := New(WithInputDir(vault), WithDateSource(DateSourceGit), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err := o.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*File{}
+	for _, f := range o.Vault.Files {
+		files[f.RelPath] = f
+	}
+	if a := files["a.md"]; !a.Created.Equal(time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)) || !a.Modified.Equal(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("a.md = %v / %v, want its first and last commits", a.Created, a.Modified)
+	}
+	if b := files["b.md"]; !b.Modified.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("b.md modified = %v, the updated property should win", b.Modified)
+	}
+	if c := files["c.md"]; c.Created.Year() < 2024 {
+		t.Errorf("untracked c.md = %v, want its filesystem time", c.Created)
+	}
+	for _, entry := range o.Vault.RSS {
+		if entry.WebPath == "/a" && !entry.PubDate.Equal(files["a.md"].Created) {
+			t.Errorf("RSS date = %v, want the creation date", entry.PubDate)
+		}
+	}
+}
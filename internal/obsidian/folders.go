@@ -0,0 +1,110 @@
+// @feature:folders Folder notes, the notes rendered as the pages of their folders, and page excerpts.
+package obsidian
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// excerptLength is the number of characters kept by Excerpt.
+const excerptLength = 200
+
+// blockPrefixes start the markdown blocks that aren't paragraphs: headings,
+// quotes and callouts, tables, lists, embeds, HTML, rules, code, math and
+// comments.
+var blockPrefixes = []string{"#", ">", "|", "- ", "* ", "+ ", "![[", "<", "---", "```", "$$", "%%"}
+
+// resolveFolderNotes finds the folder note of every folder: "Folder/Folder.md",
+// "Folder/index.md" or "Folder.md" next to the folder, in this order. The
+// note moves to the web and output paths of its folder, whose page it
+// becomes. The other candidates share the path of the folder page, so they
+// aren't published.
+func (o *Obsidian) resolveFolderNotes() {
+	notes := make(map[string]*File, len(o.Vault.Files))
+	for _, f := range o.Vault.Files {
+		switch f.Ext {
+		case ".md", ".canvas", ".base":
+			notes[filepath.ToSlash(f.RelPath)] = f
+		}
+	}
+
+	shadowed := map[*File]bool{}
+	for _, folder := range o.Vault.Folders {
+		rel := filepath.ToSlash(folder.RelPath)
+		name := filepath.Base(folder.RelPath)
+		candidates := []string{rel + "/" + name + ".md", rel + "/index.md", rel + ".md", rel + ".canvas", rel + ".base"}
+		found := []*File{}
+		for _, c := range candidates {
+			if f, ok := notes[c]; ok {
+				found = append(found, f)
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		note := found[0]
+		for _, f := range found[1:] {
+			o.log.Warn("Folder with several folder notes, skipping the others", "folder", folder.RelPath, "note", note.RelPath, "skipped", f.RelPath)
+			shadowed[f] = true
+			o.Vault.GraphNodes = slices.DeleteFunc(o.Vault.GraphNodes, func(n GraphNode) bool {
+				return n.Type == f.Ext && n.Label == f.Name && n.ID == f.WebPath
+			})
+		}
+		delete(o.Vault.SourceMap, note.WebPath)
+		for i, node := range o.Vault.GraphNodes {
+			if node.ID == note.WebPath {
+				o.Vault.GraphNodes[i].ID, o.Vault.GraphNodes[i].URL = folder.WebPath, folder.WebPath
+			}
+		}
+		note.WebPath = folder.WebPath
+		note.OutPath = folder.OutPath
+		note.NoteOf = folder
+		folder.Note = note
+		o.Vault.SourceMap[note.WebPath] = note.RelPath
+		o.log.Debug("Found folder note", "folder", folder.RelPath, "note", note.RelPath)
+	}
+
+	o.Vault.Files = slices.DeleteFunc(o.Vault.Files, func(f *File) bool { return shadowed[f] })
+}
+
+// Excerpt returns the "description" property of a note, or the text of its
+// first paragraph, cut at a word boundary.
+func (f *File) Excerpt() string {
+	if d := frontmatterString(f, "description"); d != "" {
+		return d
+	}
+
+	inFence := false
+	for _, block := range strings.Split(string(f.Content), "\n\n") {
+		block = strings.TrimSpace(block)
+		if strings.Count(block, "```")%2 == 1 {
+			inFence = !inFence
+			continue
+		}
+		if inFence || block == "" || !isParagraph(block) {
+			continue
+		}
+		text := inlinePlainText(block)
+		if utf8.RuneCountInString(text) <= excerptLength {
+			return text
+		}
+		text = string([]rune(text)[:excerptLength])
+		if i := strings.LastIndexByte(text, ' '); i != -1 {
+			text = text[:i]
+		}
+		return text + "…"
+	}
+	return ""
+}
+
+// isParagraph reports whether a markdown block is a paragraph of text.
+func isParagraph(block string) bool {
+	for _, prefix := range blockPrefixes {
+		if strings.HasPrefix(block, prefix) {
+			return false
+		}
+	}
+	return true
+}
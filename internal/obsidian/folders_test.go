@@ -0,0 +1,89 @@
+// @feature:folders Tests for folder notes and page excerpts.
+package obsidian
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveFolderNotes(t *testing.T) {
+	var logs bytes.Buffer
+	o := scanVault(t, map[string]string{
+		"Guides/Guides.md":  "Guides note",
+		"Guides/index.md":   "Shadowed index",
+		"Guides/Setup.md":   "Setup",
+		"Recipes/index.md":  "Recipes index",
+		"Recipes/Bread.md":  "Bread",
+		"Projects.md":       "Projects note",
+		"Projects/Kiln.md":  "Kiln",
+		"Archive/Old.md":    "Old",
+		"Boards.canvas":     `{"nodes":[],"edges":[]}`,
+		"Boards/Roadmap.md": "Roadmap",
+	}, WithFolderNotes(true), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	for folder, want := range map[string]string{
+		"Guides":   "Guides/Guides.md",
+		"Recipes":  "Recipes/index.md",
+		"Projects": "Projects.md",
+		"Boards":   "Boards.canvas",
+		"Archive":  "",
+	} {
+		f := o.Vault.Folders[folder]
+		if f == nil {
+			t.Fatalf("folder %s is missing", folder)
+		}
+		got := ""
+		if f.Note != nil {
+			got = filepath.ToSlash(f.Note.RelPath)
+			if f.Note.NoteOf != f {
+				t.Errorf("%s: NoteOf doesn't point back to the folder", got)
+			}
+			if f.Note.WebPath != f.WebPath || f.Note.OutPath != f.OutPath {
+				t.Errorf("%s: paths = %s %s, want the folder's %s %s", got, f.Note.WebPath, f.Note.OutPath, f.WebPath, f.OutPath)
+			}
+			if o.Vault.SourceMap[f.WebPath] != f.Note.RelPath {
+				t.Errorf("%s: source map = %q", got, o.Vault.SourceMap[f.WebPath])
+			}
+		}
+		if got != want {
+			t.Errorf("folder note of %s = %q, want %q", folder, got, want)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "several folder notes") || !strings.Contains(logs.String(), "Guides") {
+		t.Errorf("expected a warning for the two notes of Guides, got:\n%s", logs.String())
+	}
+	for _, f := range o.Vault.Files {
+		if filepath.ToSlash(f.RelPath) == "Guides/index.md" {
+			t.Error("the shadowed index is published")
+		}
+	}
+}
+
+func TestFile_Excerpt(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+	tests := []struct {
+		name        string
+		frontmatter map[string]any
+		content     string
+		want        string
+	}{
+		{"description", map[string]any{"description": " Set by hand "}, "First paragraph.", "Set by hand"},
+		{"first paragraph", nil, "# Title\n\nFirst **bold** paragraph.\n\nSecond.", "First bold paragraph."},
+		{"skips blocks", nil, "> [!note]\n> Callout\n\n- item\n\n```go\ncode\n```\n\nText.", "Text."},
+		{"skips split fences", nil, "```\ncode\n\nmore code\n```\n\nAfter.", "After."},
+		{"truncated", nil, long, strings.TrimSpace(strings.Repeat("word ", 40)) + "…"},
+		{"empty", nil, "# Only a heading", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{Frontmatter: tt.frontmatter, Content: []byte(tt.content)}
+			if got := f.Excerpt(); got != tt.want {
+				t.Errorf("Excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// @feature:dates Commit dates of the vault files read from git.
+package obsidian
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitDates holds the dates of the first and the last commit touching a file.
+type gitDates struct {
+	First time.Time
+	Last  time.Time
+}
+
+// gitHistory returns the commit dates of the files of the input directory,
+// keyed by path relative to it. The history is read once, in a single walk,
+// and reused by the later calls. It is empty when git isn't installed or the
+// vault isn't inside a repository.
+func (o *Obsidian) gitHistory() map[string]gitDates {
+	if o.gitDates != nil {
+		return o.gitDates
+	}
+	o.gitDates = map[string]gitDates{}
+	out, err := exec.Command(
+		"git", "-C", o.InputDir, "-c", "core.quotePath=false",
+		"log", "--format=%x00%aI", "--name-only", "--no-renames", "--relative", "--", ".",
+	).Output()
+	if err != nil {
+		o.log.Debug("Couldn't read the git history of the vault", "error", err)
+		return o.gitDates
+	}
+	o.gitDates = parseGitLog(out)
+	shallow, err := exec.Command("git", "-C", o.InputDir, "rev-parse", "--is-shallow-repository").Output()
+	if err == nil && strings.TrimSpace(string(shallow)) == "true" {
+		o.log.Warn("The repository is a shallow clone, creation dates only go back to its first commit")
+	}
+	return o.gitDates
+}
+
+// parseGitLog reads the output of the git log run by gitHistory. Commits are
+// listed from the newest, so the first date seen for a path is its last
+// change and the last date seen its first commit.
+func parseGitLog(out []byte) map[string]gitDates {
+	dates := map[string]gitDates{}
+	var current time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if date, ok := strings.CutPrefix(line, "\x00"); ok {
+			current, _ = time.Parse(time.RFC3339, date)
+			continue
+		}
+		if line == "" || current.IsZero() {
+			continue
+		}
+		path := filepath.FromSlash(line)
+		d, seen := dates[path]
+		if !seen {
+			d.Last = current
+		}
+		d.First = current
+		dates[path] = d
+	}
+	return dates
+}
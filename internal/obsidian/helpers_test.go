@@ -0,0 +1,51 @@
+// @feature:vault-scan Helpers writing and scanning the vaults of the tests.
+package obsidian
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeVault writes the given files, by path relative to the vault, to a
+// temporary vault and returns its path.
+func writeVault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	return dir
+}
+
+// writeFiles writes the given files, by path relative to dir, creating
+// their folders.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scanVault scans a vault made of the given files, with a temporary output
+// directory, the https://example.com base URL and no logs. The options are
+// applied after those.
+func scanVault(t *testing.T, files map[string]string, opts ...Option) *Obsidian {
+	t.Helper()
+	o := New(append([]Option{
+		WithInputDir(writeVault(t, files)),
+		WithOutputDir(t.TempDir()),
+		WithBaseURL("https://example.com"),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, opts...)...)
+	if err := o.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
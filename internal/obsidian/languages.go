@@ -0,0 +1,169 @@
+// @feature:i18n Language of every page and links between the translations of a note.
+package obsidian
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// resolveLanguages sets the language of the files and folders of the vault
+// and links the translations of each page. The language of a note is its
+// "lang" property, or else the language of the top-level folder holding it,
+// or else the default language of the site.
+func (o *Obsidian) resolveLanguages() {
+	o.langFolders = map[string]string{}
+	for _, folder := range o.Vault.Folders {
+		if !strings.Contains(filepath.ToSlash(folder.RelPath), "/") {
+			if lang := o.folderLanguage(folder.RelPath); lang != "" {
+				o.langFolders[lang] = folder.RelPath
+			}
+		}
+	}
+	for _, folder := range o.Vault.Folders {
+		folder.Lang = o.pathLanguage(folder.RelPath)
+	}
+
+	used := map[string]bool{}
+	for _, f := range o.Vault.Files {
+		f.Lang = o.pathLanguage(f.RelPath)
+		if lang, ok := f.Frontmatter["lang"].(string); ok && strings.TrimSpace(lang) != "" {
+			f.Lang = strings.TrimSpace(lang)
+		}
+		if isPage(f) {
+			used[f.Lang] = true
+		}
+	}
+
+	// The default language comes first, then the configured ones, then the
+	// ones only found in the frontmatter
+	o.Vault.Languages = []string{}
+	for _, lang := range append([]string{o.Lang}, o.Languages...) {
+		if used[lang] && !slices.Contains(o.Vault.Languages, lang) {
+			o.Vault.Languages = append(o.Vault.Languages, lang)
+		}
+	}
+	others := []string{}
+	for lang := range used {
+		if !slices.Contains(o.Vault.Languages, lang) {
+			others = append(others, lang)
+		}
+	}
+	slices.Sort(others)
+	o.Vault.Languages = append(o.Vault.Languages, others...)
+
+	o.linkTranslations()
+}
+
+// linkTranslations links the pages sharing a translation key.
+func (o *Obsidian) linkTranslations() {
+	groups := map[string][]*File{}
+	keys := []string{}
+	for _, f := range o.Vault.Files {
+		f.Translations = nil
+		if !isPage(f) {
+			continue
+		}
+		key := o.translationKey(f)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], f)
+	}
+
+	for _, key := range keys {
+		byLang := map[string]*File{}
+		for _, f := range groups[key] {
+			if other, ok := byLang[f.Lang]; ok {
+				o.log.Warn("Several translations of a note in the same language, keeping the first", "lang", f.Lang, "kept", other.RelPath, "skipped", f.RelPath)
+				continue
+			}
+			byLang[f.Lang] = f
+		}
+		if len(byLang) < 2 {
+			continue
+		}
+		for lang, f := range byLang {
+			for _, other := range o.Vault.Languages {
+				if t, ok := byLang[other]; ok && other != lang {
+					f.Translations = append(f.Translations, t)
+				}
+			}
+		}
+	}
+}
+
+// translationKey returns the key shared by the translations of a page: its
+// "translationKey" property, or else its path inside its language folder.
+func (o *Obsidian) translationKey(f *File) string {
+	if key, ok := f.Frontmatter["translationKey"].(string); ok && strings.TrimSpace(key) != "" {
+		return "key:" + strings.TrimSpace(key)
+	}
+	rel := filepath.ToSlash(f.RelPath)
+	if first, rest, ok := strings.Cut(rel, "/"); ok && o.folderLanguage(first) != "" {
+		rel = rest
+	}
+	return "path:" + rel
+}
+
+// pathLanguage returns the language of a vault path: the language of its
+// top-level folder, or the default language.
+func (o *Obsidian) pathLanguage(relPath string) string {
+	first, _, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	if lang := o.folderLanguage(first); lang != "" {
+		return lang
+	}
+	return o.Lang
+}
+
+// folderLanguage returns the configured language a folder is named after,
+// or "".
+func (o *Obsidian) folderLanguage(name string) string {
+	for _, lang := range o.Languages {
+		if strings.EqualFold(name, lang) {
+			return lang
+		}
+	}
+	return ""
+}
+
+// IsMultilingual reports whether the pages of the vault are written in more
+// than one language.
+func (o *Obsidian) IsMultilingual() bool {
+	return len(o.Vault.Languages) > 1
+}
+
+// LanguageHome returns the web path of the home page of a language: its
+// top-level folder, or the root of the site.
+func (o *Obsidian) LanguageHome(lang string) string {
+	if rel, ok := o.langFolders[lang]; ok {
+		if folder, ok := o.Vault.Folders[rel]; ok {
+			return folder.WebPath
+		}
+	}
+	home, err := o.GetPageWebPath("index.md", ".md")
+	if err != nil {
+		return "/"
+	}
+	return home
+}
+
+// LanguageFileName returns the name of a per-language output file: the name
+// itself for the default language, or the name with the language code before
+// its extension for the others (feed.xml -> feed.it.xml).
+func LanguageFileName(name, lang, defaultLang string) string {
+	if lang == "" || lang == defaultLang {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + lang + ext
+}
+
+// isPage reports whether a file is rendered as a page.
+func isPage(f *File) bool {
+	switch f.Ext {
+	case ".md", ".canvas", ".base":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+// @feature:i18n Tests for the page languages, the translations and the per-language outputs.
+package obsidian
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// scanLanguagesVault scans a vault made of the given files, in a site whose
+// default language is English and whose "it" folder holds Italian pages.
+func scanLanguagesVault(t *testing.T, files map[string]string) *Obsidian {
+	t.Helper()
+	return scanVault(t, files, WithLang("en"), WithLanguages([]string{"en", "it"}))
+}
+
+// findFile returns the file of the vault at a relative path.
+func findFile(t *testing.T, o *Obsidian, rel string) *File {
+	t.Helper()
+	for _, f := range o.Vault.Files {
+		if filepath.ToSlash(f.RelPath) == rel {
+			return f
+		}
+	}
+	t.Fatalf("file %s not found", rel)
+	return nil
+}
+
+func TestResolveLanguages(t *testing.T) {
+	o := scanLanguagesVault(t, map[string]string{
+		"index.md":           "Home",
+		"guides/setup.md":    "Setup",
+		"it/guides/setup.md": "Installazione",
+		"notes/about.md":     "---\ntranslationKey: about\n---\nAbout",
+		"it/chi-siamo.md":    "---\ntranslationKey: about\n---\nChi siamo",
+		"notes/bonjour.md":   "---\nlang: fr\ntranslationKey: about\n---\nBonjour",
+		"it/solo.md":         "Solo in italiano",
+		"it/pic.png":         "png",
+	})
+
+	langs := map[string]string{
+		"index.md":           "en",
+		"guides/setup.md":    "en",
+		"it/guides/setup.md": "it",
+		"it/chi-siamo.md":    "it",
+		"notes/bonjour.md":   "fr",
+		"it/pic.png":         "it",
+	}
+	for rel, want := range langs {
+		if got := findFile(t, o, rel).Lang; got != want {
+			t.Errorf("%s: Lang = %q, want %q", rel, got, want)
+		}
+	}
+	if got := o.Vault.Folders["it"].Lang; got != "it" {
+		t.Errorf("folder it: Lang = %q, want it", got)
+	}
+	if want := []string{"en", "it", "fr"}; !slices.Equal(o.Vault.Languages, want) {
+		t.Errorf("Languages = %v, want %v", o.Vault.Languages, want)
+	}
+	if !o.IsMultilingual() {
+		t.Error("IsMultilingual = false, want true")
+	}
+
+	translations := func(rel string) []string {
+		paths := []string{}
+		for _, tr := range findFile(t, o, rel).Translations {
+			paths = append(paths, filepath.ToSlash(tr.RelPath))
+		}
+		return paths
+	}
+	if got := translations("guides/setup.md"); !slices.Equal(got, []string{"it/guides/setup.md"}) {
+		t.Errorf("guides/setup.md translations = %v", got)
+	}
+	if got := translations("notes/about.md"); !slices.Equal(got, []string{"it/chi-siamo.md", "notes/bonjour.md"}) {
+		t.Errorf("notes/about.md translations = %v", got)
+	}
+	if got := translations("it/chi-siamo.md"); !slices.Equal(got, []string{"notes/about.md", "notes/bonjour.md"}) {
+		t.Errorf("it/chi-siamo.md translations = %v", got)
+	}
+	if got := translations("it/solo.md"); len(got) != 0 {
+		t.Errorf("it/solo.md translations = %v, want none", got)
+	}
+}
+
+func TestResolveLanguages_SingleLanguage(t *testing.T) {
+	o := scanVault(t, map[string]string{
+		"index.md":   "Home",
+		"it/note.md": "Not a language folder",
+	})
+	if o.IsMultilingual() {
+		t.Errorf("IsMultilingual = true, languages %v", o.Vault.Languages)
+	}
+	if f := findFile(t, o, "it/note.md"); len(f.Translations) != 0 {
+		t.Errorf("Translations = %v, want none", f.Translations)
+	}
+}
+
+func TestScan_SkipsTranslationFiles(t *testing.T) {
+	o := scanVault(t, map[string]string{
+		"index.md":           "Home",
+		"_i18n/fr.yaml":      "copy: Copier",
+		"_themes/brand.yaml": "extends: nord",
+		"_layouts/note.html": "{{ .Content }}",
+	})
+	for _, dir := range []string{"_i18n", "_themes", "_layouts"} {
+		if _, ok := o.Vault.Folders[dir]; ok {
+			t.Errorf("the %s folder is part of the vault", dir)
+		}
+	}
+	for _, f := range o.Vault.Files {
+		if rel := filepath.ToSlash(f.RelPath); strings.HasPrefix(rel, "_i18n/") || strings.HasPrefix(rel, "_themes/") || strings.HasPrefix(rel, "_layouts/") {
+			t.Errorf("file %s is part of the vault", f.RelPath)
+		}
+	}
+}
+
+func TestGenerateNavbars(t *testing.T) {
+	o := scanLanguagesVault(t, map[string]string{
+		"index.md":           "Home",
+		"guides/setup.md":    "Setup",
+		"it/index.md":        "Casa",
+		"it/guides/setup.md": "Installazione",
+	})
+	navbars := o.GenerateNavbars()
+
+	names := func(nodes []*NavbarNode) []string {
+		out := []string{}
+		for _, n := range nodes {
+			out = append(out, n.Name)
+		}
+		return out
+	}
+	if got := names(navbars["en"].Children); slices.Contains(got, "it") || !slices.Contains(got, "guides") {
+		t.Errorf("en navbar = %v, want the English pages only", got)
+	}
+	it := navbars["it"]
+	if it.Path != o.Vault.Folders["it"].WebPath {
+		t.Errorf("it navbar path = %q, want %q", it.Path, o.Vault.Folders["it"].WebPath)
+	}
+	if got := names(it.Children); !slices.Contains(got, "guides") {
+		t.Errorf("it navbar = %v, want the content of the it folder", got)
+	}
+	if home := o.LanguageHome("it"); home != "/it" {
+		t.Errorf("LanguageHome(it) = %q, want /it", home)
+	}
+}
+
+func TestGenerateSitemap_Multilingual(t *testing.T) {
+	o := scanLanguagesVault(t, map[string]string{
+		"index.md":           "Home",
+		"guides/setup.md":    "Setup",
+		"it/guides/setup.md": "Installazione",
+	})
+	if err := o.GenerateSitemap(); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(o.OutputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<sitemapindex", "https://example.com/sitemap-en.xml", "https://example.com/sitemap-it.xml"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("sitemap.xml lacks %q:\n%s", want, index)
+		}
+	}
+
+	en, err := os.ReadFile(filepath.Join(o.OutputDir, "sitemap-en.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		`<xhtml:link rel="alternate" hreflang="en" href="https://example.com/guides/setup">`,
+		`<xhtml:link rel="alternate" hreflang="it" href="https://example.com/it/guides/setup">`,
+		`<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/guides/setup">`,
+	} {
+		if !strings.Contains(string(en), want) {
+			t.Errorf("sitemap-en.xml lacks %q:\n%s", want, en)
+		}
+	}
+	if strings.Contains(string(en), "<loc>https://example.com/it/") {
+		t.Errorf("sitemap-en.xml lists Italian pages:\n%s", en)
+	}
+}
+
+func TestGenerateRSS_Multilingual(t *testing.T) {
+	o := scanLanguagesVault(t, map[string]string{
+		"index.md":     "Home",
+		"it/ciao.md":   "Ciao",
+		"it/mondo.md":  "Mondo",
+		"notes/hi.md":  "Hi",
+		"notes/pic.md": "Pic",
+	})
+	if err := o.GenerateRSS(); err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := os.ReadFile(filepath.Join(o.OutputDir, "feed.it.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(it), "<language>it</language>") || strings.Count(string(it), "<item>") != 2 {
+		t.Errorf("feed.it.xml:\n%s", it)
+	}
+	en, err := os.ReadFile(filepath.Join(o.OutputDir, "feed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(en), "<language>en</language>") || strings.Count(string(en), "<item>") != 3 {
+		t.Errorf("feed.xml:\n%s", en)
+	}
+}
+
+func TestLanguageFileName(t *testing.T) {
+	tests := []struct{ name, lang, want string }{
+		{"feed.xml", "en", "feed.xml"},
+		{"feed.xml", "it", "feed.it.xml"},
+		{"search-index.json", "pt-BR", "search-index.pt-BR.json"},
+		{"feed.xml", "", "feed.xml"},
+	}
+	for _, tt := range tests {
+		if got := LanguageFileName(tt.name, tt.lang, "en"); got != tt.want {
+			t.Errorf("LanguageFileName(%q, %q) = %q, want %q", tt.name, tt.lang, got, tt.want)
+		}
+	}
+}
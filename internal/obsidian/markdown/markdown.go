@@ -3,6 +3,7 @@ package markdown
 
 import (
 	"bytes"
+	"fmt"
 	"html/template"
 	"os"
 
@@ -24,14 +25,17 @@ import (
 
 // newMarkdownParser creates a Goldmark instance configured for Obsidian compatibility.
 // It enables GFM, MathJax, syntax highlighting, and custom link resolution.
+// Links are resolved with the link index of the vault, which reports the
+// ambiguous ones.
 func New(
 	fileIndex map[string][]*obsidian.File,
+	links *obsidian.LinkIndex,
 	loader func(path string) ([]byte, error),
 ) *ObsidianMarkdown {
 	resolver := &IndexResolver{
-		Index:    fileIndex,
-		Links:    []obsidian.GraphLink{},
-		ReadFile: loader,
+		LinkIndex: links,
+		Links:     []obsidian.GraphLink{},
+		ReadFile:  loader,
 	}
 	sourceMap := make(map[string]string)
 	for _, candidate := range fileIndex {
@@ -90,6 +94,9 @@ func (o *ObsidianMarkdown) Render(file obsidian.File) (NoteData, error) {
 	// This allows us to extract metadata (Frontmatter) and generate the TOC before rendering HTML.
 	ctx := parser.NewContext()
 	doc := o.markdown.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+	frontmatter := meta.Get(ctx)
+	o.Resolver.PageImages = pageImageOptions(frontmatter)
+	defer func() { o.Resolver.PageImages = PageImageOptions{} }()
 
 	// Generate Table of Contents from the AST
 	tocHTML := extractTOC(doc, source)
@@ -104,7 +111,7 @@ func (o *ObsidianMarkdown) Render(file obsidian.File) (NoteData, error) {
 	finalHTML := buf.String()
 	finalHTML = applyTransforms(finalHTML)
 
-	return NoteData{Content: finalHTML, TOC: tocHTML, Frontmatter: meta.Get(ctx)}, nil
+	return NoteData{Content: finalHTML, TOC: tocHTML, Frontmatter: frontmatter}, nil
 }
 
 // RenderNote processes the given content
@@ -128,6 +135,37 @@ type ObsidianMarkdown struct {
 	ImageResults map[string]*imgopt.Result // Optimized image variants keyed by WebPath
 }
 
+// PageImageOptions are the image defaults of a note, set in its frontmatter:
+//
+//	images:
+//	  loading: eager # eager or lazy
+//	  captions: true # use the alt text of every image as its caption
+type PageImageOptions struct {
+	Loading  string
+	Captions bool
+}
+
+// pageImageOptions reads the "images" key of the frontmatter.
+func pageImageOptions(frontmatter map[string]any) PageImageOptions {
+	opts := PageImageOptions{}
+	images := map[string]any{}
+	switch m := frontmatter["images"].(type) {
+	case map[string]any:
+		images = m
+	case map[any]any:
+		for k, v := range m {
+			images[fmt.Sprint(k)] = v
+		}
+	}
+	if loading, ok := images["loading"].(string); ok && (loading == "eager" || loading == "lazy") {
+		opts.Loading = loading
+	}
+	if captions, ok := images["captions"].(bool); ok {
+		opts.Captions = captions
+	}
+	return opts
+}
+
 // NoteData holds the data of the rendered note, like the body HTML and the table of contents
 type NoteData struct {
 	Content     string         // Content of the note
@@ -2,6 +2,8 @@
 package markdown
 
 import (
+	"bytes"
+	"log/slog"
 	"strings"
 	"testing"
 
@@ -16,7 +18,7 @@ func newTestMarkdown(files ...*obsidian.File) *ObsidianMarkdown {
 	loader := func(path string) ([]byte, error) {
 		return nil, nil
 	}
-	return New(index, loader)
+	return New(index, obsidian.NewLinkIndex(files, nil), loader)
 }
 
 func TestRenderNote_Wikilink(t *testing.T) {
@@ -159,3 +161,24 @@ func TestRenderNote_GraphLinksCollected(t *testing.T) {
 		t.Errorf("expected graph link to '/sibling', got links: %+v", links)
 	}
 }
+
+func TestRenderNote_AmbiguousWikilinkWarns(t *testing.T) {
+	files := []*obsidian.File{
+		{Name: "Target", FullName: "Target.md", RelPath: "a/Target.md", Ext: ".md", WebPath: "/a/target"},
+		{Name: "Target", FullName: "Target.md", RelPath: "b/Target.md", Ext: ".md", WebPath: "/b/target"},
+	}
+	index := make(map[string][]*obsidian.File)
+	for _, f := range files {
+		index[f.Name] = append(index[f.Name], f)
+	}
+	var logs bytes.Buffer
+	links := obsidian.NewLinkIndex(files, slog.New(slog.NewTextHandler(&logs, nil)))
+	md := New(index, links, func(path string) ([]byte, error) { return nil, nil })
+
+	if _, err := md.RenderNote([]byte("[[Target]]")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "Ambiguous link") {
+		t.Errorf("expected an ambiguity warning, got: %s", logs.String())
+	}
+}
@@ -27,7 +27,7 @@ func TestWriteImage_WithVariants(t *testing.T) {
 
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
-	r.writeImage(w, "/img/photo", []byte("a photo"), nil)
+	r.writeImage(w, "/img/photo", []byte("a photo"), nil, imgopt.Directives{})
 	w.Flush()
 	out := buf.String()
 
@@ -68,7 +68,7 @@ func TestWriteImage_NoVariants(t *testing.T) {
 
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
-	r.writeImage(w, "/img/notfound", []byte("alt"), nil)
+	r.writeImage(w, "/img/notfound", []byte("alt"), nil, imgopt.Directives{})
 	w.Flush()
 	out := buf.String()
 
@@ -111,7 +111,7 @@ func TestWriteImage_WithAVIFAndWebP(t *testing.T) {
 
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
-	r.writeImage(w, "/img/photo", []byte("a photo"), nil)
+	r.writeImage(w, "/img/photo", []byte("a photo"), nil, imgopt.Directives{})
 	w.Flush()
 	out := buf.String()
 
@@ -156,7 +156,7 @@ func TestWriteImage_NilResults(t *testing.T) {
 
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
-	r.writeImage(w, "/img/photo", []byte("alt"), nil)
+	r.writeImage(w, "/img/photo", []byte("alt"), nil, imgopt.Directives{})
 	w.Flush()
 	out := buf.String()
 
@@ -196,7 +196,7 @@ func TestWriteImage_FullOutputStructure(t *testing.T) {
 
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
-	r.writeImage(w, "/img/hero.png", []byte("Hero image"), nil)
+	r.writeImage(w, "/img/hero.png", []byte("Hero image"), nil, imgopt.Directives{})
 	w.Flush()
 	out := buf.String()
 
@@ -235,3 +235,70 @@ func TestWriteImage_FullOutputStructure(t *testing.T) {
 		t.Error("button must come before closing figure")
 	}
 }
+
+func TestWriteImage_Directives(t *testing.T) {
+	r := &IndexResolver{
+		ImageResults: map[string]*imgopt.Result{
+			"/img/photo": {
+				Original: "/img/photo.png",
+				Width:    1200,
+				Height:   800,
+				Variants: []imgopt.Variant{
+					{Width: 800, Format: "png", WebPath: "/img/photo-800w.png"},
+				},
+				Sized: map[string][]imgopt.Variant{
+					"300x0": {
+						{Width: 300, Format: "png", WebPath: "/img/photo-300w.png"},
+						{Width: 600, Format: "png", WebPath: "/img/photo-600w.png"},
+					},
+				},
+				Placeholder: "data:image/png;base64,AAAA",
+			},
+		},
+	}
+
+	d, alt := imgopt.ParseDirectives("A <cat>|300|eager|caption")
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	r.writeImage(w, "/img/photo", []byte(alt), nil, d)
+	w.Flush()
+	out := buf.String()
+
+	checks := []string{
+		`srcset="/img/photo-300w.png 300w, /img/photo-600w.png 600w"`,
+		`sizes="min(300px, 100vw)"`,
+		`width="300" height="200"`,
+		`loading="eager" decoding="async"`,
+		`background:url(data:image/png;base64,AAAA)`,
+		`<figcaption>A &lt;cat&gt;</figcaption>`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\ngot: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "photo-800w") {
+		t.Errorf("sized images should not use the breakpoint variants\ngot: %s", out)
+	}
+}
+
+func TestWriteImage_PageDefaults(t *testing.T) {
+	r := &IndexResolver{
+		ImageResults: map[string]*imgopt.Result{
+			"/img/anim.gif": {Original: "/img/anim.gif", Width: 120, Height: 80},
+		},
+		PageImages: PageImageOptions{Loading: "eager", Captions: true},
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	r.writeImage(w, "/img/anim.gif", []byte("Spinner"), nil, imgopt.Directives{})
+	w.Flush()
+	out := buf.String()
+
+	for _, want := range []string{`width="120" height="80"`, `loading="eager"`, `<figcaption>Spinner</figcaption>`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\ngot: %s", want, out)
+		}
+	}
+}
@@ -2,11 +2,12 @@
 package markdown
 
 import (
+	"bytes"
 	"fmt"
+	"html"
 	"path"
 	"strings"
 
-	"errors"
 	"path/filepath"
 
 	"github.com/otaleghani/kiln/internal/imgopt"
@@ -55,88 +56,12 @@ func (r *IndexResolver) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
 	return []byte(file.WebPath + anchor), nil
 }
 
-var ErrorCandidateNotFound = errors.New("Candidate not found")
+var ErrorCandidateNotFound = obsidian.ErrorCandidateNotFound
 
-// FindFile returns the best File match for the given target and the anchor
+// FindFile returns the best File match for the given target and the anchor,
+// resolving relative links from the current source.
 func (r *IndexResolver) FindFile(target []byte) (*obsidian.File, string, error) {
-	dest := string(target)
-	dest = strings.TrimSpace(dest)
-
-	// Separate anchor
-	anchor := ""
-	if idx := strings.Index(dest, "#"); idx != -1 {
-		anchor = dest[idx:]
-		dest = dest[:idx]
-	}
-
-	name, ext := SplitExt(dest)
-	// Remove extension
-	if ext == ".md" {
-		dest = strings.TrimSuffix(dest, ext)
-	}
-
-	// Lookup in Index (case insensitive)
-	candidates, ok := r.Index[name]
-	// Fallback: Try looking up the full path (for .base and .canvas files)
-	// Then try it lowercased (rare, but good for safety)
-	if !ok {
-		if alt, ok := r.Index[dest]; ok {
-			candidates = alt
-		} else {
-			lowerDest := strings.ToLower(dest)
-			if alt, ok := r.Index[lowerDest]; ok {
-				candidates = alt
-			} else {
-				return nil, anchor, ErrorCandidateNotFound
-			}
-		}
-	}
-
-	if len(candidates) == 0 {
-		return nil, anchor, ErrorCandidateNotFound
-	}
-
-	// Select best match
-	var bestMatch *obsidian.File
-
-	// If user provided a path (e.g. "Deployment/Cloudflare Pages")
-	if strings.Contains(dest, "/") || strings.Contains(dest, "\\") {
-		for _, file := range candidates {
-			if strings.Contains(file.RelPath, dest) {
-				bestMatch = file
-				break
-			}
-		}
-
-		// Fallback: If contains failed, return first match
-		if bestMatch == nil {
-			bestMatch = candidates[0]
-		}
-
-	} else {
-		// No path provided (e.g. "Cloudflare Pages"), use standard priority (first root match, then shortest path)
-
-		// Root Match
-		for _, file := range candidates {
-			if !strings.Contains(file.RelPath, "/") && !strings.Contains(file.RelPath, "\\") {
-				bestMatch = file
-				break
-			}
-		}
-
-		// Shortest Path
-		if bestMatch == nil {
-			shortest := candidates[0]
-			for _, file := range candidates {
-				if len(file.Path) < len(shortest.Path) {
-					shortest = file
-				}
-			}
-			bestMatch = shortest
-		}
-	}
-
-	return bestMatch, anchor, nil
+	return r.LinkIndex.Find(r.LinkIndex.FileByWebPath(r.CurrentSource), string(target))
 }
 
 // recordLink adds a directed edge to the graph if the source and target differ.
@@ -366,8 +291,17 @@ func (r *IndexResolver) renderImage(
 	n := node.(*ast.Image)
 	newDest := r.ResolveMarkdownLink(string(n.Destination))
 
+	// Obsidian accepts directives in the alt text too: ![Alt|300](photo.png)
+	alt := n.Text(source)
+	directives := imgopt.Directives{}
+	if bytes.Contains(alt, []byte("|")) {
+		var altText string
+		directives, altText = imgopt.ParseDirectives(string(alt))
+		alt = []byte(altText)
+	}
+
 	// Use the helper
-	r.writeImage(w, newDest, n.Text(source), n.Title)
+	r.writeImage(w, newDest, alt, n.Title, directives)
 
 	return ast.WalkSkipChildren, nil
 }
@@ -375,19 +309,39 @@ func (r *IndexResolver) renderImage(
 // writeImage is a helper to ensure consistent image rendering for both
 // standard markdown images and Obsidian wikilink images.
 // If ImageResults contains variants for src, it emits a <picture> element;
-// otherwise it emits a plain <img loading="lazy">.
-func (r *IndexResolver) writeImage(w util.BufWriter, src string, alt []byte, title []byte) {
+// otherwise it emits a plain <img>. Known dimensions are always written to
+// avoid layout shifts.
+func (r *IndexResolver) writeImage(w util.BufWriter, src string, alt []byte, title []byte, d imgopt.Directives) {
 	w.WriteString(`<figure class="img-figure">`)
 
+	var result *imgopt.Result
 	if r.ImageResults != nil {
-		if result, ok := r.ImageResults[src]; ok && len(result.Variants) > 0 {
-			r.writePicture(w, src, alt, title, result)
-			r.writeExpandButton(w)
-			w.WriteString("</figure>")
-			return
-		}
+		result = r.ImageResults[src]
+	}
+
+	if result != nil && len(result.Variants)+len(result.Sized[d.Key()]) > 0 {
+		r.writePicture(w, src, alt, title, result, d)
+	} else {
+		r.writeImg(w, src, alt, title, result, d, "")
+	}
+	r.writeExpandButton(w)
+
+	caption := d.Caption
+	if caption == "" && r.PageImages.Captions {
+		// The alt text of wikilink images is already escaped
+		caption = html.UnescapeString(string(alt))
+	}
+	if caption != "" {
+		w.WriteString("<figcaption>")
+		w.WriteString(html.EscapeString(caption))
+		w.WriteString("</figcaption>")
 	}
+	w.WriteString("</figure>")
+}
 
+// writeImg writes the <img> tag of an image, with its dimensions, loading
+// strategy, crop and placeholder.
+func (r *IndexResolver) writeImg(w util.BufWriter, src string, alt []byte, title []byte, result *imgopt.Result, d imgopt.Directives, sizes string) {
 	w.WriteString("<img src=\"")
 	w.WriteString(src)
 	w.WriteString("\" alt=\"")
@@ -398,9 +352,50 @@ func (r *IndexResolver) writeImage(w util.BufWriter, src string, alt []byte, tit
 		w.Write(title)
 		w.WriteString("\"")
 	}
-	w.WriteString(" loading=\"lazy\">")
-	r.writeExpandButton(w)
-	w.WriteString("</figure>")
+
+	intrinsicW, intrinsicH := 0, 0
+	if result != nil {
+		intrinsicW, intrinsicH = result.Width, result.Height
+	}
+	if width, height := d.Dimensions(intrinsicW, intrinsicH); width > 0 && height > 0 {
+		fmt.Fprintf(w, ` width="%d" height="%d"`, width, height)
+	} else if width > 0 {
+		fmt.Fprintf(w, ` width="%d"`, width)
+	}
+	if sizes != "" {
+		w.WriteString(` sizes="`)
+		w.WriteString(sizes)
+		w.WriteString(`"`)
+	}
+
+	loading := d.Loading
+	if loading == "" {
+		loading = r.PageImages.Loading
+	}
+	if loading == "eager" {
+		w.WriteString(` loading="eager" decoding="async"`)
+	} else {
+		w.WriteString(` loading="lazy" decoding="async"`)
+	}
+
+	styles := []string{}
+	if d.Crop {
+		styles = append(styles, fmt.Sprintf("object-fit:cover;object-position:%d%% %d%%", d.FocusX, d.FocusY))
+	}
+	if result != nil && result.Placeholder != "" {
+		styles = append(styles, "background:url("+result.Placeholder+") center/cover no-repeat")
+	}
+	if len(styles) > 0 {
+		w.WriteString(` style="`)
+		w.WriteString(strings.Join(styles, ";"))
+		w.WriteString(`"`)
+	}
+	if result != nil && result.BlurHash != "" {
+		w.WriteString(` data-blurhash="`)
+		w.WriteString(html.EscapeString(result.BlurHash))
+		w.WriteString(`"`)
+	}
+	w.WriteString(">")
 }
 
 func (r *IndexResolver) writeExpandButton(w util.BufWriter) {
@@ -415,14 +410,22 @@ func (r *IndexResolver) writeExpandButton(w util.BufWriter) {
 }
 
 // writePicture emits a <picture> element with <source> entries for each format
-// and a fallback <img>.
-func (r *IndexResolver) writePicture(w util.BufWriter, src string, alt []byte, title []byte, result *imgopt.Result) {
+// and a fallback <img>. Sized directives use their own variants.
+func (r *IndexResolver) writePicture(w util.BufWriter, src string, alt []byte, title []byte, result *imgopt.Result, d imgopt.Directives) {
 	w.WriteString("<picture>")
 
+	variants := result.Variants
+	sizes := "min(65ch, 100vw)"
+	if sized := result.Sized[d.Key()]; d.Sized() && len(sized) > 0 {
+		variants = sized
+		width, _ := d.Dimensions(result.Width, result.Height)
+		sizes = fmt.Sprintf("min(%dpx, 100vw)", width)
+	}
+
 	// Group variants by format and build srcset strings.
 	formatOrder := []string{}
 	srcsets := map[string][]string{}
-	for _, v := range result.Variants {
+	for _, v := range variants {
 		if _, seen := srcsets[v.Format]; !seen {
 			formatOrder = append(formatOrder, v.Format)
 		}
@@ -436,21 +439,13 @@ func (r *IndexResolver) writePicture(w util.BufWriter, src string, alt []byte, t
 		w.WriteString(mime)
 		w.WriteString("\" srcset=\"")
 		w.WriteString(strings.Join(entries, ", "))
-		w.WriteString("\" sizes=\"min(65ch, 100vw)\">")
+		w.WriteString("\" sizes=\"")
+		w.WriteString(sizes)
+		w.WriteString("\">")
 	}
 
 	// Fallback <img>
-	w.WriteString("<img src=\"")
-	w.WriteString(src)
-	w.WriteString("\" alt=\"")
-	w.Write(alt)
-	w.WriteString("\"")
-	if len(title) > 0 {
-		w.WriteString(" title=\"")
-		w.Write(title)
-		w.WriteString("\"")
-	}
-	w.WriteString(" sizes=\"min(65ch, 100vw)\" loading=\"lazy\">")
+	r.writeImg(w, src, alt, title, result, d, sizes)
 	w.WriteString("</picture>")
 }
 
@@ -542,16 +537,21 @@ func (r *IndexResolver) renderWikilink(
 	if isImageFile(ext) {
 		// Extract Alt Text: In Wikilinks ([[Image.png|Alt Text]]),
 		// the text after the pipe is stored as the node's children.
-		var altText []byte
+		var label []byte
 		if n.HasChildren() {
 			// Iterate over children to reconstruct the label/alt text
 			for child := n.FirstChild(); child != nil; child = child.NextSibling() {
 				// We assume text nodes here; simpler than full recursion
 				if child.Kind() == ast.KindText {
-					altText = append(altText, child.Text(source)...)
+					label = append(label, child.Text(source)...)
 				}
 			}
-		} else {
+		}
+
+		// The label holds the alt text and the directives ([[Image.png|Alt|300x200]])
+		directives, alt := imgopt.ParseDirectives(string(label))
+		altText := []byte(html.EscapeString(alt))
+		if alt == "" {
 			// If no alt text is provided, some people prefer using the filename,
 			// others prefer empty. Obsidian uses the filename often.
 			altText = n.Target
@@ -559,7 +559,7 @@ func (r *IndexResolver) renderWikilink(
 
 		// Note: Wikilinks don't officially support "Title" attributes (tooltip),
 		// so we pass nil. If you want title, you'd have to parse it from the alt text manually.
-		r.writeImage(w, webPath, altText, nil)
+		r.writeImage(w, webPath, altText, nil, directives)
 		return ast.WalkSkipChildren, nil
 	}
 
@@ -597,10 +597,7 @@ func isImageFile(ext string) bool {
 
 // SplitExt returns the base name and the extension of the given path
 func SplitExt(path string) (name, ext string) {
-	ext = filepath.Ext(path)
-	// name = strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ext)
-	name = strings.TrimSuffix(filepath.Base(path), ext)
-	return
+	return obsidian.SplitExt(path)
 }
 
 // slugify converts a string to a URL-friendly format.
@@ -628,7 +625,7 @@ func slugify(s string) string {
 // It intercepts both Wikilinks ([[...]]) and standard Markdown links to ensure
 // they point to the correct URL, respecting the site's BasePath.
 type IndexResolver struct {
-	Index         map[string][]*obsidian.File       // Lowercase filename -> Candidate files
+	LinkIndex     *obsidian.LinkIndex               // Resolves the links, shared with the vault
 	SourceMap     map[string]string                 // Webpath -> Real file (used for text embedding)
 	Links         []obsidian.GraphLink              // All of the graph links
 	CurrentSource string                            // The current source
@@ -636,4 +633,5 @@ type IndexResolver struct {
 	Engine        goldmark.Markdown                 // Needed for recursion
 	ReadFile      func(path string) ([]byte, error) // Needed for loading files
 	ImageResults  map[string]*imgopt.Result         // Optimized image variants keyed by src path
+	PageImages    PageImageOptions                  // Image defaults of the page being rendered
 }
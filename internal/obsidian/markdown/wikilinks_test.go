@@ -9,8 +9,12 @@ import (
 )
 
 func newTestResolver(index map[string][]*obsidian.File) *IndexResolver {
+	files := []*obsidian.File{}
+	for _, candidates := range index {
+		files = append(files, candidates...)
+	}
 	return &IndexResolver{
-		Index:     index,
+		LinkIndex: obsidian.NewLinkIndex(files, nil),
 		SourceMap: map[string]string{},
 		Links:     []obsidian.GraphLink{},
 	}
@@ -96,13 +100,13 @@ func TestFindFile_PathBasedLookupNoMatch(t *testing.T) {
 		"note": {candidate},
 	})
 
-	// Path-based lookup where the path doesn't match — should fallback to first candidate
+	// Path-based lookup where the path doesn't match: like Obsidian, the link is unresolved
 	file, _, err := r.FindFile([]byte("folder/note"))
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if !errors.Is(err, ErrorCandidateNotFound) {
+		t.Fatalf("expected ErrorCandidateNotFound, got %v", err)
 	}
-	if file != candidate {
-		t.Errorf("expected fallback to first candidate, got %+v", file)
+	if file != nil {
+		t.Errorf("expected nil file, got %+v", file)
 	}
 }
 
@@ -0,0 +1,324 @@
+// @feature:backlinks Backlink context excerpts and unlinked mentions.
+package obsidian
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// mentionContext is the maximum number of characters kept on each side of a mention.
+const mentionContext = 120
+
+// minMentionTerm is the minimum length of a title or alias searched for unlinked mentions.
+const minMentionTerm = 3
+
+var (
+	// Leading YAML frontmatter, whose title and aliases are not mentions
+	mentionFrontmatterRegex = regexp.MustCompile(`\A---\r?\n(?s:.*?)\r?\n---[ \t]*(?:\r?\n|\z)`)
+	// Fenced code blocks, removed before searching for unlinked mentions
+	mentionFenceRegex = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)")
+	// Inline code spans and bare URLs, never considered a mention
+	mentionCodeRegex = regexp.MustCompile("`[^`\n]*`|https?://\\S+")
+	// Markdown emphasis, highlight and strikethrough markers
+	mentionEmphasisRegex = regexp.MustCompile(`\*\*|__|==|~~|\*`)
+	// Leading heading, quote and list markers of a line
+	mentionMarkerRegex = regexp.MustCompile(`(?m)^\s*(#{1,6}\s+|>\s*|[-*+]\s+(\[.\]\s+)?|\d+[.)]\s+)`)
+)
+
+// Mention is an occurrence of a note inside another note, with the text
+// around it. Before and After are plain text, Text is the linked or
+// mentioned text itself.
+type Mention struct {
+	Source *File  // The note containing the mention
+	Before string // Text preceding the mention in the same paragraph
+	Text   string // The link text or the matched title
+	After  string // Text following the mention in the same paragraph
+}
+
+// linkContexts returns one Mention per occurrence of the raw link in the
+// content of source.
+func linkContexts(source *File, rawLink string) []Mention {
+	content := string(source.Content)
+	mentions := []Mention{}
+	for offset := 0; offset < len(content); {
+		i := strings.Index(content[offset:], rawLink)
+		if i == -1 {
+			break
+		}
+		start := offset + i
+		end := start + len(rawLink)
+		offset = end
+
+		// Keep the embed marker out of the excerpt
+		if start > 0 && content[start-1] == '!' {
+			start--
+		}
+		mentions = append(mentions, newMention(source, content, start, end, linkText(rawLink)))
+	}
+	return mentions
+}
+
+// newMention builds a Mention for content[start:end], using the block
+// around it as context.
+func newMention(source *File, content string, start, end int, text string) Mention {
+	blockStart, blockEnd := blockBounds(content, start, end)
+	return Mention{
+		Source: source,
+		Before: trimBefore(inlinePlainText(content[blockStart:start])),
+		Text:   text,
+		After:  trimAfter(inlinePlainText(content[end:blockEnd])),
+	}
+}
+
+// blockBounds returns the paragraph containing content[start:end]. List
+// items, headings, quotes and table rows are narrowed down to their line.
+func blockBounds(content string, start, end int) (int, int) {
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := strings.IndexByte(content[end:], '\n'); i != -1 {
+		lineEnd = end + i
+	}
+	line := strings.TrimSpace(content[lineStart:lineEnd])
+	if mentionMarkerRegex.MatchString(line) || strings.HasPrefix(line, "|") {
+		return lineStart, lineEnd
+	}
+
+	blockStart := 0
+	if i := strings.LastIndex(content[:start], "\n\n"); i != -1 {
+		blockStart = i + 2
+	}
+	blockEnd := len(content)
+	if i := strings.Index(content[end:], "\n\n"); i != -1 {
+		blockEnd = end + i
+	}
+	return blockStart, blockEnd
+}
+
+// linkText returns the text displayed for a raw wikilink or markdown link.
+func linkText(rawLink string) string {
+	if isMarkdownLink(rawLink) {
+		text := rawLink[1:strings.Index(rawLink, "](")]
+		if text == "" {
+			return linkTarget(rawLink)
+		}
+		return text
+	}
+	inner := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(rawLink, "!"), "[["), "]]")
+	target, alias, found := strings.Cut(inner, "|")
+	if found && strings.TrimSpace(alias) != "" {
+		return strings.TrimSpace(alias)
+	}
+	target, heading, _ := strings.Cut(target, "#")
+	if i := strings.LastIndex(target, "/"); i != -1 {
+		target = target[i+1:]
+	}
+	if heading = strings.TrimPrefix(heading, "^"); heading != "" {
+		if target == "" {
+			return heading
+		}
+		return target + " > " + heading
+	}
+	return strings.TrimSpace(target)
+}
+
+// linkTarget returns the destination of a raw markdown link.
+func linkTarget(rawLink string) string {
+	start := strings.Index(rawLink, "](")
+	if start == -1 {
+		return ""
+	}
+	return rawLink[start+2 : len(rawLink)-1]
+}
+
+// inlinePlainText converts a markdown fragment to a single line of text,
+// replacing links with their displayed text.
+func inlinePlainText(s string) string {
+	s = wikilinkRegex.ReplaceAllStringFunc(s, linkText)
+	s = mdLinkRegex.ReplaceAllStringFunc(s, func(m string) string {
+		return linkText(strings.TrimPrefix(m, "!"))
+	})
+	s = mentionMarkerRegex.ReplaceAllString(s, "")
+	s = mentionEmphasisRegex.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "`", "")
+	// Table cell separators
+	s = strings.ReplaceAll(s, "|", " ")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// trimBefore keeps the last mentionContext characters of s, cutting at a word boundary.
+func trimBefore(s string) string {
+	if utf8.RuneCountInString(s) <= mentionContext {
+		return s
+	}
+	r := []rune(s)
+	s = string(r[len(r)-mentionContext:])
+	if i := strings.IndexByte(s, ' '); i != -1 {
+		s = s[i+1:]
+	}
+	return "…" + s
+}
+
+// trimAfter keeps the first mentionContext characters of s, cutting at a word boundary.
+func trimAfter(s string) string {
+	if utf8.RuneCountInString(s) <= mentionContext {
+		return s
+	}
+	s = string([]rune(s)[:mentionContext])
+	if i := strings.LastIndexByte(s, ' '); i != -1 {
+		s = s[:i]
+	}
+	return s + "…"
+}
+
+// mentionTerms returns the names a note can be mentioned by: its file name,
+// its frontmatter title and its aliases.
+func mentionTerms(f *File) []string {
+	candidates := []string{f.Name}
+	if title, ok := f.Frontmatter["title"].(string); ok {
+		candidates = append(candidates, title)
+	}
+	switch aliases := f.Frontmatter["aliases"].(type) {
+	case string:
+		candidates = append(candidates, aliases)
+	case []any:
+		for _, a := range aliases {
+			candidates = append(candidates, fmt.Sprint(a))
+		}
+	}
+
+	terms := []string{}
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		c = strings.TrimSpace(c)
+		key := strings.ToLower(c)
+		if utf8.RuneCountInString(c) < minMentionTerm || seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, c)
+	}
+	return terms
+}
+
+// mentionRegex compiles a case-insensitive regular expression matching any
+// of the terms. Longer terms come first, so that the longest one wins when
+// several start at the same position. Word boundaries are checked by the
+// caller, see isMentionBoundary.
+func mentionRegex(terms []string) *regexp.Regexp {
+	sorted := slices.Clone(terms)
+	slices.SortStableFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+	quoted := make([]string, len(sorted))
+	for i, t := range sorted {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// isMentionBoundary reports whether content[start:end] is a whole word, that
+// is not preceded nor followed by a letter, a digit or an underscore.
+func isMentionBoundary(content string, start, end int) bool {
+	isWord := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	if before, _ := utf8.DecodeLastRuneInString(content[:start]); start > 0 && isWord(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(content[end:]); end < len(content) && isWord(after) {
+		return false
+	}
+	return true
+}
+
+// blank replaces every byte of m but newlines with a space.
+func blank(m string) string {
+	b := []byte(m)
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// stripFrontmatter blanks out the leading YAML frontmatter of content,
+// keeping byte offsets intact.
+func stripFrontmatter(content string) string {
+	return mentionFrontmatterRegex.ReplaceAllStringFunc(content, blank)
+}
+
+// maskLinks blanks out fenced code, inline code, URLs and links, keeping
+// byte offsets intact, so that only plain text is searched for mentions.
+func maskLinks(content string) string {
+	content = mentionFenceRegex.ReplaceAllStringFunc(content, blank)
+	content = mentionCodeRegex.ReplaceAllStringFunc(content, blank)
+	content = wikilinkRegex.ReplaceAllStringFunc(content, blank)
+	content = mdLinkRegex.ReplaceAllStringFunc(content, blank)
+	return content
+}
+
+// GenerateUnlinkedMentions populates the UnlinkedMentions field of every
+// note with the plain-text occurrences of its name, title or aliases in the
+// other notes. Text that is already a link, or inside code, is ignored.
+func GenerateUnlinkedMentions(files []*File) {
+	notes := []*File{}
+	// Notes mentioned by each term, keyed by its lowercase form
+	targets := map[string][]*File{}
+	terms := []string{}
+	for _, f := range files {
+		if f.Ext != ".md" {
+			continue
+		}
+		f.UnlinkedMentions = nil
+		notes = append(notes, f)
+		for _, term := range mentionTerms(f) {
+			key := strings.ToLower(term)
+			if _, ok := targets[key]; !ok {
+				terms = append(terms, term)
+			}
+			targets[key] = append(targets[key], f)
+		}
+	}
+	if len(terms) == 0 {
+		return
+	}
+	re := mentionRegex(terms)
+
+	for _, source := range notes {
+		content := stripFrontmatter(string(source.Content))
+		masked := maskLinks(content)
+		// A single mention per paragraph is enough context
+		blocks := map[*File]map[int]bool{}
+		for offset := 0; offset < len(masked); {
+			m := re.FindStringIndex(masked[offset:])
+			if m == nil {
+				break
+			}
+			start, end := offset+m[0], offset+m[1]
+			if !isMentionBoundary(masked, start, end) {
+				_, size := utf8.DecodeRuneInString(masked[start:])
+				offset = start + size
+				continue
+			}
+			offset = end
+
+			blockStart, _ := blockBounds(content, start, end)
+			for _, target := range targets[strings.ToLower(content[start:end])] {
+				if target == source || blocks[target][blockStart] {
+					continue
+				}
+				if blocks[target] == nil {
+					blocks[target] = map[int]bool{}
+				}
+				blocks[target][blockStart] = true
+				target.UnlinkedMentions = append(
+					target.UnlinkedMentions,
+					newMention(source, content, start, end, content[start:end]),
+				)
+			}
+		}
+	}
+}
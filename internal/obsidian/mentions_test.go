@@ -0,0 +1,128 @@
+// @feature:backlinks Tests for backlink contexts and unlinked mentions.
+package obsidian
+
+import (
+	"strings"
+	"testing"
+)
+
+// newNote returns a parsed note with the given name, frontmatter and body.
+func newNote(t *testing.T, name, content string) *File {
+	t.Helper()
+	f := newTestFile(t, content)
+	f.Name = name
+	if err := f.processMarkdown(); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestGenerateBacklinks_Contexts(t *testing.T) {
+	target := newNote(t, "Target", "Nothing here.")
+	source := newNote(t, "Source", "Intro line.\n\nWe talk about **the** [[Target|target note]] in this paragraph.\nStill the same one.\n\n- a list item with [[Target]]\n- another item\n\nEmbedded: ![[Target]]")
+
+	GenerateBacklinks([]*File{target, source})
+
+	if len(target.Backlinks) != 1 || target.Backlinks[0] != source {
+		t.Fatalf("unexpected backlinks %v", target.Backlinks)
+	}
+	got := target.BacklinkContexts
+	if len(got) != 3 {
+		t.Fatalf("expected 3 contexts, got %d: %+v", len(got), got)
+	}
+
+	first := got[0]
+	if first.Source != source {
+		t.Error("context source should be the linking note")
+	}
+	if first.Before != "We talk about the" || first.Text != "target note" || first.After != "in this paragraph. Still the same one." {
+		t.Errorf("unexpected paragraph context %+v", first)
+	}
+	if got[1].Before != "a list item with" || got[1].Text != "Target" || got[1].After != "" {
+		t.Errorf("list items should be narrowed to their line, got %+v", got[1])
+	}
+	if got[2].Before != "Embedded:" {
+		t.Errorf("embed marker should not be part of the context, got %+v", got[2])
+	}
+
+	// Running again must not duplicate the contexts
+	GenerateBacklinks([]*File{target, source})
+	if len(target.BacklinkContexts) != 3 {
+		t.Errorf("expected contexts to be reset, got %d", len(target.BacklinkContexts))
+	}
+}
+
+func TestLinkText(t *testing.T) {
+	cases := map[string]string{
+		"[[Note]]":             "Note",
+		"[[Folder/Note]]":      "Note",
+		"[[Note|Alias]]":       "Alias",
+		"[[Note#Heading]]":     "Note > Heading",
+		"[[#Heading]]":         "Heading",
+		"[text](note.md)":      "text",
+		"[](folder/note.md)":   "folder/note.md",
+		"![[image.png|300]]":   "300",
+		"[[Note#^block-id|x]]": "x",
+	}
+	for raw, want := range cases {
+		if got := linkText(raw); got != want {
+			t.Errorf("linkText(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestTrimContext(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+	before := trimBefore(strings.TrimSpace(long))
+	if !strings.HasPrefix(before, "…word") {
+		t.Errorf("expected ellipsis and a whole word, got %q", before)
+	}
+	after := trimAfter(strings.TrimSpace(long))
+	if !strings.HasSuffix(after, "word…") {
+		t.Errorf("expected a whole word and ellipsis, got %q", after)
+	}
+	if trimAfter("short") != "short" {
+		t.Error("short text should be left untouched")
+	}
+}
+
+func TestGenerateUnlinkedMentions(t *testing.T) {
+	target := newNote(t, "Kiln", "---\naliases:\n  - static generator\n  - SG\n---\nBody.")
+	linked := newNote(t, "Linked", "Already linked: [[Kiln]] and [kiln](Kiln.md).\n\n`kiln generate` and https://kiln.dev")
+	plain := newNote(t, "Plain", "I use kiln daily. Kiln is great.\n\nA Static Generator is handy.\n\nkilns and unkiln are not mentions.\n\n```\nkiln in code\n```")
+	short := newNote(t, "Other", "SG alone is too short to match.")
+
+	GenerateUnlinkedMentions([]*File{target, linked, plain, short})
+
+	got := target.UnlinkedMentions
+	if len(got) != 2 {
+		t.Fatalf("expected 2 mentions, got %d: %+v", len(got), got)
+	}
+	if got[0].Source != plain || got[0].Text != "kiln" || got[0].Before != "I use" || got[0].After != "daily. Kiln is great." {
+		t.Errorf("unexpected first mention %+v", got[0])
+	}
+	if got[1].Text != "Static Generator" {
+		t.Errorf("expected alias mention, got %+v", got[1])
+	}
+
+	if len(plain.UnlinkedMentions) != 0 {
+		t.Errorf("notes never mention themselves, got %+v", plain.UnlinkedMentions)
+	}
+}
+
+func TestGenerateUnlinkedMentions_Boundaries(t *testing.T) {
+	alpha := newNote(t, "Alpha", "Body.")
+	beta := newNote(t, "Beta", "Body.")
+	source := newNote(t, "Source", "Alpha Beta,Alpha.")
+	// Frontmatter left in the content is never searched
+	source.Content = []byte("---\ntitle: About Beta\n---\n" + string(source.Content))
+
+	GenerateUnlinkedMentions([]*File{alpha, beta, source})
+
+	if len(alpha.UnlinkedMentions) != 1 || alpha.UnlinkedMentions[0].After != "Beta,Alpha." {
+		t.Errorf("expected one mention of Alpha per paragraph, got %+v", alpha.UnlinkedMentions)
+	}
+	if len(beta.UnlinkedMentions) != 1 || beta.UnlinkedMentions[0].Before != "Alpha" {
+		t.Errorf("expected the mention of Beta right after Alpha, got %+v", beta.UnlinkedMentions)
+	}
+}
@@ -2,15 +2,47 @@
 package obsidian
 
 import (
+	"cmp"
 	"net/url"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 // GenerateNavbar constructs the navigation tree using the existing Vault data.
 // It replaces getSidebarRootNode and buildSidebarTree.
 func (o *Obsidian) GenerateNavbar() *NavbarNode {
+	rootNode := o.generateNavbar(nil)
+	o.log.Info("Sidebar tree constructed from Vault data")
+	return rootNode
+}
+
+// GenerateNavbars constructs the navigation tree of every language of the
+// vault, listing only the pages written in it. The tree of a language with
+// its own top-level folder starts inside that folder.
+func (o *Obsidian) GenerateNavbars() map[string]*NavbarNode {
+	navbars := make(map[string]*NavbarNode, len(o.Vault.Languages))
+	for _, lang := range o.Vault.Languages {
+		rootNode := o.generateNavbar(func(f *File) bool { return f.Lang == lang })
+		if folder, ok := o.langFolders[lang]; ok {
+			for _, child := range rootNode.Children {
+				if child.IsFolder && child.sortName() == filepath.Base(folder) {
+					rootNode.Path = child.Path
+					rootNode.Children = child.Children
+					break
+				}
+			}
+		}
+		navbars[lang] = rootNode
+	}
+	o.log.Info("Sidebar trees constructed from Vault data", "languages", len(navbars))
+	return navbars
+}
+
+// generateNavbar constructs the navigation tree of the files accepted by
+// keep, or of every file when keep is nil.
+func (o *Obsidian) generateNavbar(keep func(*File) bool) *NavbarNode {
 	// Calculate base path from BaseURL
 	basePath := "/"
 	if u, err := url.Parse(o.BaseURL); err == nil {
@@ -51,10 +83,11 @@ func (o *Obsidian) GenerateNavbar() *NavbarNode {
 		}
 
 		node := &NavbarNode{
-			Name:     filepath.Base(folder.RelPath),
+			Name:     o.DisplayName(filepath.Base(folder.RelPath)),
 			Path:     folder.WebPath,
 			IsFolder: true,
 			Children: []*NavbarNode{},
+			sortKey:  filepath.Base(folder.RelPath),
 		}
 		folderMap[folder.RelPath] = node
 	}
@@ -74,39 +107,32 @@ func (o *Obsidian) GenerateNavbar() *NavbarNode {
 		default:
 			continue
 		}
+		if keep != nil && !keep(file) {
+			continue
+		}
 
-		// Do not the file again if a folder with the same name exists
+		// Do not the file again if it's the folder note of a folder.
 		// Also change the folder path to the current file webpath.
 		// This should solve non-flat-urls links
-		if folder, exists := folderMap[strings.TrimSuffix(file.RelPath, file.Ext)]; exists {
-			switch file.Ext {
-			case ".md":
-				folder.IsNote = true
-				folder.Path = file.WebPath
-			case ".canvas":
-				folder.IsCanvas = true
-				folder.Path = file.WebPath
-			case ".base":
-				folder.IsBase = true
-				folder.Path = file.WebPath
-			default:
-				folder.Path = file.WebPath
-				continue
-			}
+		if folder := o.folderNoteOf(folderMap, file); folder != nil {
+			folder.applyFolderNote(file)
+			continue
+		}
+		if navHidden(file) {
 			continue
 		}
 
 		node := &NavbarNode{
-			Name: strings.TrimSuffix(
-				file.Name,
-				file.Ext,
-			), // TODO: file.Name should be without ext, no?
+			Name:     o.NavName(file),
 			Path:     file.WebPath,
 			IsNote:   isNote,
 			IsCanvas: isCanvas,
 			IsBase:   isBase,
 			IsFolder: false,
+			Icon:     frontmatterString(file, "icon"),
+			sortKey:  strings.TrimSuffix(file.Name, file.Ext),
 		}
+		node.Weight, node.Weighted = file.NavWeight()
 
 		// Determine the parent folder based on Relative Path
 		parentDir := filepath.Dir(file.RelPath)
@@ -145,17 +171,18 @@ func (o *Obsidian) GenerateNavbar() *NavbarNode {
 	rootNode.Children = pruneNavbarTree(rootNode.Children)
 	sortNavbarTree(rootNode.Children)
 
-	o.log.Info("Sidebar tree constructed from Vault data")
-
 	return rootNode
 }
 
 // --- Helper Functions (Preserved logic) ---
 
-// pruneNavbarTree removes folders that end up empty.
+// pruneNavbarTree removes folders that end up empty or are hidden.
 func pruneNavbarTree(nodes []*NavbarNode) []*NavbarNode {
 	var kept []*NavbarNode
 	for _, n := range nodes {
+		if n.hidden {
+			continue
+		}
 		if n.IsFolder {
 			// Recursively prune children first
 			n.Children = pruneNavbarTree(n.Children)
@@ -170,9 +197,17 @@ func pruneNavbarTree(nodes []*NavbarNode) []*NavbarNode {
 	return kept
 }
 
-// sortNavbarTree sorts nodes in place: Folders top, then files, both alphabetically.
+// sortNavbarTree sorts nodes in place: nodes with a weight top, by weight,
+// then Folders, then files, both in natural order.
 func sortNavbarTree(nodes []*NavbarNode) {
 	sort.Slice(nodes, func(i, j int) bool {
+		// Prioritize weighted nodes, lighter first
+		if nodes[i].Weighted != nodes[j].Weighted {
+			return nodes[i].Weighted
+		}
+		if nodes[i].Weighted && nodes[i].Weight != nodes[j].Weight {
+			return nodes[i].Weight < nodes[j].Weight
+		}
 		// Prioritize Folders over Files
 		if nodes[i].IsFolder && !nodes[j].IsFolder {
 			return true
@@ -180,8 +215,12 @@ func sortNavbarTree(nodes []*NavbarNode) {
 		if !nodes[i].IsFolder && nodes[j].IsFolder {
 			return false
 		}
-		// Alphabetical sort for same types
-		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+		// Natural sort for same types, by file name so that numeric
+		// prefixes keep their order when they aren't shown
+		if c := naturalCompare(nodes[i].sortName(), nodes[j].sortName()); c != 0 {
+			return c < 0
+		}
+		return nodes[i].sortName() < nodes[j].sortName()
 	})
 
 	// Recursively sort children
@@ -192,6 +231,144 @@ func sortNavbarTree(nodes []*NavbarNode) {
 	}
 }
 
+// naturalCompare compares two names ignoring case, with the runs of digits
+// compared by their value, so that "2 Setup" comes before "10 Usage".
+func naturalCompare(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			da, db := digitRun(a), digitRun(b)
+			// Leading zeros don't change the value
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if c := cmp.Compare(len(na), len(nb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// digitRun returns the leading digits of s.
+func digitRun(s string) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// NavWeight returns the position of a page in the navbar, from its
+// "nav_order", "weight" or "order" property.
+func (f *File) NavWeight() (float64, bool) {
+	for _, key := range []string{"nav_order", "weight", "order"} {
+		switch v := f.Frontmatter[key].(type) {
+		case int:
+			return float64(v), true
+		case float64:
+			return v, true
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// NavName returns the name of a page in the navbar: its "nav_title"
+// property, or its file name.
+func (o *Obsidian) NavName(f *File) string {
+	if title := frontmatterString(f, "nav_title"); title != "" {
+		return title
+	}
+	return o.DisplayName(strings.TrimSuffix(f.Name, f.Ext))
+}
+
+// navHidden reports whether the "nav_hidden" property of a page hides it
+// from the navbar.
+func navHidden(f *File) bool {
+	switch v := f.Frontmatter["nav_hidden"].(type) {
+	case bool:
+		return v
+	case string:
+		hidden, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return hidden
+	}
+	return false
+}
+
+// frontmatterString returns a string property of a page, or "" when it's
+// missing or isn't a string.
+func frontmatterString(f *File, key string) string {
+	v, _ := f.Frontmatter[key].(string)
+	return strings.TrimSpace(v)
+}
+
+// folderNoteOf returns the folder node a file is the folder note of: a page
+// named after a folder next to it, or a note inside the folder named after
+// it or "index". It returns nil for other files.
+func (o *Obsidian) folderNoteOf(folderMap map[string]*NavbarNode, file *File) *NavbarNode {
+	if o.FolderNotes {
+		if file.NoteOf == nil {
+			return nil
+		}
+		return folderMap[file.NoteOf.RelPath]
+	}
+	if folder, ok := folderMap[strings.TrimSuffix(file.RelPath, file.Ext)]; ok {
+		return folder
+	}
+	if file.Ext != ".md" {
+		return nil
+	}
+	dir := filepath.Dir(file.RelPath)
+	name := strings.TrimSuffix(filepath.Base(file.RelPath), file.Ext)
+	if folder, ok := folderMap[dir]; ok && (strings.EqualFold(name, "index") || name == filepath.Base(dir)) {
+		return folder
+	}
+	return nil
+}
+
+// applyFolderNote links the folder node to the page of its folder note and
+// applies the navbar properties of the note to the folder.
+func (n *NavbarNode) applyFolderNote(file *File) {
+	n.Path = file.WebPath
+	switch file.Ext {
+	case ".md":
+		n.IsNote = true
+	case ".canvas":
+		n.IsCanvas = true
+	case ".base":
+		n.IsBase = true
+	}
+	n.Weight, n.Weighted = file.NavWeight()
+	if title := frontmatterString(file, "nav_title"); title != "" {
+		n.Name = title
+	}
+	n.Icon = frontmatterString(file, "icon")
+	n.hidden = navHidden(file)
+}
+
+// sortName returns the name the node is sorted by.
+func (n *NavbarNode) sortName() string {
+	if n.sortKey != "" {
+		return n.sortKey
+	}
+	return n.Name
+}
+
 // TODO: Delete this, is deprecated in favor of a small client-side script
 // SetNavbarNodeActive traverses the tree and marks the node matching currentPath as Active.
 func SetNavbarNodeActive(nodes []*NavbarNode, currentPath string) {
@@ -215,4 +392,11 @@ type NavbarNode struct {
 	IsNote   bool
 	Active   bool
 	Children []*NavbarNode
+
+	Weight   float64 // Position set by the "nav_order", "weight" or "order" property
+	Weighted bool    // Whether the page sets its position
+	Icon     string  // Emoji, text or image URL set by the "icon" property
+
+	sortKey string // File or folder name, sorted by instead of the displayed name
+	hidden  bool   // Set by the "nav_hidden" property of a folder note
 }
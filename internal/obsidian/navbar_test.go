@@ -0,0 +1,182 @@
+// @feature:navbar Tests for the order of the navbar tree.
+package obsidian
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// scanNavbarVault scans a vault made of the given files and returns its
+// navbar.
+func scanNavbarVault(t *testing.T, files map[string]string, opts ...Option) *NavbarNode {
+	t.Helper()
+	return scanVault(t, files, opts...).GenerateNavbar()
+}
+
+func TestSortNavbarTree_Weights(t *testing.T) {
+	nodes := []*NavbarNode{
+		{Name: "zeta"},
+		{Name: "guides", IsFolder: true},
+		{Name: "install", Weight: 2, Weighted: true},
+		{Name: "intro", Weight: 1, Weighted: true},
+		{Name: "alpha"},
+		{Name: "reference", IsFolder: true, Weight: 1.5, Weighted: true},
+	}
+	sortNavbarTree(nodes)
+
+	want := []string{"intro", "reference", "install", "guides", "alpha", "zeta"}
+	for i, name := range want {
+		if nodes[i].Name != name {
+			t.Fatalf("order = %v, want %v", names(nodes), want)
+		}
+	}
+}
+
+func TestFile_NavWeight(t *testing.T) {
+	for _, tc := range []struct {
+		frontmatter map[string]any
+		want        float64
+		ok          bool
+	}{
+		{map[string]any{"weight": 3}, 3, true},
+		{map[string]any{"order": 1.5}, 1.5, true},
+		{map[string]any{"weight": " 10 "}, 10, true},
+		{map[string]any{"weight": 2, "order": 5}, 2, true},
+		{map[string]any{"weight": "first"}, 0, false},
+		{nil, 0, false},
+	} {
+		got, ok := (&File{Frontmatter: tc.frontmatter}).NavWeight()
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("NavWeight(%v) = %v, %v, want %v, %v", tc.frontmatter, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestGenerateNavbar_Frontmatter(t *testing.T) {
+	root := scanNavbarVault(t, map[string]string{
+		"Guide/index.md":     "---\nnav_title: User Guide\nicon: 📘\nnav_order: 1\n---\nGuide",
+		"Guide/Setup.md":     "---\nnav_title: Getting set up\n---\nSetup",
+		"Guide/Drafts.md":    "---\nnav_hidden: true\n---\nDraft",
+		"Archive/Archive.md": "---\nnav_hidden: true\n---\nArchive",
+		"Archive/Old.md":     "Old",
+		"Blog.md":            "---\nicon: /icons/blog.svg\n---\nBlog",
+		"Blog/Hello.md":      "Hello",
+		"About.md":           "About",
+	})
+
+	if got := names(root.Children); len(got) != 3 || got[0] != "User Guide" || got[1] != "Blog" || got[2] != "About" {
+		t.Fatalf("navbar = %v, want the weighted guide, then the folders and notes, without the hidden archive", got)
+	}
+	guide := root.Children[0]
+	if guide.Icon != "📘" || !guide.IsNote || guide.Path != "/guide" {
+		t.Errorf("guide = %+v, want the icon and page of its index note", guide)
+	}
+	if got := names(guide.Children); len(got) != 1 || got[0] != "Getting set up" {
+		t.Errorf("guide children = %v, want the titled note without the folder note and the hidden draft", got)
+	}
+	if blog := root.Children[1]; blog.Icon != "/icons/blog.svg" || blog.Path != "/blog" {
+		t.Errorf("blog = %+v, want the icon and page of its sibling note", blog)
+	}
+}
+
+func TestGenerateNavbar_StripPrefixes(t *testing.T) {
+	root := scanNavbarVault(t, map[string]string{
+		"01 Basics/02 Install.md": "Install",
+		"01 Basics/10 Usage.md":   "Usage",
+		"01 Basics/01 Intro.md":   "Intro",
+		"2024.md":                 "Year",
+	}, WithStripPrefixes(true))
+
+	basics := root.Children[0]
+	if basics.Name != "Basics" || basics.Path != "/basics" {
+		t.Errorf("folder = %s at %s, want Basics at /basics", basics.Name, basics.Path)
+	}
+	if got := names(basics.Children); got[0] != "Intro" || got[1] != "Install" || got[2] != "Usage" {
+		t.Errorf("children = %v, want the order of the prefixes", got)
+	}
+	if got := basics.Children[0].Path; got != "/basics/intro" {
+		t.Errorf("Path = %s, want /basics/intro", got)
+	}
+	if root.Children[1].Name != "2024" {
+		t.Errorf("a name made of numbers lost them: %s", root.Children[1].Name)
+	}
+}
+
+func TestGenerateNavbar_NaturalOrder(t *testing.T) {
+	root := scanNavbarVault(t, map[string]string{
+		"10 Usage.md":   "Usage",
+		"2 Install.md":  "Install",
+		"1 Intro.md":    "Intro",
+		"Chapter 10.md": "Ten",
+		"Chapter 9.md":  "Nine",
+	}, WithStripPrefixes(true))
+
+	want := []string{"Intro", "Install", "Usage", "Chapter 9", "Chapter 10"}
+	if got := names(root.Children); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("children = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateNavbar_StripPrefixesCollision(t *testing.T) {
+	var logs bytes.Buffer
+	root := scanVault(t, map[string]string{
+		"01 Intro.md": "First",
+		"02 Intro.md": "Second",
+		"03 Setup.md": "Setup",
+	}, WithStripPrefixes(true), WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))).GenerateNavbar()
+
+	paths := []string{}
+	for _, n := range root.Children {
+		paths = append(paths, n.Path)
+	}
+	want := []string{"/01-intro", "/02-intro", "/setup"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if !strings.Contains(logs.String(), `names="01 Intro.md, 02 Intro.md"`) {
+		t.Errorf("expected a warning about the collision, got:\n%s", logs.String())
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"2 Setup", "10 Usage", -1},
+		{"Chapter 10", "chapter 9", 1},
+		{"01 Intro", "1 Intro", 0},
+		{"v2", "v2.1", -1},
+		{"Alpha", "beta", -1},
+	} {
+		if got := naturalCompare(c.a, c.b); got != c.want {
+			t.Errorf("naturalCompare(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestStripNumberPrefix(t *testing.T) {
+	for in, want := range map[string]string{
+		"01 Intro":    "Intro",
+		"1. Intro":    "Intro",
+		"02-setup.md": "setup.md",
+		"03_Usage":    "Usage",
+		"2024":        "2024",
+		"01 .md":      "01 .md",
+		"v2":          "v2",
+	} {
+		if got := StripNumberPrefix(in); got != want {
+			t.Errorf("StripNumberPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func names(nodes []*NavbarNode) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.Name
+	}
+	return out
+}
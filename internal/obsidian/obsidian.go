@@ -1,4 +1,4 @@
-// Vault scanning, file/folder processing, backlink generation, and core domain types. @feature:vault-scan
+// Vault scanning and incremental updates, file/folder processing, backlink generation, and core domain types. @feature:vault-scan
 package obsidian
 
 import (
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -74,9 +75,6 @@ func (o *Obsidian) NewFolder(path string) (*Folder, error) {
 		Modified: info.ModTime(),
 	}
 
-	// Add folder to sitemap
-	o.AddSitemapEntry(info.ModTime(), o.BaseURL, webPath)
-
 	return f, nil
 }
 
@@ -144,38 +142,17 @@ func (o *Obsidian) NewFile(path string) (*File, error) {
 		Size:     info.Size(),
 		// Initialize slices to ensure they are empty JSON arrays [] instead of null
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
+		Outlinks:  []*File{},
 		Tags:      make(map[string]struct{}),
 		Embeds:    []string{},
 	}
 
-	// Add file to sitemap
-	o.AddSitemapEntry(modTime, o.BaseURL, webPath)
-
 	// 3. Conditional Processing for Markdown
 	if ext == ".md" {
 		if err := f.processMarkdown(); err != nil {
 			return nil, fmt.Errorf("failed to process markdown for %s: %w", fullName, err)
 		}
-
-		title := f.Name
-		if t, ok := f.Frontmatter["title"]; ok {
-			if s, ok := t.(string); ok && s != "" {
-				title = s
-			}
-		}
-		var desc string
-		if d, ok := f.Frontmatter["description"]; ok {
-			if s, ok := d.(string); ok {
-				desc = s
-			}
-		}
-		o.Vault.RSS = append(o.Vault.RSS, RSSEntry{
-			Title:       title,
-			Description: desc,
-			WebPath:     webPath,
-			PubDate:     birthTime,
-		})
 	}
 
 	return f, nil
@@ -284,103 +261,50 @@ func isMarkdownLink(raw string) bool {
 	return strings.HasPrefix(raw, "[") && strings.Contains(raw, "](") && strings.HasSuffix(raw, ")")
 }
 
-// GenerateBacklinks populates the Backlinks field for every file in the slice.
+// GenerateBacklinks populates the Backlinks and BacklinkContexts fields for
+// every file in the slice. Links are resolved like the markdown renderer does,
+// so notes sharing a name in different folders are told apart.
 func GenerateBacklinks(files []*File) {
-	// 1. Create a Lookup Map (Name -> *File)
-	fileMap := make(map[string]*File)
+	generateBacklinks(files, NewLinkIndex(files, nil))
+}
+
+// generateBacklinks populates the backlinks resolving links through idx.
+func generateBacklinks(files []*File, idx *LinkIndex) {
 	for _, file := range files {
-		key := strings.ToLower(file.Name)
-		if file.Ext != ".md" {
-			fileMap[key+file.Ext] = file
-		} else {
-			fileMap[key] = file
-		}
+		file.Backlinks = []*File{}
+		file.Outlinks = []*File{}
+		file.BacklinkContexts = nil
 	}
 
-	// 2. Process Outgoing Links AND Embeds
 	for _, sourceFile := range files {
+		// Embeds are also stored in Links, so Links covers both
+		seenLinks := make(map[string]bool)
+		// A target is linked once from a source, so it gets the source as
+		// backlink once too
+		seenTargets := make(map[*File]struct{})
+		for _, rawLink := range slices.Concat(sourceFile.Links, sourceFile.Embeds) {
+			if seenLinks[rawLink] {
+				continue
+			}
+			seenLinks[rawLink] = true
 
-		// Create a temporary list of lists to iterate over both Links and Embeds
-		// using the same logic.
-		referenceGroups := [][]string{sourceFile.Links, sourceFile.Embeds}
-
-		for _, group := range referenceGroups {
-			for _, rawLink := range group {
-				var lookupKey string
-
-				if isMarkdownLink(rawLink) {
-					// --- Markdown link: [text](path) ---
-					start := strings.Index(rawLink, "](")
-					if start == -1 {
-						continue
-					}
-					path := rawLink[start+2 : len(rawLink)-1]
-
-					// Skip external URLs
-					if strings.HasPrefix(path, "http://") ||
-						strings.HasPrefix(path, "https://") ||
-						strings.HasPrefix(path, "mailto:") {
-						continue
-					}
-
-					// Remove anchor fragment
-					if idx := strings.Index(path, "#"); idx != -1 {
-						path = path[:idx]
-					}
-					if path == "" {
-						continue
-					}
-
-					// Extract base name and strip .md extension
-					base := filepath.Base(path)
-					base = strings.TrimSuffix(base, ".md")
-
-					lookupKey = strings.ToLower(base)
-				} else {
-					// --- Wikilink: [[Link]] or ![[Embed]] ---
-
-					// 1. Remove optional Embed "!" prefix
-					cleanLink := strings.TrimPrefix(rawLink, "!")
-
-					// 2. Remove surrounding brackets [[ and ]]
-					cleanLink = strings.TrimPrefix(cleanLink, "[[")
-					cleanLink = strings.TrimSuffix(cleanLink, "]]")
-
-					// 3. Remove Alias (if exists) -> "My Page#Heading"
-					if idx := strings.Index(cleanLink, "|"); idx != -1 {
-						cleanLink = cleanLink[:idx]
-					}
-
-					// 4. Remove Anchor/Header (if exists) -> "My Page"
-					if idx := strings.Index(cleanLink, "#"); idx != -1 {
-						cleanLink = cleanLink[:idx]
-					}
-
-					// 5. Normalize for Lookup
-					// Handle paths like "Folder/Note" -> just "Note"
-					if strings.Contains(cleanLink, "/") {
-						cleanLink = filepath.Base(cleanLink)
-					}
-
-					lookupKey = strings.ToLower(cleanLink)
-				}
-
-				// --- B. FIND TARGET AND ADD BACKLINK ---
-				if targetFile, found := fileMap[lookupKey]; found {
-					// Prevent self-linking
-					if targetFile.Path == sourceFile.Path {
-						continue
-					}
-
-					// Format the backlink: [[Name]]
-					newBacklink := fmt.Sprintf("[[%s]]", sourceFile.Name)
+			targetFile := idx.Resolve(sourceFile, rawLink)
+			// Prevent self-linking
+			if targetFile == nil || targetFile == sourceFile {
+				continue
+			}
 
-					// Check for duplicates before adding
-					if !slices.Contains(targetFile.Backlinks, newBacklink) {
-						targetFile.Backlinks = append(targetFile.Backlinks, newBacklink)
-					}
-				}
+			if _, seen := seenTargets[targetFile]; !seen {
+				seenTargets[targetFile] = struct{}{}
+				sourceFile.Outlinks = append(sourceFile.Outlinks, targetFile)
+				targetFile.Backlinks = append(targetFile.Backlinks, sourceFile)
 			}
+
+			// Record the text around each occurrence of the link
+			targetFile.BacklinkContexts = append(
+				targetFile.BacklinkContexts,
+				linkContexts(sourceFile, rawLink)...,
+			)
 		}
 	}
 }
@@ -415,6 +339,42 @@ func WithFlatURLs(b bool) Option {
 	}
 }
 
+func WithLang(s string) Option {
+	return func(o *Obsidian) {
+		o.Lang = s
+	}
+}
+
+func WithLanguages(langs []string) Option {
+	return func(o *Obsidian) {
+		o.Languages = langs
+	}
+}
+
+func WithDateSource(s string) Option {
+	return func(o *Obsidian) {
+		o.DateSource = s
+	}
+}
+
+func WithUnlinkedMentions(b bool) Option {
+	return func(o *Obsidian) {
+		o.UnlinkedMentions = b
+	}
+}
+
+func WithStripPrefixes(b bool) Option {
+	return func(o *Obsidian) {
+		o.StripPrefixes = b
+	}
+}
+
+func WithFolderNotes(b bool) Option {
+	return func(o *Obsidian) {
+		o.FolderNotes = b
+	}
+}
+
 func New(opts ...Option) *Obsidian {
 	// Default to the standard no-op or default logger
 	o := &Obsidian{
@@ -429,68 +389,245 @@ func New(opts ...Option) *Obsidian {
 
 // Scans the obsidian vault
 func (o *Obsidian) Scan() error {
-	o.Vault = &Vault{
-		FileIndex:  make(map[string][]*File),
-		Tags:       make(map[string]*Tag),
-		Folders:    make(map[string]*Folder),
-		SourceMap:  make(map[string]string),
-		GraphNodes: []GraphNode{},
-		Files:      []*File{},
-		Sitemap: &Sitemap{
-			Path: filepath.Join(o.BaseURL, "/sitemap.xml"),
-		},
-		RSS: []RSSEntry{},
-	}
+	o.files = make(map[string]*File)
+	o.folders = make(map[string]*Folder)
+	o.prefixCollisions = nil
+	filepath.WalkDir(o.InputDir, o.visit)
+	o.index()
+	return nil
+}
 
-	filepath.WalkDir(o.InputDir, func(path string, info fs.DirEntry, err error) error {
-		l := o.log.With("file", path)
+// Update brings the model of a scanned vault up to date after some files
+// changed, by path relative to the input directory. Changed files are read
+// again and removed ones dropped, directories as a whole; the other files
+// aren't read, the indexes are rebuilt from the model in memory. Without a
+// previous scan, the whole vault is scanned.
+func (o *Obsidian) Update(changed, removed []string) error {
+	if o.files == nil {
+		return o.Scan()
+	}
 
-		// Handle permission errors and other related problems
-		if err != nil {
-			return nil
+	o.prefixCollisions = nil
+	for _, relPath := range removed {
+		o.drop(relPath)
+	}
+	for _, relPath := range changed {
+		if IgnoredPath(relPath) {
+			continue
 		}
-
-		// Create relative path
-		relPath, err := filepath.Rel(o.InputDir, path)
+		path := filepath.Join(o.InputDir, relPath)
+		info, err := os.Stat(path)
 		if err != nil {
-			return err
+			o.drop(relPath)
+			continue
+		}
+		o.addParents(relPath)
+		if info.IsDir() {
+			filepath.WalkDir(path, o.visit)
+			continue
 		}
+		o.visit(path, fs.FileInfoToDirEntry(info), nil)
+	}
+
+	o.index()
+	return nil
+}
+
+// visit reads a file or folder of the vault into the model, skipping the
+// hidden and special ones. It is the walk function of Scan.
+func (o *Obsidian) visit(path string, info fs.DirEntry, err error) error {
+	l := o.log.With("file", path)
+
+	// Handle permission errors and other related problems
+	if err != nil {
+		return nil
+	}
+
+	// Create relative path
+	relPath, err := filepath.Rel(o.InputDir, path)
+	if err != nil {
+		return err
+	}
+
+	// Skip root file
+	if path == o.InputDir {
+		l.Debug("Skipping file", "reason", "Root folder")
+		return nil
+	}
+
+	// Skip dotfiles
+	if strings.HasPrefix(relPath, ".") && path != o.InputDir {
+		l.Debug("Skipping file", "reason", "File is a hidden file or directory")
+		return nil
+	}
+
+	// Skip
+	if strings.HasPrefix(relPath, "_hidden_") {
+		l.Debug("Skipping hidden file", "reason", "File has prefix _hidden_")
+		return nil
+	}
 
-		// Skip root file
-		if path == o.InputDir {
-			l.Debug("Skipping file", "reason", "Root folder")
+	// Skip special files handled by dedicated loaders (LoadRedirects, LoadHeaders, LoadCname, LoadFavicon)
+	if !info.IsDir() {
+		base := filepath.Base(path)
+		if base == "_redirects" || base == "_headers" || base == "CNAME" || base == "favicon.ico" {
+			l.Debug("Skipping special file", "reason", "Handled by dedicated loader")
 			return nil
 		}
+	}
+
+	// Skip the translation files of the UI labels, loaded by i18n.Load
+	if info.IsDir() && relPath == "_i18n" {
+		l.Debug("Skipping folder", "reason", "Holds the translations of the UI labels")
+		return filepath.SkipDir
+	}
+
+	// Skip the theme files, loaded by builder.RegisterAppearance
+	if info.IsDir() && relPath == "_themes" {
+		l.Debug("Skipping folder", "reason", "Holds the user-defined themes")
+		return filepath.SkipDir
+	}
 
-		// Skip dotfiles
-		if strings.HasPrefix(relPath, ".") && path != o.InputDir {
-			l.Debug("Skipping file", "reason", "File is a hidden file or directory")
+	// Skip the layout overrides, loaded by the builder
+	if info.IsDir() && relPath == "_layouts" {
+		l.Debug("Skipping folder", "reason", "Holds the layout overrides")
+		return filepath.SkipDir
+	}
+
+	// Skip directories
+	if info.IsDir() {
+		folder, err := o.NewFolder(path)
+		if err != nil {
+			l.Error("Couldn't create new folder", "error", err)
 			return nil
 		}
+		o.log.Debug("Processed folder", "folder", folder.LogValue())
+		o.folders[folder.RelPath] = folder
+		return nil
+	}
 
-		// Skip
-		if strings.HasPrefix(relPath, "_hidden_") {
-			l.Debug("Skipping hidden file", "reason", "File has prefix _hidden_")
-			return nil
+	file, err := o.NewFile(path)
+	if err != nil {
+		l.Error("Couldn't create new file", "error", err)
+		return nil
+	}
+	o.files[file.RelPath] = file
+	o.log.Debug("Processed file", "file", file.LogValue())
+
+	return nil
+}
+
+// IgnoredPath reports whether a path relative to the input directory is
+// skipped by Scan: hidden files and the folders read by other loaders.
+func IgnoredPath(relPath string) bool {
+	top, _, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	return strings.HasPrefix(relPath, ".") || strings.HasPrefix(relPath, "_hidden_") ||
+		top == "_i18n" || top == "_themes" || top == "_layouts"
+}
+
+// drop removes a file, or a folder and everything in it, from the model,
+// along with the parent folders gone from the disk.
+func (o *Obsidian) drop(relPath string) {
+	prefix := relPath + string(filepath.Separator)
+	for rel := range o.files {
+		if rel == relPath || strings.HasPrefix(rel, prefix) {
+			delete(o.files, rel)
+		}
+	}
+	for rel := range o.folders {
+		if rel == relPath || strings.HasPrefix(rel, prefix) {
+			delete(o.folders, rel)
+		}
+	}
+	for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(o.InputDir, dir)); err == nil {
+			break
 		}
+		delete(o.folders, dir)
+	}
+}
 
-		// Skip special files handled by dedicated loaders (LoadRedirects, LoadCname, LoadFavicon)
-		if !info.IsDir() {
-			base := filepath.Base(path)
-			if base == "_redirects" || base == "CNAME" || base == "favicon.ico" {
-				l.Debug("Skipping special file", "reason", "Handled by dedicated loader")
-				return nil
-			}
+// addParents adds the folders holding a path to the model, when they are
+// new.
+func (o *Obsidian) addParents(relPath string) {
+	for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+		if _, exists := o.folders[dir]; exists {
+			return
 		}
+		folder, err := o.NewFolder(filepath.Join(o.InputDir, dir))
+		if err != nil {
+			o.log.Error("Couldn't create new folder", "folder", dir, "error", err)
+			return
+		}
+		o.folders[dir] = folder
+	}
+}
 
-		// Skip directories
-		if info.IsDir() {
-			folder, err := o.NewFolder(path)
-			if err != nil {
-				l.Error("Couldn't create new folder", "error", err)
-				return nil
-			}
-			o.log.Debug("Processed folder", "folder", folder.LogValue())
+// walkOrder compares two relative paths in the order filepath.WalkDir visits
+// them: segment by segment, folders before their content.
+func walkOrder(a, b string) int {
+	as := strings.Split(a, string(filepath.Separator))
+	bs := strings.Split(b, string(filepath.Separator))
+	for i := range min(len(as), len(bs)) {
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// clone returns a copy of the file as read from the vault, without the
+// fields set by the indexing.
+func (f *File) clone() *File {
+	c := *f
+	c.Backlinks = []*File{}
+	c.Outlinks = []*File{}
+	c.BacklinkContexts = nil
+	c.UnlinkedMentions = nil
+	c.Translations = nil
+	c.NoteOf = nil
+	return &c
+}
+
+// clone returns a copy of the folder as read from the vault, without the
+// fields set by the indexing.
+func (f *Folder) clone() *Folder {
+	c := *f
+	c.Files = []*File{}
+	c.Folders = nil
+	c.Note = nil
+	return &c
+}
+
+// index builds the vault from the files and folders of the model: the file
+// index, folder notes, dates, languages, backlinks, folder contents and tags.
+// It works on copies, so that it can run again after an Update.
+func (o *Obsidian) index() {
+	o.Vault = &Vault{
+		FileIndex:  make(map[string][]*File),
+		Tags:       make(map[string]*Tag),
+		Folders:    make(map[string]*Folder),
+		SourceMap:  make(map[string]string),
+		GraphNodes: []GraphNode{},
+		Files:      []*File{},
+		Sitemap: &Sitemap{
+			Path: strings.TrimRight(o.BaseURL, "/") + "/sitemap.xml",
+		},
+		RSS: []RSSEntry{},
+	}
+
+	paths := make([]string, 0, len(o.files)+len(o.folders))
+	for relPath := range o.folders {
+		paths = append(paths, relPath)
+	}
+	for relPath := range o.files {
+		paths = append(paths, relPath)
+	}
+	slices.SortFunc(paths, walkOrder)
+
+	for _, relPath := range paths {
+		if f, ok := o.folders[relPath]; ok {
+			folder := f.clone()
 			o.Vault.Folders[folder.RelPath] = folder
 
 			o.Vault.GraphNodes = append(o.Vault.GraphNodes, GraphNode{
@@ -500,15 +637,10 @@ func (o *Obsidian) Scan() error {
 				Val:   1,
 				Type:  "folder",
 			})
-
-			return nil
+			continue
 		}
 
-		file, err := o.NewFile(path)
-		if err != nil {
-			l.Error("Couldn't create new file", "error", err)
-			return nil
-		}
+		file := o.files[relPath].clone()
 
 		// Register the file in the global index (filename -> public URL)
 		// This is used later for resolving [[WikiLinks]]
@@ -529,13 +661,21 @@ func (o *Obsidian) Scan() error {
 			Val:   1,
 			Type:  file.Ext,
 		})
+	}
 
-		o.log.Debug("Processed file", "file", file.LogValue())
+	if o.FolderNotes {
+		o.resolveFolderNotes()
+	}
+	o.resolveDates()
+	o.resolveLanguages()
+	o.collectRSS()
 
-		GenerateBacklinks(o.Vault.Files)
+	o.Vault.Links = NewLinkIndex(o.Vault.Files, o.log)
+	generateBacklinks(o.Vault.Files, o.Vault.Links)
 
-		return nil
-	})
+	if o.UnlinkedMentions {
+		GenerateUnlinkedMentions(o.Vault.Files)
+	}
 
 	// Adds files to folders
 	for _, file := range o.Vault.Files {
@@ -625,8 +765,6 @@ func (o *Obsidian) Scan() error {
 			Type:  "tag",
 		})
 	}
-
-	return nil
 }
 
 // loadFavicon loads the favicon.ico file if it exists
@@ -666,6 +804,25 @@ func (o *Obsidian) LoadRedirects() error {
 	return nil
 }
 
+// LoadHeaders loads the _headers file if it exists. Used by Netlify and
+// cloudflare pages deployments, and by "kiln serve", for setting custom
+// response headers.
+//
+// For more information check out this link:
+// https://developers.cloudflare.com/pages/configuration/headers/
+func (o *Obsidian) LoadHeaders() error {
+	headersSrc := filepath.Join(o.InputDir, "_headers")
+	if _, err := os.Stat(headersSrc); err != nil {
+		return err
+	}
+	err := CopyFile(headersSrc, filepath.Join(o.OutputDir, "_headers"))
+	if err != nil {
+		return err
+	}
+	o.log.Debug("'_headers' file loaded correctly")
+	return nil
+}
+
 // loadCname loads the CNAME file if it exists
 func (o *Obsidian) LoadCname() error {
 	faviconSrc := filepath.Join(o.InputDir, "CNAME")
@@ -704,13 +861,14 @@ func CopyFile(src, dst string) error {
 func (o *Obsidian) GetFolderLinks() []GraphLink {
 	links := []GraphLink{}
 
-	for _, folder := range o.Vault.Folders {
+	for _, key := range slices.Sorted(maps.Keys(o.Vault.Folders)) {
+		folder := o.Vault.Folders[key]
 		for _, file := range folder.Files {
-			links = append(links, GraphLink{Source: folder.WebPath, Target: file.WebPath})
+			links = append(links, GraphLink{Source: folder.WebPath, Target: file.WebPath, Kind: "folder"})
 		}
 
 		for _, subFolder := range folder.Folders {
-			links = append(links, GraphLink{Source: folder.WebPath, Target: subFolder.WebPath})
+			links = append(links, GraphLink{Source: folder.WebPath, Target: subFolder.WebPath, Kind: "folder"})
 		}
 	}
 
@@ -724,9 +882,10 @@ func (o *Obsidian) GetFolderLinks() []GraphLink {
 func (o *Obsidian) GetTagLinks() []GraphLink {
 	links := []GraphLink{}
 
-	for _, tag := range o.Vault.Tags {
+	for _, key := range slices.Sorted(maps.Keys(o.Vault.Tags)) {
+		tag := o.Vault.Tags[key]
 		for _, file := range tag.Files {
-			links = append(links, GraphLink{Source: tag.WebPath, Target: file.WebPath})
+			links = append(links, GraphLink{Source: tag.WebPath, Target: file.WebPath, Kind: "tag"})
 		}
 	}
 
@@ -749,10 +908,19 @@ type File struct {
 	Frontmatter map[string]any      // Frontmatter, only for notes
 	Content     []byte              // Content, only for notes
 	Links       []string            // Outgoing links
-	Backlinks   []string            // Backlinks to the file
+	Backlinks   []*File             // Files linking to this file
+	Outlinks    []*File             // Files this file links to or embeds, resolved
 	Tags        map[string]struct{} // Tags
 	Embeds      []string            // Embed files
 	Breadcrumbs []Breadcrumb
+
+	BacklinkContexts []Mention // Excerpts around every incoming link
+	UnlinkedMentions []Mention // Plain-text mentions of the note, when enabled
+
+	Lang         string  // Language of the page
+	Translations []*File // Versions of the page in the other languages
+
+	NoteOf *Folder // Folder whose page the note is, when it's a folder note
 }
 
 // LogValue is used to log out the file
@@ -777,6 +945,8 @@ type Folder struct {
 	Folders  []*Folder // List of folders
 	Created  time.Time // When the folder was created
 	Modified time.Time // When the folder was last modified
+	Lang     string    // Language of the pages of the folder
+	Note     *File     // Folder note, rendered as the page of the folder
 }
 
 // LogValue is used to log out the folder
@@ -795,6 +965,7 @@ type RSSEntry struct {
 	Description string
 	WebPath     string
 	PubDate     time.Time
+	Lang        string
 }
 
 // Vault represents the vault scan
@@ -807,6 +978,8 @@ type Vault struct {
 	RSS        []RSSEntry         // RSS feed entries collected during scan
 	Folders    map[string]*Folder // Map of all the folder -> Name of folder -> Folder
 	Tags       map[string]*Tag    //
+	Links      *LinkIndex         // Resolves links to files, reports ambiguous links
+	Languages  []string           // Languages of the pages, the default one first
 }
 
 // Tag rappresents a tag instance
@@ -819,11 +992,17 @@ type Tag struct {
 
 // GraphNode represents a single node in the interactive graph view.
 type GraphNode struct {
-	ID    string `json:"id"`
-	Label string `json:"label"`
-	URL   string `json:"url"`
-	Val   int    `json:"val"`
-	Type  string `json:"type"`
+	ID        string   `json:"id"`
+	Label     string   `json:"label"`
+	URL       string   `json:"url"`
+	Val       float64  `json:"val"`              // Node size, computed from the graph metrics
+	Type      string   `json:"type"`             // File extension, "folder" or "tag"
+	Degree    int      `json:"degree"`           // Number of links to and from the node
+	Component int      `json:"component"`        // Connected component id, 0 is the largest
+	Orphan    bool     `json:"orphan,omitempty"` // Set if the page has no links to other pages
+	Color     string   `json:"color,omitempty"`  // Color group (palette name or hex)
+	RelPath   string   `json:"-"`                // Vault relative path, used for filtering
+	Tags      []string `json:"-"`                // Tags of the page, used for filtering
 }
 
 // GraphLink represents a directed edge in the note graph.
@@ -832,6 +1011,7 @@ type GraphNode struct {
 type GraphLink struct {
 	Source string `json:"source"`
 	Target string `json:"target"`
+	Kind   string `json:"kind,omitempty"` // "folder", "tag", "attachment" or empty for links between pages
 }
 
 // Obsidian represents the configs regarding the generation
@@ -842,6 +1022,20 @@ type Obsidian struct {
 	BaseURL   string       // BaseURL of the parsed vault (e.g. https://something.com/folder)
 	FlatURLs  bool         // True if flat urls are active (e.g. /folder/note/index.html)
 	Vault     *Vault       // Vault scan
+
+	UnlinkedMentions bool     // True if plain-text mentions of notes should be collected
+	DateSource       string   // Where the dates of the files come from: "filesystem", "frontmatter" or "git"
+	Lang             string   // Default language of the pages
+	Languages        []string // Languages of the site, whose top-level folders hold their pages
+	StripPrefixes    bool     // True if numeric prefixes ("01 Intro") are removed from names and slugs
+	FolderNotes      bool     // True if folder notes become the pages of their folders
+
+	gitDates    map[string]gitDates // Commit dates of the files, read once
+	langFolders map[string]string   // Language -> top-level folder holding its pages
+	files       map[string]*File    // Files as read from the vault, by RelPath, indexed into Vault
+	folders     map[string]*Folder  // Folders as read from the vault, by RelPath, indexed into Vault
+
+	prefixCollisions map[string]map[string]bool // Directory -> names keeping their numeric prefix, read once per scan
 }
 
 // Option allows users to configure the Worker
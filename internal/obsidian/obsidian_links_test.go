@@ -21,7 +21,7 @@ func newTestFile(t *testing.T, content string) *File {
 		Name:      "test",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 		Tags:      make(map[string]struct{}),
 		Embeds:    []string{},
 	}
@@ -138,56 +138,60 @@ func TestProcessMarkdown_MdLinkHttpSkipped(t *testing.T) {
 func TestGenerateBacklinks_MixedWikiAndMdLinks(t *testing.T) {
 	fileA := &File{
 		Path:      "/vault/A.md",
+		RelPath:   "A.md",
 		Name:      "A",
 		Ext:       ".md",
 		Links:     []string{"[[B]]", "[link](./C.md)"},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 	fileB := &File{
 		Path:      "/vault/B.md",
 		Name:      "B",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 	fileC := &File{
 		Path:      "/vault/C.md",
+		RelPath:   "C.md",
 		Name:      "C",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 
 	GenerateBacklinks([]*File{fileA, fileB, fileC})
 
-	if !slices.Contains(fileB.Backlinks, "[[A]]") {
-		t.Errorf("expected B to have backlink [[A]], got %v", fileB.Backlinks)
+	if !slices.Contains(fileB.Backlinks, fileA) {
+		t.Errorf("expected B to have backlink A, got %v", fileB.Backlinks)
 	}
-	if !slices.Contains(fileC.Backlinks, "[[A]]") {
-		t.Errorf("expected C to have backlink [[A]], got %v", fileC.Backlinks)
+	if !slices.Contains(fileC.Backlinks, fileA) {
+		t.Errorf("expected C to have backlink A, got %v", fileC.Backlinks)
 	}
 }
 
 func TestGenerateBacklinks_MdLinkWithPath(t *testing.T) {
 	fileA := &File{
-		Path:      "/vault/A.md",
+		Path:      "/vault/sub/A.md",
+		RelPath:   "sub/A.md",
 		Name:      "A",
 		Ext:       ".md",
 		Links:     []string{"[text](../folder/note.md)"},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 	fileNote := &File{
 		Path:      "/vault/folder/note.md",
+		RelPath:   "folder/note.md",
 		Name:      "note",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 
 	GenerateBacklinks([]*File{fileA, fileNote})
 
-	if !slices.Contains(fileNote.Backlinks, "[[A]]") {
-		t.Errorf("expected note to have backlink [[A]], got %v", fileNote.Backlinks)
+	if !slices.Contains(fileNote.Backlinks, fileA) {
+		t.Errorf("expected note to have backlink A, got %v", fileNote.Backlinks)
 	}
 }
 
@@ -197,14 +201,14 @@ func TestGenerateBacklinks_ExternalMdLinkSkipped(t *testing.T) {
 		Name:      "A",
 		Ext:       ".md",
 		Links:     []string{"[text](https://example.com)"},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 	fileB := &File{
 		Path:      "/vault/B.md",
 		Name:      "B",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 
 	GenerateBacklinks([]*File{fileA, fileB})
@@ -220,25 +224,70 @@ func TestGenerateBacklinks_NoDuplicates(t *testing.T) {
 		Name:      "A",
 		Ext:       ".md",
 		Links:     []string{"[[B]]", "[link](./B.md)"},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 	fileB := &File{
 		Path:      "/vault/B.md",
 		Name:      "B",
 		Ext:       ".md",
 		Links:     []string{},
-		Backlinks: []string{},
+		Backlinks: []*File{},
 	}
 
 	GenerateBacklinks([]*File{fileA, fileB})
 
 	count := 0
 	for _, bl := range fileB.Backlinks {
-		if bl == "[[A]]" {
+		if bl == fileA {
 			count++
 		}
 	}
 	if count != 1 {
-		t.Errorf("expected exactly 1 backlink [[A]], got %d in %v", count, fileB.Backlinks)
+		t.Errorf("expected exactly 1 backlink A, got %d in %v", count, fileB.Backlinks)
+	}
+}
+
+func TestGenerateBacklinks_SameNameInFolders(t *testing.T) {
+	src := &File{
+		Path:    "/vault/projects/src.md",
+		RelPath: "projects/src.md",
+		Name:    "src",
+		Ext:     ".md",
+		Links: []string{
+			"[[archive/Note]]",
+			"[[Note#Heading|alias]]",
+			"[rel](./Note.md#^block)",
+		},
+	}
+	other := &File{
+		Path:    "/vault/other/other.md",
+		RelPath: "other/other.md",
+		Name:    "other",
+		Ext:     ".md",
+		Links:   []string{"[[note]]", "[root](/Note.md)"},
+	}
+	root := &File{Path: "/vault/Note.md", RelPath: "Note.md", Name: "Note", Ext: ".md"}
+	archived := &File{Path: "/vault/archive/Note.md", RelPath: "archive/Note.md", Name: "Note", Ext: ".md"}
+	sibling := &File{Path: "/vault/projects/Note.md", RelPath: "projects/Note.md", Name: "Note", Ext: ".md"}
+
+	GenerateBacklinks([]*File{src, other, root, archived, sibling})
+
+	// The path link targets the archived note, bare names prefer the current
+	// folder, then the root note.
+	want := map[*File]*File{root: other, archived: src, sibling: src}
+	for f, source := range want {
+		if len(f.Backlinks) != 1 || f.Backlinks[0] != source {
+			t.Errorf("%s: expected a single backlink from %s, got %v", f.RelPath, source.RelPath, f.Backlinks)
+		}
+	}
+}
+
+func TestGenerateBacklinks_SelfAndHeadingLinks(t *testing.T) {
+	a := &File{Path: "/vault/A.md", RelPath: "A.md", Name: "A", Ext: ".md", Links: []string{"[[A]]", "[[#Heading]]", "[top](#top)"}}
+
+	GenerateBacklinks([]*File{a})
+
+	if len(a.Backlinks) != 0 {
+		t.Errorf("expected no self backlinks, got %v", a.Backlinks)
 	}
 }
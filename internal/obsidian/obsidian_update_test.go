@@ -0,0 +1,122 @@
+// @feature:vault-scan Tests for the incremental updates of a scanned vault.
+package obsidian
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// vaultSummary describes the indexes of a vault, to compare two models.
+func vaultSummary(o *Obsidian) []string {
+	lines := []string{}
+	for _, f := range o.Vault.Files {
+		backlinks := []string{}
+		for _, b := range f.Backlinks {
+			backlinks = append(backlinks, b.RelPath)
+		}
+		lines = append(lines, fmt.Sprintf("file %s %s %s backlinks=%v", f.RelPath, f.WebPath, f.OutPath, backlinks))
+	}
+	for rel, folder := range o.Vault.Folders {
+		note := ""
+		if folder.Note != nil {
+			note = folder.Note.RelPath
+		}
+		lines = append(lines, fmt.Sprintf("folder %s files=%d folders=%d note=%s", rel, len(folder.Files), len(folder.Folders), note))
+	}
+	for name, tag := range o.Vault.Tags {
+		lines = append(lines, fmt.Sprintf("tag %s files=%d", name, len(tag.Files)))
+	}
+	for _, n := range o.Vault.GraphNodes {
+		if n.Type != "tag" {
+			lines = append(lines, "node "+n.ID+" "+n.Type)
+		}
+	}
+	for name, files := range o.Vault.FileIndex {
+		lines = append(lines, fmt.Sprintf("index %s %d", name, len(files)))
+	}
+	slices.Sort(lines[len(o.Vault.Files):])
+	return lines
+}
+
+func TestUpdate(t *testing.T) {
+	in := writeVault(t, map[string]string{
+		"Home.md":              "Links to [[Guide]] and [[Old]]. #start",
+		"docs/Guide.md":        "The guide, see [[Home]].",
+		"docs/Old.md":          "Old note. #old",
+		"docs/sub/Deep.md":     "Deep note.",
+		"Projects/Projects.md": "Folder note.",
+		"_i18n/it.yaml":        "search: Cerca",
+	})
+	out := t.TempDir()
+
+	newVault := func() *Obsidian {
+		o := New(
+			WithInputDir(in),
+			WithOutputDir(out),
+			WithFolderNotes(true),
+			WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		)
+		if err := o.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	o := newVault()
+
+	// Edit a note, rename another, add a folder and remove one
+	writeFiles(t, in, map[string]string{
+		"docs/Guide.md":     "The guide, see [[Home]] and [[New]]. #guide",
+		"blog/2024/Post.md": "A post linking [[New]].",
+		"_i18n/fr.yaml":     "search: Chercher",
+	})
+	if err := os.Rename(filepath.Join(in, "docs/Old.md"), filepath.Join(in, "New.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(in, "docs/sub")); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := []string{"docs/Guide.md", "New.md", "blog", "_i18n/fr.yaml"}
+	removed := []string{"docs/Old.md", "docs/sub"}
+	for i := range changed {
+		changed[i] = filepath.FromSlash(changed[i])
+	}
+	for i := range removed {
+		removed[i] = filepath.FromSlash(removed[i])
+	}
+	if err := o.Update(changed, removed); err != nil {
+		t.Fatal(err)
+	}
+
+	got, want := vaultSummary(o), vaultSummary(newVault())
+	if !slices.Equal(got, want) {
+		t.Errorf("updated vault differs from a new scan:\n got: %s\nwant: %s", strings.Join(got, "\n      "), strings.Join(want, "\n      "))
+	}
+	if news := findFile(t, o, "New.md"); len(news.Backlinks) != 2 {
+		t.Errorf("New.md backlinks = %d, want 2", len(news.Backlinks))
+	}
+	if _, ok := o.Vault.FileIndex["Old"]; ok {
+		t.Error("the renamed note is still indexed under its old name")
+	}
+}
+
+func TestWalkOrder(t *testing.T) {
+	paths := []string{"a b", "a/z.md", "a", "a b/c.md", "B.md", "a/b/c.md"}
+	for i := range paths {
+		paths[i] = filepath.FromSlash(paths[i])
+	}
+	slices.SortFunc(paths, walkOrder)
+	want := []string{"B.md", "a", "a/b/c.md", "a/z.md", "a b", "a b/c.md"}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("order = %v, want %v", paths, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -17,19 +18,104 @@ func Slugify(s string) string {
 	return s
 }
 
+// numberPrefixRegex matches the numeric prefixes used to order notes and
+// folders, e.g. "01 ", "1. " or "02-".
+var numberPrefixRegex = regexp.MustCompile(`^\d+[.)]?[\s_-]+`)
+
+// StripNumberPrefix removes the numeric prefix of a file or folder name.
+// Names made only of a prefix are kept as they are.
+//
+// E.g.: "01 Intro" -> "Intro"
+func StripNumberPrefix(name string) string {
+	stripped := numberPrefixRegex.ReplaceAllString(name, "")
+	if stripped == "" || strings.HasPrefix(stripped, ".") {
+		return name
+	}
+	return stripped
+}
+
+// DisplayName returns the name of a file or folder as shown on the site,
+// without its numeric prefix when StripPrefixes is set.
+func (o *Obsidian) DisplayName(name string) string {
+	if o.StripPrefixes {
+		return StripNumberPrefix(name)
+	}
+	return name
+}
+
 // getSlugPath converts a relative file path into a slugified path string.
-// It iterates through every directory in the path and slugifies it.
+// It iterates through every directory in the path and slugifies it. Names
+// whose slug would collide with a sibling once stripped keep their prefix.
 //
 // E.g.: /Directory/Cool File.md -> /directory/cool-file.md
 func (o *Obsidian) GetSlugPath(relPath string) string {
 	parts := strings.Split(relPath, string(os.PathSeparator))
 	for i, p := range parts {
-		parts[i] = Slugify(p)
+		name := o.DisplayName(p)
+		if name != p && o.prefixCollides(filepath.Join(parts[:i]...), p) {
+			name = p
+		}
+		parts[i] = Slugify(name)
 	}
 	slugPath := filepath.Join(parts...)
 	return slugPath
 }
 
+// prefixCollides reports whether a name inside dir, relative to the input
+// directory, shares its slug with a sibling once their numeric prefixes are
+// stripped, e.g. "01 Intro.md" and "02 Intro.md". The names of each
+// directory are read once per scan.
+func (o *Obsidian) prefixCollides(dir, name string) bool {
+	if o.prefixCollisions == nil {
+		o.prefixCollisions = make(map[string]map[string]bool)
+	}
+	collisions, ok := o.prefixCollisions[dir]
+	if !ok {
+		collisions = o.findPrefixCollisions(dir)
+		o.prefixCollisions[dir] = collisions
+	}
+	return collisions[name]
+}
+
+// findPrefixCollisions returns the names inside dir whose slugs collide
+// once stripped, warning about each collision.
+func (o *Obsidian) findPrefixCollisions(dir string) map[string]bool {
+	entries, err := os.ReadDir(filepath.Join(o.InputDir, dir))
+	if err != nil {
+		return nil
+	}
+
+	bySlug := map[string][]string{}
+	slugs := []string{}
+	for _, e := range entries {
+		if IgnoredPath(filepath.Join(dir, e.Name())) {
+			continue
+		}
+		slug := Slugify(StripNumberPrefix(e.Name()))
+		if _, ok := bySlug[slug]; !ok {
+			slugs = append(slugs, slug)
+		}
+		bySlug[slug] = append(bySlug[slug], e.Name())
+	}
+
+	collisions := map[string]bool{}
+	for _, slug := range slugs {
+		names := bySlug[slug]
+		if len(names) < 2 {
+			continue
+		}
+		o.log.Warn(
+			"Names collide without their numeric prefixes, keeping the prefixes",
+			"folder", filepath.ToSlash(dir),
+			"names", strings.Join(names, ", "),
+		)
+		for _, name := range names {
+			collisions[name] = true
+		}
+	}
+	return collisions
+}
+
 // GetPageWebPath returns the relative webpath of the given slugifies path
 //
 // E.g.: /directory/cool-file.md -> /directory/cool-file
@@ -238,7 +324,7 @@ func (o *Obsidian) GetBreadcrumbs(f *File) ([]Breadcrumb, error) {
 			}
 
 			crumbs = append(crumbs, Breadcrumb{
-				Label: part,
+				Label: o.DisplayName(part),
 				Url:   url,
 			})
 		}
@@ -249,7 +335,7 @@ func (o *Obsidian) GetBreadcrumbs(f *File) ([]Breadcrumb, error) {
 	// we just added, so we don't add it again.
 	if !strings.EqualFold(f.Name, "index") {
 		crumbs = append(crumbs, Breadcrumb{
-			Label: f.Name,
+			Label: o.DisplayName(f.Name),
 			Url:   f.WebPath,
 		})
 	}
@@ -299,7 +385,7 @@ func (o *Obsidian) GetFolderBreadcrumbs(f *Folder) ([]Breadcrumb, error) {
 			}
 
 			crumbs = append(crumbs, Breadcrumb{
-				Label: part,
+				Label: o.DisplayName(part),
 				Url:   url,
 			})
 		}
@@ -0,0 +1,307 @@
+// Link resolution shared by backlinks, the markdown renderer and the graph. @feature:wikilinks
+package obsidian
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrorCandidateNotFound = errors.New("Candidate not found")
+
+// SplitExt returns the base name and the extension of the given path
+func SplitExt(path string) (name, ext string) {
+	ext = filepath.Ext(path)
+	name = strings.TrimSuffix(filepath.Base(path), ext)
+	return
+}
+
+// LinkIndex resolves link targets to the files of the vault following
+// Obsidian's rules:
+//
+//   - matching is case-insensitive;
+//   - "[[Note]]" targets notes, attachments need their extension ("[[photo.png]]");
+//   - "[[folder/Note]]" is an exact path from the vault root, then a path
+//     relative to the current folder, then any path ending with it;
+//   - "./" and "../" are always relative to the current folder;
+//   - a bare name shared by several files resolves to the one in the current
+//     folder, then to the one with the shortest path.
+//
+// A link is ambiguous when several files match and neither the current
+// folder nor the vault root decides between them. Ambiguous links are
+// reported once on the logger, when one is set.
+type LinkIndex struct {
+	names    map[string][]*File // Lowercased file name, with and without the .md extension
+	stems    map[string][]*File // Lowercased name of canvases and bases without their extension
+	paths    map[string]*File   // Lowercased slash separated relative path
+	webPaths map[string]*File   // Web path of every file
+	log      *slog.Logger
+	warned   map[string]bool
+}
+
+// NewLinkIndex indexes the given files. If log is not nil, ambiguous links
+// are reported on it.
+func NewLinkIndex(files []*File, log *slog.Logger) *LinkIndex {
+	idx := &LinkIndex{
+		names:    make(map[string][]*File, len(files)),
+		stems:    make(map[string][]*File),
+		paths:    make(map[string]*File, len(files)),
+		webPaths: make(map[string]*File, len(files)),
+		log:      log,
+		warned:   make(map[string]bool),
+	}
+	for _, f := range files {
+		full := strings.ToLower(f.FullName)
+		if full == "" {
+			full = strings.ToLower(f.Name + f.Ext)
+		}
+		name := strings.ToLower(f.Name)
+		idx.names[full] = append(idx.names[full], f)
+		if f.Ext == ".md" {
+			idx.names[name] = append(idx.names[name], f)
+		} else if f.Ext == ".canvas" || f.Ext == ".base" {
+			// Other attachments need their extension, like in Obsidian
+			idx.stems[name] = append(idx.stems[name], f)
+		}
+		if rel := relKey(f); rel != "" {
+			idx.paths[rel] = f
+		}
+		idx.webPaths[f.WebPath] = f
+	}
+	return idx
+}
+
+// relKey returns the lowercased, slash separated relative path of f.
+func relKey(f *File) string {
+	return strings.ToLower(filepath.ToSlash(f.RelPath))
+}
+
+// dirKey returns the lowercased folder of f, "." for the vault root.
+func dirKey(f *File) string {
+	if f == nil {
+		return "."
+	}
+	return path.Dir(relKey(f))
+}
+
+// FileByWebPath returns the file published at the given web path, or nil.
+func (idx *LinkIndex) FileByWebPath(webPath string) *File {
+	return idx.webPaths[webPath]
+}
+
+// Find returns the file targeted by a wikilink destination such as
+// "Folder/Note#Heading" written in source, and the anchor of the link.
+// The source may be nil, in which case links are resolved from the vault root.
+func (idx *LinkIndex) Find(source *File, target string) (*File, string, error) {
+	dest := strings.TrimSpace(target)
+
+	// Separate anchor
+	anchor := ""
+	if i := strings.Index(dest, "#"); i != -1 {
+		anchor = dest[i:]
+		dest = strings.TrimSpace(dest[:i])
+	}
+	if dest == "" {
+		return nil, anchor, ErrorCandidateNotFound
+	}
+	key := strings.ToLower(strings.ReplaceAll(dest, "\\", "/"))
+
+	var file *File
+	if strings.Contains(key, "/") {
+		file = idx.findPath(source, key, dest)
+	} else {
+		file = idx.findName(source, key, dest)
+	}
+	if file == nil {
+		return nil, anchor, ErrorCandidateNotFound
+	}
+	return file, anchor, nil
+}
+
+// findPath resolves a link containing a folder.
+func (idx *LinkIndex) findPath(source *File, key, dest string) *File {
+	dir := dirKey(source)
+	if strings.HasPrefix(key, "./") || strings.HasPrefix(key, "../") {
+		return idx.lookupPath(path.Join(dir, key))
+	}
+
+	// Exact path from the vault root, then relative to the current folder
+	if f := idx.lookupPath(path.Clean(strings.TrimPrefix(key, "/"))); f != nil {
+		return f
+	}
+	if f := idx.lookupPath(path.Join(dir, key)); f != nil {
+		return f
+	}
+
+	// Any path ending with the link, e.g. [[Sub/Note]] for "Projects/Sub/Note.md"
+	candidates := []*File{}
+	for _, f := range idx.names[path.Base(key)] {
+		if hasPathSuffix(f, key) {
+			candidates = append(candidates, f)
+		}
+	}
+	for _, f := range idx.stems[path.Base(key)] {
+		if hasPathSuffix(f, key) {
+			candidates = append(candidates, f)
+		}
+	}
+	return idx.pick(source, dest, candidates)
+}
+
+// findName resolves a link made of a bare file name.
+func (idx *LinkIndex) findName(source *File, key, dest string) *File {
+	candidates := idx.names[key]
+	if len(candidates) == 0 {
+		// Lenient fallback for canvases and bases linked without extension
+		candidates = idx.stems[key]
+	}
+	return idx.pick(source, dest, candidates)
+}
+
+// lookupPath returns the file at the given lowercased relative path. The
+// .md extension of notes may be omitted.
+func (idx *LinkIndex) lookupPath(rel string) *File {
+	if f, ok := idx.paths[rel]; ok {
+		return f
+	}
+	if f, ok := idx.paths[rel+".md"]; ok {
+		return f
+	}
+	return nil
+}
+
+// hasPathSuffix reports whether the relative path of f, with or without
+// its extension, ends with the given folder-qualified key.
+func hasPathSuffix(f *File, key string) bool {
+	rel := relKey(f)
+	stem := strings.TrimSuffix(rel, strings.ToLower(f.Ext))
+	return rel == key || stem == key ||
+		strings.HasSuffix(rel, "/"+key) || strings.HasSuffix(stem, "/"+key)
+}
+
+// pick chooses among the files matching a link: the one in the folder of
+// the source, then the one with the shortest path.
+func (idx *LinkIndex) pick(source *File, dest string, candidates []*File) *File {
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return candidates[0]
+	}
+
+	dir := dirKey(source)
+	for _, f := range candidates {
+		if dirKey(f) == dir {
+			return f
+		}
+	}
+
+	sorted := slices.Clone(candidates)
+	slices.SortFunc(sorted, func(a, b *File) int {
+		ra, rb := relKey(a), relKey(b)
+		if d := strings.Count(ra, "/") - strings.Count(rb, "/"); d != 0 {
+			return d
+		}
+		if d := len(ra) - len(rb); d != 0 {
+			return d
+		}
+		return strings.Compare(ra, rb)
+	})
+	best := sorted[0]
+
+	// A root file owns its bare name, as Obsidian never prefixes it with a folder
+	if dirKey(best) != "." {
+		idx.warnAmbiguous(source, dest, best, sorted)
+	}
+	return best
+}
+
+// warnAmbiguous logs an ambiguous link once per source folder and target.
+func (idx *LinkIndex) warnAmbiguous(source *File, dest string, chosen *File, candidates []*File) {
+	if idx.log == nil {
+		return
+	}
+	key := dirKey(source) + "\x00" + strings.ToLower(dest)
+	if idx.warned[key] {
+		return
+	}
+	idx.warned[key] = true
+
+	paths := make([]string, len(candidates))
+	for i, f := range candidates {
+		paths[i] = filepath.ToSlash(f.RelPath)
+	}
+	sourcePath := ""
+	if source != nil {
+		sourcePath = source.RelPath
+	}
+	idx.log.Warn(
+		"Ambiguous link, using the shortest path",
+		"link", dest,
+		"source", sourcePath,
+		"resolved", filepath.ToSlash(chosen.RelPath),
+		"candidates", strings.Join(paths, ", "),
+	)
+}
+
+// Resolve returns the file targeted by a raw "[[target|alias]]" or
+// "[text](path)" link found in source, or nil if it points outside the vault,
+// to the source itself through an anchor, or to nothing.
+func (idx *LinkIndex) Resolve(source *File, rawLink string) *File {
+	rawLink = strings.TrimPrefix(rawLink, "!")
+	if isMarkdownLink(rawLink) {
+		return idx.resolveMarkdownLink(source, linkTarget(rawLink))
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(rawLink, "[["), "]]")
+	inner, _, _ = strings.Cut(inner, "|")
+	file, _, err := idx.Find(source, inner)
+	if err != nil {
+		return nil
+	}
+	return file
+}
+
+// resolveMarkdownLink resolves a markdown link destination: first relative
+// to the source note, then from the vault root, then like a wikilink.
+func (idx *LinkIndex) resolveMarkdownLink(source *File, dest string) *File {
+	dest = strings.TrimSpace(dest)
+	// Drop the optional title: [text](path "title")
+	if i := strings.Index(dest, ` "`); i != -1 {
+		dest = dest[:i]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+
+	if strings.HasPrefix(dest, "http://") ||
+		strings.HasPrefix(dest, "https://") ||
+		strings.HasPrefix(dest, "mailto:") {
+		return nil
+	}
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	dest, _, _ = strings.Cut(dest, "#")
+	if dest == "" {
+		return nil
+	}
+
+	key := strings.ToLower(filepath.ToSlash(dest))
+	if strings.HasPrefix(key, "/") {
+		return idx.lookupPath(path.Clean(strings.TrimPrefix(key, "/")))
+	}
+	if f := idx.lookupPath(path.Join(dirKey(source), key)); f != nil {
+		return f
+	}
+	if strings.HasPrefix(key, "./") || strings.HasPrefix(key, "../") {
+		return nil
+	}
+	file, _, err := idx.Find(source, dest)
+	if err != nil {
+		return nil
+	}
+	return file
+}
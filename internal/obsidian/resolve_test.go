@@ -0,0 +1,103 @@
+// Tests for the shared link resolution. @feature:wikilinks
+package obsidian
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vaultFile returns a File at the given vault-relative path.
+func vaultFile(rel string) *File {
+	name, ext := SplitExt(rel)
+	return &File{RelPath: filepath.FromSlash(rel), Name: name, Ext: ext, FullName: name + ext}
+}
+
+func TestLinkIndex_Find(t *testing.T) {
+	rootNote := vaultFile("Note.md")
+	projNote := vaultFile("projects/Note.md")
+	deepNote := vaultFile("projects/sub/Deep.md")
+	otherDeep := vaultFile("archive/Deep.md")
+	photo := vaultFile("assets/Photo.png")
+	canvas := vaultFile("Board.canvas")
+	source := vaultFile("projects/Source.md")
+	files := []*File{rootNote, projNote, deepNote, otherDeep, photo, canvas, source}
+
+	idx := NewLinkIndex(files, nil)
+	cases := []struct {
+		source *File
+		target string
+		want   *File
+	}{
+		{nil, "note", rootNote},
+		{source, "Note", projNote},
+		{nil, "NOTE.md", rootNote},
+		{nil, "projects/note", projNote},
+		{source, "sub/Deep", deepNote},
+		{source, "./Note", projNote},
+		{source, "../Note", rootNote},
+		{nil, "photo.png", photo},
+		{nil, "photo", nil},
+		{nil, "assets/Photo", nil},
+		{nil, "Board", canvas},
+		{nil, "missing/Note", nil},
+		{source, "Note#Heading", projNote},
+	}
+	for _, c := range cases {
+		got, _, err := idx.Find(c.source, c.target)
+		if got != c.want {
+			t.Errorf("Find(%q) = %v, want %v", c.target, got, c.want)
+		}
+		if (err != nil) != (c.want == nil) {
+			t.Errorf("Find(%q) unexpected error %v", c.target, err)
+		}
+	}
+}
+
+func TestLinkIndex_AmbiguousWarning(t *testing.T) {
+	a := vaultFile("b/Deep.md")
+	b := vaultFile("a/Deep.md")
+	c := vaultFile("long/folder/Deep.md")
+	source := vaultFile("Source.md")
+
+	var buf bytes.Buffer
+	idx := NewLinkIndex([]*File{a, b, c, source}, slog.New(slog.NewTextHandler(&buf, nil)))
+
+	got, _, _ := idx.Find(source, "Deep")
+	if got != b {
+		t.Fatalf("expected the alphabetically first shortest path, got %s", got.RelPath)
+	}
+	idx.Find(source, "deep")
+
+	out := buf.String()
+	if strings.Count(out, "Ambiguous link") != 1 {
+		t.Fatalf("expected a single warning, got %q", out)
+	}
+	for _, want := range []string{"resolved=a/Deep.md", "a/Deep.md, b/Deep.md, long/folder/Deep.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("warning should contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestLinkIndex_ResolveMarkdownLink(t *testing.T) {
+	note := vaultFile("docs/My Note.md")
+	source := vaultFile("docs/Source.md")
+	idx := NewLinkIndex([]*File{note, source}, nil)
+
+	cases := map[string]*File{
+		"[x](My%20Note.md)":           note,
+		"[x](<My Note.md>)":           note,
+		"[x](/docs/My%20Note.md#top)": note,
+		"[x](../My%20Note.md)":        nil,
+		"[x](https://example.com)":    nil,
+		"[[My Note|alias]]":           note,
+	}
+	for raw, want := range cases {
+		if got := idx.Resolve(source, raw); got != want {
+			t.Errorf("Resolve(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -10,8 +10,38 @@ import (
 	"github.com/otaleghani/kiln/internal/rss"
 )
 
+// collectRSS collects the RSS entries of the notes of the vault, published
+// at their creation date.
+func (o *Obsidian) collectRSS() {
+	for _, f := range o.Vault.Files {
+		if f.Ext != ".md" {
+			continue
+		}
+		title := f.Name
+		if t, ok := f.Frontmatter["title"]; ok {
+			if s, ok := t.(string); ok && s != "" {
+				title = s
+			}
+		}
+		var desc string
+		if d, ok := f.Frontmatter["description"]; ok {
+			if s, ok := d.(string); ok {
+				desc = s
+			}
+		}
+		o.Vault.RSS = append(o.Vault.RSS, RSSEntry{
+			Title:       title,
+			Description: desc,
+			WebPath:     f.WebPath,
+			PubDate:     f.Created,
+			Lang:        f.Lang,
+		})
+	}
+}
+
 // GenerateRSS builds an RSS 2.0 feed from entries collected during vault scanning
-// and writes it to feed.xml in the output directory.
+// and writes it to feed.xml in the output directory. Multilingual sites get a
+// feed per language, feed.<lang>.xml for the languages other than the default.
 func (o *Obsidian) GenerateRSS() error {
 	o.log.Debug("Generating RSS feed...")
 
@@ -19,7 +49,25 @@ func (o *Obsidian) GenerateRSS() error {
 		return o.Vault.RSS[i].PubDate.After(o.Vault.RSS[j].PubDate)
 	})
 
-	entries := o.Vault.RSS
+	if !o.IsMultilingual() {
+		return o.writeFeed("feed.xml", o.Lang, o.Vault.RSS)
+	}
+	for _, lang := range o.Vault.Languages {
+		entries := []RSSEntry{}
+		for _, entry := range o.Vault.RSS {
+			if entry.Lang == lang {
+				entries = append(entries, entry)
+			}
+		}
+		if err := o.writeFeed(LanguageFileName("feed.xml", lang, o.Lang), lang, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFeed writes the 50 most recent entries to the named feed.
+func (o *Obsidian) writeFeed(name, lang string, entries []RSSEntry) error {
 	if len(entries) > 50 {
 		entries = entries[:50]
 	}
@@ -38,12 +86,13 @@ func (o *Obsidian) GenerateRSS() error {
 	}
 
 	xmlStr, err := rss.BuildFeedXML(rss.FeedParams{
-		Title: o.BaseURL,
-		Link:  o.BaseURL,
+		Title:    o.BaseURL,
+		Link:     o.BaseURL,
+		Language: lang,
 	}, items)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(o.OutputDir, "feed.xml"), []byte(xmlStr), 0644)
+	return os.WriteFile(filepath.Join(o.OutputDir, name), []byte(xmlStr), 0644)
 }
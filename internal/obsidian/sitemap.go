@@ -2,13 +2,27 @@
 package obsidian
 
 import (
+	"bytes"
 	"encoding/xml"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// maxSitemapURLs is the number of URLs a sitemap file may hold. Larger
+// sites are split into several files listed by a sitemap index.
+var maxSitemapURLs = 50000
+
+// maxSitemapImages is the number of images a sitemap URL may list.
+const maxSitemapImages = 1000
+
+// sitemapChangeFreqs are the accepted values of the "sitemap.changefreq" property.
+var sitemapChangeFreqs = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
 // generateRobots creates a robots.txt file in the output directory.
 // It points crawlers to the Sitemap location.
 func (o *Obsidian) GenerateRobots() error {
@@ -23,44 +37,326 @@ func (o *Obsidian) GenerateRobots() error {
 	return nil
 }
 
+// GenerateSitemap writes sitemap.xml, listing the HTML pages of the site.
+// When the site has more URLs than a sitemap may hold, they are written to
+// sitemap-1.xml, sitemap-2.xml, ... and sitemap.xml becomes their index.
+// Multilingual sites get a sitemap-<lang>.xml per language, listed by the
+// sitemap.xml index.
 func (o *Obsidian) GenerateSitemap() error {
 	o.log.Debug("Generating sitemap...")
-	sitemapFile, err := os.Create(filepath.Join(o.OutputDir, "sitemap.xml"))
-	if err != nil {
-		return err
+	o.Vault.Sitemap.Entries = o.sitemapEntries()
+	entries := o.Vault.Sitemap.Entries
+
+	if !o.IsMultilingual() {
+		if len(entries) <= maxSitemapURLs {
+			return writeURLSet(filepath.Join(o.OutputDir, "sitemap.xml"), entries)
+		}
+		refs, err := o.writeSitemaps("sitemap", entries)
+		if err != nil {
+			return err
+		}
+		o.log.Debug("Split sitemap", "urls", len(entries), "files", len(refs))
+		return writeSitemapIndex(filepath.Join(o.OutputDir, "sitemap.xml"), refs)
+	}
+
+	refs := []sitemapRef{}
+	for _, lang := range o.Vault.Languages {
+		group := []SitemapEntry{}
+		for _, entry := range entries {
+			if entry.Lang == lang {
+				group = append(group, entry)
+			}
+		}
+		written, err := o.writeSitemaps("sitemap-"+lang, group)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, written...)
+	}
+	return writeSitemapIndex(filepath.Join(o.OutputDir, "sitemap.xml"), refs)
+}
+
+// writeSitemaps writes entries to <prefix>.xml, or to <prefix>-1.xml,
+// <prefix>-2.xml, ... when they don't fit a single file, and returns the
+// references to list in the sitemap index.
+func (o *Obsidian) writeSitemaps(prefix string, entries []SitemapEntry) ([]sitemapRef, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	refs := []sitemapRef{}
+	for i := 0; i*maxSitemapURLs < len(entries); i++ {
+		chunk := entries[i*maxSitemapURLs : min((i+1)*maxSitemapURLs, len(entries))]
+		name := prefix + ".xml"
+		if len(entries) > maxSitemapURLs {
+			name = fmt.Sprintf("%s-%d.xml", prefix, i+1)
+		}
+		if err := writeURLSet(filepath.Join(o.OutputDir, name), chunk); err != nil {
+			return nil, err
+		}
+		refs = append(refs, sitemapRef{
+			Loc:     strings.TrimRight(o.BaseURL, "/") + "/" + name,
+			LastMod: latestLastMod(chunk),
+		})
+	}
+	return refs, nil
+}
+
+// writeSitemapIndex writes a sitemap index listing the given sitemaps.
+func writeSitemapIndex(path string, refs []sitemapRef) error {
+	var index bytes.Buffer
+	index.WriteString(xml.Header)
+	index.WriteString(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for _, ref := range refs {
+		output, err := xml.MarshalIndent(ref, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		index.Write(output)
+		index.WriteString("\n")
+	}
+	index.WriteString(`</sitemapindex>`)
+	return os.WriteFile(path, index.Bytes(), 0644)
+}
+
+// writeURLSet writes a sitemap file holding the given entries.
+func writeURLSet(path string, entries []SitemapEntry) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"`)
+	buf.WriteString(` xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`)
+	buf.WriteString(` xmlns:xhtml="http://www.w3.org/1999/xhtml">` + "\n")
+	for _, entry := range entries {
+		output, err := xml.MarshalIndent(entry, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(output)
+		buf.WriteString("\n")
+	}
+	buf.WriteString(`</urlset>`)
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// sitemapEntries lists the pages of the vault: notes, canvases and bases,
+// folder pages that aren't overridden by a note, and tag pages. Entries are
+// sorted by URL.
+func (o *Obsidian) sitemapEntries() []SitemapEntry {
+	baseURL := strings.TrimRight(o.BaseURL, "/")
+	pageURL := func(webPath string, dir bool) string {
+		if (dir || o.FlatURLs) && !strings.HasSuffix(webPath, "/") {
+			webPath += "/"
+		}
+		return baseURL + webPath
+	}
+
+	entries := []SitemapEntry{}
+	lastMods := map[*File]time.Time{}
+	pages := map[string]struct{}{}
+	for _, f := range o.Vault.Files {
+		switch f.Ext {
+		case ".md", ".canvas", ".base":
+		default:
+			continue
+		}
+		lastMod := o.fileLastMod(f)
+		lastMods[f] = lastMod
+		pages[f.WebPath] = struct{}{}
+		entry := SitemapEntry{
+			Loc:     pageURL(f.WebPath, false),
+			LastMod: formatLastMod(lastMod),
+			Images:  o.sitemapImages(f, baseURL),
+			Lang:    f.Lang,
+		}
+		entry.Alternates = o.sitemapAlternates(f, pageURL)
+		o.applySitemapProperties(&entry, f)
+		entries = append(entries, entry)
+	}
+
+	for _, folder := range o.Vault.Folders {
+		if len(folder.Files) == 0 && len(folder.Folders) == 0 {
+			continue
+		}
+		// Folders overridden by a note are already listed
+		if _, overridden := pages[folder.WebPath]; overridden {
+			continue
+		}
+		lastMod := folder.Modified
+		if latest := latestOf(folder.Files, lastMods); !latest.IsZero() {
+			lastMod = latest
+		}
+		entries = append(entries, SitemapEntry{
+			Loc:     pageURL(folder.WebPath, true),
+			LastMod: formatLastMod(lastMod),
+			Lang:    folder.Lang,
+		})
+	}
+
+	for _, tag := range o.Vault.Tags {
+		entries = append(entries, SitemapEntry{
+			Loc:     pageURL(tag.WebPath, false),
+			LastMod: formatLastMod(latestOf(tag.Files, lastMods)),
+			Lang:    o.Lang,
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b SitemapEntry) int { return strings.Compare(a.Loc, b.Loc) })
+	return entries
+}
+
+// sitemapAlternates returns the hreflang links of a translated note: the
+// note itself, its translations and the version in the default language as
+// x-default.
+func (o *Obsidian) sitemapAlternates(f *File, pageURL func(string, bool) string) []SitemapAlternate {
+	if len(f.Translations) == 0 {
+		return nil
 	}
-	defer sitemapFile.Close()
-	sitemapFile.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
-	sitemapFile.WriteString(
-		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n",
-	)
-	for _, entry := range o.Vault.Sitemap.Entries {
-		o.log.Debug("Sitemap adding", "entry", entry)
-		output, _ := xml.MarshalIndent(entry, "  ", "  ")
-		sitemapFile.Write(output)
-		sitemapFile.WriteString("\n")
+	fallback := pageURL(f.WebPath, false)
+	links := []SitemapAlternate{{Rel: "alternate", Hreflang: f.Lang, Href: fallback}}
+	for _, t := range f.Translations {
+		links = append(links, SitemapAlternate{Rel: "alternate", Hreflang: t.Lang, Href: pageURL(t.WebPath, false)})
+		if t.Lang == o.Lang {
+			fallback = pageURL(t.WebPath, false)
+		}
 	}
-	sitemapFile.WriteString(`</urlset>`)
+	return append(links, SitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: fallback})
+}
+
+// fileLastMod returns when a page last changed: its "updated" property, the
+// date of the last commit touching it, or its modification date.
+func (o *Obsidian) fileLastMod(f *File) time.Time {
+	if updated := ParseDate(f.Frontmatter["updated"]); !updated.IsZero() {
+		return updated
+	}
+	if d, ok := o.gitHistory()[f.RelPath]; ok {
+		return d.Last
+	}
+	return f.Modified
+}
+
+// latestOf returns the latest of the last modification times of files.
+func latestOf(files []*File, lastMods map[*File]time.Time) time.Time {
+	var latest time.Time
+	for _, f := range files {
+		if t := lastMods[f]; t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
 
+// formatLastMod formats a time as a sitemap date, or "" for the zero time.
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// latestLastMod returns the latest lastmod of the entries.
+func latestLastMod(entries []SitemapEntry) string {
+	latest := ""
+	for _, e := range entries {
+		// Dates in the same format compare as strings
+		latest = max(latest, e.LastMod)
+	}
+	return latest
+}
+
+// sitemapImages returns the images embedded in a note.
+func (o *Obsidian) sitemapImages(f *File, baseURL string) []SitemapImage {
+	if o.Vault.Links == nil {
+		return nil
+	}
+	images := []SitemapImage{}
+	seen := map[*File]struct{}{}
+	for _, raw := range f.Embeds {
+		target := o.Vault.Links.Resolve(f, raw)
+		if target == nil || !isImageExt(target.Ext) {
+			continue
+		}
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		images = append(images, SitemapImage{Loc: baseURL + target.WebPath})
+		if len(images) == maxSitemapImages {
+			break
+		}
+	}
+	return images
+}
+
+// applySitemapProperties sets the priority and change frequency of an entry
+// from the "sitemap.priority" and "sitemap.changefreq" properties of a note.
+func (o *Obsidian) applySitemapProperties(entry *SitemapEntry, f *File) {
+	if v := sitemapProperty(f.Frontmatter, "priority"); v != nil {
+		priority, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64)
+		if err != nil || priority < 0 || priority > 1 {
+			o.log.Warn("Invalid sitemap priority, expected a number between 0 and 1", "path", f.RelPath, "priority", v)
+		} else {
+			entry.Priority = strconv.FormatFloat(priority, 'f', 1, 64)
+		}
+	}
+	if v := sitemapProperty(f.Frontmatter, "changefreq"); v != nil {
+		freq := strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+		if !slices.Contains(sitemapChangeFreqs, freq) {
+			o.log.Warn("Invalid sitemap change frequency", "path", f.RelPath, "changefreq", v, "allowed", strings.Join(sitemapChangeFreqs, ", "))
+		} else {
+			entry.ChangeFreq = freq
+		}
+	}
+}
+
+// sitemapProperty returns a sitemap setting of a note, written either as a
+// "sitemap.<key>" property or as a key of a "sitemap" map.
+func sitemapProperty(frontmatter map[string]any, key string) any {
+	if v, ok := frontmatter["sitemap."+key]; ok {
+		return v
+	}
+	if m, ok := frontmatter["sitemap"].(map[string]any); ok {
+		return m[key]
+	}
 	return nil
 }
 
-// addEntry appends a new entry to the sitemap slice.
-// It retrieves the file's modification time to populate 'lastmod'.
-func (o *Obsidian) AddSitemapEntry(modTime time.Time, baseURL, webPath string) {
-	fullURL := strings.TrimRight(baseURL, "/") + webPath
-	o.Vault.Sitemap.Entries = append(o.Vault.Sitemap.Entries, SitemapEntry{
-		Loc:     fullURL,
-		LastMod: modTime.Format("2006-01-02"),
-	})
-	o.log.Debug("Added to sitemap", "url", webPath)
+// isImageExt reports whether ext is the extension of an image a crawler can index.
+func isImageExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg":
+		return true
+	}
+	return false
 }
 
 // SitemapEntry represents a single URL entry in the sitemap.xml.
 type SitemapEntry struct {
-	XMLName xml.Name `xml:"url"`
-	Loc     string   `xml:"loc"`     // The absolute URL
-	LastMod string   `xml:"lastmod"` // The last modification date
+	XMLName    xml.Name           `xml:"url"`
+	Loc        string             `xml:"loc"`                  // The absolute URL
+	LastMod    string             `xml:"lastmod,omitempty"`    // The last modification date
+	ChangeFreq string             `xml:"changefreq,omitempty"` // How often the page changes
+	Priority   string             `xml:"priority,omitempty"`   // Priority relative to the other pages
+	Images     []SitemapImage     `xml:"image:image"`          // Images embedded in the page
+	Alternates []SitemapAlternate `xml:"xhtml:link"`           // Versions of the page in other languages
+	Lang       string             `xml:"-"`                    // Language of the page
+}
+
+// SitemapAlternate is a version of a sitemap entry in another language.
+type SitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// SitemapImage is an image listed under a sitemap entry.
+type SitemapImage struct {
+	Loc string `xml:"image:loc"` // The absolute URL of the image
+}
+
+// sitemapRef is an entry of a sitemap index.
+type sitemapRef struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
 }
 
 // Sitemap holds all of the entries to generate the sitemap
@@ -0,0 +1,137 @@
+// @feature:sitemap Tests for the sitemap entries, the sitemap index and the git dates.
+package obsidian
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSitemapEntries(t *testing.T) {
+	o := scanVault(t, map[string]string{
+		"index.md":         "---\nsitemap:\n  priority: 1\n  changefreq: Daily\n---\nHome",
+		"notes/a.md":       "---\nupdated: 2024-05-01\nsitemap.priority: 0.8\n---\n#go ![[pic.png]] ![[pic.png]] ![[b]]",
+		"notes/b.md":       "---\nsitemap:\n  priority: high\n---\nB",
+		"notes/pic.png":    "png",
+		"guides/index.md":  "Guides",
+		"guides/setup.md":  "Setup",
+		"board.canvas":     "{}",
+		"empty/.gitkeep":   "",
+		"notes/styles.css": "body{}",
+		"notes/report.pdf": "pdf",
+	})
+
+	entries := map[string]SitemapEntry{}
+	for _, e := range o.sitemapEntries() {
+		entries[e.Loc] = e
+	}
+	want := []string{
+		"https://example.com/",
+		"https://example.com/board",
+		"https://example.com/guides",
+		"https://example.com/guides/setup",
+		"https://example.com/notes/",
+		"https://example.com/notes/a",
+		"https://example.com/notes/b",
+		"https://example.com/tags/go",
+	}
+	if len(entries) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(entries), len(want), entries)
+	}
+	for _, loc := range want {
+		if _, ok := entries[loc]; !ok {
+			t.Errorf("missing entry %q", loc)
+		}
+	}
+	if _, ok := entries["https://example.com/guides/"]; ok {
+		t.Error("folder overridden by its index note should not be listed twice")
+	}
+
+	home := entries["https://example.com/"]
+	if home.Priority != "1.0" || home.ChangeFreq != "daily" {
+		t.Errorf("home priority/changefreq = %q/%q", home.Priority, home.ChangeFreq)
+	}
+	a := entries["https://example.com/notes/a"]
+	if a.LastMod != "2024-05-01" {
+		t.Errorf("lastmod from updated = %q", a.LastMod)
+	}
+	if a.Priority != "0.8" {
+		t.Errorf("flat sitemap.priority = %q", a.Priority)
+	}
+	if len(a.Images) != 1 || a.Images[0].Loc != "https://example.com/notes/pic.png" {
+		t.Errorf("images = %v", a.Images)
+	}
+	if b := entries["https://example.com/notes/b"]; b.Priority != "" {
+		t.Errorf("invalid priority should be dropped, got %q", b.Priority)
+	}
+	if folder, b := entries["https://example.com/notes/"], entries["https://example.com/notes/b"]; folder.LastMod != b.LastMod {
+		t.Errorf("folder lastmod = %q, want the latest of its notes %q", folder.LastMod, b.LastMod)
+	}
+}
+
+func TestGenerateSitemap_Index(t *testing.T) {
+	o := scanVault(t, map[string]string{
+		"a.md": "A",
+		"b.md": "B",
+		"c.md": "C",
+	})
+	defer func(n int) { maxSitemapURLs = n }(maxSitemapURLs)
+	maxSitemapURLs = 2
+
+	if err := o.GenerateSitemap(); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(o.OutputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<sitemapindex", "<loc>https://example.com/sitemap-1.xml</loc>", "<loc>https://example.com/sitemap-2.xml</loc>"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index is missing %q:\n%s", want, index)
+		}
+	}
+	second, err := os.ReadFile(filepath.Join(o.OutputDir, "sitemap-2.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(second), "<url>") != 1 || !strings.Contains(string(second), "https://example.com/c") {
+		t.Errorf("second sitemap = %s", second)
+	}
+}
+
+func TestGenerateSitemap_Single(t *testing.T) {
+	o := scanVault(t, map[string]string{"a.md": "![[pic.png]]", "pic.png": "png"})
+	if err := o.GenerateSitemap(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(o.OutputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`, "<image:image>", "<image:loc>https://example.com/pic.png</image:loc>"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("sitemap is missing %q:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(o.OutputDir, "sitemap-1.xml")); err == nil {
+		t.Error("small sites should not be split")
+	}
+}
+
+func TestSitemapPath(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://example.com":       "https://example.com/sitemap.xml",
+		"https://example.com/docs/": "https://example.com/docs/sitemap.xml",
+	} {
+		o := New(WithBaseURL(base), WithInputDir(t.TempDir()), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		if err := o.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		if o.Vault.Sitemap.Path != want {
+			t.Errorf("Path for %q = %q, want %q", base, o.Vault.Sitemap.Path, want)
+		}
+	}
+}
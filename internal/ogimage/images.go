@@ -0,0 +1,120 @@
+// @feature:ogimage Loading and placing cover images and logos on cards.
+package ogimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var errNoPNGIcon = errors.New("ogimage: icon holds no PNG image")
+
+// LoadImage decodes a PNG, JPEG, GIF or WebP image. ICO files are
+// supported when they embed PNG images, as modern favicons do; the largest
+// one is returned.
+func LoadImage(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ogimage: read %s: %w", path, err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".ico") {
+		data, err = largestPNGIcon(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("ogimage: decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// largestPNGIcon returns the largest PNG image of an ICO file.
+func largestPNGIcon(data []byte) ([]byte, error) {
+	if len(data) < 6 || binary.LittleEndian.Uint16(data[2:]) != 1 {
+		return nil, errNoPNGIcon
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+
+	var best []byte
+	bestSize := -1
+	for i := range count {
+		entry := 6 + i*16
+		if entry+16 > len(data) {
+			break
+		}
+		// A width or height of 0 means 256 pixels
+		w, h := int(data[entry]), int(data[entry+1])
+		if w == 0 {
+			w = 256
+		}
+		if h == 0 {
+			h = 256
+		}
+		size := int(binary.LittleEndian.Uint32(data[entry+8:]))
+		offset := int(binary.LittleEndian.Uint32(data[entry+12:]))
+		if offset+size > len(data) || size < 8 {
+			continue
+		}
+		img := data[offset : offset+size]
+		if !bytes.HasPrefix(img, []byte("\x89PNG")) {
+			continue
+		}
+		if w*h > bestSize {
+			best, bestSize = img, w*h
+		}
+	}
+	if best == nil {
+		return nil, errNoPNGIcon
+	}
+	return best, nil
+}
+
+// drawCover scales src to fill r entirely, cropping its center.
+func drawCover(dst draw.Image, r image.Rectangle, src image.Image) {
+	b := src.Bounds()
+	if b.Empty() || r.Empty() {
+		return
+	}
+	crop := b
+	// Compare aspect ratios: src is wider than r when sw/sh > rw/rh
+	if b.Dx()*r.Dy() > r.Dx()*b.Dy() {
+		w := b.Dy() * r.Dx() / r.Dy()
+		crop.Min.X = b.Min.X + (b.Dx()-w)/2
+		crop.Max.X = crop.Min.X + w
+	} else {
+		h := b.Dx() * r.Dy() / r.Dx()
+		crop.Min.Y = b.Min.Y + (b.Dy()-h)/2
+		crop.Max.Y = crop.Min.Y + h
+	}
+	draw.CatmullRom.Scale(dst, r, src, crop, draw.Over, nil)
+}
+
+// drawContain scales src to fit in a size x size square at (x, y), keeping
+// its aspect ratio.
+func drawContain(dst draw.Image, x, y, size int, src image.Image) {
+	b := src.Bounds()
+	if b.Empty() {
+		return
+	}
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = size * b.Dy() / b.Dx()
+	} else {
+		w = size * b.Dx() / b.Dy()
+	}
+	r := image.Rect(x+(size-w)/2, y+(size-h)/2, x+(size-w)/2+w, y+(size-h)/2+h)
+	draw.CatmullRom.Scale(dst, r, src, b, draw.Over, nil)
+}
@@ -2,16 +2,19 @@
 package ogimage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 )
 
@@ -22,14 +25,43 @@ const (
 	twitterHeight = 600
 
 	accentBarHeight = 8
-	padding         = 60
-	titleY          = 120
-	descY           = 260
-	siteNameY       = 560
-	maxTitleChars   = 80
-	wrapWidth       = 50
+	padding         = 72
+	logoSize        = 56
 )
 
+// cardVersion is part of every card key. Bump it whenever the rendering of
+// the same inputs changes.
+const cardVersion = 2
+
+// Built-in card styles
+const (
+	StyleDefault = "default" // Accent bar, title, description and a footer with date and tags
+	StyleMinimal = "minimal" // Centered title and site name
+	StyleSplit   = "split"   // Text on the left, cover image on the right
+	StyleCover   = "cover"   // Cover image behind a darkened overlay
+)
+
+// Styles lists the built-in card styles.
+var Styles = []string{StyleDefault, StyleMinimal, StyleSplit, StyleCover}
+
+// Options configures the generated cards. It maps to the "og" section of kiln.yaml.
+type Options struct {
+	Style    string `yaml:"style"`     // One of Styles (default "default")
+	Logo     string `yaml:"logo"`      // Vault path of the logo, defaults to favicon.png or favicon.ico
+	HideTags bool   `yaml:"hide-tags"` // Leave the tags out of the cards
+	HideDate bool   `yaml:"hide-date"` // Leave the date out of the cards
+}
+
+// StyleOrDefault returns the configured style, or StyleDefault when it is
+// empty or unknown.
+func (o Options) StyleOrDefault() string {
+	style := strings.ToLower(o.Style)
+	if slices.Contains(Styles, style) {
+		return style
+	}
+	return StyleDefault
+}
+
 type ImageConfig struct {
 	Title       string
 	Description string
@@ -37,7 +69,12 @@ type ImageConfig struct {
 	AccentColor string
 	BgColor     string
 	TextColor   string
-	Face        font.Face
+	Date        string   // Formatted date, empty to leave it out
+	Tags        []string // Tags without the leading #
+	Style       string   // One of Styles, StyleDefault when empty
+	CoverPath   string   // Cover image, used by the split and cover styles
+	LogoPath    string   // Site logo shown next to the site name
+	Fonts       Fonts
 }
 
 // GenerateOGImage creates a 1200x630 branded PNG for Open Graph.
@@ -50,68 +87,275 @@ func GenerateTwitterImage(cfg ImageConfig, outPath string) error {
 	return generateImage(cfg, outPath, twitterWidth, twitterHeight)
 }
 
+// OGKey identifies the Open Graph card of cfg. It changes whenever any
+// input of the card changes, including the content of the cover and logo.
+func (cfg ImageConfig) OGKey() (string, error) {
+	return cfg.key(ogWidth, ogHeight)
+}
+
+// TwitterKey identifies the Twitter card of cfg, like OGKey.
+func (cfg ImageConfig) TwitterKey() (string, error) {
+	return cfg.key(twitterWidth, twitterHeight)
+}
+
+func (cfg ImageConfig) key(width, height int) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d %dx%d %q %q %q %s %s %s %q %q %s %s\n",
+		cardVersion, width, height, cfg.Title, cfg.Description, cfg.SiteName,
+		cfg.AccentColor, cfg.BgColor, cfg.TextColor, cfg.Date, cfg.Tags, cfg.Style, cfg.Fonts.Key)
+	for _, path := range []string{cfg.CoverPath, cfg.LogoPath} {
+		if path == "" {
+			io.WriteString(h, "-\n")
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("ogimage: open %s: %w", path, err)
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("ogimage: read %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func generateImage(cfg ImageConfig, outPath string, width, height int) error {
+	img := renderCard(cfg, width, height)
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("ogimage: create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("ogimage: encode png: %w", err)
+	}
+	return nil
+}
+
+// palette holds the colors of a card.
+type palette struct {
+	bg, accent, text, muted color.RGBA
+}
+
+// renderCard draws the card in the configured style. The split and cover
+// styles fall back to the default one without a readable cover image.
+func renderCard(cfg ImageConfig, width, height int) *image.RGBA {
 	if cfg.Title == "" {
 		cfg.Title = "Untitled"
 	}
+	p := palette{
+		bg:     parseHex(cfg.BgColor),
+		accent: parseHex(cfg.AccentColor),
+		text:   parseHex(cfg.TextColor),
+	}
+	p.muted = mix(p.text, p.bg, 0.7)
+
+	var cover, logo image.Image
+	if cfg.CoverPath != "" {
+		cover, _ = LoadImage(cfg.CoverPath)
+	}
+	if cfg.LogoPath != "" {
+		logo, _ = LoadImage(cfg.LogoPath)
+	}
 
-	bg := parseHex(cfg.BgColor)
-	accent := parseHex(cfg.AccentColor)
-	text := parseHex(cfg.TextColor)
+	style := cfg.Style
+	if (style == StyleSplit || style == StyleCover) && cover == nil {
+		style = StyleDefault
+	}
 
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{p.bg}, image.Point{}, draw.Src)
 
-	// Background
-	draw.Draw(img, img.Bounds(), &image.Uniform{bg}, image.Point{}, draw.Src)
+	switch style {
+	case StyleMinimal:
+		drawMinimal(img, cfg, p, logo)
+	case StyleSplit:
+		split := width * 3 / 5
+		drawCover(img, image.Rect(split, 0, width, height), cover)
+		draw.Draw(img, image.Rect(0, 0, split, accentBarHeight), &image.Uniform{p.accent}, image.Point{}, draw.Src)
+		drawStacked(img, cfg, p, logo, image.Rect(padding, padding, split-padding/2, height-padding))
+	case StyleCover:
+		drawCover(img, img.Bounds(), cover)
+		darken(img)
+		white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+		p.text, p.muted = white, mix(white, color.RGBA{A: 255}, 0.8)
+		drawStacked(img, cfg, p, logo, image.Rect(padding, padding, width-padding, height-padding))
+	default:
+		draw.Draw(img, image.Rect(0, 0, width, accentBarHeight), &image.Uniform{p.accent}, image.Point{}, draw.Src)
+		drawStacked(img, cfg, p, logo, image.Rect(padding, padding, width-padding, height-padding))
+	}
+	return img
+}
 
-	// Accent bar at top
-	draw.Draw(
-		img,
-		image.Rect(0, 0, width, accentBarHeight),
-		&image.Uniform{accent},
-		image.Point{},
-		draw.Src,
-	)
+// drawStacked lays out the header, title, description and footer of a card
+// from top to bottom inside area.
+func drawStacked(img *image.RGBA, cfg ImageConfig, p palette, logo image.Image, area image.Rectangle) {
+	fonts := cfg.Fonts
+	width := area.Dx()
 
-	face := cfg.Face
-	if face == nil {
-		face = basicfont.Face7x13
+	// Header: logo and site name
+	headerFace := fonts.face(30, true)
+	y := area.Min.Y
+	x := area.Min.X
+	if logo != nil {
+		drawContain(img, x, y, logoSize, logo)
+		x += logoSize + 20
 	}
+	if cfg.SiteName != "" {
+		m := headerFace.Metrics()
+		baseline := y + (logoSize+m.Ascent.Ceil()-m.Descent.Ceil())/2
+		drawLabel(img, headerFace, x, baseline, ellipsize(headerFace, cfg.SiteName, area.Max.X-x), p.accent)
+	}
+	y += logoSize + 48
 
-	// Title — wrap long text
-	titleLines := wrapText(cfg.Title, wrapWidth)
-	lineHeight := 42
-	for i, line := range titleLines {
-		// Draw each character twice offset for a faux-bold effect
-		drawLabel(img, face, padding, titleY+i*lineHeight, line, text)
-		drawLabel(img, face, padding+1, titleY+i*lineHeight, line, text)
+	// Footer: date on the left, tags on the right
+	footerFace := fonts.face(26, false)
+	footerY := area.Max.Y
+	dateWidth := 0
+	if cfg.Date != "" {
+		drawLabel(img, footerFace, area.Min.X, footerY, cfg.Date, p.muted)
+		dateWidth = font.MeasureString(footerFace, cfg.Date).Ceil() + 40
+	}
+	drawTags(img, footerFace, cfg.Tags, area.Min.X+dateWidth, area.Max.X, footerY, p.accent)
+	bottom := footerY - lineHeight(footerFace) - 24
+	if cfg.Date == "" && len(cfg.Tags) == 0 {
+		bottom = area.Max.Y
 	}
 
-	// Description
+	// Title, as large as possible in at most three lines while leaving room
+	// for a line of description
+	descFace := fonts.face(32, false)
+	descLH := lineHeight(descFace) + 6
+	reserve := 0
 	if cfg.Description != "" {
-		descLines := wrapText(cfg.Description, wrapWidth+10)
-		descStartY := descY + len(titleLines)*lineHeight
-		for i, line := range descLines {
-			drawLabel(img, face, padding, descStartY+i*lineHeight, line, text)
+		reserve = descLH + 20
+	}
+	var titleFace font.Face
+	var title []string
+	for _, size := range []float64{72, 60, 52} {
+		titleFace = fonts.face(size, true)
+		title = wrapText(titleFace, cfg.Title, width, 0)
+		if len(title) <= 3 && y+len(title)*lineHeight(titleFace)+reserve <= bottom {
+			break
 		}
 	}
+	titleLH := lineHeight(titleFace)
+	maxTitle := max(1, min(3, (bottom-y)/titleLH))
+	title = wrapText(titleFace, cfg.Title, width, maxTitle)
+	for _, line := range title {
+		y += titleLH
+		drawLabel(img, titleFace, area.Min.X, y-titleFace.Metrics().Descent.Ceil(), line, p.text)
+	}
+
+	// Description, in the remaining space
+	if cfg.Description == "" {
+		return
+	}
+	y += 20
+	maxDesc := min(3, (bottom-y)/descLH)
+	if maxDesc < 1 {
+		return
+	}
+	for _, line := range wrapText(descFace, cfg.Description, width, maxDesc) {
+		y += descLH
+		drawLabel(img, descFace, area.Min.X, y-descFace.Metrics().Descent.Ceil(), line, p.muted)
+	}
+}
+
+// drawTags writes as many "#tag" labels as fit between minX and maxX,
+// aligned to the right.
+func drawTags(img *image.RGBA, face font.Face, tags []string, minX, maxX, y int, c color.RGBA) {
+	const gap = 24
+	labels := []string{}
+	total := 0
+	for _, tag := range tags {
+		label := "#" + strings.TrimPrefix(tag, "#")
+		w := font.MeasureString(face, label).Ceil()
+		if total+w > maxX-minX {
+			break
+		}
+		labels = append(labels, label)
+		total += w + gap
+	}
+	x := maxX - total + gap
+	for _, label := range labels {
+		drawLabel(img, face, x, y, label, c)
+		x += font.MeasureString(face, label).Ceil() + gap
+	}
+}
+
+// drawMinimal centers the title on the card, with the logo and site name
+// below an accent rule.
+func drawMinimal(img *image.RGBA, cfg ImageConfig, p palette, logo image.Image) {
+	b := img.Bounds()
+	width := b.Dx() - 2*padding*2
+
+	var titleFace font.Face
+	var title []string
+	for _, size := range []float64{80, 64, 56} {
+		titleFace = cfg.Fonts.face(size, true)
+		title = wrapText(titleFace, cfg.Title, width, 0)
+		if len(title) <= 3 {
+			break
+		}
+	}
+	title = wrapText(titleFace, cfg.Title, width, 3)
+	lh := lineHeight(titleFace)
+
+	siteFace := cfg.Fonts.face(30, false)
+	footer := logoSize
+	blockH := len(title)*lh + 40 + 4 + 40 + footer
+	y := (b.Dy() - blockH) / 2
+
+	for _, line := range title {
+		y += lh
+		w := font.MeasureString(titleFace, line).Ceil()
+		drawLabel(img, titleFace, (b.Dx()-w)/2, y-titleFace.Metrics().Descent.Ceil(), line, p.text)
+	}
+	y += 40
+	draw.Draw(img, image.Rect(b.Dx()/2-60, y, b.Dx()/2+60, y+4), &image.Uniform{p.accent}, image.Point{}, draw.Src)
+	y += 44
 
-	// Site name at bottom
+	siteW := 0
+	if cfg.SiteName != "" {
+		siteW = font.MeasureString(siteFace, cfg.SiteName).Ceil()
+	}
+	rowW := siteW
+	if logo != nil {
+		rowW += logoSize + 16
+	}
+	x := (b.Dx() - rowW) / 2
+	if logo != nil {
+		drawContain(img, x, y, logoSize, logo)
+		x += logoSize + 16
+	}
 	if cfg.SiteName != "" {
-		siteY := height - padding
-		drawLabel(img, face, padding, siteY, cfg.SiteName, accent)
+		m := siteFace.Metrics()
+		drawLabel(img, siteFace, x, y+(logoSize+m.Ascent.Ceil()-m.Descent.Ceil())/2, cfg.SiteName, p.muted)
 	}
+}
 
-	f, err := os.Create(outPath)
-	if err != nil {
-		return fmt.Errorf("ogimage: create file: %w", err)
+// darken lays a vertical gradient over img, from translucent at the top to
+// almost opaque black at the bottom, so that white text stays readable.
+func darken(img *image.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		alpha := 0.45 + 0.4*float64(y-b.Min.Y)/float64(b.Dy())
+		overlay := &image.Uniform{color.NRGBA{A: uint8(alpha * 255)}}
+		draw.Draw(img, image.Rect(b.Min.X, y, b.Max.X, y+1), overlay, image.Point{}, draw.Over)
 	}
-	defer f.Close()
+}
 
-	if err := png.Encode(f, img); err != nil {
-		return fmt.Errorf("ogimage: encode png: %w", err)
+// mix blends a over b with the given weight of a.
+func mix(a, b color.RGBA, weight float64) color.RGBA {
+	blend := func(x, y uint8) uint8 {
+		return uint8(float64(x)*weight + float64(y)*(1-weight) + 0.5)
 	}
-	return nil
+	return color.RGBA{R: blend(a.R, b.R), G: blend(a.G, b.G), B: blend(a.B, b.B), A: 255}
 }
 
 func drawLabel(img *image.RGBA, face font.Face, x, y int, text string, c color.RGBA) {
@@ -140,24 +384,3 @@ func parseHex(s string) color.RGBA {
 	}
 	return c
 }
-
-func wrapText(text string, maxWidth int) []string {
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{text}
-	}
-
-	var lines []string
-	current := words[0]
-
-	for _, w := range words[1:] {
-		if len(current)+1+len(w) > maxWidth {
-			lines = append(lines, current)
-			current = w
-		} else {
-			current += " " + w
-		}
-	}
-	lines = append(lines, current)
-	return lines
-}
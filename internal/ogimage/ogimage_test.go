@@ -2,11 +2,18 @@
 package ogimage
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
 	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
 )
 
 func defaultConfig() ImageConfig {
@@ -133,3 +140,205 @@ func TestGenerateOGImage_WritesToFile(t *testing.T) {
 		t.Error("output file is empty")
 	}
 }
+
+// writePNG writes a solid w x h PNG and returns its path.
+func writePNG(t *testing.T, dir, name string, w, h int, c color.RGBA) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRenderCard_Styles(t *testing.T) {
+	dir := t.TempDir()
+	red := color.RGBA{R: 255, A: 255}
+	cover := writePNG(t, dir, "cover.png", 300, 200, red)
+
+	cfg := defaultConfig()
+	cfg.Date = "Jan 02, 2006"
+	cfg.Tags = []string{"go", "obsidian"}
+	cfg.CoverPath = cover
+
+	cfg.Style = StyleSplit
+	img := renderCard(cfg, ogWidth, ogHeight)
+	if got := img.RGBAAt(ogWidth-10, ogHeight/2); got != red {
+		t.Errorf("split: expected the cover on the right, got %v", got)
+	}
+	if got := img.RGBAAt(10, ogHeight/2); got != parseHex(cfg.BgColor) {
+		t.Errorf("split: expected the background on the left, got %v", got)
+	}
+
+	cfg.Style = StyleCover
+	img = renderCard(cfg, ogWidth, ogHeight)
+	if got := img.RGBAAt(ogWidth/2, 4); got.R == 0 || got.G != 0 {
+		t.Errorf("cover: expected a darkened cover behind the text, got %v", got)
+	}
+}
+
+func TestRenderCard_FallsBackWithoutCover(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Style = StyleSplit
+	cfg.CoverPath = filepath.Join(t.TempDir(), "missing.png")
+
+	img := renderCard(cfg, ogWidth, ogHeight)
+	// The default style draws the accent bar across the whole width
+	if got := img.RGBAAt(ogWidth-10, 2); got != parseHex(cfg.AccentColor) {
+		t.Errorf("expected the default style's accent bar, got %v", got)
+	}
+}
+
+// TestRenderCard_BoldTitle renders the title with two bold typefaces: the
+// minimal style draws nothing else in bold.
+func TestRenderCard_BoldTitle(t *testing.T) {
+	mono, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := defaultConfig()
+	cfg.Style = StyleMinimal
+	withBold := renderCard(cfg, ogWidth, ogHeight)
+	cfg.Fonts.Bold = mono
+	withMono := renderCard(cfg, ogWidth, ogHeight)
+	if bytes.Equal(withBold.Pix, withMono.Pix) {
+		t.Error("expected the title to be drawn with the bold typeface")
+	}
+}
+
+func TestImageConfig_Key(t *testing.T) {
+	dir := t.TempDir()
+	cover := writePNG(t, dir, "cover.png", 10, 10, color.RGBA{R: 255, A: 255})
+
+	cfg := defaultConfig()
+	cfg.CoverPath = cover
+	base, err := cfg.OGKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := cfg.OGKey(); again != base {
+		t.Error("expected the key to be stable")
+	}
+	if twitter, _ := cfg.TwitterKey(); twitter == base {
+		t.Error("expected the Twitter card to have its own key")
+	}
+
+	changed := cfg
+	changed.Tags = []string{"new"}
+	if key, _ := changed.OGKey(); key == base {
+		t.Error("expected the key to change with the tags")
+	}
+
+	changed = cfg
+	changed.Fonts.Key = "other font files"
+	if key, _ := changed.OGKey(); key == base {
+		t.Error("expected the key to change with the font files")
+	}
+
+	writePNG(t, dir, "cover.png", 10, 10, color.RGBA{B: 255, A: 255})
+	if key, _ := cfg.OGKey(); key == base {
+		t.Error("expected the key to change with the cover content")
+	}
+
+	cfg.CoverPath = filepath.Join(dir, "missing.png")
+	if _, err := cfg.OGKey(); err == nil {
+		t.Error("expected an error for a missing cover")
+	}
+}
+
+func TestOptions_StyleOrDefault(t *testing.T) {
+	tests := map[string]string{"": StyleDefault, "Split": StyleSplit, "cover": StyleCover, "fancy": StyleDefault}
+	for in, want := range tests {
+		if got := (Options{Style: in}).StyleOrDefault(); got != want {
+			t.Errorf("StyleOrDefault(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadImage_ICO(t *testing.T) {
+	dir := t.TempDir()
+	small, _ := os.ReadFile(writePNG(t, dir, "16.png", 16, 16, color.RGBA{R: 255, A: 255}))
+	large, _ := os.ReadFile(writePNG(t, dir, "32.png", 32, 32, color.RGBA{G: 255, A: 255}))
+
+	// ICONDIR header followed by two ICONDIRENTRY records
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint16{0, 1, 2})
+	offset := 6 + 2*16
+	for _, icon := range []struct {
+		size int
+		data []byte
+	}{{16, small}, {32, large}} {
+		buf.Write([]byte{byte(icon.size), byte(icon.size), 0, 0})
+		binary.Write(&buf, binary.LittleEndian, []uint16{1, 32})
+		binary.Write(&buf, binary.LittleEndian, []uint32{uint32(len(icon.data)), uint32(offset)})
+		offset += len(icon.data)
+	}
+	buf.Write(small)
+	buf.Write(large)
+
+	path := filepath.Join(dir, "favicon.ico")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img, err := LoadImage(path)
+	if err != nil {
+		t.Fatalf("LoadImage returned error: %v", err)
+	}
+	if img.Bounds().Dx() != 32 {
+		t.Errorf("expected the largest icon, got width %d", img.Bounds().Dx())
+	}
+
+	bmp := filepath.Join(dir, "old.ico")
+	os.WriteFile(bmp, []byte{0, 0, 1, 0, 0, 0}, 0o644)
+	if _, err := LoadImage(bmp); err == nil {
+		t.Error("expected an error for an icon without PNG images")
+	}
+}
+
+func TestGenerateSceneImage(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "scene.png")
+
+	scene := Scene{
+		Width:   400,
+		Height:  200,
+		BgColor: "#ffffff",
+		Rects: []SceneRect{
+			{Rect: image.Rect(10, 10, 110, 60), Fill: "#ff0000", Stroke: "#000000", StrokeWidth: 2},
+			{Rect: image.Rect(200, 10, 300, 60), Fill: "#00ff00", FillOpacity: 0.1},
+		},
+		Lines:  []SceneLine{{Points: []image.Point{{110, 35}, {200, 35}}, Color: "#0000ff", Width: 2}},
+		Labels: []SceneLabel{{X: 14, Y: 40, Text: "A very long label that overflows", Color: "#000000", MaxWidth: 90}},
+	}
+	if err := GenerateSceneImage(scene, out); err != nil {
+		t.Fatalf("GenerateSceneImage returned error: %v", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("failed to open output file: %v", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("failed to decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 400 || img.Bounds().Dy() != 200 {
+		t.Errorf("expected 400x200, got %dx%d", img.Bounds().Dx(), img.Bounds().Dy())
+	}
+	if r, g, b, _ := img.At(150, 35).RGBA(); r != 0 || g != 0 || b>>8 != 0xff {
+		t.Errorf("expected blue line pixel, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+	if r, _, _, _ := img.At(5, 5).RGBA(); r>>8 != 0xff {
+		t.Error("expected background to be white")
+	}
+}
@@ -0,0 +1,176 @@
+// @feature:ogimage Generic scene rasteriser for diagram-style images (rects, polylines, labels).
+package ogimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Scene describes a flat list of shapes to rasterise, in pixel coordinates.
+// Shapes are painted in order: rects, then lines, then labels.
+type Scene struct {
+	Width   int
+	Height  int
+	BgColor string
+	Rects   []SceneRect
+	Lines   []SceneLine
+	Labels  []SceneLabel
+	Face    font.Face
+}
+
+// SceneRect is a filled and/or stroked rectangle.
+type SceneRect struct {
+	Rect        image.Rectangle
+	Fill        string // Hex colour, empty for no fill
+	FillOpacity float64
+	Stroke      string // Hex colour, empty for no border
+	StrokeWidth int
+}
+
+// SceneLine is an open polyline.
+type SceneLine struct {
+	Points []image.Point
+	Color  string
+	Width  int
+}
+
+// SceneLabel is a single line of text drawn with its baseline at Y.
+// Text wider than MaxWidth (when set) is truncated with an ellipsis.
+type SceneLabel struct {
+	X, Y     int
+	Text     string
+	Color    string
+	MaxWidth int
+}
+
+// GenerateSceneImage rasterises the scene and writes it as a PNG to outPath.
+func GenerateSceneImage(scene Scene, outPath string) error {
+	img := image.NewRGBA(image.Rect(0, 0, scene.Width, scene.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{parseHex(scene.BgColor)}, image.Point{}, draw.Src)
+
+	face := scene.Face
+	if face == nil {
+		face = basicfont.Face7x13
+	}
+
+	for _, r := range scene.Rects {
+		if r.Fill != "" {
+			fill := parseHex(r.Fill)
+			alpha := r.FillOpacity
+			if alpha <= 0 || alpha > 1 {
+				alpha = 1
+			}
+			c := color.NRGBA{R: fill.R, G: fill.G, B: fill.B, A: uint8(alpha * 255)}
+			draw.Draw(img, r.Rect, &image.Uniform{c}, image.Point{}, draw.Over)
+		}
+		if r.Stroke != "" {
+			strokeRect(img, r.Rect, parseHex(r.Stroke), max(r.StrokeWidth, 1))
+		}
+	}
+
+	for _, l := range scene.Lines {
+		c := parseHex(l.Color)
+		for i := 1; i < len(l.Points); i++ {
+			drawSegment(img, l.Points[i-1], l.Points[i], c, max(l.Width, 1))
+		}
+	}
+
+	for _, l := range scene.Labels {
+		text := l.Text
+		if l.MaxWidth > 0 {
+			text = truncateToWidth(face, text, l.MaxWidth)
+		}
+		if text == "" {
+			continue
+		}
+		drawLabel(img, face, l.X, l.Y, text, parseHex(l.Color))
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("ogimage: create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("ogimage: encode png: %w", err)
+	}
+	return nil
+}
+
+// strokeRect draws the border of r with the given thickness, inside the rect.
+func strokeRect(img *image.RGBA, r image.Rectangle, c color.RGBA, width int) {
+	src := &image.Uniform{c}
+	draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width), src, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y), src, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y), src, image.Point{}, draw.Src)
+}
+
+// drawSegment plots a straight line between a and b using Bresenham's algorithm,
+// stamping a width x width square at every step.
+func drawSegment(img *image.RGBA, a, b image.Point, c color.RGBA, width int) {
+	dx := abs(b.X - a.X)
+	dy := -abs(b.Y - a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	err := dx + dy
+	x, y := a.X, a.Y
+	half := width / 2
+	for {
+		for ox := -half; ox < width-half; ox++ {
+			for oy := -half; oy < width-half; oy++ {
+				img.SetRGBA(x+ox, y+oy, c)
+			}
+		}
+		if x == b.X && y == b.Y {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// truncateToWidth shortens text until it fits maxWidth pixels, appending an ellipsis.
+func truncateToWidth(face font.Face, text string, maxWidth int) string {
+	limit := fixed.I(maxWidth)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if font.MeasureString(face, candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+// @feature:ogimage Font faces, bold weight and measured text wrapping for cards.
+package ogimage
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Fonts holds the typefaces of the cards.
+type Fonts struct {
+	Key     string         // Content hash of the font files, part of the cache keys
+	Regular *opentype.Font // Body text, nil for the Go font bundled with x/image
+	Bold    *opentype.Font // Titles, nil for the bold Go font bundled with x/image
+}
+
+var (
+	fallbackOnce sync.Once
+	fallbackFont *opentype.Font
+	fallbackBold *opentype.Font
+)
+
+// loadFallbacks parses the Go fonts bundled with x/image.
+func loadFallbacks() {
+	fallbackOnce.Do(func() {
+		fallbackFont, _ = opentype.Parse(goregular.TTF)
+		fallbackBold, _ = opentype.Parse(gobold.TTF)
+	})
+}
+
+// regular returns the regular typeface, or the bundled fallback.
+func (f Fonts) regular() *opentype.Font {
+	if f.Regular != nil {
+		return f.Regular
+	}
+	loadFallbacks()
+	return fallbackFont
+}
+
+// bold returns the bold typeface, or the bundled bold fallback.
+func (f Fonts) bold() *opentype.Font {
+	if f.Bold != nil {
+		return f.Bold
+	}
+	loadFallbacks()
+	return fallbackBold
+}
+
+// face returns a face of the given pixel size.
+func (f Fonts) face(size float64, bold bool) font.Face {
+	typeface := f.regular()
+	if bold {
+		typeface = f.bold()
+	}
+	face, err := opentype.NewFace(typeface, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil
+	}
+	return face
+}
+
+// wrapText breaks text into lines no wider than maxWidth pixels. Words
+// longer than a line are split. When the text needs more than maxLines
+// lines, the last one ends with an ellipsis.
+func wrapText(face font.Face, text string, maxWidth, maxLines int) []string {
+	limit := fixed.I(maxWidth)
+	fits := func(s string) bool { return font.MeasureString(face, s) <= limit }
+
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if fits(candidate) {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		// Split words that don't fit on a line of their own
+		for !fits(word) {
+			runes := []rune(word)
+			n := len(runes) - 1
+			for n > 1 && !fits(string(runes[:n])) {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			word = string(runes[n:])
+		}
+		current = word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		last := strings.Join(lines[maxLines-1:], " ")
+		lines = append(lines[:maxLines-1], ellipsize(face, last, maxWidth))
+	}
+	return lines
+}
+
+// ellipsize shortens text to fit maxWidth pixels, cutting at a word
+// boundary when possible and appending an ellipsis.
+func ellipsize(face font.Face, text string, maxWidth int) string {
+	limit := fixed.I(maxWidth)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		cut := strings.TrimRight(string(runes[:n]), " ,.;:-")
+		if font.MeasureString(face, cut+"…") > limit {
+			continue
+		}
+		// Prefer the previous word boundary when it is close enough
+		if i := strings.LastIndex(cut, " "); i > 0 && len(cut)-i < 16 {
+			cut = strings.TrimRight(cut[:i], " ,.;:-")
+		}
+		return cut + "…"
+	}
+	return ""
+}
+
+// lineHeight returns the distance between two baselines of face.
+func lineHeight(face font.Face) int {
+	m := face.Metrics()
+	return (m.Ascent + m.Descent).Ceil()
+}
@@ -0,0 +1,84 @@
+// @feature:ogimage Tests for text measuring and bold faces.
+package ogimage
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestWrapText_FitsWidth(t *testing.T) {
+	face := Fonts{}.face(40, false)
+	text := "The quick brown fox jumps over the lazy dog and keeps running far away"
+
+	lines := wrapText(face, text, 400, 0)
+	if len(lines) < 2 {
+		t.Fatalf("expected several lines, got %q", lines)
+	}
+	for _, line := range lines {
+		if w := font.MeasureString(face, line); w > fixed.I(400) {
+			t.Errorf("line %q is %d pixels wide, want <= 400", line, w.Ceil())
+		}
+	}
+	if got := strings.Join(lines, " "); got != text {
+		t.Errorf("wrapping changed the text: %q", got)
+	}
+}
+
+func TestWrapText_SplitsLongWords(t *testing.T) {
+	face := Fonts{}.face(40, false)
+	lines := wrapText(face, strings.Repeat("x", 80), 300, 0)
+	if len(lines) < 2 {
+		t.Fatalf("expected the word to be split, got %q", lines)
+	}
+	for _, line := range lines {
+		if font.MeasureString(face, line) > fixed.I(300) {
+			t.Errorf("line %q overflows", line)
+		}
+	}
+}
+
+func TestWrapText_Ellipsizes(t *testing.T) {
+	face := Fonts{}.face(40, false)
+	lines := wrapText(face, strings.Repeat("lorem ipsum dolor ", 20), 400, 2)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if !strings.HasSuffix(lines[1], "…") {
+		t.Errorf("expected the last line to end with an ellipsis, got %q", lines[1])
+	}
+	if font.MeasureString(face, lines[1]) > fixed.I(400) {
+		t.Errorf("ellipsized line %q overflows", lines[1])
+	}
+}
+
+func TestEllipsize_ShortText(t *testing.T) {
+	face := Fonts{}.face(40, false)
+	if got := ellipsize(face, "Short", 400); got != "Short" {
+		t.Errorf("expected text to be kept, got %q", got)
+	}
+}
+
+func TestFace_BoldTypeface(t *testing.T) {
+	regular := Fonts{}.face(48, false)
+	bold := Fonts{}.face(48, true)
+
+	text := "Kiln"
+	if font.MeasureString(bold, text) <= font.MeasureString(regular, text) {
+		t.Error("expected bold text to be wider than regular text")
+	}
+
+	mono, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := Fonts{Bold: mono}.face(48, true)
+	// Every glyph of a monospace font has the same advance
+	if font.MeasureString(custom, "iiii") != font.MeasureString(custom, "MMMM") {
+		t.Error("expected the bold face to use the given typeface")
+	}
+}
@@ -11,6 +11,7 @@ type FeedParams struct {
 	Title       string // Site name (from config)
 	Link        string // Base URL of the site
 	Description string // Site description (use SiteName + " RSS Feed" as fallback)
+	Language    string // Language code of the items, optional
 }
 
 // ItemParams holds the data for a single RSS item.
@@ -33,6 +34,7 @@ type rssChannel struct {
 	Title         string    `xml:"title"`
 	Link          string    `xml:"link"`
 	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
 	LastBuildDate string    `xml:"lastBuildDate"`
 	Items         []rssItem `xml:"item"`
 }
@@ -75,6 +77,7 @@ func BuildFeedXML(params FeedParams, items []ItemParams) (string, error) {
 			Title:         params.Title,
 			Link:          params.Link,
 			Description:   params.Description,
+			Language:      params.Language,
 			LastBuildDate: time.Now().Format(time.RFC1123Z),
 			Items:         rssItems,
 		},
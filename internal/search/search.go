@@ -78,9 +78,15 @@ func BuildIndex(files []*obsidian.File) []SearchEntry {
 }
 
 func WriteIndex(entries []SearchEntry, outputDir string) error {
+	return WriteIndexFile(entries, filepath.Join(outputDir, "search-index.json"))
+}
+
+// WriteIndexFile writes the entries to the given path, used for the
+// per-language indexes of multilingual sites.
+func WriteIndexFile(entries []SearchEntry, path string) error {
 	data, err := json.Marshal(entries)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(outputDir, "search-index.json"), data, 0644)
+	return os.WriteFile(path, data, 0644)
 }
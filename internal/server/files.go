@@ -0,0 +1,218 @@
+// @feature:dev-server Serving of the files of the site with ETags, Cache-Control and brotli or gzip compression.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// minCompressSize is the size under which files aren't compressed on the
+// fly, as the savings don't pay for the encoding.
+const minCompressSize = 1024
+
+// encodings are the supported content encodings, preferred first, with the
+// extension of their precompressed files.
+var encodings = []struct{ name, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// fingerprinted matches the names of assets with a content hash, e.g.
+// "app.3f2a1c.js", which never change and are cached for a year.
+var fingerprinted = regexp.MustCompile(`\.[0-9a-f]{6,}\.[a-z0-9]+$`)
+
+// Cache-Control values by asset type.
+const (
+	cacheImmutable = "public, max-age=31536000, immutable"
+	cacheMedia     = "public, max-age=86400"
+	cacheAsset     = "public, max-age=3600, must-revalidate"
+	cachePage      = "public, max-age=0, must-revalidate"
+	cacheNone      = "no-cache"
+)
+
+// serveFile writes the file with its ETag and Cache-Control, compressed
+// with the best encoding the client accepts. http.ServeContent answers the
+// conditional and range requests.
+func (s *site) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := os.Open(name)
+	if err != nil {
+		serve404(w, s.notFoundPage)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		serve404(w, s.notFoundPage)
+		return
+	}
+
+	h := w.Header()
+	ctype := contentType(name)
+	h.Set("Content-Type", ctype)
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", s.cacheControl(name))
+	}
+	etag := fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+
+	var content io.ReadSeeker = f
+	if s.opts.Compress {
+		h.Add("Vary", "Accept-Encoding")
+		accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+		for _, enc := range encodings {
+			if !accepted[enc.name] {
+				continue
+			}
+			if pre, err := os.Open(name + enc.ext); err == nil {
+				defer pre.Close()
+				content = pre
+			} else if compressible(ctype) && info.Size() >= minCompressSize {
+				data, err := s.compress(f, name, enc.name, etag)
+				if err != nil {
+					s.log.Warn("Couldn't compress file", "file", name, "encoding", enc.name, "error", err)
+					break
+				}
+				content = bytes.NewReader(data)
+			} else {
+				continue
+			}
+			h.Set("Content-Encoding", enc.name)
+			etag += "-" + enc.name
+			break
+		}
+	}
+	h.Set("ETag", strconv.Quote(etag))
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// compressedFile is a file compressed on the fly, with the version of the
+// file it was compressed from.
+type compressedFile struct {
+	version string
+	data    []byte
+}
+
+// compress returns the file compressed with the encoding, reusing the
+// result while the file doesn't change. Only the latest version of each
+// file is kept, so rebuilds don't grow the cache.
+func (s *site) compress(f io.ReadSeeker, name, encoding, version string) ([]byte, error) {
+	key := name + "\x00" + encoding
+	s.compressedMu.Lock()
+	cached, ok := s.compressed[key]
+	s.compressedMu.Unlock()
+	if ok && cached.version == version {
+		return cached.data, nil
+	}
+
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch encoding {
+	case "br":
+		zw = brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+	default:
+		zw = gzip.NewWriter(&buf)
+	}
+	if _, err := io.Copy(zw, f); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	s.compressedMu.Lock()
+	s.compressed[key] = compressedFile{version: version, data: buf.Bytes()}
+	s.compressedMu.Unlock()
+	return buf.Bytes(), nil
+}
+
+// cacheControl returns the Cache-Control header of a file: fingerprinted
+// assets are immutable, media are cached for a day, stylesheets and scripts
+// for an hour, and pages are revalidated on every request.
+func (s *site) cacheControl(name string) string {
+	if !s.opts.Cache {
+		return cacheNone
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case ext != ".html" && fingerprinted.MatchString(strings.ToLower(filepath.Base(name))):
+		return cacheImmutable
+	case ext == ".css" || ext == ".js" || ext == ".mjs":
+		return cacheAsset
+	}
+	ctype := contentType(name)
+	for _, media := range []string{"image/", "font/", "audio/", "video/"} {
+		if strings.HasPrefix(ctype, media) {
+			return cacheMedia
+		}
+	}
+	if ext == ".woff" || ext == ".woff2" || ext == ".ttf" || ext == ".otf" {
+		return cacheMedia
+	}
+	return cachePage
+}
+
+// contentType returns the media type of a file from its extension.
+func contentType(name string) string {
+	if ctype := mime.TypeByExtension(filepath.Ext(name)); ctype != "" {
+		return ctype
+	}
+	return "application/octet-stream"
+}
+
+// compressible reports whether the media type is text, which compresses
+// well, unlike images, fonts and archives that are compressed already.
+func compressible(ctype string) bool {
+	ctype, _, _ = strings.Cut(ctype, ";")
+	if strings.HasPrefix(ctype, "text/") {
+		return true
+	}
+	for _, kind := range []string{"javascript", "json", "xml", "svg", "wasm"} {
+		if strings.Contains(ctype, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedEncodings parses an Accept-Encoding header into the encodings
+// the client accepts. A zero quality refuses an encoding, even with "*".
+func acceptedEncodings(header string) map[string]bool {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				accepted[name] = false
+				continue
+			}
+		}
+		if name == "*" {
+			for _, enc := range encodings {
+				if _, set := accepted[enc.name]; !set {
+					accepted[enc.name] = true
+				}
+			}
+			continue
+		}
+		accepted[name] = true
+	}
+	return accepted
+}
@@ -0,0 +1,256 @@
+// @feature:dev-server Netlify and Cloudflare Pages style _redirects and _headers rules.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the rules files in the output directory.
+const (
+	redirectsFile = "_redirects"
+	headersFile   = "_headers"
+)
+
+// rules are the parsed _redirects and _headers files.
+type rules struct {
+	redirects []redirectRule
+	headers   []headerRule
+}
+
+// redirectRule redirects or rewrites the paths matching a pattern, e.g.
+// "/blog/:year/* /news/:splat 301". Without force, the rule only applies to
+// paths without a file, as on Netlify.
+type redirectRule struct {
+	from   []string
+	to     string
+	status int
+	force  bool
+}
+
+// headerRule sets or removes, with "! Name", headers on the paths matching
+// a pattern.
+type headerRule struct {
+	pattern []string
+	set     [][2]string
+	unset   []string
+}
+
+// redirect returns the first redirect rule matching the path with its
+// expanded target. exists tells if a file serves the path.
+func (rs *rules) redirect(p string, exists bool) (*redirectRule, string) {
+	if rs == nil {
+		return nil, ""
+	}
+	for i, rule := range rs.redirects {
+		if exists && !rule.force {
+			continue
+		}
+		params, ok := matchPattern(rule.from, p)
+		if !ok {
+			continue
+		}
+		return &rs.redirects[i], expandTarget(rule.to, params)
+	}
+	return nil, ""
+}
+
+// applyHeaders applies the header rules matching the path. The values of a
+// header set by several rules are joined with commas.
+func (rs *rules) applyHeaders(h http.Header, p string) {
+	if rs == nil {
+		return
+	}
+	values := map[string][]string{}
+	names := []string{}
+	for _, rule := range rs.headers {
+		if _, ok := matchPattern(rule.pattern, p); !ok {
+			continue
+		}
+		for _, kv := range rule.set {
+			name := http.CanonicalHeaderKey(kv[0])
+			if _, seen := values[name]; !seen {
+				names = append(names, name)
+			}
+			values[name] = append(values[name], kv[1])
+		}
+		for _, name := range rule.unset {
+			name = http.CanonicalHeaderKey(name)
+			delete(values, name)
+			names = slices.DeleteFunc(names, func(n string) bool { return n == name })
+			h.Del(name)
+		}
+	}
+	for _, name := range names {
+		h.Set(name, strings.Join(values[name], ", "))
+	}
+}
+
+// matchPattern matches a path against the segments of a pattern, where
+// ":name" matches a segment and a final "*" matches the rest of the path,
+// named "splat".
+func matchPattern(pattern []string, p string) (map[string]string, bool) {
+	segments := splitPath(p)
+	params := map[string]string{}
+	for i, seg := range pattern {
+		if seg == "*" && i == len(pattern)-1 {
+			params["splat"] = strings.Join(segments[min(i, len(segments)):], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			params[seg[1:]] = segments[i]
+		case seg != segments[i]:
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segments)
+}
+
+// expandTarget replaces the placeholders of a redirect target, the longest
+// names first so that ":id" doesn't replace the start of ":identifier".
+func expandTarget(to string, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int { return len(b) - len(a) })
+	for _, name := range names {
+		to = strings.ReplaceAll(to, ":"+name, params[name])
+	}
+	return to
+}
+
+// splitPath splits a path into its segments, ignoring the slashes around it.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// parseRedirects parses a _redirects file. Rules with query or country
+// conditions and proxies to other sites aren't supported and are skipped.
+func parseRedirects(data []byte, log *slog.Logger) []redirectRule {
+	redirects := []redirectRule{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			log.Warn("Skipping redirect without a target", "file", redirectsFile, "line", n)
+			continue
+		}
+		rule := redirectRule{from: splitPath(fields[0]), to: fields[1], status: http.StatusMovedPermanently}
+		if len(fields) > 2 {
+			code, force := strings.CutSuffix(fields[2], "!")
+			status, err := strconv.Atoi(code)
+			if err != nil || len(fields) > 3 {
+				log.Warn("Skipping redirect with unsupported conditions", "file", redirectsFile, "line", n)
+				continue
+			}
+			rule.status, rule.force = status, force
+		}
+		if rule.status < 300 && strings.Contains(rule.to, "://") {
+			log.Warn("Skipping proxy to another site", "file", redirectsFile, "line", n, "to", rule.to)
+			continue
+		}
+		redirects = append(redirects, rule)
+	}
+	return redirects
+}
+
+// parseHeaders parses a _headers file: unindented lines are path patterns,
+// followed by indented "Name: value" lines, or "! Name" to remove a header.
+func parseHeaders(data []byte, log *slog.Logger) []headerRule {
+	headers := []headerRule{}
+	var current *headerRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if raw[0] != ' ' && raw[0] != '\t' {
+			if strings.Contains(line, "://") {
+				log.Warn("Skipping headers for another host", "file", headersFile, "line", n, "pattern", line)
+				current = nil
+				continue
+			}
+			headers = append(headers, headerRule{pattern: splitPath(line)})
+			current = &headers[len(headers)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "!"); ok {
+			current.unset = append(current.unset, strings.TrimSpace(name))
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			log.Warn("Skipping malformed header", "file", headersFile, "line", n)
+			continue
+		}
+		current.set = append(current.set, [2]string{strings.TrimSpace(name), strings.TrimSpace(value)})
+	}
+	return headers
+}
+
+// ruleFiles loads the rules files of the output directory, parsing them
+// again when they change, e.g. on the rebuilds of "kiln dev".
+type ruleFiles struct {
+	dir   string
+	log   *slog.Logger
+	mu    sync.Mutex
+	stamp string
+	rules *rules
+}
+
+// load returns the current rules, or nil without rules files.
+func (c *ruleFiles) load() *rules {
+	stamp := ""
+	for _, name := range []string{redirectsFile, headersFile} {
+		if info, err := os.Stat(filepath.Join(c.dir, name)); err == nil {
+			stamp += info.ModTime().Format(time.RFC3339Nano) + strconv.FormatInt(info.Size(), 10)
+		}
+		stamp += "|"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stamp == c.stamp {
+		return c.rules
+	}
+	c.stamp = stamp
+	c.rules = nil
+	redirects, errR := os.ReadFile(filepath.Join(c.dir, redirectsFile))
+	headers, errH := os.ReadFile(filepath.Join(c.dir, headersFile))
+	if errR != nil && errH != nil {
+		return nil
+	}
+	c.rules = &rules{
+		redirects: parseRedirects(redirects, c.log),
+		headers:   parseHeaders(headers, c.log),
+	}
+	c.log.Debug("Loaded the rules files", "redirects", len(c.rules.redirects), "headers", len(c.rules.headers))
+	return c.rules
+}
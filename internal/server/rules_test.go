@@ -0,0 +1,144 @@
+// @feature:dev-server Tests for the _redirects and _headers rules.
+package server
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Redirects(t *testing.T) {
+	h := newTestHandler(t, map[string]string{
+		"index.html":     "home",
+		"news/post.html": "post",
+		"kept.html":      "kept",
+		"forced.html":    "forced",
+		"app/index.html": "app shell",
+		"gone.html":      "gone page",
+		"404.html":       "custom 404",
+		"_redirects": `# Moved pages
+/blog/:year/*  /news/:splat  301
+/old           /new?from=old 302
+/kept          /elsewhere
+/forced        /elsewhere    302!
+/app/*         /app          200
+/removed       /gone         410
+/tag/:name/:id /tags/:id-:name
+/search q=:q   /find         301
+/proxy         https://example.com/api 200
+`,
+	}, Options{Rules: true, BaseURL: "http://localhost:8080/site"})
+
+	tests := []struct {
+		path     string
+		status   int
+		location string
+		body     string
+	}{
+		{"/site/blog/2024/post?page=2", http.StatusMovedPermanently, "/site/news/post?page=2", ""},
+		{"/site/old?x=1", http.StatusFound, "/site/new?from=old", ""},
+		{"/site/kept", http.StatusOK, "", "kept"},
+		{"/site/forced", http.StatusFound, "/site/elsewhere", ""},
+		{"/site/app/settings/profile", http.StatusOK, "", "app shell"},
+		{"/site/removed", http.StatusGone, "", "gone page"},
+		{"/site/tag/go/7", http.StatusMovedPermanently, "/site/tags/7-go", ""},
+		{"/site/search", http.StatusNotFound, "", "custom 404"},
+		{"/site/proxy", http.StatusNotFound, "", "custom 404"},
+	}
+	for _, tt := range tests {
+		w := get(h, tt.path)
+		if w.Code != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.status)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("GET %s: Location = %q, want %q", tt.path, got, tt.location)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("GET %s: body = %q, want %q", tt.path, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestHandler_Headers(t *testing.T) {
+	h := newTestHandler(t, map[string]string{
+		"index.html":      "home",
+		"docs/intro.html": "intro",
+		"docs/api.html":   "api",
+		"_headers": `/*
+  X-Frame-Options: DENY
+  Link: </style.css>; rel=preload
+/docs/*
+  Link: </docs.css>; rel=preload
+  Cache-Control: public, max-age=60
+/docs/api
+  ! X-Frame-Options
+https://example.com/*
+  X-Robots-Tag: noindex
+`,
+	}, Options{Rules: true, Cache: true})
+
+	w := get(h, "/docs/intro")
+	if got := w.Header().Get("Link"); got != "</style.css>; rel=preload, </docs.css>; rel=preload" {
+		t.Errorf("Link = %q, want both values joined", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q, want the one of the rule", got)
+	}
+	if got := w.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q", got)
+	}
+	if got := get(h, "/docs/api").Header().Get("X-Frame-Options"); got != "" {
+		t.Errorf("X-Frame-Options = %q, want it removed", got)
+	}
+	if w := get(h, "/"); w.Header().Get("X-Robots-Tag") != "" || w.Header().Get("Cache-Control") != cachePage {
+		t.Errorf("headers of / = %v", w.Header())
+	}
+}
+
+func TestParseRedirects_Warnings(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+	rules := parseRedirects([]byte("/a\n/b /c 301 Country=us\n/d /e 302!\n"), log)
+	if len(rules) != 1 || rules[0].status != http.StatusFound || !rules[0].force {
+		t.Errorf("rules = %+v, want only the forced /d", rules)
+	}
+	if strings.Count(logs.String(), "level=WARN") != 2 {
+		t.Errorf("expected two warnings, got:\n%s", logs.String())
+	}
+	if parseHeaders(nil, slog.New(slog.NewTextHandler(io.Discard, nil))) == nil {
+		t.Error("parsing an empty _headers file returns nil")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		ok      bool
+		params  map[string]string
+	}{
+		{"/", "/", true, map[string]string{}},
+		{"/a/b", "/a/b/", true, map[string]string{}},
+		{"/a/b", "/a", false, nil},
+		{"/a/:id", "/a/7", true, map[string]string{"id": "7"}},
+		{"/a/:id", "/a/7/8", false, nil},
+		{"/a/*", "/a", true, map[string]string{"splat": ""}},
+		{"/a/*", "/a/b/c", true, map[string]string{"splat": "b/c"}},
+		{"/*", "/anything", true, map[string]string{"splat": "anything"}},
+	}
+	for _, tt := range tests {
+		params, ok := matchPattern(splitPath(tt.pattern), tt.path)
+		if ok != tt.ok {
+			t.Errorf("%s ~ %s = %v, want %v", tt.pattern, tt.path, ok, tt.ok)
+			continue
+		}
+		for k, v := range tt.params {
+			if params[k] != v {
+				t.Errorf("%s ~ %s: %s = %q, want %q", tt.pattern, tt.path, k, params[k], v)
+			}
+		}
+	}
+}
@@ -1,158 +1,255 @@
-// Local development server with clean URL support and base path handling. @feature:dev-server
+// Local server with clean URL support, base path handling, compression, caching headers and _redirects/_headers rules. @feature:dev-server
 package server
 
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-// Serve starts a simple static file server on the specified port.
+// Options configures the server and its middleware.
+type Options struct {
+	Host      string // Interface to listen on, every interface when empty
+	Port      string
+	OutputDir string
+	BaseURL   string // Its path is the prefix the site is served under
+
+	Compress  bool // Serves brotli and gzip, using the precompressed .br and .gz files when present
+	Cache     bool // Sets Cache-Control by asset type, "no-cache" otherwise
+	Rules     bool // Applies the _redirects and _headers files of the output directory
+	AccessLog bool // Logs every request
+
+	// Middleware wraps the handler of the site, the first one outermost.
+	Middleware []func(http.Handler) http.Handler
+}
+
+// Serve starts a static file server for the site in the output directory.
 // It includes logic to handle "Clean URLs" (extensionless linking) and directory indices,
 // mimicking the behavior of production static hosting providers.
-func Serve(ctx context.Context, port, outputDir, baseURL string, log *slog.Logger) {
-	// Determine path prefix
-	// If the user's BaseURL includes a path (e.g., "https://example.com/docs"),
-	// we need to serve the site under that prefix ("/docs") locally to match production.
-	u, err := url.Parse(baseURL)
+func Serve(ctx context.Context, opts Options, log *slog.Logger) {
+	handler, err := NewHandler(opts, log)
 	if err != nil {
-		log.Error("Couldn't parse baseURL", "error", err)
+		log.Error("Couldn't create the server", "error", err)
 		os.Exit(1)
 	}
-	pathPrefix := u.Path
 
-	// Normalize prefix: ensure it starts with "/" and doesn't end with one.
-	if pathPrefix != "" && !strings.HasPrefix(pathPrefix, "/") {
-		pathPrefix = "/" + pathPrefix
+	addr := net.JoinHostPort(opts.Host, opts.Port)
+	host := opts.Host
+	if host == "" {
+		host = "localhost"
 	}
-	pathPrefix = strings.TrimSuffix(pathPrefix, "/")
-
-	// Setup the standard file server
-	fileServer := http.FileServer(http.Dir(outputDir))
-
-	notFoundPage := filepath.Join(outputDir, "404.html")
-
-	// Create custom request handler
-	// It handles clean URLs, trailing slashes, and fallback lookups
-	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// r.URL.Path here is relative to the outputDir (prefix already stripped by http.StripPrefix)
-		path := r.URL.Path
+	prefix, _ := pathPrefix(opts.BaseURL)
+	log.Info("Serving...", "url", "http://"+net.JoinHostPort(host, opts.Port)+prefix+"/")
+	log.Info("Press Ctrl+C to stop")
 
-		// Trailing slash canonicalization
-		// Example: /about/ -> /about
-		if path != "/" && strings.HasSuffix(path, "/") {
-			newPath := strings.TrimSuffix(path, "/")
-			// Reconstruct full path for the browser redirect (must include the prefix if one exists)
-			fullRedirectPath := pathPrefix + newPath
-			http.Redirect(w, r, fullRedirectPath, http.StatusMovedPermanently)
-			return
-		}
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
 
-		// Pretty URL support
-		// If the request has no extension (e.g. "/my-note"), we try to find the actual file on disk.
-		if filepath.Ext(path) == "" {
-			// Check for an HTML file with the same name
-			// Request: /my-note -> serves: /my-note.html
-			htmlPath := filepath.Join(outputDir, path+".html")
-			if _, err := os.Stat(htmlPath); err == nil {
-				http.ServeFile(w, r, htmlPath)
-				return
-			}
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Error("Server failed", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Server stopped")
+}
 
-			// Check for a directory with an index.html (flat-urls)
-			// Request: /folder -> serves: /folder/index.html
-			localPath := filepath.Join(outputDir, path)
-			if info, err := os.Stat(localPath); err == nil && info.IsDir() {
-				indexPath := filepath.Join(localPath, "index.html")
-				if _, err := os.Stat(indexPath); err == nil {
-					http.ServeFile(w, r, indexPath)
-					return
-				}
-			}
-		}
+// NewHandler returns the handler serving the site in the output directory
+// under the path of the base URL, wrapped in the middleware of the options.
+func NewHandler(opts Options, log *slog.Logger) (http.Handler, error) {
+	prefix, err := pathPrefix(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
 
-		// Use the standard file server, but intercept 404 responses to serve
-		// the custom 404.html page instead of Go's default plain text 404.
-		recorder := &notFoundRecorder{ResponseWriter: w}
-		fileServer.ServeHTTP(recorder, r)
-		if recorder.status == http.StatusNotFound {
-			serve404(w, notFoundPage)
-		}
-	})
+	s := &site{
+		dir:          opts.OutputDir,
+		prefix:       prefix,
+		opts:         opts,
+		notFoundPage: filepath.Join(opts.OutputDir, "404.html"),
+		rules:        &ruleFiles{dir: opts.OutputDir, log: log},
+		compressed:   map[string]compressedFile{},
+		log:          log,
+	}
 
 	// Mount the handler
 	// If a path prefix is configured, we must strip it from the request URL
-	// so that the file server sees the path relative to 'outputDir'
-	if pathPrefix != "" {
+	// so that the site sees the path relative to the output directory
+	mux := http.NewServeMux()
+	if prefix != "" {
 		// Handle the subpath (e.g. requests to /kiln/...)
-		http.Handle(pathPrefix+"/", http.StripPrefix(pathPrefix, baseHandler))
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, s))
 
 		// Redirect root "/" to the subpath "/kiln/"
-		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == "/" {
-				http.Redirect(w, r, pathPrefix+"/", http.StatusFound)
+				http.Redirect(w, r, prefix+"/", http.StatusFound)
 			} else {
-				serve404(w, notFoundPage)
+				serve404(w, s.notFoundPage)
 			}
 		})
-		log.Info("Serving...", "port", port, "path", pathPrefix)
 	} else {
 		// Standard root serving (no prefix)
-		http.Handle("/", baseHandler)
-		log.Info("Serving...", "port", port)
+		mux.Handle("/", s)
 	}
 
-	log.Info("Press Ctrl+C to stop")
-
-	srv := &http.Server{Addr: ":" + port}
-	go func() {
-		<-ctx.Done()
-		srv.Shutdown(context.Background())
-	}()
-
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Error("Server failed", "error", err)
-		os.Exit(1)
+	var handler http.Handler = mux
+	if opts.AccessLog {
+		handler = accessLog(handler, log)
 	}
-	log.Info("Server stopped")
+	for i := len(opts.Middleware) - 1; i >= 0; i-- {
+		handler = opts.Middleware[i](handler)
+	}
+	return handler, nil
 }
 
-// serve404 writes the custom 404.html page if it exists, otherwise falls back
-// to the standard Go 404 response.
-func serve404(w http.ResponseWriter, notFoundPage string) {
-	content, err := os.ReadFile(notFoundPage)
+// pathPrefix returns the path of the base URL the site is served under.
+// If the user's BaseURL includes a path (e.g., "https://example.com/docs"),
+// we need to serve the site under that prefix ("/docs") locally to match production.
+func pathPrefix(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		http.Error(w, "404 page not found", http.StatusNotFound)
-		return
+		return "", err
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusNotFound)
-	w.Write(content)
+	prefix := u.Path
+
+	// Normalize prefix: ensure it starts with "/" and doesn't end with one.
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return strings.TrimSuffix(prefix, "/"), nil
 }
 
-// notFoundRecorder wraps an http.ResponseWriter to intercept 404 responses from
-// the standard file server. When a 404 is detected, it suppresses the default
-// response body so the caller can serve a custom 404 page instead.
-type notFoundRecorder struct {
-	http.ResponseWriter
-	status int
+// site serves the files of the output directory.
+type site struct {
+	dir          string
+	prefix       string
+	opts         Options
+	notFoundPage string
+	rules        *ruleFiles
+	compressed   map[string]compressedFile // Files compressed on the fly, by path and encoding
+	compressedMu sync.Mutex
+	log          *slog.Logger
 }
 
-func (r *notFoundRecorder) WriteHeader(code int) {
-	r.status = code
-	if code == http.StatusNotFound {
+// ServeHTTP handles clean URLs, trailing slashes, the _redirects and
+// _headers rules and the 404 page.
+func (s *site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// r.URL.Path here is relative to the output directory (prefix already stripped by http.StripPrefix)
+	p := r.URL.Path
+
+	// Trailing slash canonicalization
+	// Example: /about/ -> /about
+	if p != "/" && strings.HasSuffix(p, "/") {
+		target := s.prefix + strings.TrimSuffix(p, "/")
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	var rules *rules
+	if s.opts.Rules {
+		rules = s.rules.load()
+	}
+
+	file, status := s.resolve(p), http.StatusOK
+	if redirect, target := rules.redirect(p, file != ""); redirect != nil {
+		switch {
+		case redirect.status >= 300 && redirect.status < 400:
+			if strings.HasPrefix(target, "/") {
+				target = s.prefix + target
+			}
+			if r.URL.RawQuery != "" && !strings.Contains(target, "?") {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, redirect.status)
+			return
+		default:
+			// Rewrites serve the target with the status of the rule
+			targetPath, _, _ := strings.Cut(target, "?")
+			file, status = s.resolve(targetPath), redirect.status
+		}
+	}
+
+	rules.applyHeaders(w.Header(), p)
+
+	if file == "" {
+		serve404(w, s.notFoundPage)
 		return
 	}
-	r.ResponseWriter.WriteHeader(code)
+	if status != http.StatusOK {
+		servePage(w, file, status)
+		return
+	}
+	s.serveFile(w, r, file)
 }
 
-func (r *notFoundRecorder) Write(b []byte) (int, error) {
-	if r.status == http.StatusNotFound {
-		return len(b), nil
+// resolve returns the file serving the path, or an empty string.
+func (s *site) resolve(p string) string {
+	name := filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+p)))
+
+	// Pretty URL support
+	// If the request has no extension (e.g. "/my-note"), we try to find the actual file on disk.
+	if filepath.Ext(name) == "" {
+		// Check for an HTML file with the same name
+		// Request: /my-note -> serves: /my-note.html
+		if isFile(name + ".html") {
+			return name + ".html"
+		}
+		// Check for a directory with an index.html (flat-urls)
+		// Request: /folder -> serves: /folder/index.html
+		if index := filepath.Join(name, "index.html"); isFile(index) {
+			return index
+		}
+	}
+
+	// The rules files configure the server, they aren't part of the site
+	if base := filepath.Base(name); base == redirectsFile || base == headersFile {
+		return ""
 	}
-	return r.ResponseWriter.Write(b)
+	if isFile(name) {
+		return name
+	}
+	return ""
+}
+
+// isFile reports whether name is a regular file.
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// serve404 writes the custom 404.html page if it exists, otherwise falls back
+// to the standard Go 404 response.
+func serve404(w http.ResponseWriter, notFoundPage string) {
+	servePage(w, notFoundPage, http.StatusNotFound)
+}
+
+// servePage writes the page with the given status, falling back to the
+// standard Go response when the page doesn't exist.
+func servePage(w http.ResponseWriter, page string, status int) {
+	content, err := os.ReadFile(page)
+	if err != nil {
+		if status == http.StatusNotFound {
+			http.Error(w, "404 page not found", status)
+		} else {
+			http.Error(w, strconv.Itoa(status)+" "+strings.ToLower(http.StatusText(status)), status)
+		}
+		return
+	}
+	w.Header().Del("ETag")
+	w.Header().Set("Content-Type", contentType(page))
+	w.WriteHeader(status)
+	w.Write(content)
 }
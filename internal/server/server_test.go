@@ -1,12 +1,19 @@
-// @feature:dev-server Tests for custom 404 page serving and notFoundRecorder.
+// @feature:dev-server Tests for clean URLs, the base path, compression, caching headers and the 404 page.
 package server
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/andybalholm/brotli"
 )
 
 func TestServe404_CustomPage(t *testing.T) {
@@ -43,38 +50,186 @@ func TestServe404_MissingPage(t *testing.T) {
 	}
 }
 
-func TestNotFoundRecorder_Suppresses404(t *testing.T) {
+// newTestHandler writes the files to an output directory and returns the
+// handler serving it.
+func newTestHandler(t *testing.T, files map[string]string, opts Options) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts.OutputDir = dir
+	h, err := NewHandler(opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// get requests the path from the handler.
+func get(h http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
 	w := httptest.NewRecorder()
-	rec := &notFoundRecorder{ResponseWriter: w}
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler_CleanURLs(t *testing.T) {
+	h := newTestHandler(t, map[string]string{
+		"index.html":        "home",
+		"note.html":         "note",
+		"folder/index.html": "folder",
+		"style.css":         "body {}",
+		"404.html":          "custom 404",
+		"_redirects":        "/a /b",
+	}, Options{BaseURL: "http://localhost:8080/docs"})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/docs/", http.StatusOK, "home"},
+		{"/docs/note", http.StatusOK, "note"},
+		{"/docs/folder", http.StatusOK, "folder"},
+		{"/docs/style.css", http.StatusOK, "body {}"},
+		{"/docs/missing", http.StatusNotFound, "custom 404"},
+		{"/docs/_redirects", http.StatusNotFound, "custom 404"},
+		{"/elsewhere", http.StatusNotFound, "custom 404"},
+	}
+	for _, tt := range tests {
+		w := get(h, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+
+	if w := get(h, "/docs/note/?x=1"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/docs/note?x=1" {
+		t.Errorf("trailing slash = %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := get(h, "/"); w.Code != http.StatusFound || w.Header().Get("Location") != "/docs/" {
+		t.Errorf("root = %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
 
-	rec.WriteHeader(http.StatusNotFound)
-	n, err := rec.Write([]byte("default 404 body"))
+func TestHandler_Compression(t *testing.T) {
+	page := strings.Repeat("<p>hello</p>", 200)
+	h := newTestHandler(t, map[string]string{
+		"page.html":        page,
+		"small.html":       "<p>hi</p>",
+		"app.js":           "console.log(1)",
+		"app.js.br":        "precompressed",
+		"photo.png":        strings.Repeat("x", 2048),
+		"index.html":       "home",
+		"index.html.gz":    "gzipped home",
+		"style.3f2a1c.css": "body {}",
+	}, Options{Compress: true, Cache: true})
 
+	w := get(h, "/page", "Accept-Encoding", "gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
 	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+		t.Fatal(err)
 	}
-	if n != 16 {
-		t.Errorf("expected Write to report 16 bytes, got %d", n)
+	if body, _ := io.ReadAll(zr); string(body) != page {
+		t.Error("the gzipped page doesn't decode to the page")
 	}
-	if rec.status != http.StatusNotFound {
-		t.Errorf("expected recorded status 404, got %d", rec.status)
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("headers = %v", w.Header())
 	}
-	if w.Body.Len() != 0 {
-		t.Errorf("expected suppressed body, got %q", w.Body.String())
+
+	w = get(h, "/page", "Accept-Encoding", "gzip, br")
+	if w.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("Content-Encoding = %q, want br", w.Header().Get("Content-Encoding"))
+	}
+	if body, _ := io.ReadAll(brotli.NewReader(w.Body)); string(body) != page {
+		t.Error("the brotli page doesn't decode to the page")
+	}
+
+	for _, tt := range []struct{ path, accept, encoding, body string }{
+		{"/app.js", "br", "br", "precompressed"},
+		{"/app.js", "gzip", "", "console.log(1)"},
+		{"/", "gzip", "gzip", "gzipped home"},
+		{"/small", "gzip", "", "<p>hi</p>"},
+		{"/photo.png", "gzip", "", strings.Repeat("x", 2048)},
+		{"/page", "br;q=0, *", "gzip", ""},
+	} {
+		w := get(h, tt.path, "Accept-Encoding", tt.accept)
+		if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s with %q: Content-Encoding = %q, want %q", tt.path, tt.accept, got, tt.encoding)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s with %q: body = %q, want %q", tt.path, tt.accept, w.Body.String(), tt.body)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/page":             cachePage,
+		"/app.js":           cacheAsset,
+		"/photo.png":        cacheMedia,
+		"/style.3f2a1c.css": cacheImmutable,
+	} {
+		if got := get(h, path).Header().Get("Cache-Control"); got != want {
+			t.Errorf("%s: Cache-Control = %q, want %q", path, got, want)
+		}
 	}
 }
 
-func TestNotFoundRecorder_PassesThrough(t *testing.T) {
-	w := httptest.NewRecorder()
-	rec := &notFoundRecorder{ResponseWriter: w}
+// TestCompress_ReplacesVersions compresses two versions of a file, the
+// second replacing the first in the cache.
+func TestCompress_ReplacesVersions(t *testing.T) {
+	s := &site{compressed: map[string]compressedFile{}}
+	for _, version := range []string{"v1", "v2"} {
+		content := strings.Repeat("<p>"+version+"</p>", 200)
+		data, err := s.compress(strings.NewReader(content), "page.html", "gzip", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body, _ := io.ReadAll(zr); string(body) != content {
+			t.Errorf("%s: the compressed file doesn't decode to the file", version)
+		}
+	}
+	if len(s.compressed) != 1 || s.compressed["page.html\x00gzip"].version != "v2" {
+		t.Errorf("cache = %d entries, want the latest version only", len(s.compressed))
+	}
+}
 
-	rec.WriteHeader(http.StatusOK)
-	rec.Write([]byte("hello"))
+func TestHandler_ETag(t *testing.T) {
+	h := newTestHandler(t, map[string]string{"note.html": "note"}, Options{})
+	w := get(h, "/note")
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Cache-Control") != cacheNone {
+		t.Fatalf("headers = %v, want an ETag and no-cache", w.Header())
+	}
+	if w := get(h, "/note", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("conditional request = %d, want 304", w.Code)
+	}
+}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", w.Code)
+func TestHandler_AccessLog(t *testing.T) {
+	dir := t.TempDir()
+	var logs bytes.Buffer
+	h, err := NewHandler(Options{OutputDir: dir, AccessLog: true}, slog.New(slog.NewTextHandler(&logs, nil)))
+	if err != nil {
+		t.Fatal(err)
 	}
-	if w.Body.String() != "hello" {
-		t.Errorf("expected body 'hello', got %q", w.Body.String())
+	get(h, "/missing?q=1")
+	if !strings.Contains(logs.String(), `path="/missing?q=1"`) || !strings.Contains(logs.String(), "status=404") {
+		t.Errorf("access log = %q", logs.String())
 	}
 }
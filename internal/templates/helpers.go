@@ -7,7 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/a-h/templ"
 	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/obsidian"
 )
 
 // FormatDate formats a time.Time as "Jan 02, 2006".
@@ -51,7 +53,57 @@ func toStr(v any) string {
 // For flat URLs the image is inside the page directory: /path/slug-kind.png
 // For non-flat URLs the image sits next to the .html file: /parent/slug-kind.png
 func ogImageURL(baseURL, webPath, slug, kind string, flatURLs bool) string {
-	filename := slug + "-" + kind + ".png"
+	return pageAssetURL(baseURL, webPath, slug+"-"+kind+".png", flatURLs)
+}
+
+// noteOGImageURL returns the URL of the Open Graph or Twitter Card image of
+// a note: the image set in its "og:image" or "image" frontmatter property
+// when present, or the card generated for it.
+func noteOGImageURL(data *PageData, kind string) string {
+	if own := strings.TrimSpace(toStr(data.Frontmatter["og:image"])); own != "" {
+		return absoluteURL(data.Site.BaseURL, own)
+	}
+	if data.Image != "" {
+		return absoluteURL(data.Site.BaseURL, data.Image)
+	}
+	return ogImageURL(data.Site.BaseURL, data.File.WebPath, data.File.Name, kind, data.Site.FlatURLs)
+}
+
+// absoluteURL resolves a URL written in the frontmatter against the site
+// root. Absolute URLs are returned as they are.
+func absoluteURL(baseURL, ref string) string {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ref
+	}
+	return baseURL + "/" + strings.TrimPrefix(ref, "/")
+}
+
+// feedURL returns the URL of the RSS feed of the page language. Multilingual
+// sites have a feed per language.
+func feedURL(data *PageData) string {
+	if len(data.Languages) > 1 {
+		return data.Site.BaseURL + "/" + obsidian.LanguageFileName("feed.xml", data.Site.Lang, data.Site.DefaultLang)
+	}
+	return data.Site.BaseURL + "/feed.xml"
+}
+
+// oobAttrs returns the attributes swapping an element out of band on
+// boosted navigation, or none when swap is false.
+func oobAttrs(swap bool) templ.Attributes {
+	if !swap {
+		return templ.Attributes{}
+	}
+	return templ.Attributes{"hx-swap-oob": "true"}
+}
+
+// canvasSVGURL builds the URL of the static SVG export of a canvas page.
+func canvasSVGURL(baseURL, webPath, slug string, flatURLs bool) string {
+	return pageAssetURL(baseURL, webPath, slug+"-canvas.svg", flatURLs)
+}
+
+// pageAssetURL builds the URL of a file generated alongside a page,
+// following the same placement rules as ogImageURL.
+func pageAssetURL(baseURL, webPath, filename string, flatURLs bool) string {
 	if flatURLs {
 		return baseURL + webPath + "/" + filename
 	}
@@ -84,12 +136,14 @@ func buildThemeCSS(theme *ThemeData) string {
 			theme.FontFamily,
 		),
 	)
+	writeCSSVars(&b, theme.LightVars)
 	b.WriteString("}\n\n")
 
 	// Dark theme (data attribute)
 	b.WriteString("/* --- DARK THEME OVERRIDES (Data Attribute) --- */\n")
 	b.WriteString(":root[data-theme=\"dark\"] {\n")
 	writeColorVars(&b, theme.Dark)
+	writeCSSVars(&b, theme.DarkVars)
 	b.WriteString("}\n\n")
 
 	// Dark theme (system preference)
@@ -97,6 +151,7 @@ func buildThemeCSS(theme *ThemeData) string {
 	b.WriteString("@media (prefers-color-scheme: dark) {\n")
 	b.WriteString(":root:not([data-theme=\"light\"]) {\n")
 	writeColorVars(&b, theme.Dark)
+	writeCSSVars(&b, theme.DarkVars)
 	b.WriteString("}\n}\n")
 
 	b.WriteString("</style>")
@@ -104,46 +159,69 @@ func buildThemeCSS(theme *ThemeData) string {
 }
 
 // buildStructuredDataJSON returns HTML script tags containing JSON-LD
-// structured data for articles and breadcrumbs.
+// structured data: the article, questions and steps of notes, the site and
+// its publisher on the home page, the collection of folder and tag pages,
+// and the breadcrumbs.
 func buildStructuredDataJSON(data *PageData) string {
-	var articleJSON string
+	schemas := []string{}
+	seo := data.Site.SEO
+
 	if data.IsNote && data.File != nil {
 		title := toStr(data.Frontmatter["title"])
 		if title == "" {
 			title = data.File.Name
 		}
+		description := toStr(data.Frontmatter["description"])
+		imageURL := noteOGImageURL(data, "og")
 		params := jsonld.ArticleParams{
 			Title:        title,
-			Description:  toStr(data.Frontmatter["description"]),
-			Author:       toStr(data.Frontmatter["author"]),
+			Description:  description,
 			BaseURL:      data.Site.BaseURL,
 			WebPath:      data.File.WebPath,
 			SiteName:     data.Site.SiteName,
 			DateCreated:  data.File.Created,
 			DateModified: data.File.Modified,
-			ImageURL:     ogImageURL(data.Site.BaseURL, data.File.WebPath, data.File.Name, "og", data.Site.FlatURLs),
+			ImageURL:     imageURL,
+			Authors:      noteAuthors(data),
+			Publisher:    seo.Publisher,
+			Keywords:     noteKeywords(data),
+		}
+		schemas = append(schemas, jsonld.BuildArticleJSON(params))
+
+		if isHomePage(data) {
+			schemas = append(schemas,
+				jsonld.BuildWebSiteJSON(data.Site.BaseURL, data.Site.SiteName, seo.Publisher),
+				jsonld.BuildEntityJSON(seo.PublisherOrDefault(data.Site.SiteName), jsonld.TypeOrganization),
+			)
 		}
-		articleJSON = jsonld.BuildArticleJSON(params)
+		schemas = append(schemas, jsonld.BuildFAQJSON(jsonld.ExtractFAQ(data.File.Content, seo.FAQCalloutOrDefault())))
+		if strings.EqualFold(toStr(data.Frontmatter["schema"]), "howto") {
+			schemas = append(schemas, jsonld.BuildHowToJSON(title, description, imageURL, jsonld.ExtractSteps(data.File.Content)))
+		}
+	}
+	if data.IsFolder && data.Folder != nil {
+		schemas = append(schemas, jsonld.BuildCollectionPageJSON(data.Folder.RelPath, canonicalURL(data), collectionItems(data.Site.BaseURL, data.Folder.Files)))
+	}
+	if data.IsTag && data.Tag != nil {
+		schemas = append(schemas, jsonld.BuildCollectionPageJSON(data.Tag.Name, canonicalURL(data), collectionItems(data.Site.BaseURL, data.Tag.Files)))
 	}
 
 	items := make([]jsonld.BreadcrumbItem, len(data.Breadcrumbs))
 	for i, c := range data.Breadcrumbs {
 		items[i] = jsonld.BreadcrumbItem{Label: c.Label, URL: c.Url}
 	}
-	breadcrumbJSON := jsonld.BuildBreadcrumbJSON(data.Site.BaseURL, items)
+	schemas = append(schemas, jsonld.BuildBreadcrumbJSON(data.Site.BaseURL, items))
 
 	var b strings.Builder
-	if articleJSON != "" {
-		b.WriteString(`<script type="application/ld+json">`)
-		b.WriteString(articleJSON)
-		b.WriteString(`</script>`)
-	}
-	if breadcrumbJSON != "" {
+	for _, schema := range schemas {
+		if schema == "" {
+			continue
+		}
 		if b.Len() > 0 {
 			b.WriteString("\n")
 		}
 		b.WriteString(`<script type="application/ld+json">`)
-		b.WriteString(breadcrumbJSON)
+		b.WriteString(schema)
 		b.WriteString(`</script>`)
 	}
 	return b.String()
@@ -166,3 +244,14 @@ func writeColorVars(b *strings.Builder, c *ThemeColors) {
 	fmt.Fprintf(b, "--color-cyan: %s;\n", c.Cyan)
 	fmt.Fprintf(b, "--color-comment: %s;\n", c.Comment)
 }
+
+// writeCSSVars writes the CSS custom properties set by the user.
+func writeCSSVars(b *strings.Builder, vars []CSSVar) {
+	if len(vars) == 0 {
+		return
+	}
+	b.WriteString("/* Overrides */\n")
+	for _, v := range vars {
+		fmt.Fprintf(b, "%s: %s;\n", v.Name, v.Value)
+	}
+}
@@ -110,13 +110,13 @@ func TestHead_OGMetaTags(t *testing.T) {
 	expected := []string{
 		`<meta property="og:title" content="My Title"`,
 		`<meta property="og:description" content="My Desc"`,
-		`<meta property="og:image" content="https://example.com/blog/my-post/og.png"`,
+		`<meta property="og:image" content="https://example.com/blog/my-post-og.png"`,
 		`<meta property="og:url" content="https://example.com/blog/my-post"`,
 		`<meta property="og:type" content="article"`,
 		`<meta name="twitter:card" content="summary_large_image"`,
 		`<meta name="twitter:title" content="My Title"`,
 		`<meta name="twitter:description" content="My Desc"`,
-		`<meta name="twitter:image" content="https://example.com/blog/my-post/twitter.png"`,
+		`<meta name="twitter:image" content="https://example.com/blog/my-post-twitter.png"`,
 	}
 
 	for _, want := range expected {
@@ -126,6 +126,39 @@ func TestHead_OGMetaTags(t *testing.T) {
 	}
 }
 
+func TestHead_OGMetaTags_OwnImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"https://cdn.example.org/card.png", "https://cdn.example.org/card.png"},
+		{"/images/card.png", "https://example.com/images/card.png"},
+		{"images/card.png", "https://example.com/images/card.png"},
+	}
+
+	for _, tt := range tests {
+		data := &PageData{
+			File:        &obsidian.File{Name: "my-post", WebPath: "/blog/my-post"},
+			Frontmatter: map[string]any{"og:image": tt.image},
+			Site:        &SiteData{BaseURL: "https://example.com", SiteName: "My Site"},
+		}
+
+		var buf bytes.Buffer
+		if err := Head(data).Render(context.Background(), &buf); err != nil {
+			t.Fatalf("Head() returned error: %v", err)
+		}
+		html := buf.String()
+		for _, want := range []string{
+			`<meta property="og:image" content="` + tt.want + `"`,
+			`<meta name="twitter:image" content="` + tt.want + `"`,
+		} {
+			if !strings.Contains(html, want) {
+				t.Errorf("og:image %q: expected HTML to contain %q", tt.image, want)
+			}
+		}
+	}
+}
+
 func TestHead_OGMetaTags_Folder(t *testing.T) {
 	data := &PageData{
 		IsFolder: true,
@@ -0,0 +1,66 @@
+// @feature:layouts Partials of the vault replacing components of the layouts, and extra assets of every page.
+package templates
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// OverrideNames are the components of the layouts a vault can replace, each
+// with a <name>.html partial.
+var OverrideNames = []string{"head", "header", "footer", "sidebar", "note", "folder", "tag"}
+
+// Overrides holds the html/template partials replacing components of the
+// layouts, and the stylesheets and scripts added to every page.
+type Overrides struct {
+	Partials *template.Template // Partials by file name, e.g. "footer.html"
+	CSS      []string           // URLs of the stylesheets added to every page
+	JS       []string           // URLs of the scripts added to every page
+}
+
+// OverrideFuncs are the functions available to the partials.
+var OverrideFuncs = template.FuncMap{
+	"safeHTML":   func(s string) template.HTML { return template.HTML(s) },
+	"safeURL":    func(s string) template.URL { return template.URL(s) },
+	"formatDate": FormatDate,
+	"toStr":      toStr,
+}
+
+// Slot renders the partial overriding the named component with the data of
+// the page, or the component itself when the vault doesn't override it.
+func Slot(data *PageData, name string, component templ.Component) templ.Component {
+	overrides := data.Site.Overrides
+	if overrides == nil || overrides.Partials == nil {
+		return component
+	}
+	partial := overrides.Partials.Lookup(name + ".html")
+	if partial == nil {
+		return component
+	}
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if err := partial.Execute(w, data); err != nil {
+			return fmt.Errorf("templates: couldn't render the %s override: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// extraCSS returns the URLs of the stylesheets added to every page.
+func extraCSS(data *PageData) []string {
+	if data.Site.Overrides == nil {
+		return nil
+	}
+	return data.Site.Overrides.CSS
+}
+
+// extraJS returns the URLs of the scripts added to every page.
+func extraJS(data *PageData) []string {
+	if data.Site.Overrides == nil {
+		return nil
+	}
+	return data.Site.Overrides.JS
+}
@@ -0,0 +1,72 @@
+// @feature:layouts Tests for the partials overriding components of the layouts.
+package templates
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// overriddenPage returns a note page of a site whose partials override the
+// footer and the note.
+func overriddenPage(t *testing.T) *PageData {
+	t.Helper()
+	partials := template.Must(template.New("_layouts").Funcs(OverrideFuncs).Parse(`
+{{define "footer.html"}}<footer class="brand">© {{.Site.SiteName}}</footer>{{end}}
+{{define "note.html"}}<article class="brand-note">{{safeHTML .Content}}</article>{{end}}`))
+
+	data := notePage(nil)
+	data.Content = "<p>Hello</p>"
+	data.Site.Labels = i18n.Resolve("en")
+	data.Site.Theme = &ThemeData{Light: &ThemeColors{}, Dark: &ThemeColors{}}
+	data.Site.Overrides = &Overrides{
+		Partials: partials,
+		CSS:      []string{"https://example.com/_layouts/extra.css"},
+		JS:       []string{"https://example.com/_layouts/extra.js"},
+	}
+	return data
+}
+
+func TestSlot_Overrides(t *testing.T) {
+	for name, layout := range map[string]func(*PageData) string{
+		"default": func(d *PageData) string { return render(t, DefaultLayout(d)) },
+		"simple":  func(d *PageData) string { return render(t, SimpleLayout(d)) },
+		"docs":    func(d *PageData) string { return render(t, DocsLayout(d)) },
+	} {
+		html := layout(overriddenPage(t))
+		for _, want := range []string{
+			`<footer class="brand">© My Site</footer>`,
+			`<article class="brand-note"><p>Hello</p></article>`,
+			`<link rel="stylesheet" href="https://example.com/_layouts/extra.css">`,
+			`<script src="https://example.com/_layouts/extra.js" defer></script>`,
+		} {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s layout lacks %q", name, want)
+			}
+		}
+		if strings.Contains(html, "kiln.talesign.com") {
+			t.Errorf("%s layout still renders the built-in footer", name)
+		}
+	}
+}
+
+func TestSlot_FallsBackToComponent(t *testing.T) {
+	data := overriddenPage(t)
+	data.Site.Overrides.Partials = nil
+	html := render(t, DefaultLayout(data))
+	if !strings.Contains(html, "kiln.talesign.com") || !strings.Contains(html, `<div class="p-4 content">`) {
+		t.Error("the built-in components aren't rendered without partials")
+	}
+
+	folder := overriddenPage(t)
+	folder.IsNote = false
+	folder.IsFolder = true
+	folder.Folder = &obsidian.Folder{Name: "blog", WebPath: "/blog"}
+	html = render(t, SimpleLayout(folder))
+	if strings.Contains(html, "brand-note") || !strings.Contains(html, "blog") {
+		t.Error("the note partial is rendered on a folder page")
+	}
+}
@@ -0,0 +1,177 @@
+// @feature:seo Per-page metadata overrides read from the frontmatter.
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// Alternate is a version of the page in another language.
+type Alternate struct {
+	Lang string
+	URL  string
+}
+
+// canonicalURL returns the canonical URL of the page: the "canonical"
+// property of a note when set, or the URL the page is published at.
+func canonicalURL(data *PageData) string {
+	switch {
+	case data.IsFolder && data.Folder != nil:
+		return data.Site.BaseURL + data.Folder.WebPath + "/"
+	case data.IsTag && data.Tag != nil:
+		if data.Site.FlatURLs {
+			return data.Site.BaseURL + data.Tag.WebPath + "/"
+		}
+		return data.Site.BaseURL + data.Tag.WebPath
+	case data.File != nil:
+		if own := strings.TrimSpace(toStr(data.Frontmatter["canonical"])); own != "" {
+			return absoluteURL(data.Site.BaseURL, own)
+		}
+		if data.Site.FlatURLs {
+			return data.Site.BaseURL + data.File.WebPath + "/"
+		}
+		return data.Site.BaseURL + data.File.WebPath
+	}
+	return ""
+}
+
+// noteURL returns the og:url of a note: its canonical URL when overridden,
+// or the URL it is published at.
+func noteURL(data *PageData) string {
+	if own := strings.TrimSpace(toStr(data.Frontmatter["canonical"])); own != "" {
+		return absoluteURL(data.Site.BaseURL, own)
+	}
+	return data.Site.BaseURL + data.File.WebPath
+}
+
+// noteOGType returns the "og:type" property of a note, "article" by default.
+func noteOGType(data *PageData) string {
+	if own := strings.TrimSpace(toStr(data.Frontmatter["og:type"])); own != "" {
+		return own
+	}
+	return "article"
+}
+
+// noteAuthors returns the authors of a note, resolving the ids of its
+// "author" property through the registry of kiln.yaml.
+func noteAuthors(data *PageData) []jsonld.Entity {
+	return data.Site.SEO.ResolveAuthors(toStrings(data.Frontmatter["author"]))
+}
+
+// noteAuthorNames returns the names of the authors of a note, comma separated.
+func noteAuthorNames(data *PageData) string {
+	names := []string{}
+	for _, a := range noteAuthors(data) {
+		names = append(names, a.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// noteKeywords returns the "keywords" property of a note, given as a list
+// or as a comma separated string.
+func noteKeywords(data *PageData) []string {
+	keywords := []string{}
+	for _, v := range toStrings(data.Frontmatter["keywords"]) {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" && !slices.Contains(keywords, k) {
+				keywords = append(keywords, k)
+			}
+		}
+	}
+	return keywords
+}
+
+// alternates returns the hreflang links of the page: the page itself in its
+// language, its translations in the vault, the translations listed in the
+// "alternates" property of a note, and the version in the default language
+// as the x-default one.
+func alternates(data *PageData) []Alternate {
+	self := canonicalURL(data)
+	if self == "" || data.Site.Lang == "" {
+		return nil
+	}
+	links := []Alternate{{Lang: data.Site.Lang, URL: self}}
+	xDefault := self
+	if !data.IsFolder && !data.IsTag {
+		if data.File != nil {
+			for _, t := range data.File.Translations {
+				url := data.Site.BaseURL + t.WebPath
+				if data.Site.FlatURLs {
+					url += "/"
+				}
+				links = append(links, Alternate{Lang: t.Lang, URL: url})
+				if t.Lang == data.Site.DefaultLang {
+					xDefault = url
+				}
+			}
+		}
+		langs := []string{}
+		urls := map[string]string{}
+		switch m := data.Frontmatter["alternates"].(type) {
+		case map[string]any:
+			for lang, url := range m {
+				langs, urls[lang] = append(langs, lang), toStr(url)
+			}
+		case map[any]any:
+			for lang, url := range m {
+				langs, urls[toStr(lang)] = append(langs, toStr(lang)), toStr(url)
+			}
+		}
+		slices.Sort(langs)
+		for _, lang := range langs {
+			if hasAlternate(links, lang) || strings.TrimSpace(urls[lang]) == "" {
+				continue
+			}
+			links = append(links, Alternate{Lang: lang, URL: absoluteURL(data.Site.BaseURL, strings.TrimSpace(urls[lang]))})
+		}
+	}
+	return append(links, Alternate{Lang: "x-default", URL: xDefault})
+}
+
+// hasAlternate reports whether links hold a version of the page in lang.
+func hasAlternate(links []Alternate, lang string) bool {
+	return slices.ContainsFunc(links, func(a Alternate) bool { return a.Lang == lang })
+}
+
+// isHomePage reports whether the page is the root index note.
+func isHomePage(data *PageData) bool {
+	return data.IsNote && data.File != nil && strings.EqualFold(data.File.RelPath, "index.md")
+}
+
+// collectionItems lists the pages of a folder or tag page.
+func collectionItems(baseURL string, files []*obsidian.File) []jsonld.CollectionItem {
+	items := []jsonld.CollectionItem{}
+	for _, f := range files {
+		switch f.Ext {
+		case ".md", ".canvas", ".base":
+			items = append(items, jsonld.CollectionItem{Name: f.Name, URL: baseURL + f.WebPath})
+		}
+	}
+	return items
+}
+
+// toStrings converts a frontmatter value holding a string or a list into a
+// slice of strings.
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if item != nil {
+				out = append(out, fmt.Sprint(item))
+			}
+		}
+		return out
+	}
+	return []string{toStr(v)}
+}
@@ -0,0 +1,192 @@
+// @feature:seo Tests for frontmatter metadata overrides and structured data.
+package templates
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/obsidian"
+)
+
+// render renders a component and returns its HTML.
+func render(t *testing.T, c templ.Component) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.Render(context.Background(), &buf); err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+	return buf.String()
+}
+
+func notePage(frontmatter map[string]any) *PageData {
+	return &PageData{
+		IsNote:      true,
+		File:        &obsidian.File{Name: "my-post", RelPath: "blog/my-post.md", WebPath: "/blog/my-post"},
+		Frontmatter: frontmatter,
+		Site:        &SiteData{BaseURL: "https://example.com", SiteName: "My Site", Lang: "en"},
+	}
+}
+
+func TestHead_FrontmatterOverrides(t *testing.T) {
+	data := notePage(map[string]any{
+		"canonical": "https://original.example.org/post",
+		"og:type":   "website",
+		"author":    []any{"jane", "John Smith"},
+		"keywords":  "go, static sites, go",
+	})
+	data.Site.SEO = jsonld.Options{Authors: map[string]jsonld.Entity{"jane": {Name: "Jane Doe"}}}
+
+	html := render(t, Head(data))
+	for _, want := range []string{
+		`<meta property="og:url" content="https://original.example.org/post"`,
+		`<meta property="og:type" content="website"`,
+		`<meta name="author" content="Jane Doe, John Smith"`,
+		`<meta name="keywords" content="go, static sites"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+
+	canonical := render(t, Canonical(data))
+	if !strings.Contains(canonical, `<link rel="canonical" href="https://original.example.org/post"`) {
+		t.Errorf("expected the canonical override, got:\n%s", canonical)
+	}
+}
+
+func TestHead_PageImage(t *testing.T) {
+	data := notePage(nil)
+	data.Image = "/assets/social.png"
+
+	html := render(t, Head(data))
+	if !strings.Contains(html, `<meta property="og:image" content="https://example.com/assets/social.png"`) {
+		t.Errorf("expected the page image as og:image, got:\n%s", html)
+	}
+}
+
+func TestCanonical_Hreflang(t *testing.T) {
+	data := notePage(map[string]any{
+		"alternates": map[any]any{"it": "/it/mio-post", "en": "/ignored"},
+	})
+
+	html := render(t, Canonical(data))
+	for _, want := range []string{
+		`<link rel="canonical" href="https://example.com/blog/my-post"`,
+		`<link rel="alternate" hreflang="en" href="https://example.com/blog/my-post"`,
+		`<link rel="alternate" hreflang="it" href="https://example.com/it/mio-post"`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/blog/my-post"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "/ignored") {
+		t.Error("the site language should not be listed twice")
+	}
+}
+
+func TestCanonical_Translations(t *testing.T) {
+	data := notePage(map[string]any{
+		"alternates": map[string]any{"fr": "/fr/mon-post", "en": "/ignored"},
+	})
+	data.Site.Lang, data.Site.DefaultLang = "it", "en"
+	data.File.Lang = "it"
+	data.File.Translations = []*obsidian.File{{WebPath: "/en/my-post", Lang: "en"}}
+
+	html := render(t, Canonical(data))
+	for _, want := range []string{
+		`<link rel="alternate" hreflang="it" href="https://example.com/blog/my-post"`,
+		`<link rel="alternate" hreflang="en" href="https://example.com/en/my-post"`,
+		`<link rel="alternate" hreflang="fr" href="https://example.com/fr/mon-post"`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/en/my-post"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "/ignored") {
+		t.Error("a translation in the vault should win over the alternates property")
+	}
+}
+
+func TestLanguageSwitcher(t *testing.T) {
+	data := notePage(nil)
+	data.Site.Labels = i18n.Resolve("en")
+	if html := render(t, LanguageSwitcher(data, true)); strings.TrimSpace(html) != "" {
+		t.Errorf("expected no switcher on a single-language site, got:\n%s", html)
+	}
+
+	data.Site.Lang, data.Site.DefaultLang = "it", "en"
+	data.Languages = []LanguageLink{
+		{Lang: "en", Name: "English", URL: "/en/my-post"},
+		{Lang: "it", Name: "Italiano", URL: "/blog/my-post", Current: true},
+	}
+	html := render(t, LanguageSwitcher(data, true))
+	for _, want := range []string{
+		`aria-label="Language"`,
+		`hx-swap-oob="true"`,
+		`<a href="/en/my-post" hreflang="en" lang="en" title="English" hx-boost="false">EN</a>`,
+		`aria-current="page"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+	if html := render(t, LanguageSwitcher(data, false)); strings.Contains(html, "hx-swap-oob") {
+		t.Errorf("expected no out of band swap, got:\n%s", html)
+	}
+
+	head := render(t, Head(data))
+	if !strings.Contains(head, `href="https://example.com/feed.it.xml"`) {
+		t.Errorf("expected the Italian feed, got:\n%s", head)
+	}
+}
+
+func TestStructuredData_Note(t *testing.T) {
+	data := notePage(map[string]any{"schema": "HowTo", "author": "jane"})
+	data.File.Content = []byte("> [!faq] Is it free?\n> Yes.\n\n1. Install\n2. Build\n")
+
+	html := render(t, StructuredData(data))
+	for _, want := range []string{`"@type":"Article"`, `"@type":"FAQPage"`, `"@type":"HowTo"`, `"name":"jane"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected structured data to contain %q, got:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, `"@type":"WebSite"`) {
+		t.Error("WebSite should only be described on the home page")
+	}
+}
+
+func TestStructuredData_HomePage(t *testing.T) {
+	data := notePage(nil)
+	data.File.RelPath = "Index.md"
+	data.Site.SEO = jsonld.Options{Publisher: jsonld.Entity{Type: "person", Name: "Jane Doe"}}
+
+	html := render(t, StructuredData(data))
+	for _, want := range []string{`"@type":"WebSite"`, `"@type":"SearchAction"`, `"@type":"Person","name":"Jane Doe"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected structured data to contain %q, got:\n%s", want, html)
+		}
+	}
+}
+
+func TestStructuredData_Folder(t *testing.T) {
+	data := &PageData{
+		IsFolder: true,
+		Folder: &obsidian.Folder{RelPath: "blog", WebPath: "/blog", Files: []*obsidian.File{
+			{Name: "First", Ext: ".md", WebPath: "/blog/first"},
+			{Name: "photo", Ext: ".png", WebPath: "/blog/photo.png"},
+		}},
+		Site: &SiteData{BaseURL: "https://example.com", SiteName: "My Site"},
+	}
+
+	html := render(t, StructuredData(data))
+	if !strings.Contains(html, `"@type":"CollectionPage"`) || !strings.Contains(html, `"numberOfItems":1`) {
+		t.Errorf("expected a collection of one page, got:\n%s", html)
+	}
+}
@@ -5,31 +5,73 @@ import (
 	"time"
 
 	"github.com/otaleghani/kiln/internal/i18n"
+	"github.com/otaleghani/kiln/internal/jsonld"
+	"github.com/otaleghani/kiln/internal/listing"
 	"github.com/otaleghani/kiln/internal/obsidian"
 	"github.com/otaleghani/kiln/internal/obsidian/bases"
 )
 
 // PageData is the top-level data passed to every page template.
 type PageData struct {
-	Site        *SiteData
-	Content     string
-	TOC         string
-	CanvasJSON  string
-	Breadcrumbs []obsidian.Breadcrumb
-	File        *obsidian.File
-	Folder      *obsidian.Folder
-	Tag         *obsidian.Tag
-	IsGraph     bool
-	IsCanvas    bool
-	IsBase      bool
-	IsNote      bool
-	IsFolder    bool
-	IsTag       bool
-	Is404       bool
-	Frontmatter map[string]any
-	Meta        *NoteMeta
-	Backlinks   []Backlink
-	Base        BaseViewData
+	Site       *SiteData
+	Content    string
+	TOC        string
+	CanvasJSON string
+	CanvasSVG  string
+	// LocalGraphURL is the URL of the page's local graph JSON, empty if disabled
+	LocalGraphURL string
+	Breadcrumbs   []obsidian.Breadcrumb
+	File          *obsidian.File
+	Folder        *obsidian.Folder
+	Tag           *obsidian.Tag
+	IsGraph       bool
+	IsCanvas      bool
+	IsBase        bool
+	IsNote        bool
+	IsFolder      bool
+	IsTag         bool
+	Is404         bool
+	Frontmatter   map[string]any
+	Image         string // Web path or URL of the "image" property of a note, used as its social image
+	Meta          *NoteMeta
+	Backlinks     []Backlink
+	Mentions      []Backlink // Unlinked mentions, grouped by note
+	Base          BaseViewData
+	Languages     []LanguageLink // Entries of the language switcher, empty on single-language sites
+	Docs          *DocsData      // Navigation of the docs layout, nil with the other layouts
+	Listing       *Listing       // Children of a folder page or of a folder note, nil on other pages
+}
+
+// Listing holds the children listed on the page of a folder.
+type Listing struct {
+	Style string         // "list", "cards" or "none"
+	Items []listing.Item // Sorted children, folders first
+}
+
+// DocsData holds the navigation of a page of the docs layout.
+type DocsData struct {
+	Sections []DocsLink             // Top-level folders of the site, shown as tabs
+	Versions []DocsLink             // Version folders next to the one holding the page, newest first
+	Sidebar  []*obsidian.NavbarNode // Navbar of the section and version of the page
+	Prev     *DocsLink              // Previous page in navbar order, nil on the first page
+	Next     *DocsLink              // Next page in navbar order, nil on the last page
+	EditURL  string                 // Link to the source of the page, empty without an edit-url
+}
+
+// DocsLink is a link of the docs layout navigation.
+type DocsLink struct {
+	Name    string
+	URL     string
+	Current bool // True for the section or version of the page
+}
+
+// LanguageLink is an entry of the language switcher: the translation of the
+// page in a language, or the home page of that language.
+type LanguageLink struct {
+	Lang    string
+	Name    string // Name of the language, written in the language itself
+	URL     string
+	Current bool // True for the language of the page
 }
 
 // NoteMeta holds reading metadata for a note page.
@@ -43,8 +85,16 @@ type NoteMeta struct {
 
 // Backlink represents a resolved incoming link to the current page.
 type Backlink struct {
-	Name    string
-	WebPath string
+	Name     string
+	WebPath  string
+	Excerpts []Excerpt // Text around each link or mention in the linking note
+}
+
+// Excerpt is a snippet of text around a link, with the link text highlighted.
+type Excerpt struct {
+	Before string
+	Text   string
+	After  string
 }
 
 // SiteData holds global site configuration used across all pages.
@@ -57,8 +107,22 @@ type SiteData struct {
 	DisableTOC        bool
 	DisableBacklinks  bool
 	FlatURLs          bool
-	Lang              string
+	Lang              string // Language of the page
+	DefaultLang       string // Default language of the site
 	Labels            *i18n.Labels
+	SEO               jsonld.Options // Publisher, authors and FAQ callout, from kiln.yaml
+
+	Overrides *Overrides        // Partials and assets of the _layouts folder of the vault, nil without one
+	Assets    map[string]string // Fingerprinted names of the stylesheets and scripts, by name
+}
+
+// Asset returns the URL of a stylesheet or script of the layout, e.g.
+// "style.css", under its fingerprinted name when it has one.
+func (s *SiteData) Asset(name string) string {
+	if fingerprinted, ok := s.Assets[name]; ok {
+		name = fingerprinted
+	}
+	return s.BaseURL + "/" + name
 }
 
 // ThemeData bundles color schemes and typography for the site.
@@ -67,6 +131,15 @@ type ThemeData struct {
 	Dark        *ThemeColors
 	FontFamily  string
 	FontFaceCSS string
+
+	LightVars []CSSVar // CSS custom properties of both modes, set after the colors
+	DarkVars  []CSSVar // CSS custom properties of the dark mode
+}
+
+// CSSVar is a CSS custom property set by the user.
+type CSSVar struct {
+	Name  string
+	Value string
 }
 
 // ThemeColors defines the color palette for a single mode (light or dark).
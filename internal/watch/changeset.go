@@ -7,7 +7,22 @@ import (
 	"strings"
 )
 
+// Changes are the files of the vault created, modified, removed or renamed
+// since the last rebuild, by RelPath. A renamed file is listed both under
+// its old path in Removed and under its new one in Changed.
+type Changes struct {
+	Changed []string          // Created or modified files
+	Removed []string          // Removed files
+	Renamed map[string]string // Old RelPath -> new RelPath of the renamed files
+}
+
+// Empty reports whether no file changed.
+func (c Changes) Empty() bool {
+	return len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
 type ChangeSet struct {
+	Changed []string // RelPaths of the created and modified files, read again
 	Rebuild []string // RelPaths to re-render
 	Remove  []string // RelPaths to delete from output
 }
@@ -17,21 +32,34 @@ type ChangeSet struct {
 // dependents are added to the rebuild set. Removed paths are excluded from
 // rebuild and placed in Remove instead.
 func ComputeChangeSet(changed, removed []string, graph *DepGraph) *ChangeSet {
+	return ComputeChanges(Changes{Changed: changed, Removed: removed}, graph)
+}
+
+// ComputeChanges is ComputeChangeSet for the changes reported by the
+// watcher. The notes linked by a removed or renamed note are rebuilt too,
+// as their backlinks list it.
+func ComputeChanges(changes Changes, graph *DepGraph) *ChangeSet {
 	rebuildSet := make(map[string]struct{})
 	removedSet := make(map[string]struct{})
+	changed, removed := changes.Changed, changes.Removed
 
 	for _, relPath := range changed {
 		rebuildSet[relPath] = struct{}{}
-		for _, dep := range graph.Dependents(nameFromRelPath(relPath)) {
+		for _, dep := range dependents(graph, relPath) {
 			rebuildSet[dep] = struct{}{}
 		}
 	}
 
 	for _, relPath := range removed {
 		removedSet[relPath] = struct{}{}
-		for _, dep := range graph.Dependents(nameFromRelPath(relPath)) {
+		for _, dep := range dependents(graph, relPath) {
 			rebuildSet[dep] = struct{}{}
 		}
+		for target := range graph.Forward[relPath] {
+			if isPathKey(target) {
+				rebuildSet[target] = struct{}{}
+			}
+		}
 		graph.RemoveSource(relPath)
 	}
 
@@ -50,11 +78,24 @@ func ComputeChangeSet(changed, removed []string, graph *DepGraph) *ChangeSet {
 	sort.Strings(remove)
 
 	return &ChangeSet{
+		Changed: changed,
 		Rebuild: rebuild,
 		Remove:  remove,
 	}
 }
 
+// dependents returns the sources linking to the file at relPath, either
+// through its path or through its name.
+func dependents(graph *DepGraph, relPath string) []string {
+	return append(graph.Dependents(relPath), graph.Dependents(nameFromRelPath(relPath))...)
+}
+
+// isPathKey reports whether a key of the graph is the RelPath of a file,
+// rather than a normalised name.
+func isPathKey(key string) bool {
+	return key != nameFromRelPath(key)
+}
+
 // nameFromRelPath extracts the normalised name from a relative path:
 // base filename, lowercased, with .md extension stripped.
 func nameFromRelPath(relPath string) string {
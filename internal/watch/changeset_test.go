@@ -68,3 +68,33 @@ func TestComputeChangeSetDedup(t *testing.T) {
 		t.Errorf("Remove = %v, want []", cs.Remove)
 	}
 }
+
+func TestComputeChangesRenamed(t *testing.T) {
+	g := NewDepGraph()
+	g.AddEdge("notes/b.md", "notes/old.md")
+	g.AddEdge("notes/b.md", "old")
+	g.AddEdge("notes/old.md", "notes/target.md")
+	g.AddEdge("notes/old.md", "target")
+	g.AddEdge("notes/old.md", "missing")
+
+	cs := ComputeChanges(Changes{
+		Changed: []string{"notes/new.md"},
+		Removed: []string{"notes/old.md"},
+		Renamed: map[string]string{"notes/old.md": "notes/new.md"},
+	}, g)
+
+	// b links to the old path, target lists the renamed note in its backlinks
+	want := []string{"notes/b.md", "notes/new.md", "notes/target.md"}
+	if !slices.Equal(cs.Rebuild, want) {
+		t.Errorf("Rebuild = %v, want %v", cs.Rebuild, want)
+	}
+	if !slices.Equal(cs.Remove, []string{"notes/old.md"}) {
+		t.Errorf("Remove = %v, want [notes/old.md]", cs.Remove)
+	}
+	if !slices.Equal(cs.Changed, []string{"notes/new.md"}) {
+		t.Errorf("Changed = %v, want [notes/new.md]", cs.Changed)
+	}
+	if _, ok := g.Forward["notes/old.md"]; ok {
+		t.Error("the edges of the old path are still in the graph")
+	}
+}
@@ -10,8 +10,11 @@ import (
 )
 
 // DepGraph tracks dependencies between vault files. Forward maps each source
-// RelPath to the set of target names it links to. Reverse maps each target
-// name to the set of source RelPaths that reference it.
+// RelPath to the set of targets it links to. Reverse maps each target to the
+// set of source RelPaths that reference it. Targets are keyed both by the
+// RelPath of the resolved file and by their normalised name, so that adding
+// a file with the same name, which may change how the link resolves, also
+// invalidates the source.
 type DepGraph struct {
 	Forward map[string]map[string]struct{}
 	Reverse map[string]map[string]struct{}
@@ -66,20 +69,19 @@ func (g *DepGraph) Dependents(name string) []string {
 	return result
 }
 
-// UpdateFiles refreshes the graph for the given files, removing stale edges
-// and adding new ones based on current links.
-func (g *DepGraph) UpdateFiles(files []*obsidian.File) {
+// UpdateSources refreshes the edges of the given sources only, by RelPath,
+// resolving their links against every file of the vault. Sources gone from
+// the vault lose their edges.
+func (g *DepGraph) UpdateSources(files []*obsidian.File, sources []string) {
+	links := obsidian.NewLinkIndex(files, nil)
+	byPath := make(map[string]*obsidian.File, len(files))
 	for _, f := range files {
-		if f.Ext != ".md" {
-			continue
-		}
-		g.RemoveSource(f.RelPath)
-		for _, link := range f.Links {
-			target := parseTarget(link)
-			if target == "" {
-				continue
-			}
-			g.AddEdge(f.RelPath, target)
+		byPath[f.RelPath] = f
+	}
+	for _, relPath := range sources {
+		g.RemoveSource(relPath)
+		if f, ok := byPath[relPath]; ok && f.Ext == ".md" {
+			g.addLinks(links, f)
 		}
 	}
 }
@@ -88,22 +90,39 @@ var mdLinkRe = regexp.MustCompile(`\[([^\]]*)\]\(([^)]+)\)`)
 
 // BuildFromFiles populates the graph from a slice of obsidian files.
 func (g *DepGraph) BuildFromFiles(files []*obsidian.File) {
+	links := obsidian.NewLinkIndex(files, nil)
 	for _, f := range files {
 		if f.Ext != ".md" {
 			continue
 		}
-		for _, link := range f.Links {
-			target := parseTarget(link)
-			if target == "" {
-				continue
-			}
+		g.addLinks(links, f)
+	}
+}
+
+// addLinks adds an edge from f to the targets of each of its links.
+func (g *DepGraph) addLinks(links *obsidian.LinkIndex, f *obsidian.File) {
+	for _, link := range f.Links {
+		for _, target := range parseTarget(links, f, link) {
 			g.AddEdge(f.RelPath, target)
 		}
 	}
 }
 
-// parseTarget extracts a normalised target name from a raw link string.
-func parseTarget(link string) string {
+// parseTarget returns the dependency keys of a raw link: the RelPath and the
+// name of the file it resolves to or, for links to missing files, the
+// normalised target name.
+func parseTarget(links *obsidian.LinkIndex, source *obsidian.File, link string) []string {
+	if target := links.Resolve(source, link); target != nil {
+		return []string{target.RelPath, nameFromRelPath(target.RelPath)}
+	}
+	if name := parseTargetName(link); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// parseTargetName extracts a normalised target name from a raw link string.
+func parseTargetName(link string) string {
 	if strings.HasPrefix(link, "[[") {
 		return parseWikilink(link)
 	}
@@ -142,20 +142,23 @@ func TestBuildFromFiles(t *testing.T) {
 	}
 }
 
-func TestUpdateFiles(t *testing.T) {
+func TestUpdateSources(t *testing.T) {
 	g := NewDepGraph()
 	g.AddEdge("a.md", "b")
+	g.AddEdge("c.md", "b")
+	g.AddEdge("gone.md", "b")
 
 	files := []*obsidian.File{
-		{RelPath: "a.md", Ext: ".md", Links: []string{"[[c]]"}},
+		{RelPath: "a.md", Ext: ".md", Name: "a", Links: []string{"[[c]]"}},
+		{RelPath: "c.md", Ext: ".md", Name: "c", Links: []string{"[[b]]"}},
 	}
-	g.UpdateFiles(files)
+	g.UpdateSources(files, []string{"a.md", "gone.md"})
 
-	if deps := g.Dependents("b"); len(deps) != 0 {
-		t.Errorf("expected no dependents for b, got %v", deps)
+	if deps := g.Dependents("b"); len(deps) != 1 || deps[0] != "c.md" {
+		t.Errorf("expected [c.md] for b, got %v", deps)
 	}
-	if deps := g.Dependents("c"); len(deps) != 1 || deps[0] != "a.md" {
-		t.Errorf("expected [a.md] for c, got %v", deps)
+	if deps := g.Dependents("c.md"); len(deps) != 1 || deps[0] != "a.md" {
+		t.Errorf("expected [a.md] for c.md, got %v", deps)
 	}
 }
 
@@ -176,3 +179,36 @@ func TestBuildFromFilesMailtoSkipped(t *testing.T) {
 		t.Errorf("expected no forward edges for mailto link, got %v", g.Forward["note.md"])
 	}
 }
+
+func TestBuildFromFilesResolvesPaths(t *testing.T) {
+	files := []*obsidian.File{
+		{
+			RelPath: "notes/a.md",
+			Ext:     ".md",
+			Name:    "a",
+			Links:   []string{"[x](../other/My%20Note.md)", "[[archive/Dup]]"},
+		},
+		{RelPath: "other/My Note.md", Ext: ".md", Name: "My Note"},
+		{RelPath: "archive/Dup.md", Ext: ".md", Name: "Dup"},
+		{RelPath: "notes/Dup.md", Ext: ".md", Name: "Dup"},
+	}
+
+	g := NewDepGraph()
+	g.BuildFromFiles(files)
+
+	if got := g.Dependents("other/My Note.md"); !slices.Equal(got, []string{"notes/a.md"}) {
+		t.Errorf("Dependents(other/My Note.md) = %v, want [notes/a.md]", got)
+	}
+	if got := g.Dependents("archive/Dup.md"); !slices.Equal(got, []string{"notes/a.md"}) {
+		t.Errorf("Dependents(archive/Dup.md) = %v, want [notes/a.md]", got)
+	}
+	if got := g.Dependents("notes/Dup.md"); len(got) != 0 {
+		t.Errorf("the path link should not depend on notes/Dup.md, got %v", got)
+	}
+
+	// Adding or changing any "Dup" may change the resolution, so the name is tracked too
+	cs := ComputeChangeSet([]string{"notes/Dup.md"}, nil, g)
+	if !slices.Contains(cs.Rebuild, "notes/a.md") {
+		t.Errorf("expected notes/a.md to be rebuilt, got %v", cs.Rebuild)
+	}
+}
@@ -2,21 +2,28 @@
 package watch
 
 import (
+	"crypto/sha256"
+	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
 
 // MtimeStore tracks file modification times so that successive calls to
-// Update can report which files changed or were removed.
+// Update can report which files changed or were removed. It also keeps the
+// hash of the content of every file, to tell renames apart.
 type MtimeStore struct {
-	Entries map[string]time.Time // key = RelPath
+	Entries map[string]time.Time         // key = RelPath
+	Sums    map[string][sha256.Size]byte // key = RelPath
 }
 
 func NewMtimeStore() *MtimeStore {
 	return &MtimeStore{
 		Entries: make(map[string]time.Time),
+		Sums:    make(map[string][sha256.Size]byte),
 	}
 }
 
@@ -27,7 +34,92 @@ func NewMtimeStore() *MtimeStore {
 func (s *MtimeStore) Update(inputDir string) (changed []string, removed []string, err error) {
 	seen := make(map[string]struct{})
 
-	err = filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, walkErr error) error {
+	err = s.walk(inputDir, inputDir, func(relPath string, isNew bool) {
+		changed = append(changed, relPath)
+	}, seen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for relPath := range s.Entries {
+		if _, ok := seen[relPath]; !ok {
+			removed = append(removed, relPath)
+			delete(s.Entries, relPath)
+			delete(s.Sums, relPath)
+		}
+	}
+
+	return changed, removed, nil
+}
+
+// Apply updates the entries of the given paths only, as reported by the
+// watcher, and returns the changes. A path missing from the disk removes the
+// file, or every file of the directory, tracked under it. A new directory
+// adds every file in it. A removed file whose content shows up under a new
+// path is reported as renamed.
+func (s *MtimeStore) Apply(inputDir string, paths []string) Changes {
+	changes := Changes{Renamed: map[string]string{}}
+	created := []string{}
+	removedSums := map[string][sha256.Size]byte{}
+
+	for _, path := range paths {
+		relPath, err := filepath.Rel(inputDir, path)
+		if err != nil || relPath == "." {
+			continue
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			prefix := relPath + string(filepath.Separator)
+			for tracked := range s.Entries {
+				if tracked == relPath || strings.HasPrefix(tracked, prefix) {
+					changes.Removed = append(changes.Removed, tracked)
+					if sum, ok := s.Sums[tracked]; ok {
+						removedSums[tracked] = sum
+					}
+					delete(s.Entries, tracked)
+					delete(s.Sums, tracked)
+				}
+			}
+			continue
+		}
+
+		s.walk(inputDir, path, func(relPath string, isNew bool) {
+			changes.Changed = append(changes.Changed, relPath)
+			if isNew {
+				created = append(created, relPath)
+			}
+		}, nil)
+	}
+
+	// Pairs the removed files with the new files holding the same content
+	slices.Sort(changes.Removed)
+	slices.Sort(created)
+	for _, old := range changes.Removed {
+		oldSum, ok := removedSums[old]
+		if !ok {
+			continue
+		}
+		i := slices.IndexFunc(created, func(relPath string) bool {
+			sum, ok := s.Sums[relPath]
+			return ok && sum == oldSum
+		})
+		if i < 0 {
+			continue
+		}
+		changes.Renamed[old] = created[i]
+		created = slices.Delete(created, i, i+1)
+	}
+
+	slices.Sort(changes.Changed)
+	changes.Changed = slices.Compact(changes.Changed)
+	return changes
+}
+
+// walk records the files under root, calling changed for every file new or
+// modified since the last walk. Paths are relative to inputDir. The files
+// seen are added to seen, when not nil.
+func (s *MtimeStore) walk(inputDir, root string, changed func(relPath string, isNew bool), seen map[string]struct{}) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return nil
 		}
@@ -58,27 +150,36 @@ func (s *MtimeStore) Update(inputDir string) (changed []string, removed []string
 			return nil
 		}
 
-		seen[relPath] = struct{}{}
+		if seen != nil {
+			seen[relPath] = struct{}{}
+		}
 		modTime := info.ModTime()
 
 		prev, tracked := s.Entries[relPath]
 		if !tracked || !modTime.Equal(prev) {
-			changed = append(changed, relPath)
+			if sum, err := fileSum(path); err == nil {
+				s.Sums[relPath] = sum
+			}
+			changed(relPath, !tracked)
 		}
 		s.Entries[relPath] = modTime
 
 		return nil
 	})
+}
+
+// fileSum returns the hash of the content of a file.
+func fileSum(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, nil, err
+		return sum, err
 	}
-
-	for relPath := range s.Entries {
-		if _, ok := seen[relPath]; !ok {
-			removed = append(removed, relPath)
-			delete(s.Entries, relPath)
-		}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
 	}
-
-	return changed, removed, nil
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
 }
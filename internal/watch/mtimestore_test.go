@@ -136,3 +136,59 @@ func writeFile(t *testing.T, path, content string) {
 		t.Fatal(err)
 	}
 }
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), "alpha")
+	writeFile(t, filepath.Join(dir, "old.md"), "renamed content")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "c.md"), "charlie")
+	writeFile(t, filepath.Join(dir, "sub", "d.md"), "delta")
+
+	s := NewMtimeStore()
+	if _, _, err := s.Update(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(2 * time.Second)
+	writeFile(t, filepath.Join(dir, "a.md"), "alpha-modified")
+	if err := os.Chtimes(filepath.Join(dir, "a.md"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "old.md"), filepath.Join(dir, "new.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "e.md"), "echo")
+
+	changes := s.Apply(dir, []string{
+		filepath.Join(dir, "a.md"),
+		filepath.Join(dir, "e.md"),
+		filepath.Join(dir, "new.md"),
+		filepath.Join(dir, "old.md"),
+		filepath.Join(dir, "sub"),
+	})
+
+	if want := []string{"a.md", "e.md", "new.md"}; !slices.Equal(changes.Changed, want) {
+		t.Errorf("changed = %v, want %v", changes.Changed, want)
+	}
+	want := []string{"old.md", filepath.Join("sub", "c.md"), filepath.Join("sub", "d.md")}
+	if !slices.Equal(changes.Removed, want) {
+		t.Errorf("removed = %v, want %v", changes.Removed, want)
+	}
+	if len(changes.Renamed) != 1 || changes.Renamed["old.md"] != "new.md" {
+		t.Errorf("renamed = %v, want old.md -> new.md", changes.Renamed)
+	}
+	if _, tracked := s.Entries[filepath.Join("sub", "c.md")]; tracked {
+		t.Error("the files of the removed directory are still tracked")
+	}
+
+	// Events for files that didn't change report nothing
+	if changes := s.Apply(dir, []string{filepath.Join(dir, "e.md")}); !changes.Empty() {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
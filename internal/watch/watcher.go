@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,8 +16,9 @@ import (
 
 const DefaultDebounce = 300 * time.Millisecond
 
-// RebuildFunc is the callback invoked when file changes are detected.
-type RebuildFunc func() error
+// RebuildFunc is the callback invoked when file changes are detected, with
+// the files changed since the previous call.
+type RebuildFunc func(changes Changes) error
 
 // Watcher monitors InputDir for filesystem changes and calls OnRebuild after
 // a debounce period of inactivity.
@@ -24,14 +26,16 @@ type Watcher struct {
 	InputDir  string
 	Debounce  time.Duration
 	Log       *slog.Logger
+	Store     *MtimeStore // Files known to the last build, read from InputDir when nil
 	OnRebuild RebuildFunc
 }
 
 // Watch creates an fsnotify watcher, recursively adds all directories under
 // InputDir (skipping dotfiles and _hidden_ prefixed paths), and runs an event
 // loop until ctx is cancelled. Write, Create, Remove, and Rename events reset
-// a debounce timer; when the timer fires, OnRebuild is called. Newly created
-// directories are automatically added to the watcher.
+// a debounce timer and collect their paths; when the timer fires, the paths
+// are compared against the Store and OnRebuild is called with the changes,
+// if any. Newly created directories are automatically added to the watcher.
 func (w *Watcher) Watch(ctx context.Context) error {
 	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -43,6 +47,14 @@ func (w *Watcher) Watch(ctx context.Context) error {
 		return err
 	}
 
+	if w.Store == nil {
+		w.Store = NewMtimeStore()
+		if _, _, err := w.Store.Update(w.InputDir); err != nil {
+			return err
+		}
+	}
+	pending := map[string]struct{}{}
+
 	debounce := w.Debounce
 	if debounce == 0 {
 		debounce = DefaultDebounce
@@ -81,6 +93,7 @@ func (w *Watcher) Watch(ctx context.Context) error {
 				w.tryAddDir(fsw, event.Name, log)
 			}
 
+			pending[event.Name] = struct{}{}
 			timer.Reset(debounce)
 
 		case watchErr, ok := <-fsw.Errors:
@@ -90,8 +103,26 @@ func (w *Watcher) Watch(ctx context.Context) error {
 			log.Warn("fsnotify error", "err", watchErr)
 
 		case <-timer.C:
-			log.Info("rebuilding after file change")
-			if rebuildErr := w.OnRebuild(); rebuildErr != nil {
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			slices.Sort(paths)
+			clear(pending)
+
+			changes := w.Store.Apply(w.InputDir, paths)
+			if changes.Empty() {
+				log.Debug("no file changed", "events", len(paths))
+				continue
+			}
+			for old, renamed := range changes.Renamed {
+				log.Info("file renamed", "from", old, "to", renamed)
+			}
+			log.Info("rebuilding after file change",
+				"changed", len(changes.Changed),
+				"removed", len(changes.Removed),
+			)
+			if rebuildErr := w.OnRebuild(changes); rebuildErr != nil {
 				log.Error("rebuild failed", "err", rebuildErr)
 			}
 		}
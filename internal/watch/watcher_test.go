@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -20,7 +21,7 @@ func TestWatchDetectsFileChange(t *testing.T) {
 	w := &Watcher{
 		InputDir: dir,
 		Debounce: 50 * time.Millisecond,
-		OnRebuild: func() error {
+		OnRebuild: func(Changes) error {
 			called.Add(1)
 			select {
 			case done <- struct{}{}:
@@ -67,7 +68,7 @@ func TestWatchDebounce(t *testing.T) {
 	w := &Watcher{
 		InputDir: dir,
 		Debounce: 200 * time.Millisecond,
-		OnRebuild: func() error {
+		OnRebuild: func(Changes) error {
 			called.Add(1)
 			select {
 			case done <- struct{}{}:
@@ -117,7 +118,7 @@ func TestWatchContextCancellation(t *testing.T) {
 	w := &Watcher{
 		InputDir: dir,
 		Debounce: 50 * time.Millisecond,
-		OnRebuild: func() error {
+		OnRebuild: func(Changes) error {
 			t.Error("callback should not fire")
 			return nil
 		},
@@ -151,7 +152,7 @@ func TestWatchNewSubdirectory(t *testing.T) {
 	w := &Watcher{
 		InputDir: dir,
 		Debounce: 50 * time.Millisecond,
-		OnRebuild: func() error {
+		OnRebuild: func(Changes) error {
 			called.Add(1)
 			select {
 			case done <- struct{}{}:
@@ -188,3 +189,54 @@ func TestWatchNewSubdirectory(t *testing.T) {
 		t.Errorf("Watch returned error: %v", err)
 	}
 }
+
+func TestWatchReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "old.md"), "moved note")
+	writeFile(t, filepath.Join(dir, "b.md"), "bravo")
+
+	got := make(chan Changes, 1)
+	w := &Watcher{
+		InputDir: dir,
+		Debounce: 50 * time.Millisecond,
+		OnRebuild: func(changes Changes) error {
+			got <- changes
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Watch(ctx) }()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.Rename(filepath.Join(dir, "old.md"), filepath.Join(dir, "new.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changes := <-got:
+		if !slices.Equal(changes.Changed, []string{"new.md"}) {
+			t.Errorf("changed = %v, want [new.md]", changes.Changed)
+		}
+		if !slices.Equal(changes.Removed, []string{"b.md", "old.md"}) {
+			t.Errorf("removed = %v, want [b.md old.md]", changes.Removed)
+		}
+		if changes.Renamed["old.md"] != "new.md" {
+			t.Errorf("renamed = %v, want old.md -> new.md", changes.Renamed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback did not fire")
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("Watch returned error: %v", err)
+	}
+}